@@ -15,22 +15,40 @@ import (
 
 	"github.com/hibiken/asynq"
 
+	"github.com/Proton-105/himera-bot/internal/alerts"
 	"github.com/Proton-105/himera-bot/internal/bot"
+	"github.com/Proton-105/himera-bot/internal/dca"
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+	"github.com/Proton-105/himera-bot/internal/exits"
 	"github.com/Proton-105/himera-bot/internal/health"
+	"github.com/Proton-105/himera-bot/internal/history"
 	"github.com/Proton-105/himera-bot/internal/i18n"
 	"github.com/Proton-105/himera-bot/internal/idempotency"
 	"github.com/Proton-105/himera-bot/internal/jobs"
 	"github.com/Proton-105/himera-bot/internal/jobs/handlers"
+	"github.com/Proton-105/himera-bot/internal/leader"
+	"github.com/Proton-105/himera-bot/internal/ledger"
 	"github.com/Proton-105/himera-bot/internal/lifecycle"
+	"github.com/Proton-105/himera-bot/internal/market"
 	"github.com/Proton-105/himera-bot/internal/middleware"
+	"github.com/Proton-105/himera-bot/internal/orders"
+	"github.com/Proton-105/himera-bot/internal/portfolio"
+	"github.com/Proton-105/himera-bot/internal/pricecache"
 	"github.com/Proton-105/himera-bot/internal/ratelimit"
 	"github.com/Proton-105/himera-bot/internal/repository"
 	"github.com/Proton-105/himera-bot/internal/state"
+	"github.com/Proton-105/himera-bot/internal/tokens"
+	"github.com/Proton-105/himera-bot/internal/toptokens"
+	"github.com/Proton-105/himera-bot/internal/trade"
 	"github.com/Proton-105/himera-bot/internal/user"
 	"github.com/Proton-105/himera-bot/internal/usercache"
+	"github.com/Proton-105/himera-bot/internal/watchlist"
+	"github.com/Proton-105/himera-bot/pkg/coingecko"
 	"github.com/Proton-105/himera-bot/pkg/config"
+	"github.com/Proton-105/himera-bot/pkg/dexscreener"
 	"github.com/Proton-105/himera-bot/pkg/logger"
 	"github.com/Proton-105/himera-bot/pkg/metrics"
+	"github.com/Proton-105/himera-bot/pkg/money"
 	redisclient "github.com/Proton-105/himera-bot/pkg/redis"
 	"github.com/fsnotify/fsnotify"
 	"github.com/getsentry/sentry-go"
@@ -110,6 +128,39 @@ func run() int {
 	userCache := usercache.NewCache(coreRedisClient.Raw())
 	userRepo := repository.NewUserRepository(db, log, userCache)
 	userService := user.NewService(userRepo, log)
+	tradeRepo := repository.NewTradeRepository(db, log, userCache)
+	positionRepo := repository.NewPositionRepository(db, log)
+	ledgerRepo := repository.NewLedgerRepository(db, log, userCache)
+	priceHistoryRepo := repository.NewPriceHistoryRepository(db, log)
+	dexClient := dexscreener.NewClient(cfg.API.DexScreenerURL, cfg.API.Timeout, log.With(slog.String("component", "dexscreener")))
+	coinGeckoClient := coingecko.NewClient(cfg.API.CoinGeckoURL, cfg.API.Timeout, log.With(slog.String("component", "coingecko")))
+	priceProviders := []market.PriceProvider{dexClient, coinGeckoClient}
+	// Trades need two agreeing feeds; cached valuations accept whichever feeds are up.
+	tradePrices := market.NewAggregator(priceProviders, market.AggregatorConfig{MinSources: trade.MinPriceSources}, log.With(slog.String("component", "prices")))
+	cachePrices := market.NewAggregator(priceProviders, market.AggregatorConfig{MinSources: 1}, log.With(slog.String("component", "prices")))
+	priceCache := pricecache.NewCache(coreRedisClient.Raw(), cfg.Prices.StaleAfter)
+	tokenRepo := repository.NewTokenRepository(db, log)
+	tokenSearch := tokens.NewService(tokenRepo, dexClient, log.With(slog.String("component", "tokens")))
+	executionModel, err := trade.NewExecutionModel(feeTiers(cfg.Trading.FeeTiers), cfg.Trading.MaxPriceImpactBps)
+	if err != nil {
+		log.Error("invalid trading configuration", "error", err)
+		return 0
+	}
+	tradeService := trade.NewService(tradeRepo, positionRepo, market.NewSource(dexClient, tradePrices), priceCache, tokenSearch, executionModel, log)
+	portfolioService := portfolio.NewService(positionRepo, priceCache, log)
+	historySessions := history.NewSessionStore(coreRedisClient.Raw(), 30*time.Minute)
+	historyService := history.NewService(tradeRepo, tradeService, historySessions, log)
+	orderRepo := repository.NewOrderRepository(db, log, userCache)
+	ordersService := orders.NewService(orderRepo, tradeService, log.With(slog.String("component", "orders")))
+	exitRepo := repository.NewPositionExitRepository(db, log, userCache)
+	exitsService := exits.NewService(exitRepo, tradeService, log.With(slog.String("component", "exits")))
+	dcaRepo := repository.NewDCARepository(db, log, userCache)
+	dcaService := dca.NewService(dcaRepo, tradeService, userService, log.With(slog.String("component", "dca")))
+	alertRepo := repository.NewAlertRepository(db, log)
+	alertsService := alerts.NewService(alertRepo, tradeService, userService, log.With(slog.String("component", "alerts")))
+	watchlistRepo := repository.NewWatchlistRepository(db, log)
+	watchlistService := watchlist.NewService(watchlistRepo, tradeService, priceCache, priceHistoryRepo, log.With(slog.String("component", "watchlist")))
+	topTokens := toptokens.NewService(tokenRepo, priceCache, priceHistoryRepo, toptokens.NewCache(coreRedisClient.Raw()), log.With(slog.String("component", "top_tokens")))
 	shutdownCoordinator.Register("redis-close", func(ctx context.Context) error {
 		if redisClient == nil {
 			return nil
@@ -127,9 +178,25 @@ func run() int {
 		DB:       cfg.Redis.DB,
 	}
 
-	jobManager := jobs.NewManager(redisConnectionOpt, log)
-	jobWorker := jobs.NewWorker(redisConnectionOpt, cfg.Jobs.Queues.ToMap(), log)
-	jobScheduler := jobs.NewScheduler(redisConnectionOpt, log)
+	var (
+		jobManager   jobs.Manager
+		jobScheduler jobs.Scheduler
+		jobInspector *asynq.Inspector
+		memoryJobs   *jobs.MemoryBackend
+	)
+	if cfg.Jobs.Backend == "memory" {
+		memoryJobs = jobs.NewMemoryBackend(jobs.MemoryOptions{
+			Queues: cfg.Jobs.Queues.ToMap(),
+			Log:    log,
+		})
+		jobManager = memoryJobs.Manager()
+		jobScheduler = memoryJobs.Scheduler()
+		log.Warn("using in-memory job backend, queued tasks are lost on restart")
+	} else {
+		jobManager = jobs.NewManager(redisConnectionOpt, log)
+		jobScheduler = jobs.NewScheduler(redisConnectionOpt, log)
+		jobInspector = asynq.NewInspector(redisConnectionOpt)
+	}
 
 	shutdownCoordinator.Register("jobs-manager-close", func(ctx context.Context) error {
 		if jobManager == nil {
@@ -137,12 +204,11 @@ func run() int {
 		}
 		return jobManager.Close()
 	})
-	shutdownCoordinator.Register("jobs-worker-shutdown", func(ctx context.Context) error {
-		if jobWorker == nil {
+	shutdownCoordinator.Register("jobs-inspector-close", func(ctx context.Context) error {
+		if jobInspector == nil {
 			return nil
 		}
-		jobWorker.Shutdown()
-		return nil
+		return jobInspector.Close()
 	})
 	shutdownCoordinator.Register("jobs-scheduler-shutdown", func(ctx context.Context) error {
 		if jobScheduler == nil {
@@ -156,19 +222,34 @@ func run() int {
 	fsm := state.NewStateMachine(stateStorage, log, coreRedisClient.Raw())
 	log.Info("state machine initialized")
 
+	// Loops that scan shared Redis keys or enqueue cron tasks run only on the leader replica.
+	elector := leader.NewElector(coreRedisClient.Raw(), "singletons", leader.DefaultLeaseTTL, log.With(slog.String("component", "leader")))
+
 	stateCollector := metrics.NewStateCollector(fsm)
-	go stateCollector.Run(ctx)
-	log.Info("state metrics collector started")
+	elector.Add("state_collector", stateCollector.Run)
+	log.Info("state metrics collector registered")
 
 	idempotencyStore := idempotency.NewRedisStore(coreRedisClient.Raw(), log)
 	idempotencyManager := idempotency.NewManager(idempotencyStore, log)
 	idempotencyCleaner := idempotency.NewCleaner(coreRedisClient.Raw(), log, time.Hour)
-	go idempotencyCleaner.Run(ctx)
-	log.Info("idempotency cleaner started")
+	elector.Add("idempotency_cleaner", idempotencyCleaner.Run)
+	log.Info("idempotency cleaner registered")
 
 	cleaner := state.NewCleaner(coreRedisClient.Raw(), stateStorage, log, time.Hour, 5*time.Minute)
-	go cleaner.Run(ctx)
-	log.Info("state cleaner started", slog.Duration("ttl", time.Hour), slog.Duration("interval", 5*time.Minute))
+	elector.Add("state_cleaner", cleaner.Run)
+	log.Info("state cleaner registered", slog.Duration("ttl", time.Hour), slog.Duration("interval", 5*time.Minute))
+
+	priceSubscriber := pricecache.NewSubscriber(coreRedisClient.Raw(), log.With(slog.String("component", "prices")))
+	go func() {
+		if err := priceSubscriber.Run(ctx); err != nil {
+			log.Error("price update subscriber stopped", slog.Any("error", err))
+		}
+	}()
+	log.Info("price update subscriber started", slog.String("channel", pricecache.UpdatesChannel))
+
+	ledgerReconciler := ledger.NewReconciler(ledgerRepo, log.With(slog.String("component", "ledger")), time.Hour)
+	elector.Add("ledger_reconciler", ledgerReconciler.Run)
+	log.Info("ledger reconciler registered", slog.Duration("interval", time.Hour))
 
 	rules := ratelimit.NewRules(cfg.RateLimit)
 	redisLimiter := ratelimit.NewRedisLimiter(coreRedisClient.Raw(), log)
@@ -176,21 +257,170 @@ func run() int {
 	adaptiveLimiter := ratelimit.NewAdaptiveLimiter(redisLimiter, memoryLimiter, log)
 	rateLimitMw := middleware.NewRateLimitMiddleware(adaptiveLimiter, rules, log)
 	rateLimitCleaner := ratelimit.NewCleaner(coreRedisClient.Raw(), log, time.Minute)
-	go rateLimitCleaner.Run(ctx)
-	log.Info("rate limit cleaner started", slog.Duration("interval", time.Minute))
+	elector.Add("ratelimit_cleaner", rateLimitCleaner.Run)
+	log.Info("rate limit cleaner registered", slog.Duration("interval", time.Minute))
 
 	jobLog := log.With(slog.String("component", "jobs"))
+	deadLetterRepo := repository.NewDeadLetterRepository(db, log)
+	// Archived tasks only exist in Redis; the in-memory backend requeues from the stored payload.
+	var archivedTasks jobs.TaskRunner
+	if jobInspector != nil {
+		archivedTasks = jobInspector
+	}
+	deadLetterRequeuer := jobs.NewDeadLetterRequeuer(jobLog, deadLetterRepo, archivedTasks, jobManager)
+
+	i18nManager, err := i18n.Load("ru")
+	if err != nil {
+		log.Error("failed to load translations", "error", err)
+		return 0
+	}
+
+	tgBot, err := bot.New(*cfg, log, db, fsm, idempotencyManager, rateLimitMw, userRepo, userService, tradeService, tokenSearch, portfolioService, historyService, ordersService, exitsService, dcaService, alertsService, watchlistService, topTokens, i18nManager, deadLetterRequeuer)
+	if err != nil {
+		log.Error("failed to create telegram bot", "error", err)
+		return 0
+	}
+
+	// Fills notify their owners in Telegram, so the matcher is built after the bot.
+	orderMatcher := orders.NewMatcher(orderRepo, tradeService, priceSubscriber, tgBot, elector, log.With(slog.String("component", "orders")))
+	elector.Add("order_matcher", orderMatcher.Run)
+	log.Info("limit order matcher registered")
+
+	exitEvaluator := exits.NewEvaluator(exitRepo, tradeService, priceSubscriber, tgBot, elector, log.With(slog.String("component", "exits")))
+	elector.Add("exit_evaluator", exitEvaluator.Run)
+	log.Info("position exit evaluator registered")
+
+	alertEvaluator := alerts.NewEvaluator(alertRepo, priceHistoryRepo, userService, priceSubscriber, tgBot, log.With(slog.String("component", "alerts")))
+	elector.Add("alert_evaluator", alertEvaluator.Run)
+	log.Info("price alert evaluator registered")
+
+	// The worker is built after the bot so that terminal job failures can alert admins in Telegram.
+	deadLetterHandler := jobs.NewDeadLetterHandler(
+		jobLog,
+		deadLetterRepo,
+		apperrors.NewHandler(log, cfg.Sentry.Enabled),
+		tgBot,
+	)
+	var jobWorker jobs.Worker
+	if memoryJobs != nil {
+		jobWorker = memoryJobs.Worker(deadLetterHandler)
+	} else {
+		jobWorker = jobs.NewWorker(redisConnectionOpt, cfg.Jobs.Queues.ToMap(), deadLetterHandler, log)
+	}
+	shutdownCoordinator.Register("jobs-worker-shutdown", func(ctx context.Context) error {
+		if jobWorker == nil {
+			return nil
+		}
+		jobWorker.Shutdown()
+		return nil
+	})
 
 	if cfg.Jobs.Enabled {
-		priceUpdateHandler := handlers.NewPriceUpdateHandler(jobLog.With(slog.String("handler", "price_update")))
+		jobTimeouts, err := jobs.ParseTimeouts(cfg.Jobs.Timeouts)
+		if err != nil {
+			jobLog.Error("invalid job timeouts", slog.Any("error", err), slog.Any("known_task_types", jobs.KnownTaskTypes()))
+			return 0
+		}
+		jobWorker.Use(
+			jobs.CorrelationMiddleware(),
+			jobs.LoggingMiddleware(jobLog),
+			jobs.MetricsMiddleware(),
+			jobs.RecoveryMiddleware(jobLog),
+			jobs.TimeoutMiddleware(jobTimeouts),
+		)
+
+		priceUpdateHandler := handlers.NewPriceUpdateHandler(
+			jobLog.With(slog.String("handler", "price_update")),
+			cachePrices,
+			priceCache,
+			priceHistoryRepo,
+			handlers.TokenListers{positionRepo, watchlistRepo, tokenRepo},
+			cfg.Prices.TTL,
+		)
 		jobWorker.RegisterHandler(jobs.TaskTypePriceUpdate, priceUpdateHandler)
-
-		if err := jobScheduler.RegisterTasks(); err != nil {
+		candleRollupHandler := handlers.NewCandleRollupHandler(
+			jobLog.With(slog.String("handler", "candle_rollup")),
+			priceHistoryRepo,
+		)
+		jobWorker.RegisterHandler(jobs.TaskTypeCandleRollup, candleRollupHandler)
+
+		cleanupTargets := map[string]handlers.Purger{"idempotency": idempotencyCleaner}
+		for _, table := range repository.RetentionTables() {
+			purger, err := repository.NewRetentionRepository(db, log, table)
+			if err != nil {
+				jobLog.Error("failed to create retention purger", slog.String("table", table), slog.Any("error", err))
+				return 0
+			}
+			cleanupTargets[table] = purger
+		}
+		cleanupHandler, err := handlers.NewCleanupHandler(
+			jobLog.With(slog.String("handler", "cleanup")),
+			cleanupTargets,
+			cfg.Jobs.Cleanup.Retention,
+			cfg.Jobs.Cleanup.BatchSize,
+		)
+		if err != nil {
+			jobLog.Error("invalid cleanup configuration", slog.Any("error", err))
+			return 0
+		}
+		jobWorker.RegisterHandler(jobs.TaskTypeCleanupData, cleanupHandler)
+		tokenSyncHandler := handlers.NewTokenSyncHandler(
+			jobLog.With(slog.String("handler", "token_sync")),
+			dexClient,
+			tokenRepo,
+			handlers.TokenListers{positionRepo, watchlistRepo, tokenRepo},
+		)
+		jobWorker.RegisterHandler(jobs.TaskTypeTokenSync, tokenSyncHandler)
+		topTokensHandler := handlers.NewTopTokensHandler(
+			jobLog.With(slog.String("handler", "top_tokens")),
+			topTokens,
+		)
+		jobWorker.RegisterHandler(jobs.TaskTypeTopTokens, topTokensHandler)
+
+		dcaRunner := dca.NewRunner(dcaRepo, tradeService, tgBot, log.With(slog.String("component", "dca")))
+		jobWorker.RegisterHandler(jobs.TaskTypeDCABuy, handlers.NewDCABuyHandler(jobLog.With(slog.String("handler", "dca_buy")), dcaRunner))
+		dcaScheduler := dca.NewScheduler(dcaRepo, jobManager, userService, elector, log.With(slog.String("component", "dca")))
+		elector.Add("dca_scheduler", dcaScheduler.Run)
+
+		schedules, err := jobs.ParseSchedules(cfg.Jobs.Schedules)
+		if err != nil {
+			jobLog.Error("invalid job schedules", slog.Any("error", err), slog.Any("known_task_types", jobs.KnownTaskTypes()))
+			return 0
+		}
+		if err := jobScheduler.Apply(schedules); err != nil {
 			jobLog.Error("failed to register scheduled jobs", slog.Any("error", err))
-		} else {
-			jobScheduler.Run()
-			jobLog.Info("scheduler started")
 		}
+		elector.Add("job_scheduler", func(ctx context.Context) {
+			jobScheduler.Run()
+			<-ctx.Done()
+			jobScheduler.Shutdown()
+		})
+		jobLog.Info("scheduler registered", slog.Int("entries", len(schedules)))
+
+		config.WatchSections(v, func(event fsnotify.Event, err error) {
+			if err != nil {
+				configLog.Error("failed to reload jobs config", slog.String("event", event.String()), slog.Any("error", err))
+				return
+			}
+
+			var entries []config.JobScheduleConfig
+			if err := v.UnmarshalKey("jobs.schedules", &entries); err != nil {
+				configLog.Error("failed to decode job schedules", slog.Any("error", err))
+				return
+			}
+
+			// A bad edit keeps the current schedules running.
+			schedules, err := jobs.ParseSchedules(entries)
+			if err != nil {
+				configLog.Error("rejected job schedules", slog.Any("error", err))
+				return
+			}
+			if err := jobScheduler.Apply(schedules); err != nil {
+				configLog.Error("failed to apply job schedules", slog.Any("error", err))
+				return
+			}
+			configLog.Info("job schedules reloaded", slog.Int("entries", len(schedules)))
+		})
 
 		go func() {
 			if err := jobWorker.Run(); err != nil {
@@ -203,6 +433,8 @@ func run() int {
 		jobLog.Info("background jobs disabled, skipping worker and scheduler")
 	}
 
+	go elector.Run(ctx)
+
 	log.Info("performing test redis operations for metrics")
 	if err := redisClient.Set(ctx, "test_key", "test_value", 10*time.Second); err != nil {
 		log.Error("redis set error", "error", err, slog.String("key", "test_key"))
@@ -217,18 +449,6 @@ func run() int {
 		log.Error("redis delete error", "error", err, slog.String("key", "test_key"))
 	}
 
-	i18nManager, err := i18n.Load("ru")
-	if err != nil {
-		log.Error("failed to load translations", "error", err)
-		return 0
-	}
-
-	tgBot, err := bot.New(*cfg, log, db, fsm, idempotencyManager, rateLimitMw, userRepo, userService, i18nManager)
-	if err != nil {
-		log.Error("failed to create telegram bot", "error", err)
-		return 0
-	}
-
 	checker := health.NewChecker(log)
 	checker.AddCheck("database", health.NewDBChecker(db))
 	checker.AddCheck("redis", health.NewRedisChecker(coreRedisClient))
@@ -242,6 +462,15 @@ func run() int {
 	go func() {
 		mux := http.NewServeMux()
 		mux.Handle("/metrics", logger.Middleware(loggingMiddleware(promhttp.Handler())))
+		switch {
+		case jobInspector == nil:
+			metricsLog.Info("job admin endpoints require the redis job backend")
+		case cfg.Server.AdminToken != "":
+			adminLog := metricsLog.With(slog.String("component", "jobs_admin"))
+			mux.Handle(jobs.AdminPathPrefix, logger.Middleware(loggingMiddleware(jobs.NewAdminHandler(jobInspector, cfg.Server.AdminToken, adminLog))))
+		default:
+			metricsLog.Warn("admin token not set, job admin endpoints disabled")
+		}
 		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 			results := checker.Check(r.Context())
 
@@ -292,6 +521,10 @@ func run() int {
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	shutdownStart := time.Now()
+	// The lease is released over Redis, so the elector must finish before the hooks close it.
+	if err := elector.Wait(shutdownCtx); err != nil {
+		log.Error("leader election did not stop in time", slog.Any("error", err))
+	}
 	if err := shutdownCoordinator.Execute(shutdownCtx); err != nil {
 		log.Error("shutdown completed with errors", slog.Any("error", err), slog.Duration("elapsed", time.Since(shutdownStart)))
 		cancel()
@@ -302,3 +535,12 @@ func run() int {
 	cancel()
 	return 0
 }
+
+// feeTiers converts the configured fee schedule into execution model tiers.
+func feeTiers(configured []config.FeeTierConfig) []trade.FeeTier {
+	tiers := make([]trade.FeeTier, 0, len(configured))
+	for _, tier := range configured {
+		tiers = append(tiers, trade.FeeTier{MinUSD: money.NewFromInt(tier.MinUSD), FeeBps: tier.FeeBps})
+	}
+	return tiers
+}
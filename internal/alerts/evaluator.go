@@ -0,0 +1,262 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/pricecache"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// PriceFeed delivers price cache updates; pricecache.Subscriber implements it.
+type PriceFeed interface {
+	Subscribe(listener pricecache.Listener) (unsubscribe func())
+}
+
+// PriceHistory looks up past prices for move alerts;
+// repository.PriceHistoryRepository implements it.
+type PriceHistory interface {
+	LastCandleBefore(ctx context.Context, tokenAddress string, interval domain.CandleInterval, before time.Time) (*domain.Candle, error)
+}
+
+// Notifier delivers a message to a user in Telegram.
+type Notifier interface {
+	NotifyUser(ctx context.Context, telegramID int64, text string) error
+}
+
+// Evaluator fires alerts whose condition a price update meets. It must run
+// on a single instance at a time; firing is still recorded atomically, so a
+// second instance never notifies twice.
+type Evaluator struct {
+	repo     repository.AlertRepository
+	history  PriceHistory
+	settings Settings
+	feed     PriceFeed
+	notifier Notifier
+	log      *slog.Logger
+	now      func() time.Time
+}
+
+// NewEvaluator constructs an Evaluator. settings may be nil, in which case
+// every user receives notifications; notifier may be nil.
+func NewEvaluator(repo repository.AlertRepository, history PriceHistory, settings Settings, feed PriceFeed, notifier Notifier, log *slog.Logger) *Evaluator {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Evaluator{
+		repo:     repo,
+		history:  history,
+		settings: settings,
+		feed:     feed,
+		notifier: notifier,
+		log:      log,
+		now:      time.Now,
+	}
+}
+
+// Run evaluates alerts on every price update until ctx is cancelled.
+func (e *Evaluator) Run(ctx context.Context) {
+	updates := pricecache.NewQueue()
+	unsubscribe := e.feed.Subscribe(updates.Push)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-updates.Ready():
+			for _, quote := range updates.Drain() {
+				if ctx.Err() != nil {
+					return
+				}
+				e.Evaluate(ctx, quote)
+			}
+		}
+	}
+}
+
+// Evaluate fires the alerts on the quote's token whose condition the quote
+// meets and returns how many it fired. Disarmed alerts whose condition no
+// longer holds are re-armed. Alerts of users who turned notifications off
+// stay armed until they turn them on again.
+func (e *Evaluator) Evaluate(ctx context.Context, quote *domain.PriceQuote) int {
+	if quote == nil || quote.TokenAddress == "" || quote.PriceUSD.Sign() <= 0 {
+		return 0
+	}
+
+	alerts, err := e.repo.ListActiveByToken(ctx, quote.TokenAddress)
+	if err != nil {
+		e.log.ErrorContext(ctx, "alert evaluator failed to list alerts", slog.String("token_address", quote.TokenAddress), slog.Any("error", err))
+		return 0
+	}
+	if len(alerts) == 0 {
+		return 0
+	}
+
+	now := e.now()
+	references := make(map[time.Duration]money.Decimal)
+	recipients := make(map[int64]bool)
+
+	fired := 0
+	for _, alert := range alerts {
+		var reference money.Decimal
+		if alert.IsMove() {
+			reference = e.reference(ctx, quote.TokenAddress, alert.Window, now, references)
+		}
+
+		holds := alert.Holds(quote.PriceUSD, reference)
+		if !alert.Armed {
+			if !holds && (!alert.IsMove() || reference.Sign() > 0) {
+				e.rearm(ctx, alert)
+			}
+			continue
+		}
+		if !holds || alert.CoolingDown(now) {
+			continue
+		}
+
+		enabled, ok := recipients[alert.TelegramID]
+		if !ok {
+			enabled, err = notificationsEnabled(ctx, e.settings, alert.TelegramID)
+			if err != nil {
+				e.log.WarnContext(ctx, "alert evaluator failed to load settings", slog.Int64("telegram_id", alert.TelegramID), slog.Any("error", err))
+				continue
+			}
+			recipients[alert.TelegramID] = enabled
+		}
+		if !enabled {
+			continue
+		}
+
+		if e.fire(ctx, alert, quote.PriceUSD, reference, now) {
+			fired++
+		}
+	}
+
+	return fired
+}
+
+// reference returns the price window before now from one-minute candles,
+// caching it per window for the current update. It is zero when no candle
+// is recent enough.
+func (e *Evaluator) reference(ctx context.Context, tokenAddress string, window time.Duration, now time.Time, cache map[time.Duration]money.Decimal) money.Decimal {
+	if price, ok := cache[window]; ok {
+		return price
+	}
+
+	start := now.Add(-window)
+	candle, err := e.history.LastCandleBefore(ctx, tokenAddress, domain.CandleInterval1m, start.Add(time.Minute))
+	if err != nil {
+		e.log.WarnContext(ctx, "alert evaluator failed to load reference price", slog.String("token_address", tokenAddress), slog.Any("error", err))
+		return money.Zero
+	}
+
+	price := money.Zero
+	if candle != nil && !candle.OpenTime.Before(start.Add(-window)) {
+		price = candle.Close
+	}
+	cache[window] = price
+
+	return price
+}
+
+func (e *Evaluator) rearm(ctx context.Context, alert *domain.Alert) {
+	if err := e.repo.Rearm(ctx, alert.ID); err != nil {
+		e.log.ErrorContext(ctx, "alert evaluator failed to rearm alert", slog.Int64("alert_id", alert.ID), slog.Any("error", err))
+	}
+}
+
+func (e *Evaluator) fire(ctx context.Context, alert *domain.Alert, price, reference money.Decimal, now time.Time) bool {
+	fired, err := e.repo.Trigger(ctx, alert.ID, now)
+	if err != nil {
+		e.log.ErrorContext(ctx, "alert evaluator failed to trigger alert", slog.Int64("alert_id", alert.ID), slog.Any("error", err))
+		return false
+	}
+	if !fired {
+		// Deleted, fired or cooling down since it was listed.
+		return false
+	}
+
+	e.log.InfoContext(ctx, "price alert triggered",
+		slog.Int64("telegram_id", alert.TelegramID),
+		slog.Int64("alert_id", alert.ID),
+		slog.String("kind", string(alert.Kind)),
+		slog.String("price_usd", price.String()),
+	)
+
+	if e.notifier != nil {
+		if err := e.notifier.NotifyUser(ctx, alert.TelegramID, triggeredMessage(alert, price, reference)); err != nil {
+			e.log.WarnContext(ctx, "alert evaluator failed to notify user", slog.Int64("telegram_id", alert.TelegramID), slog.Any("error", err))
+		}
+	}
+
+	return true
+}
+
+// Describe summarises an alert for users, e.g. "PEPE above $1.25, repeating every 1h".
+func Describe(alert *domain.Alert) string {
+	var b strings.Builder
+	b.WriteString(tokenLabel(alert))
+
+	switch alert.Kind {
+	case domain.AlertKindAbove:
+		fmt.Fprintf(&b, " above $%s", alert.PriceUSD.StringTrimmed(12, money.RoundHalfUp))
+	case domain.AlertKindBelow:
+		fmt.Fprintf(&b, " below $%s", alert.PriceUSD.StringTrimmed(12, money.RoundHalfUp))
+	case domain.AlertKindRise:
+		fmt.Fprintf(&b, " up %d%% within %s", alert.Percent, formatDuration(alert.Window))
+	case domain.AlertKindFall:
+		fmt.Fprintf(&b, " down %d%% within %s", alert.Percent, formatDuration(alert.Window))
+	}
+
+	if alert.Repeating {
+		fmt.Fprintf(&b, ", repeating every %s", formatDuration(alert.Cooldown))
+	} else {
+		b.WriteString(", once")
+	}
+
+	return b.String()
+}
+
+func triggeredMessage(alert *domain.Alert, price, reference money.Decimal) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔔 Alert #%d: ", alert.ID)
+
+	token := tokenLabel(alert)
+	switch alert.Kind {
+	case domain.AlertKindAbove:
+		fmt.Fprintf(&b, "%s rose above $%s\n", token, alert.PriceUSD.StringTrimmed(12, money.RoundHalfUp))
+	case domain.AlertKindBelow:
+		fmt.Fprintf(&b, "%s fell below $%s\n", token, alert.PriceUSD.StringTrimmed(12, money.RoundHalfUp))
+	case domain.AlertKindRise, domain.AlertKindFall:
+		change := price.Sub(reference).Mul(money.New(100, 0))
+		if quotient, err := change.Quo(reference, 2, money.RoundHalfEven); err == nil {
+			change = quotient
+		}
+		sign := "+"
+		if change.Sign() < 0 {
+			sign = ""
+		}
+		fmt.Fprintf(&b, "%s moved %s%s%% in %s\n", token, sign, change.StringFixed(2, money.RoundHalfEven), formatDuration(alert.Window))
+	}
+	fmt.Fprintf(&b, "Price: $%s", price.StringTrimmed(12, money.RoundHalfUp))
+
+	if alert.Repeating {
+		fmt.Fprintf(&b, "\nThis alert repeats; next at most in %s. Manage alerts with /alerts.", formatDuration(alert.Cooldown))
+	}
+
+	return b.String()
+}
+
+func tokenLabel(alert *domain.Alert) string {
+	if alert.TokenSymbol != "" {
+		return alert.TokenSymbol
+	}
+	return alert.TokenAddress
+}
@@ -0,0 +1,240 @@
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type fakeAlertRepo struct {
+	repository.AlertRepository
+
+	alerts map[int64]*domain.Alert
+}
+
+func (r *fakeAlertRepo) ListActiveByToken(_ context.Context, tokenAddress string) ([]*domain.Alert, error) {
+	var alerts []*domain.Alert
+	for _, alert := range r.alerts {
+		if alert.TokenAddress == tokenAddress && alert.Status == domain.AlertStatusActive {
+			copied := *alert
+			alerts = append(alerts, &copied)
+		}
+	}
+	return alerts, nil
+}
+
+func (r *fakeAlertRepo) Rearm(_ context.Context, alertID int64) error {
+	if alert, ok := r.alerts[alertID]; ok && alert.Status == domain.AlertStatusActive {
+		alert.Armed = true
+	}
+	return nil
+}
+
+func (r *fakeAlertRepo) Trigger(_ context.Context, alertID int64, at time.Time) (bool, error) {
+	alert, ok := r.alerts[alertID]
+	if !ok || alert.Status != domain.AlertStatusActive || !alert.Armed || alert.CoolingDown(at) {
+		return false, nil
+	}
+
+	if !alert.Repeating {
+		alert.Status = domain.AlertStatusTriggered
+	}
+	alert.Armed = false
+	alert.TriggerCount++
+	alert.LastTriggeredAt = &at
+	return true, nil
+}
+
+type fakeHistory struct {
+	candle *domain.Candle
+}
+
+func (h *fakeHistory) LastCandleBefore(_ context.Context, _ string, _ domain.CandleInterval, before time.Time) (*domain.Candle, error) {
+	if h.candle == nil || !h.candle.OpenTime.Before(before) {
+		return nil, nil
+	}
+	return h.candle, nil
+}
+
+type fakeSettings struct {
+	muted map[int64]bool
+}
+
+func (s *fakeSettings) GetSettings(_ context.Context, telegramID int64) (*domain.UserSettings, error) {
+	muted, ok := s.muted[telegramID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &domain.UserSettings{NotificationsEnabled: !muted}, nil
+}
+
+type fakeNotifier struct {
+	messages map[int64][]string
+}
+
+func (n *fakeNotifier) NotifyUser(_ context.Context, telegramID int64, text string) error {
+	if n.messages == nil {
+		n.messages = make(map[int64][]string)
+	}
+	n.messages[telegramID] = append(n.messages[telegramID], text)
+	return nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func mustDecimal(t *testing.T, value string) money.Decimal {
+	t.Helper()
+
+	d, err := money.Parse(value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return d
+}
+
+func newEvaluator(repo *fakeAlertRepo, history *fakeHistory, settings *fakeSettings, notifier *fakeNotifier, now time.Time) *Evaluator {
+	evaluator := NewEvaluator(repo, history, settings, nil, notifier, discardLogger())
+	evaluator.now = func() time.Time { return now }
+	return evaluator
+}
+
+func quote(t *testing.T, price string) *domain.PriceQuote {
+	t.Helper()
+	return &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, price)}
+}
+
+func TestEvaluatorFiresOneShotAlertsOnce(t *testing.T) {
+	repo := &fakeAlertRepo{alerts: map[int64]*domain.Alert{
+		1: {ID: 1, TelegramID: 10, TokenAddress: "token", TokenSymbol: "PEPE", Kind: domain.AlertKindAbove, PriceUSD: mustDecimal(t, "1.25"), Armed: true, Status: domain.AlertStatusActive},
+		2: {ID: 2, TelegramID: 10, TokenAddress: "token", TokenSymbol: "PEPE", Kind: domain.AlertKindBelow, PriceUSD: mustDecimal(t, "1"), Armed: true, Status: domain.AlertStatusActive},
+	}}
+	notifier := &fakeNotifier{}
+	evaluator := newEvaluator(repo, &fakeHistory{}, &fakeSettings{}, notifier, time.Now())
+
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "1.2")); fired != 0 {
+		t.Fatalf("fired below the level: %d", fired)
+	}
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "1.3")); fired != 1 {
+		t.Fatalf("fired = %d, want 1", fired)
+	}
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "1.4")); fired != 0 {
+		t.Fatalf("one-shot alert fired again: %d", fired)
+	}
+
+	if repo.alerts[1].Status != domain.AlertStatusTriggered || repo.alerts[2].Status != domain.AlertStatusActive {
+		t.Errorf("statuses = %s / %s", repo.alerts[1].Status, repo.alerts[2].Status)
+	}
+	if msgs := notifier.messages[10]; len(msgs) != 1 || !strings.Contains(msgs[0], "PEPE rose above $1.25") || !strings.Contains(msgs[0], "Price: $1.3") {
+		t.Errorf("notifications = %q", msgs)
+	}
+}
+
+func TestEvaluatorRepeatsAfterRearmAndCooldown(t *testing.T) {
+	repo := &fakeAlertRepo{alerts: map[int64]*domain.Alert{
+		1: {ID: 1, TelegramID: 10, TokenAddress: "token", Kind: domain.AlertKindAbove, PriceUSD: mustDecimal(t, "2"), Repeating: true, Cooldown: time.Hour, Armed: true, Status: domain.AlertStatusActive},
+	}}
+	notifier := &fakeNotifier{}
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	evaluator := newEvaluator(repo, &fakeHistory{}, &fakeSettings{}, notifier, start)
+
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "2.1")); fired != 1 {
+		t.Fatalf("fired = %d, want 1", fired)
+	}
+	// Still above the level: no new crossing.
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "2.2")); fired != 0 {
+		t.Fatalf("fired without a new crossing: %d", fired)
+	}
+
+	// Dropping below re-arms, but the next crossing falls within the cooldown.
+	evaluator.Evaluate(context.Background(), quote(t, "1.9"))
+	if !repo.alerts[1].Armed {
+		t.Fatal("alert was not re-armed")
+	}
+	evaluator.now = func() time.Time { return start.Add(30 * time.Minute) }
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "2.1")); fired != 0 {
+		t.Fatalf("fired while cooling down: %d", fired)
+	}
+
+	evaluator.now = func() time.Time { return start.Add(time.Hour) }
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "2.1")); fired != 1 {
+		t.Fatalf("fired = %d after the cooldown, want 1", fired)
+	}
+	if alert := repo.alerts[1]; alert.Status != domain.AlertStatusActive || alert.TriggerCount != 2 {
+		t.Errorf("alert = %+v", alert)
+	}
+	if msgs := notifier.messages[10]; len(msgs) != 2 || !strings.Contains(msgs[1], "repeats") {
+		t.Errorf("notifications = %q", msgs)
+	}
+}
+
+func TestEvaluatorRespectsMutedNotifications(t *testing.T) {
+	repo := &fakeAlertRepo{alerts: map[int64]*domain.Alert{
+		1: {ID: 1, TelegramID: 10, TokenAddress: "token", Kind: domain.AlertKindBelow, PriceUSD: mustDecimal(t, "1"), Armed: true, Status: domain.AlertStatusActive},
+		2: {ID: 2, TelegramID: 20, TokenAddress: "token", Kind: domain.AlertKindBelow, PriceUSD: mustDecimal(t, "1"), Armed: true, Status: domain.AlertStatusActive},
+	}}
+	settings := &fakeSettings{muted: map[int64]bool{10: true}}
+	notifier := &fakeNotifier{}
+	evaluator := newEvaluator(repo, &fakeHistory{}, settings, notifier, time.Now())
+
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "0.9")); fired != 1 {
+		t.Fatalf("fired = %d, want 1", fired)
+	}
+	if len(notifier.messages[10]) != 0 || len(notifier.messages[20]) != 1 {
+		t.Fatalf("notifications = %v", notifier.messages)
+	}
+	if alert := repo.alerts[1]; alert.Status != domain.AlertStatusActive || !alert.Armed {
+		t.Fatalf("muted alert changed: %+v", alert)
+	}
+
+	// Unmuting delivers the alert while its condition still holds.
+	settings.muted[10] = false
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "0.9")); fired != 1 || len(notifier.messages[10]) != 1 {
+		t.Errorf("fired = %d, notifications = %v", fired, notifier.messages)
+	}
+}
+
+func TestEvaluatorMoveAlerts(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeAlertRepo{alerts: map[int64]*domain.Alert{
+		1: {ID: 1, TelegramID: 10, TokenAddress: "token", TokenSymbol: "PEPE", Kind: domain.AlertKindRise, Percent: 10, Window: time.Hour, Armed: true, Status: domain.AlertStatusActive},
+		2: {ID: 2, TelegramID: 10, TokenAddress: "token", TokenSymbol: "PEPE", Kind: domain.AlertKindFall, Percent: 10, Window: time.Hour, Armed: true, Status: domain.AlertStatusActive},
+	}}
+	history := &fakeHistory{}
+	notifier := &fakeNotifier{}
+	evaluator := newEvaluator(repo, history, &fakeSettings{}, notifier, now)
+
+	// Without price history there is no reference to compare against.
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "5")); fired != 0 {
+		t.Fatalf("fired without history: %d", fired)
+	}
+
+	// A candle older than twice the window is too stale to use.
+	history.candle = &domain.Candle{OpenTime: now.Add(-3 * time.Hour), Close: mustDecimal(t, "1")}
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "5")); fired != 0 {
+		t.Fatalf("fired on a stale reference: %d", fired)
+	}
+
+	history.candle = &domain.Candle{OpenTime: now.Add(-time.Hour), Close: mustDecimal(t, "2")}
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "2.1")); fired != 0 {
+		t.Fatalf("fired on a 5%% move: %d", fired)
+	}
+	if fired := evaluator.Evaluate(context.Background(), quote(t, "2.2")); fired != 1 {
+		t.Fatalf("fired = %d on a 10%% rise, want 1", fired)
+	}
+	if repo.alerts[1].Status != domain.AlertStatusTriggered || repo.alerts[2].Status != domain.AlertStatusActive {
+		t.Errorf("statuses = %s / %s", repo.alerts[1].Status, repo.alerts[2].Status)
+	}
+	if msgs := notifier.messages[10]; len(msgs) != 1 || !strings.Contains(msgs[0], "PEPE moved +10.00% in 1h") {
+		t.Errorf("notifications = %q", msgs)
+	}
+}
@@ -0,0 +1,235 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// Bounds of alert arguments. Move windows start at 15 minutes because
+// one-minute candles are rolled up every few minutes.
+const (
+	MaxAbovePercent = 1000
+	MaxBelowPercent = 99
+	MaxMovePercent  = 1000
+	MinWindow       = 15 * time.Minute
+	MaxWindow       = 24 * time.Hour
+	MinCooldown     = time.Minute
+	MaxCooldown     = 7 * 24 * time.Hour
+	// DefaultCooldown applies to repeating alerts created without a cooldown.
+	DefaultCooldown = time.Hour
+)
+
+// ErrInvalidRequest indicates that the alert arguments could not be parsed.
+var ErrInvalidRequest = errors.New("invalid alert request")
+
+// Request is a parsed /alert command. Token is the raw user input and is
+// resolved to an address by the Service. Level alerts set either PriceUSD
+// or Percent, a distance from the current price; move alerts set Percent
+// and Window.
+type Request struct {
+	Token     string
+	Kind      domain.AlertKind
+	PriceUSD  money.Decimal
+	Percent   int
+	Window    time.Duration
+	Repeating bool
+	Cooldown  time.Duration
+}
+
+// ParseRequest parses /alert arguments:
+//
+//	<token> > <price|percent> [once|repeat [cooldown]]
+//	<token> < <price|percent> [once|repeat [cooldown]]
+//	<token> +<percent> <window> [once|repeat [cooldown]]
+//	<token> -<percent> <window> [once|repeat [cooldown]]
+//
+// Prices look like 1.25 or $1.25 and percentages like 10%; a level given as
+// a percentage is that far from the current price. > and < may be written
+// as above and below, or joined to the value as in >1.25. Windows and
+// cooldowns are durations such as 30m, 4h or 1d. Alerts fire once unless
+// repeat is given; repeating alerts wait DefaultCooldown between firings
+// unless a cooldown follows.
+func ParseRequest(args []string) (Request, error) {
+	if len(args) < 2 {
+		return Request{}, fmt.Errorf("%w: expected a token and a condition", ErrInvalidRequest)
+	}
+
+	request := Request{Token: strings.TrimSpace(args[0])}
+	condition, rest := strings.ToLower(strings.TrimSpace(args[1])), args[2:]
+
+	var kind domain.AlertKind
+	switch {
+	case condition == ">" || condition == "above":
+		kind = domain.AlertKindAbove
+	case condition == "<" || condition == "below":
+		kind = domain.AlertKindBelow
+	case strings.HasPrefix(condition, ">"):
+		kind, rest = domain.AlertKindAbove, append([]string{condition[1:]}, rest...)
+	case strings.HasPrefix(condition, "<"):
+		kind, rest = domain.AlertKindBelow, append([]string{condition[1:]}, rest...)
+	case strings.HasPrefix(condition, "+"):
+		kind, rest = domain.AlertKindRise, append([]string{condition[1:]}, rest...)
+	case strings.HasPrefix(condition, "-"):
+		kind, rest = domain.AlertKindFall, append([]string{condition[1:]}, rest...)
+	default:
+		return Request{}, fmt.Errorf("%w: unknown condition %q", ErrInvalidRequest, args[1])
+	}
+	request.Kind = kind
+
+	if len(rest) == 0 {
+		return Request{}, fmt.Errorf("%w: missing value", ErrInvalidRequest)
+	}
+	value := rest[0]
+	rest = rest[1:]
+
+	switch kind {
+	case domain.AlertKindAbove, domain.AlertKindBelow:
+		maxPercent := MaxAbovePercent
+		if kind == domain.AlertKindBelow {
+			maxPercent = MaxBelowPercent
+		}
+		if strings.HasSuffix(value, "%") {
+			percent, err := parsePercent(value, maxPercent)
+			if err != nil {
+				return Request{}, err
+			}
+			request.Percent = percent
+		} else {
+			price, err := parsePrice(value)
+			if err != nil {
+				return Request{}, err
+			}
+			request.PriceUSD = price
+		}
+	case domain.AlertKindRise, domain.AlertKindFall:
+		if !strings.HasSuffix(value, "%") {
+			return Request{}, fmt.Errorf("%w: a move must be a percentage, got %q", ErrInvalidRequest, value)
+		}
+		percent, err := parsePercent(value, MaxMovePercent)
+		if err != nil {
+			return Request{}, err
+		}
+		request.Percent = percent
+
+		if len(rest) == 0 {
+			return Request{}, fmt.Errorf("%w: missing window", ErrInvalidRequest)
+		}
+		window, err := parseDuration(rest[0], MinWindow, MaxWindow, "window")
+		if err != nil {
+			return Request{}, err
+		}
+		request.Window = window
+		rest = rest[1:]
+	}
+
+	if err := parseRepeat(&request, rest); err != nil {
+		return Request{}, err
+	}
+
+	return request, nil
+}
+
+func parseRepeat(request *Request, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "once":
+		if len(args) > 1 {
+			return fmt.Errorf("%w: unexpected %q after once", ErrInvalidRequest, args[1])
+		}
+		return nil
+	case "repeat":
+	default:
+		return fmt.Errorf("%w: expected once or repeat, got %q", ErrInvalidRequest, args[0])
+	}
+
+	request.Repeating = true
+	request.Cooldown = DefaultCooldown
+
+	switch len(args) {
+	case 1:
+		return nil
+	case 2:
+		cooldown, err := parseDuration(args[1], MinCooldown, MaxCooldown, "cooldown")
+		if err != nil {
+			return err
+		}
+		request.Cooldown = cooldown
+		return nil
+	default:
+		return fmt.Errorf("%w: too many arguments", ErrInvalidRequest)
+	}
+}
+
+func parsePrice(input string) (money.Decimal, error) {
+	cleaned := strings.TrimPrefix(strings.TrimSpace(input), "$")
+	cleaned = strings.ReplaceAll(cleaned, ",", ".")
+
+	price, err := money.Parse(cleaned)
+	if err != nil || price.Sign() <= 0 {
+		return money.Zero, fmt.Errorf("%w: bad price %q", ErrInvalidRequest, input)
+	}
+	if !price.Round(domain.PricePrecision, money.RoundHalfEven).Equal(price) {
+		return money.Zero, fmt.Errorf("%w: price %q has more than %d decimals", ErrInvalidRequest, input, domain.PricePrecision)
+	}
+
+	return price, nil
+}
+
+func parsePercent(input string, maxPercent int) (int, error) {
+	cleaned := strings.TrimSuffix(strings.TrimSpace(input), "%")
+
+	percent, err := strconv.Atoi(cleaned)
+	if err != nil || percent < 1 || percent > maxPercent {
+		return 0, fmt.Errorf("%w: percentage must be between 1%% and %d%%, got %q", ErrInvalidRequest, maxPercent, input)
+	}
+
+	return percent, nil
+}
+
+func parseDuration(input string, minimum, maximum time.Duration, name string) (time.Duration, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	var (
+		duration time.Duration
+		err      error
+	)
+	if days, ok := strings.CutSuffix(input, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		duration, err = time.ParseDuration(input)
+	}
+
+	if err != nil || duration < minimum || duration > maximum {
+		return 0, fmt.Errorf("%w: %s must be between %s and %s, got %q", ErrInvalidRequest, name, formatDuration(minimum), formatDuration(maximum), input)
+	}
+
+	return duration, nil
+}
+
+// formatDuration writes whole days as 7d and other durations like 1h30m.
+func formatDuration(d time.Duration) string {
+	day := 24 * time.Hour
+	if d >= day && d%day == 0 {
+		return strconv.FormatInt(int64(d/day), 10) + "d"
+	}
+
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
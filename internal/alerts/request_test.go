@@ -0,0 +1,83 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func TestParseRequest(t *testing.T) {
+	above, err := ParseRequest([]string{"pepe", ">", "$1.25"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if above.Token != "pepe" || above.Kind != domain.AlertKindAbove || !above.PriceUSD.Equal(money.New(125, 2)) || above.Repeating {
+		t.Fatalf("unexpected above request: %+v", above)
+	}
+
+	below, err := ParseRequest([]string{"bonk", "<10%", "repeat"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if below.Kind != domain.AlertKindBelow || below.Percent != 10 || !below.PriceUSD.IsZero() || !below.Repeating || below.Cooldown != DefaultCooldown {
+		t.Errorf("unexpected below request: %+v", below)
+	}
+
+	rise, err := ParseRequest([]string{"wif", "+15%", "4h", "repeat", "30m"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rise.Kind != domain.AlertKindRise || rise.Percent != 15 || rise.Window != 4*time.Hour || rise.Cooldown != 30*time.Minute {
+		t.Errorf("unexpected rise request: %+v", rise)
+	}
+
+	fall, err := ParseRequest([]string{"wif", "-5%", "1d", "once"})
+	if err != nil || fall.Kind != domain.AlertKindFall || fall.Window != 24*time.Hour || fall.Repeating {
+		t.Errorf("fall request = %+v, %v", fall, err)
+	}
+}
+
+func TestParseRequestErrors(t *testing.T) {
+	testCases := [][]string{
+		nil,
+		{"pepe", ">"},
+		{"pepe", "=", "1"},
+		{"pepe", ">", "0"},
+		{"pepe", "<", "100%"},
+		{"pepe", "+10%"},
+		{"pepe", "+10", "1h"},
+		{"pepe", "+10%", "5m"},
+		{"pepe", "-10%", "2d"},
+		{"pepe", ">", "1", "twice"},
+		{"pepe", ">", "1", "repeat", "10s"},
+		{"pepe", ">", "1", "once", "1h"},
+	}
+
+	for _, args := range testCases {
+		if _, err := ParseRequest(args); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("ParseRequest(%q) error = %v, want ErrInvalidRequest", args, err)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	testCases := map[time.Duration]string{
+		30 * time.Second:              "30s",
+		15 * time.Minute:              "15m",
+		time.Hour:                     "1h",
+		90 * time.Minute:              "1h30m",
+		7 * 24 * time.Hour:            "7d",
+		36 * time.Hour:                "36h",
+		time.Minute + 30*time.Second:  "1m30s",
+		24*time.Hour + 30*time.Minute: "24h30m",
+	}
+
+	for duration, want := range testCases {
+		if got := formatDuration(duration); got != want {
+			t.Errorf("formatDuration(%s) = %q, want %q", duration, got, want)
+		}
+	}
+}
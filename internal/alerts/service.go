@@ -0,0 +1,195 @@
+// Package alerts notifies users when a token's price crosses a level or
+// moves by a percentage over a window.
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// MaxActiveAlerts bounds how many active alerts a user may hold at once.
+const MaxActiveAlerts = 20
+
+var (
+	// ErrTooManyAlerts indicates that the user already holds MaxActiveAlerts active alerts.
+	ErrTooManyAlerts = errors.New("too many active alerts")
+	// ErrAlreadyAbove indicates an above alert whose level the price already reached.
+	ErrAlreadyAbove = errors.New("price is already above the level")
+	// ErrAlreadyBelow indicates a below alert whose level the price already reached.
+	ErrAlreadyBelow = errors.New("price is already below the level")
+)
+
+// Trades is the subset of trade.Service used to create alerts.
+type Trades interface {
+	FindToken(ctx context.Context, query string) (*domain.Token, error)
+	LatestPrice(ctx context.Context, tokenAddress string) (money.Decimal, error)
+}
+
+// Settings reads the user settings that say whether a user receives
+// notifications; user.Service implements it.
+type Settings interface {
+	GetSettings(ctx context.Context, telegramID int64) (*domain.UserSettings, error)
+}
+
+// Service creates, lists and deletes users' price alerts.
+type Service struct {
+	repo     repository.AlertRepository
+	trades   Trades
+	settings Settings
+	log      *slog.Logger
+}
+
+// NewService constructs an alerts Service. settings may be nil, in which
+// case every user is treated as receiving notifications.
+func NewService(repo repository.AlertRepository, trades Trades, settings Settings, log *slog.Logger) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Service{repo: repo, trades: trades, settings: settings, log: log}
+}
+
+// Quote resolves query to a token and returns its current price, for
+// offering alert presets.
+func (s *Service) Quote(ctx context.Context, query string) (*domain.Token, money.Decimal, error) {
+	token, err := s.trades.FindToken(ctx, query)
+	if err != nil {
+		return nil, money.Zero, err
+	}
+
+	price, err := s.trades.LatestPrice(ctx, token.Address)
+	if err != nil {
+		return nil, money.Zero, err
+	}
+
+	return token, price, nil
+}
+
+// Create resolves the requested token and stores the alert. Level alerts
+// given as a percentage are resolved against the current price, and a level
+// the price already reached is rejected.
+func (s *Service) Create(ctx context.Context, userID int64, request Request) (*domain.Alert, error) {
+	token, err := s.trades.FindToken(ctx, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.repo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list active alerts: %w", err)
+	}
+	if len(active) >= MaxActiveAlerts {
+		return nil, ErrTooManyAlerts
+	}
+
+	alert := &domain.Alert{
+		TelegramID:   userID,
+		TokenAddress: token.Address,
+		TokenSymbol:  token.Symbol,
+		Kind:         request.Kind,
+		Repeating:    request.Repeating,
+		Cooldown:     request.Cooldown,
+	}
+
+	switch request.Kind {
+	case domain.AlertKindAbove, domain.AlertKindBelow:
+		price, err := s.trades.LatestPrice(ctx, token.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		alert.PriceUSD = request.PriceUSD
+		if request.Percent > 0 {
+			alert.PriceUSD = offset(price, request.Kind, request.Percent)
+		}
+		if alert.Holds(price, money.Zero) {
+			if request.Kind == domain.AlertKindAbove {
+				return nil, ErrAlreadyAbove
+			}
+			return nil, ErrAlreadyBelow
+		}
+	case domain.AlertKindRise, domain.AlertKindFall:
+		alert.Percent = request.Percent
+		alert.Window = request.Window
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidRequest, request.Kind)
+	}
+
+	if err := s.repo.Create(ctx, alert); err != nil {
+		return nil, fmt.Errorf("create alert: %w", err)
+	}
+
+	s.log.Info("price alert created",
+		slog.Int64("telegram_id", userID),
+		slog.Int64("alert_id", alert.ID),
+		slog.String("token_address", alert.TokenAddress),
+		slog.String("kind", string(alert.Kind)),
+		slog.Bool("repeating", alert.Repeating),
+	)
+
+	return alert, nil
+}
+
+// List returns the user's active alerts, oldest first.
+func (s *Service) List(ctx context.Context, userID int64) ([]*domain.Alert, error) {
+	alerts, err := s.repo.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list active alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+// Delete removes an active alert of the user. It returns
+// domain.ErrAlertNotFound when there is nothing to delete.
+func (s *Service) Delete(ctx context.Context, userID, alertID int64) error {
+	if err := s.repo.Delete(ctx, userID, alertID); err != nil {
+		return err
+	}
+
+	s.log.Info("price alert deleted", slog.Int64("telegram_id", userID), slog.Int64("alert_id", alertID))
+
+	return nil
+}
+
+// NotificationsEnabled reports whether the user receives alert
+// notifications. Users without saved settings do.
+func (s *Service) NotificationsEnabled(ctx context.Context, userID int64) (bool, error) {
+	return notificationsEnabled(ctx, s.settings, userID)
+}
+
+func notificationsEnabled(ctx context.Context, settings Settings, userID int64) (bool, error) {
+	if settings == nil {
+		return true, nil
+	}
+
+	userSettings, err := settings.GetSettings(ctx, userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return true, nil
+	case err != nil:
+		return false, err
+	case userSettings == nil:
+		return true, nil
+	default:
+		return userSettings.NotificationsEnabled, nil
+	}
+}
+
+// offset moves price percent up for above alerts and down for below alerts.
+func offset(price money.Decimal, kind domain.AlertKind, percent int) money.Decimal {
+	direction := 1
+	if kind == domain.AlertKindBelow {
+		direction = -1
+	}
+
+	factor := money.New(int64(100+direction*percent), 2)
+	return price.Mul(factor).Round(domain.PricePrecision, money.RoundHalfEven)
+}
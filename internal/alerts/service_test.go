@@ -0,0 +1,70 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func TestServiceCreate(t *testing.T) {
+	repo := &fakeCreateRepo{}
+	trades := &fakeTrades{price: mustDecimal(t, "2")}
+	service := NewService(repo, trades, nil, discardLogger())
+
+	alert, err := service.Create(context.Background(), 10, Request{Token: "pepe", Kind: domain.AlertKindAbove, Percent: 25})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !alert.PriceUSD.Equal(mustDecimal(t, "2.5")) || alert.TokenSymbol != "PEPE" {
+		t.Errorf("alert = %+v", alert)
+	}
+	if got := Describe(alert); got != "PEPE above $2.5, once" {
+		t.Errorf("Describe = %q", got)
+	}
+
+	if _, err := service.Create(context.Background(), 10, Request{Token: "pepe", Kind: domain.AlertKindBelow, PriceUSD: mustDecimal(t, "2.1")}); !errors.Is(err, ErrAlreadyBelow) {
+		t.Errorf("below alert above the price error = %v", err)
+	}
+
+	repo.active = MaxActiveAlerts
+	if _, err := service.Create(context.Background(), 10, Request{Token: "pepe", Kind: domain.AlertKindRise, Percent: 10, Window: time.Hour}); !errors.Is(err, ErrTooManyAlerts) {
+		t.Errorf("limit error = %v", err)
+	}
+}
+
+type fakeCreateRepo struct {
+	repository.AlertRepository
+
+	active  int
+	created []*domain.Alert
+}
+
+func (r *fakeCreateRepo) ListActiveByUser(context.Context, int64) ([]*domain.Alert, error) {
+	return make([]*domain.Alert, r.active), nil
+}
+
+func (r *fakeCreateRepo) Create(_ context.Context, alert *domain.Alert) error {
+	alert.ID = int64(len(r.created) + 1)
+	alert.Armed = true
+	alert.Status = domain.AlertStatusActive
+	r.created = append(r.created, alert)
+	return nil
+}
+
+type fakeTrades struct {
+	price money.Decimal
+}
+
+func (t *fakeTrades) FindToken(_ context.Context, query string) (*domain.Token, error) {
+	return &domain.Token{Address: query + "-address", Symbol: strings.ToUpper(query)}, nil
+}
+
+func (t *fakeTrades) LatestPrice(context.Context, string) (money.Decimal, error) {
+	return t.price, nil
+}
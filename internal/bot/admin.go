@@ -0,0 +1,26 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+// NotifyAdmins sends text to every configured administrator. Delivery
+// continues past failures; the returned error joins all of them.
+func (b *Bot) NotifyAdmins(_ context.Context, text string) error {
+	if b.telebot == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, id := range b.cfg.Bot.AdminIDs {
+		if _, err := b.telebot.Send(&telebot.User{ID: id}, text); err != nil {
+			errs = append(errs, fmt.Errorf("notify admin %d: %w", id, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
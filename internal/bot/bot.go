@@ -7,21 +7,32 @@ import (
 
 	telebot "gopkg.in/telebot.v3"
 
+	"github.com/Proton-105/himera-bot/internal/alerts"
 	"github.com/Proton-105/himera-bot/internal/bot/handlers"
 	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/dca"
 	errors "github.com/Proton-105/himera-bot/internal/errors"
+	"github.com/Proton-105/himera-bot/internal/exits"
+	"github.com/Proton-105/himera-bot/internal/history"
 	"github.com/Proton-105/himera-bot/internal/i18n"
 	"github.com/Proton-105/himera-bot/internal/idempotency"
 	"github.com/Proton-105/himera-bot/internal/middleware"
+	"github.com/Proton-105/himera-bot/internal/orders"
+	"github.com/Proton-105/himera-bot/internal/portfolio"
 	"github.com/Proton-105/himera-bot/internal/repository"
 	"github.com/Proton-105/himera-bot/internal/state"
+	"github.com/Proton-105/himera-bot/internal/tokens"
+	"github.com/Proton-105/himera-bot/internal/toptokens"
+	"github.com/Proton-105/himera-bot/internal/trade"
 	"github.com/Proton-105/himera-bot/internal/user"
+	"github.com/Proton-105/himera-bot/internal/watchlist"
 	"github.com/Proton-105/himera-bot/pkg/config"
 )
 
 const (
 	CommandProfile  = "/profile"
 	CommandSettings = "/settings"
+	CommandRequeue  = "/requeue"
 )
 
 // Bot wraps telebot.Bot with application dependencies required for handling updates.
@@ -50,7 +61,18 @@ func New(
 	rateLimitMw *middleware.RateLimitMiddleware,
 	userRepo repository.UserRepository,
 	userService *user.Service,
+	tradeService *trade.Service,
+	tokenSearch *tokens.Service,
+	portfolioService *portfolio.Service,
+	historyService *history.Service,
+	ordersService *orders.Service,
+	exitsService *exits.Service,
+	dcaService *dca.Service,
+	alertsService *alerts.Service,
+	watchlistService *watchlist.Service,
+	topTokens *toptokens.Service,
 	i18nManager *i18n.Manager,
+	deadLetters handlers.DeadLetterRequeuer,
 ) (*Bot, error) {
 	settings := telebot.Settings{
 		Token: cfg.Bot.Token,
@@ -92,6 +114,16 @@ func New(
 	}
 
 	b.setupRouter(userRepo, userService, log)
+	b.setupTrading(tradeService, tokenSearch, log)
+	b.setupPortfolio(portfolioService, log)
+	b.setupHistory(historyService, userService, log)
+	b.setupOrders(ordersService, log)
+	b.setupExits(exitsService, log)
+	b.setupDCA(dcaService, log)
+	b.setupAlerts(alertsService, log)
+	b.setupWatchlist(watchlistService, log)
+	b.setupTopTokens(topTokens, tradeService, tokenSearch, log)
+	b.setupAdmin(deadLetters, log)
 
 	if b.rateLimitMw != nil {
 		b.telebot.Use(b.rateLimitMw.Handle)
@@ -157,6 +189,143 @@ func (b *Bot) setupRouter(userRepo repository.UserRepository, userService *user.
 	b.router.RegisterCallback("settings_set_language_", handlers.HandleSetLanguage(userService, log))
 }
 
+func (b *Bot) setupTrading(tradeService *trade.Service, tokenSearch *tokens.Service, log *slog.Logger) {
+	if b.router == nil || b.dispatcher == nil || tradeService == nil {
+		return
+	}
+
+	b.router.RegisterCommand(CommandBuy, handlers.NewBuyHandler(b.fsm, b.keyboard, log))
+	b.dispatcher.RegisterStateHandler(state.StateBuyingSearch, handlers.NewBuySearchHandler(b.fsm, tradeService, tokenSearch, b.keyboard, log))
+	b.dispatcher.RegisterStateHandler(state.StateBuyingAmount, handlers.NewBuyAmountHandler(b.fsm, tradeService, b.keyboard, log))
+
+	b.router.RegisterCallback(CallbackBuyAmount, handlers.HandleBuyAmount(b.fsm, tradeService, b.keyboard, log))
+	if tokenSearch != nil {
+		b.router.RegisterCallback(CallbackBuyPick, handlers.HandleBuyPick(b.fsm, tradeService, tokenSearch, b.keyboard, log))
+	}
+	b.router.RegisterCallback(CallbackBuyExit, handlers.HandleBuyExit(b.fsm, tradeService, b.keyboard, log))
+	b.router.RegisterCallback(CallbackBuyConfirm, handlers.HandleBuyConfirm(b.fsm, tradeService, log))
+	b.router.RegisterCallback(CallbackBuyCancel, handlers.HandleBuyCancel(b.fsm, log))
+	b.router.RegisterCommand(CommandSell, handlers.NewSellHandler(b.fsm, tradeService, log))
+	b.dispatcher.RegisterStateHandler(state.StateSellingPercent, handlers.NewSellPercentHandler(b.fsm, tradeService, b.keyboard, log))
+
+	b.router.RegisterCallback(CallbackSellPosition, handlers.HandleSellPosition(b.fsm, tradeService, b.i18n, log))
+	b.router.RegisterCallback(CallbackSellPercent, handlers.HandleSellPercent(b.fsm, tradeService, b.keyboard, log))
+	b.router.RegisterCallback(CallbackSellConfirm, handlers.HandleSellConfirm(b.fsm, tradeService, log))
+	b.router.RegisterCallback(CallbackSellCancel, handlers.HandleSellCancel(b.fsm, log))
+
+	b.router.RegisterCallback(CallbackCancel, handlers.CallbackHandler(handlers.NewCancelHandler(b.fsm, b.keyboard, log)))
+}
+
+func (b *Bot) setupPortfolio(portfolioService *portfolio.Service, log *slog.Logger) {
+	if b.router == nil || portfolioService == nil {
+		return
+	}
+
+	b.router.RegisterCommand(CommandPortfolio, handlers.NewPortfolioHandler(portfolioService, b.i18n, log))
+	b.router.RegisterCallback(CallbackPortfolio, handlers.HandlePortfolioPage(portfolioService, b.i18n, log))
+}
+
+func (b *Bot) setupHistory(historyService *history.Service, userService *user.Service, log *slog.Logger) {
+	if b.router == nil || historyService == nil {
+		return
+	}
+
+	historyHandler := handlers.NewHistoryHandler(historyService, userService, b.i18n, log)
+	b.router.RegisterCommand(CommandHistory, historyHandler)
+	b.registerMenuText("main_menu.history", historyHandler)
+	b.router.RegisterCallback(CallbackHistory, handlers.HandleHistoryPage(historyService, userService, b.i18n, log))
+}
+
+func (b *Bot) setupOrders(ordersService *orders.Service, log *slog.Logger) {
+	if b.router == nil || ordersService == nil {
+		return
+	}
+
+	b.router.RegisterCommand(CommandLimit, handlers.NewLimitHandler(ordersService, log))
+	b.router.RegisterCommand(CommandOrders, handlers.NewOrdersHandler(ordersService, log))
+	b.router.RegisterCallback(CallbackOrderCancel, handlers.HandleOrderCancel(ordersService, log))
+}
+
+func (b *Bot) setupExits(exitsService *exits.Service, log *slog.Logger) {
+	if b.router == nil || exitsService == nil {
+		return
+	}
+
+	b.router.RegisterCommand(CommandExits, handlers.NewExitsHandler(exitsService, log))
+	b.router.RegisterCallback(CallbackExitMenu, handlers.HandleExitMenu(exitsService, log))
+	b.router.RegisterCallback(CallbackExitPreset, handlers.HandleExitPreset(exitsService, log))
+}
+
+func (b *Bot) setupDCA(dcaService *dca.Service, log *slog.Logger) {
+	if b.router == nil || dcaService == nil {
+		return
+	}
+
+	b.router.RegisterCommand(CommandDCA, handlers.NewDCAHandler(dcaService, log))
+	b.router.RegisterCallback(CallbackDCAPause, handlers.HandleDCAPause(dcaService, log))
+	b.router.RegisterCallback(CallbackDCAResume, handlers.HandleDCAResume(dcaService, log))
+	b.router.RegisterCallback(CallbackDCADelete, handlers.HandleDCADelete(dcaService, log))
+}
+
+func (b *Bot) setupAlerts(alertsService *alerts.Service, log *slog.Logger) {
+	if b.router == nil || alertsService == nil {
+		return
+	}
+
+	b.router.RegisterCommand(CommandAlert, handlers.NewAlertHandler(alertsService, log))
+	b.router.RegisterCommand(CommandAlerts, handlers.NewAlertsHandler(alertsService, log))
+	b.router.RegisterCallback(CallbackAlertPreset, handlers.HandleAlertPreset(alertsService, log))
+	b.router.RegisterCallback(CallbackAlertDelete, handlers.HandleAlertDelete(alertsService, log))
+}
+
+func (b *Bot) setupWatchlist(watchlistService *watchlist.Service, log *slog.Logger) {
+	if b.router == nil || watchlistService == nil {
+		return
+	}
+
+	b.router.RegisterCommand(CommandWatch, handlers.NewWatchHandler(watchlistService, log))
+	b.router.RegisterCommand(CommandUnwatch, handlers.NewUnwatchHandler(watchlistService, log))
+	b.router.RegisterCommand(CommandWatchlist, handlers.NewWatchlistHandler(watchlistService, log))
+	b.router.RegisterCallback(CallbackWatchAdd, handlers.HandleWatchAdd(watchlistService, log))
+	b.router.RegisterCallback(CallbackWatchRemove, handlers.HandleWatchRemove(watchlistService, log))
+}
+
+func (b *Bot) setupTopTokens(topTokens *toptokens.Service, tradeService *trade.Service, tokenSearch *tokens.Service, log *slog.Logger) {
+	if b.router == nil || topTokens == nil {
+		return
+	}
+
+	topHandler := handlers.NewTopTokensHandler(topTokens, log)
+	b.router.RegisterCommand(CommandTop, topHandler)
+	b.registerMenuText("main_menu.top_tokens", topHandler)
+	b.router.RegisterCallback(CallbackTopTab, handlers.HandleTopTokensTab(topTokens, log))
+	if tradeService != nil && tokenSearch != nil {
+		b.router.RegisterCallback(CallbackTopBuy, handlers.HandleTopTokensBuy(b.fsm, tradeService, tokenSearch, b.keyboard, log))
+	}
+}
+
+func (b *Bot) setupAdmin(deadLetters handlers.DeadLetterRequeuer, log *slog.Logger) {
+	if b.router == nil || deadLetters == nil {
+		return
+	}
+
+	adminOnly := AdminOnlyMiddleware(b.cfg.Bot.AdminIDs, log)
+	b.router.RegisterCommand(CommandRequeue, adminOnly(handlers.NewRequeueHandler(deadLetters, log)))
+}
+
+// registerMenuText routes the main menu button identified by key in every loaded language to h.
+func (b *Bot) registerMenuText(key string, h handlers.Handler) {
+	if b.i18n == nil {
+		return
+	}
+
+	for _, lang := range b.i18n.Languages() {
+		if text := b.i18n.Translator(lang).T(key); text != "" && text != key {
+			b.router.RegisterText(text, h)
+		}
+	}
+}
+
 func (b *Bot) registerTelebotHandlers() {
 	if b.telebot == nil || b.router == nil {
 		return
@@ -0,0 +1,706 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/alerts"
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/dca"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/exits"
+	"github.com/Proton-105/himera-bot/internal/history"
+	"github.com/Proton-105/himera-bot/internal/orders"
+	"github.com/Proton-105/himera-bot/internal/portfolio"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/state"
+	"github.com/Proton-105/himera-bot/internal/testutil"
+	"github.com/Proton-105/himera-bot/internal/tokens"
+	"github.com/Proton-105/himera-bot/internal/toptokens"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/internal/watchlist"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func TestPortfolioPageCallback(t *testing.T) {
+	positions := make([]*domain.Position, 0, 7)
+	for i := 1; i <= 7; i++ {
+		positions = append(positions, &domain.Position{
+			ID:           int64(i),
+			TokenAddress: fmt.Sprintf("0xtoken%d", i),
+			TokenSymbol:  fmt.Sprintf("TKN%d", i),
+			Amount:       money.NewFromInt(10),
+			AvgPriceUSD:  money.NewFromInt(1),
+		})
+	}
+
+	b := newTestBot(t)
+	b.setupPortfolio(portfolio.NewService(&stubPositions{positions: positions}, stubPrices{}, discardLogger()), discardLogger())
+
+	command := sendCommand(t, b, CommandPortfolio)
+	testutil.AssertEqual(t, true, strings.Contains(sentText(command), "TKN5"))
+	testutil.AssertEqual(t, false, strings.Contains(sentText(command), "TKN6"))
+
+	press := pressButton(t, b, command.lastMarkup(t), CallbackPortfolio+":2")
+
+	testutil.AssertEqual(t, 1, len(press.responses))
+	testutil.AssertEqual(t, true, press.sent[0].edit)
+	testutil.AssertEqual(t, true, strings.Contains(sentText(press), "TKN6"))
+	testutil.AssertEqual(t, false, strings.Contains(sentText(press), "TKN5"))
+}
+
+func TestHistoryPageCallback(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	trades := make([]*domain.Trade, 0, 12)
+	for i := 1; i <= 12; i++ {
+		trades = append(trades, &domain.Trade{
+			ID:          int64(i),
+			Type:        domain.TradeTypeBuy,
+			TokenSymbol: fmt.Sprintf("TK%02d", i),
+			Amount:      money.NewFromInt(1),
+			PriceUSD:    money.NewFromInt(1),
+			TotalUSD:    money.ZeroOf(money.USD),
+			CreatedAt:   base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	b := newTestBot(t)
+	sessions := history.NewSessionStore(client, time.Minute)
+	b.setupHistory(history.NewService(&stubTrades{trades: trades}, nil, sessions, discardLogger()), nil, discardLogger())
+
+	command := sendCommand(t, b, CommandHistory)
+	testutil.AssertEqual(t, true, strings.Contains(sentText(command), "TK03"))
+	testutil.AssertEqual(t, false, strings.Contains(sentText(command), "TK02"))
+
+	press := pressButton(t, b, command.lastMarkup(t), CallbackHistory+":2")
+
+	testutil.AssertEqual(t, 1, len(press.responses))
+	testutil.AssertEqual(t, true, press.sent[0].edit)
+	testutil.AssertEqual(t, true, strings.Contains(sentText(press), "TK02"))
+	testutil.AssertEqual(t, false, strings.Contains(sentText(press), "TK03"))
+}
+
+func TestOrderCancelCallback(t *testing.T) {
+	repo := &stubOrders{open: []*domain.Order{
+		{ID: 1, Side: domain.TradeTypeSell, TokenSymbol: "BONK", LimitPriceUSD: money.NewFromInt(2), Percent: 50, Status: domain.OrderStatusOpen},
+		{ID: 2, Side: domain.TradeTypeSell, TokenSymbol: "WIF", LimitPriceUSD: money.NewFromInt(3), Percent: 100, Status: domain.OrderStatusOpen},
+	}}
+
+	b := newTestBot(t)
+	b.setupOrders(orders.NewService(repo, nil, discardLogger()), discardLogger())
+
+	command := sendCommand(t, b, CommandOrders)
+	press := pressButton(t, b, command.lastMarkup(t), CallbackOrderCancel+":2")
+
+	testutil.AssertEqual(t, 1, len(repo.cancelled))
+	testutil.AssertEqual(t, int64(2), repo.cancelled[0])
+	testutil.AssertEqual(t, "Order #2 cancelled.", press.responses[0].Text)
+	testutil.AssertEqual(t, true, press.sent[0].edit)
+	testutil.AssertEqual(t, true, strings.Contains(sentText(press), "BONK"))
+	testutil.AssertEqual(t, false, strings.Contains(sentText(press), "WIF"))
+}
+
+func TestExitMenuAndPresetCallbacks(t *testing.T) {
+	positions := &stubPositions{positions: []*domain.Position{
+		{ID: 1, TokenAddress: "0xbonk", TokenSymbol: "BONK", Amount: money.NewFromInt(10), AvgPriceUSD: money.NewFromInt(2)},
+	}}
+	repo := &stubExits{}
+	trades := &stubExitTrades{positions: positions, price: money.NewFromInt(2)}
+
+	b := newTestBot(t)
+	b.setupPortfolio(portfolio.NewService(positions, stubPrices{}, discardLogger()), discardLogger())
+	b.setupExits(exits.NewService(repo, trades, discardLogger()), discardLogger())
+
+	command := sendCommand(t, b, CommandPortfolio)
+	menu := pressButton(t, b, command.lastMarkup(t), CallbackExitMenu+":1")
+	testutil.AssertEqual(t, true, strings.Contains(sentText(menu), "Stop-loss: not set"))
+
+	preset := pressButton(t, b, menu.lastMarkup(t), CallbackExitPreset+":1:sl10")
+
+	testutil.AssertEqual(t, "Saved", preset.responses[0].Text)
+	testutil.AssertEqual(t, 1, len(repo.saved))
+	testutil.AssertEqual(t, "1.8", repo.saved[0].StopLossUSD.StringTrimmed(8, money.RoundHalfUp))
+	testutil.AssertEqual(t, true, preset.sent[0].edit)
+	testutil.AssertEqual(t, true, strings.Contains(sentText(preset), "Stop-loss: $1.8"))
+}
+
+func TestDCAPlanCallbacks(t *testing.T) {
+	monday := time.Monday
+	repo := &stubPlans{plans: []*domain.DCAPlan{{
+		ID:           1,
+		TelegramID:   1001,
+		TokenAddress: "0xbonk",
+		TokenSymbol:  "BONK",
+		AmountUSD:    money.NewMoney(money.NewFromInt(50), money.USD, money.RoundHalfEven),
+		Weekday:      &monday,
+		MinuteOfDay:  9 * 60,
+		Status:       domain.PlanStatusActive,
+		NextRunAt:    time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC),
+	}}}
+
+	b := newTestBot(t)
+	b.setupDCA(dca.NewService(repo, nil, nil, discardLogger()), discardLogger())
+
+	command := sendCommand(t, b, CommandDCA)
+
+	paused := pressButton(t, b, command.lastMarkup(t), CallbackDCAPause+":1")
+	testutil.AssertEqual(t, "Paused", paused.responses[0].Text)
+	testutil.AssertEqual(t, domain.PlanStatusPaused, repo.plans[0].Status)
+	testutil.AssertEqual(t, true, strings.Contains(sentText(paused), "Paused"))
+
+	resumed := pressButton(t, b, paused.lastMarkup(t), CallbackDCAResume+":1")
+	testutil.AssertEqual(t, "Resumed", resumed.responses[0].Text)
+	testutil.AssertEqual(t, domain.PlanStatusActive, repo.plans[0].Status)
+
+	deleted := pressButton(t, b, resumed.lastMarkup(t), CallbackDCADelete+":1")
+	testutil.AssertEqual(t, "Deleted", deleted.responses[0].Text)
+	testutil.AssertEqual(t, 0, len(repo.plans))
+	testutil.AssertEqual(t, true, strings.HasPrefix(sentText(deleted), "You have no DCA plans."))
+}
+
+func TestAlertPresetAndDeleteCallbacks(t *testing.T) {
+	trades, _ := newTestTrades(t, money.NewFromInt(2))
+	repo := &stubAlerts{}
+
+	b := newTestBot(t)
+	b.setupAlerts(alerts.NewService(repo, trades, nil, discardLogger()), discardLogger())
+
+	menu := sendCommand(t, b, CommandAlert+" BONK")
+	preset := pressButton(t, b, menu.lastMarkup(t), CallbackAlertPreset+":0xbonk:a10")
+
+	testutil.AssertEqual(t, "Alert #1 set", preset.responses[0].Text)
+	testutil.AssertEqual(t, 1, len(repo.alerts))
+	testutil.AssertEqual(t, domain.AlertKindAbove, repo.alerts[0].Kind)
+	testutil.AssertEqual(t, true, repo.alerts[0].PriceUSD.Equal(money.New(22, 1)))
+
+	list := sendCommand(t, b, CommandAlerts)
+	deleted := pressButton(t, b, list.lastMarkup(t), CallbackAlertDelete+":1")
+
+	testutil.AssertEqual(t, "Deleted", deleted.responses[0].Text)
+	testutil.AssertEqual(t, 0, len(repo.alerts))
+	testutil.AssertEqual(t, true, strings.HasPrefix(sentText(deleted), "You have no active alerts."))
+}
+
+func TestBuyConfirmStoresExitPresets(t *testing.T) {
+	b := newTestBot(t)
+	trades, repo := newTestTrades(t, money.NewFromInt(2))
+	b.setupTrading(trades, nil, discardLogger())
+
+	sendCommand(t, b, CommandBuy)
+	card := sendCommand(t, b, "BONK")
+	confirm := pressButton(t, b, card.lastMarkup(t), CallbackBuyAmount+"100")
+	testutil.AssertEqual(t, true, strings.Contains(sentText(confirm), "Exit levels: none"))
+
+	stopLoss := pressButton(t, b, confirm.lastMarkup(t), CallbackBuyExit+":sl10")
+	takeProfit := pressButton(t, b, stopLoss.lastMarkup(t), CallbackBuyExit+":tp50")
+	testutil.AssertEqual(t, true, takeProfit.sent[0].edit)
+	testutil.AssertEqual(t, true, strings.Contains(sentText(takeProfit), "Exit levels: SL −10% · TP +50%"))
+	testutil.AssertEqual(t, "✓ SL −10%", findButton(t, takeProfit.lastMarkup(t), CallbackBuyExit+":sl10").Text)
+
+	bought := pressButton(t, b, takeProfit.lastMarkup(t), CallbackBuyConfirm)
+
+	testutil.AssertEqual(t, true, strings.Contains(sentText(bought), "Exit levels set: SL −10% · TP +50%"))
+	testutil.AssertEqual(t, 1, len(repo.positions.positions))
+	testutil.AssertEqual(t, 1, len(repo.exits))
+	avgPrice := repo.positions.positions[0].AvgPriceUSD
+	exit := repo.exits[0]
+	testutil.AssertEqual(t, avgPrice.Mul(money.New(90, 2)).Round(domain.PricePrecision, money.RoundHalfEven).String(), exit.StopLossUSD.String())
+	testutil.AssertEqual(t, avgPrice.Mul(money.New(150, 2)).Round(domain.PricePrecision, money.RoundHalfEven).String(), exit.TakeProfitUSD.String())
+	testutil.AssertEqual(t, 0, exit.TrailingPercent)
+}
+
+func TestTokenCardWatchButtons(t *testing.T) {
+	const mint = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+
+	for _, tc := range []struct {
+		name    string
+		address string
+		watch   bool
+	}{
+		{name: "solana mint", address: mint, watch: true},
+		{name: "oversize address", address: strings.Repeat("x", keyboard.CallbackDataLimitBytes), watch: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestBot(t)
+			trades, _ := newSourceTrades(t, &stubSource{price: money.NewFromInt(2), address: tc.address})
+			b.setupTrading(trades, nil, discardLogger())
+
+			sendCommand(t, b, CommandBuy)
+			card := sendCommand(t, b, tc.address)
+			markup := card.lastMarkup(t)
+
+			var watch []string
+			for _, row := range markup.InlineKeyboard {
+				for _, button := range row {
+					wire := wireData(button)
+					testutil.AssertEqual(t, true, len(wire) <= keyboard.CallbackDataLimitBytes)
+					if strings.HasPrefix(wire, CallbackWatchAdd) {
+						watch = append(watch, wire)
+					}
+				}
+			}
+			if !tc.watch {
+				testutil.AssertEqual(t, 0, len(watch))
+				testutil.AssertEqual(t, true, len(markup.InlineKeyboard) > 0)
+				return
+			}
+			testutil.AssertEqual(t, 1, len(watch))
+			testutil.AssertEqual(t, CallbackWatchAdd+":"+mint, watch[0])
+		})
+	}
+}
+
+func TestWatchAddAndRemoveCallbacks(t *testing.T) {
+	const mint = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+	b := newTestBot(t)
+	trades, _ := newSourceTrades(t, &stubSource{price: money.NewFromInt(2), address: mint})
+	watched := &stubWatchlist{}
+	b.setupTrading(trades, nil, discardLogger())
+	b.setupWatchlist(watchlist.NewService(watched, trades, stubPriceCache{}, nil, discardLogger()), discardLogger())
+
+	sendCommand(t, b, CommandBuy)
+	card := sendCommand(t, b, mint)
+	added := pressButton(t, b, card.lastMarkup(t), CallbackWatchAdd)
+
+	testutil.AssertEqual(t, "⭐ BONK added to your watchlist", added.responses[0].Text)
+	testutil.AssertEqual(t, 1, len(watched.entries))
+	testutil.AssertEqual(t, mint, watched.entries[0].TokenAddress)
+
+	list := sendCommand(t, b, CommandWatchlist)
+	removed := pressButton(t, b, list.lastMarkup(t), CallbackWatchRemove)
+
+	testutil.AssertEqual(t, "Removed", removed.responses[0].Text)
+	testutil.AssertEqual(t, 0, len(watched.entries))
+	testutil.AssertEqual(t, true, strings.HasPrefix(sentText(removed), "Your watchlist is empty."))
+}
+
+func TestBuyPickCallback(t *testing.T) {
+	b := newTestBot(t)
+	trades, _ := newTestTrades(t, money.NewFromInt(2))
+	registry := &stubTokenRegistry{tokens: []*domain.TokenInfo{
+		{Token: domain.Token{Address: "0xbonk", Symbol: "BONK", Name: "Bonk"}, LiquidityUSD: money.NewFromInt(10_000_000)},
+		{Token: domain.Token{Address: "0xfake", Symbol: "BONK", Name: "Bonk Fake"}, LiquidityUSD: money.NewFromInt(1_000)},
+	}}
+	b.setupTrading(trades, tokens.NewService(registry, nil, discardLogger()), discardLogger())
+
+	sendCommand(t, b, CommandBuy)
+	picker := sendCommand(t, b, "BONK")
+	testutil.AssertEqual(t, true, strings.HasPrefix(sentText(picker), "🔎 2 tokens match your search."))
+
+	card := pressButton(t, b, picker.lastMarkup(t), CallbackBuyPick+":0xfake")
+
+	testutil.AssertEqual(t, true, strings.Contains(sentText(card), "Address: 0xfake"))
+	findButton(t, card.lastMarkup(t), CallbackBuyAmount)
+
+	userState, err := b.fsm.GetState(context.Background(), 1001)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, state.StateBuyingAmount, userState.CurrentState)
+	testutil.AssertEqual(t, any("0xfake"), userState.Context["token_address"])
+}
+
+func TestTopTokensTabAndBuyCallbacks(t *testing.T) {
+	b := newTestBot(t)
+	trades, _ := newTestTrades(t, money.NewFromInt(2))
+	wif := &domain.TokenInfo{Token: domain.Token{Address: "0xwif", Symbol: "WIF", Name: "dogwifhat"}}
+	store := &stubBoards{boards: &toptokens.Boards{
+		ComputedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Tabs: map[toptokens.Tab][]toptokens.Entry{
+			toptokens.TabVolume:  {{Address: "0xbonk", Symbol: "BONK", PriceUSD: money.NewFromInt(2), VolumeUSD: money.NewFromInt(50_000)}},
+			toptokens.TabGainers: {{Address: "0xwif", Symbol: "WIF", PriceUSD: money.NewFromInt(3), ChangePct: money.NewFromInt(12), HasChange: true}},
+		},
+	}}
+	search := tokens.NewService(&stubTokenRegistry{tokens: []*domain.TokenInfo{wif}}, nil, discardLogger())
+	b.setupTopTokens(toptokens.NewService(nil, nil, nil, store, discardLogger()), trades, search, discardLogger())
+
+	top := sendCommand(t, b, CommandTop)
+	testutil.AssertEqual(t, true, strings.HasPrefix(sentText(top), "📈 Top tokens by 24h volume"))
+
+	gainers := pressButton(t, b, top.lastMarkup(t), CallbackTopTab+":gainers")
+	testutil.AssertEqual(t, true, gainers.sent[0].edit)
+	testutil.AssertEqual(t, true, strings.HasPrefix(sentText(gainers), "🚀 Top gainers, 24h"))
+	testutil.AssertEqual(t, "• 🚀 Gainers", findButton(t, gainers.lastMarkup(t), CallbackTopTab+":gainers").Text)
+
+	card := pressButton(t, b, gainers.lastMarkup(t), CallbackTopBuy+":0xwif")
+	testutil.AssertEqual(t, true, strings.Contains(sentText(card), "Address: 0xwif"))
+	findButton(t, card.lastMarkup(t), CallbackBuyAmount)
+
+	userState, err := b.fsm.GetState(context.Background(), 1001)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, state.StateBuyingAmount, userState.CurrentState)
+	testutil.AssertEqual(t, any("0xwif"), userState.Context["token_address"])
+}
+
+// newTestBot returns a Bot with a router, a dispatcher and a Redis-backed
+// state machine but no telebot connection, ready for one of the setup methods
+// to register handlers.
+func newTestBot(t *testing.T) *Bot {
+	t.Helper()
+	log := discardLogger()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	fsm := state.NewStateMachine(state.NewRedisStorage(client, log), log, client)
+	dispatcher := NewDispatcher(fsm, log)
+
+	return &Bot{
+		log:        log,
+		fsm:        fsm,
+		router:     NewRouter(dispatcher, log),
+		dispatcher: dispatcher,
+		keyboard:   keyboard.NewBuilder(log),
+	}
+}
+
+// newTestTrades returns a trade service that fills without fees against
+// deep pools at price, with a $10,000 balance and no positions yet.
+func newTestTrades(t *testing.T, price money.Decimal) (*trade.Service, *stubTradeRepo) {
+	t.Helper()
+	return newSourceTrades(t, &stubSource{price: price})
+}
+
+// newSourceTrades is newTestTrades with the market data served by source.
+func newSourceTrades(t *testing.T, source *stubSource) (*trade.Service, *stubTradeRepo) {
+	t.Helper()
+
+	model, err := trade.NewExecutionModel([]trade.FeeTier{{MinUSD: money.Zero, FeeBps: 0}}, 10000)
+	testutil.AssertNoError(t, err)
+
+	repo := &stubTradeRepo{balance: money.NewMoney(money.NewFromInt(10000), money.USD, money.RoundHalfEven), positions: &stubPositions{}}
+	return trade.NewService(repo, repo.positions, source, nil, source, model, discardLogger()), repo
+}
+
+// sendCommand routes a text message and returns the context it answered on.
+func sendCommand(t *testing.T, b *Bot, text string) *fakeContext {
+	t.Helper()
+	ctx := newTextContext(text)
+	testutil.AssertNoError(t, b.router.Route(ctx))
+	return ctx
+}
+
+// pressButton routes the callback Telegram sends when the button whose data
+// starts with action is pressed.
+func pressButton(t *testing.T, b *Bot, markup *telebot.ReplyMarkup, action string) *fakeContext {
+	t.Helper()
+	ctx := newCallbackContext(wireData(findButton(t, markup, action)))
+	testutil.AssertNoError(t, b.router.Route(ctx))
+	return ctx
+}
+
+// sentText returns the text of the most recent message sent or edited.
+func sentText(c *fakeContext) string {
+	if len(c.sent) == 0 {
+		return ""
+	}
+	text, _ := c.sent[len(c.sent)-1].what.(string)
+	return text
+}
+
+type stubPositions struct {
+	positions []*domain.Position
+}
+
+func (s *stubPositions) ListByUser(context.Context, int64) ([]*domain.Position, error) {
+	return s.positions, nil
+}
+
+func (s *stubPositions) GetByID(_ context.Context, _ int64, positionID int64) (*domain.Position, error) {
+	for _, position := range s.positions {
+		if position.ID == positionID {
+			return position, nil
+		}
+	}
+	return nil, domain.ErrPositionNotFound
+}
+
+func (s *stubPositions) ListTokenAddresses(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+type stubPrices map[string]*domain.PriceQuote
+
+func (s stubPrices) GetMany(context.Context, []string) (map[string]*domain.PriceQuote, error) {
+	return s, nil
+}
+
+// stubTrades lists trades newest first, as the SQL repository does.
+type stubTrades struct {
+	repository.TradeRepository
+	trades []*domain.Trade
+}
+
+func (s *stubTrades) ListTrades(_ context.Context, _ int64, _ domain.TradeFilter, after *domain.TradeCursor, limit int) ([]*domain.Trade, error) {
+	listed := make([]*domain.Trade, 0, limit)
+	for i := len(s.trades) - 1; i >= 0 && len(listed) < limit; i-- {
+		if after != nil && s.trades[i].ID >= after.ID {
+			continue
+		}
+		listed = append(listed, s.trades[i])
+	}
+	return listed, nil
+}
+
+func (s *stubTrades) CountTrades(context.Context, int64, domain.TradeFilter) (int, error) {
+	return len(s.trades), nil
+}
+
+type stubOrders struct {
+	repository.OrderRepository
+	open      []*domain.Order
+	cancelled []int64
+}
+
+func (s *stubOrders) ListOpenByUser(context.Context, int64) ([]*domain.Order, error) {
+	return s.open, nil
+}
+
+func (s *stubOrders) Cancel(_ context.Context, _ int64, orderID int64, _ string) (*domain.Order, error) {
+	for i, order := range s.open {
+		if order.ID == orderID {
+			s.open = append(s.open[:i], s.open[i+1:]...)
+			s.cancelled = append(s.cancelled, orderID)
+			return order, nil
+		}
+	}
+	return nil, domain.ErrOrderNotFound
+}
+
+type stubExits struct {
+	repository.PositionExitRepository
+	saved []*domain.PositionExit
+}
+
+func (s *stubExits) Get(context.Context, int64, int64) (*domain.PositionExit, error) {
+	if len(s.saved) == 0 {
+		return nil, domain.ErrExitNotFound
+	}
+	return s.saved[len(s.saved)-1], nil
+}
+
+func (s *stubExits) Save(_ context.Context, exit *domain.PositionExit) error {
+	s.saved = append(s.saved, exit)
+	return nil
+}
+
+type stubExitTrades struct {
+	exits.Trades
+	positions *stubPositions
+	price     money.Decimal
+}
+
+func (s *stubExitTrades) GetPosition(ctx context.Context, userID, positionID int64) (*domain.Position, error) {
+	return s.positions.GetByID(ctx, userID, positionID)
+}
+
+func (s *stubExitTrades) LatestPrice(context.Context, string) (money.Decimal, error) {
+	return s.price, nil
+}
+
+// stubTradeRepo applies buys to positions in memory and keeps the exit
+// levels set with them.
+type stubTradeRepo struct {
+	repository.TradeRepository
+	balance   money.Money
+	positions *stubPositions
+	exits     []*domain.PositionExit
+}
+
+func (s *stubTradeRepo) GetBalance(context.Context, int64) (money.Money, error) {
+	return s.balance, nil
+}
+
+func (s *stubTradeRepo) ExecuteBuy(_ context.Context, trade *domain.Trade, exit repository.BuyExit) error {
+	position := &domain.Position{
+		ID:           int64(len(s.positions.positions) + 1),
+		TelegramID:   trade.TelegramID,
+		TokenAddress: trade.TokenAddress,
+		TokenSymbol:  trade.TokenSymbol,
+		Amount:       trade.Amount,
+		AvgPriceUSD:  trade.CostPriceUSD().Round(domain.PricePrecision, money.RoundHalfEven),
+	}
+
+	if exit != nil {
+		current := &domain.PositionExit{PositionID: position.ID, TelegramID: position.TelegramID, TokenAddress: position.TokenAddress}
+		levels, err := exit(position, current)
+		if err != nil {
+			return err
+		}
+		if levels != nil && !levels.IsZero() {
+			s.exits = append(s.exits, levels)
+		}
+	}
+
+	s.positions.positions = append(s.positions.positions, position)
+	return nil
+}
+
+// stubSource knows one token, BONK, priced at price with $10M of liquidity.
+// Its address is 0xbonk unless address is set.
+type stubSource struct {
+	price   money.Decimal
+	address string
+}
+
+func (s *stubSource) FindToken(context.Context, string) (*domain.Token, error) {
+	address := s.address
+	if address == "" {
+		address = "0xbonk"
+	}
+	return &domain.Token{Address: address, Symbol: "BONK", Name: "Bonk"}, nil
+}
+
+func (s *stubSource) LatestPrice(_ context.Context, tokenAddress string) (*domain.PriceQuote, error) {
+	return &domain.PriceQuote{TokenAddress: tokenAddress, PriceUSD: s.price}, nil
+}
+
+func (s *stubSource) Get(_ context.Context, address string) (*domain.TokenInfo, error) {
+	return &domain.TokenInfo{Token: domain.Token{Address: address, Symbol: "BONK", Name: "Bonk"}, LiquidityUSD: money.NewFromInt(10_000_000)}, nil
+}
+
+type stubPlans struct {
+	repository.DCARepository
+	plans []*domain.DCAPlan
+}
+
+func (s *stubPlans) ListByUser(context.Context, int64) ([]*domain.DCAPlan, error) {
+	return s.plans, nil
+}
+
+func (s *stubPlans) Get(_ context.Context, planID int64) (*domain.DCAPlan, error) {
+	for _, plan := range s.plans {
+		if plan.ID == planID {
+			return plan, nil
+		}
+	}
+	return nil, domain.ErrPlanNotFound
+}
+
+func (s *stubPlans) Pause(ctx context.Context, _ int64, planID int64) (*domain.DCAPlan, error) {
+	plan, err := s.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	plan.Status = domain.PlanStatusPaused
+	return plan, nil
+}
+
+func (s *stubPlans) Resume(ctx context.Context, _ int64, planID int64, nextRunAt time.Time) (*domain.DCAPlan, error) {
+	plan, err := s.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	plan.Status = domain.PlanStatusActive
+	plan.NextRunAt = nextRunAt
+	return plan, nil
+}
+
+func (s *stubPlans) Delete(_ context.Context, _ int64, planID int64) error {
+	for i, plan := range s.plans {
+		if plan.ID == planID {
+			s.plans = append(s.plans[:i], s.plans[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrPlanNotFound
+}
+
+type stubAlerts struct {
+	repository.AlertRepository
+	alerts []*domain.Alert
+	nextID int64
+}
+
+func (s *stubAlerts) Create(_ context.Context, alert *domain.Alert) error {
+	s.nextID++
+	alert.ID = s.nextID
+	alert.Armed = true
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+func (s *stubAlerts) ListActiveByUser(context.Context, int64) ([]*domain.Alert, error) {
+	return s.alerts, nil
+}
+
+func (s *stubAlerts) Delete(_ context.Context, _ int64, alertID int64) error {
+	for i, alert := range s.alerts {
+		if alert.ID == alertID {
+			s.alerts = append(s.alerts[:i], s.alerts[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrAlertNotFound
+}
+
+type stubWatchlist struct {
+	repository.WatchlistRepository
+	entries []*domain.WatchlistEntry
+}
+
+func (s *stubWatchlist) Add(_ context.Context, entry *domain.WatchlistEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *stubWatchlist) Remove(_ context.Context, _ int64, tokenAddress string) error {
+	for i, entry := range s.entries {
+		if entry.TokenAddress == tokenAddress {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotWatching
+}
+
+func (s *stubWatchlist) ListByUser(context.Context, int64) ([]*domain.WatchlistEntry, error) {
+	return s.entries, nil
+}
+
+type stubPriceCache struct{}
+
+func (stubPriceCache) GetMany(context.Context, []string) (map[string]*domain.PriceQuote, error) {
+	return nil, nil
+}
+
+type stubTokenRegistry struct {
+	repository.TokenRepository
+	tokens []*domain.TokenInfo
+}
+
+func (s *stubTokenRegistry) Search(context.Context, string, int) ([]*domain.TokenInfo, error) {
+	return s.tokens, nil
+}
+
+func (s *stubTokenRegistry) Get(_ context.Context, address string) (*domain.TokenInfo, error) {
+	for _, token := range s.tokens {
+		if token.Address == address {
+			return token, nil
+		}
+	}
+	return nil, nil
+}
+
+type stubBoards struct {
+	boards *toptokens.Boards
+}
+
+func (s *stubBoards) Save(_ context.Context, boards *toptokens.Boards) error {
+	s.boards = boards
+	return nil
+}
+
+func (s *stubBoards) Load(context.Context) (*toptokens.Boards, error) {
+	return s.boards, nil
+}
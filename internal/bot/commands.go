@@ -6,14 +6,45 @@ const (
 	CommandBuy       = "/buy"
 	CommandSell      = "/sell"
 	CommandPortfolio = "/portfolio"
+	CommandHistory   = "/history"
 	CommandCancel    = "/cancel"
+	CommandLimit     = "/limit"
+	CommandOrders    = "/orders"
+	CommandExits     = "/sltp"
+	CommandDCA       = "/dca"
+	CommandAlert     = "/alert"
+	CommandAlerts    = "/alerts"
+	CommandWatch     = "/watch"
+	CommandUnwatch   = "/unwatch"
+	CommandWatchlist = "/watchlist"
+	CommandTop       = "/top"
 	CommandHelp      = "/help"
 )
 
 // Callback prefix constants for inline button interactions.
 const (
-	CallbackBuyConfirm  = "buy_confirm"
-	CallbackBuyCancel   = "buy_cancel"
-	CallbackSellConfirm = "sell_confirm"
-	CallbackSellCancel  = "sell_cancel"
+	CallbackBuyAmount    = "amount_"
+	CallbackBuyPick      = "buy_pick"
+	CallbackBuyConfirm   = "buy_confirm"
+	CallbackBuyCancel    = "buy_cancel"
+	CallbackBuyExit      = "buy_exit"
+	CallbackSellPosition = "sell_pos"
+	CallbackSellPercent  = "sell_pct"
+	CallbackSellConfirm  = "sell_confirm"
+	CallbackSellCancel   = "sell_cancel"
+	CallbackCancel       = "cancel"
+	CallbackPortfolio    = "portfolio"
+	CallbackHistory      = "history"
+	CallbackOrderCancel  = "order_cancel"
+	CallbackExitMenu     = "exit_menu"
+	CallbackExitPreset   = "exit_set"
+	CallbackDCAPause     = "dca_pause"
+	CallbackDCAResume    = "dca_resume"
+	CallbackDCADelete    = "dca_delete"
+	CallbackAlertPreset  = "alert_set"
+	CallbackAlertDelete  = "alert_del"
+	CallbackWatchAdd     = "watch_add"
+	CallbackWatchRemove  = "watch_del"
+	CallbackTopTab       = "top_tab"
+	CallbackTopBuy       = "top_buy"
 )
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+const requeueUsage = "Usage: /requeue <dead letter id>"
+
+// DeadLetterRequeuer re-enqueues a dead-lettered background job and returns the new task ID.
+type DeadLetterRequeuer interface {
+	Requeue(ctx context.Context, id int64) (string, error)
+}
+
+// NewRequeueHandler returns a handler for the admin /requeue command.
+func NewRequeueHandler(requeuer DeadLetterRequeuer, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		args := commandArgs(c.Text())
+		if len(args) != 1 {
+			return c.Send(requeueUsage)
+		}
+
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || id <= 0 {
+			return c.Send(requeueUsage)
+		}
+
+		taskID, err := requeuer.Requeue(context.Background(), id)
+		switch {
+		case errors.Is(err, jobs.ErrDeadLetterNotFound):
+			return c.Send(fmt.Sprintf("Dead letter %d not found.", id))
+		case errors.Is(err, jobs.ErrAlreadyRequeued):
+			return c.Send(fmt.Sprintf("Dead letter %d: %s.", id, err))
+		case err != nil:
+			log.Error("requeue handler failed", slog.Int64("telegram_id", c.Sender().ID), slog.Int64("dead_letter_id", id), slog.Any("error", err))
+			return c.Send(fmt.Sprintf("Unable to requeue dead letter %d: %s", id, err))
+		}
+
+		log.Info("dead letter requeued by admin", slog.Int64("telegram_id", c.Sender().ID), slog.Int64("dead_letter_id", id), slog.String("task_id", taskID))
+		return c.Send(fmt.Sprintf("Dead letter %d requeued as task %s.", id, taskID))
+	}
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/alerts"
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+)
+
+const (
+	alertPresetAction = "alert_set"
+	alertDeleteAction = "alert_del"
+	alertPresetWindow = time.Hour
+	alertsUsage       = "Usage:\n/alert <token> > <price|%>\n/alert <token> < <price|%>\n/alert <token> +<%> <window>\n/alert <token> -<%> <window>\n/alert <token> — pick a preset\n\nAdd repeat [cooldown] to fire again on every new crossing, e.g. /alert PEPE > 1.25 repeat 30m or /alert PEPE -10% 1h. Windows and cooldowns look like 30m, 4h or 1d. List your alerts with /alerts."
+	alertsMutedNotice = "\n\n🔕 Your notifications are off, so this alert stays silent until you turn them on in /settings."
+)
+
+// alertPresets are the shortcut buttons of the alert menu, one row per kind.
+// Move presets use alertPresetWindow.
+var alertPresets = [][]struct {
+	label string
+	code  string
+}{
+	{{"Above +5%", "a5"}, {"Above +10%", "a10"}, {"Above +25%", "a25"}},
+	{{"Below −5%", "b5"}, {"Below −10%", "b10"}, {"Below −25%", "b25"}},
+	{{"+10% in 1h", "r10"}, {"−10% in 1h", "f10"}},
+}
+
+// NewAlertHandler returns a handler for the /alert command, which creates a
+// price alert, or offers preset alerts when called with a token only.
+func NewAlertHandler(service *alerts.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("Price alerts are temporarily unavailable.")
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		args := commandArgs(c.Text())
+		switch len(args) {
+		case 0:
+			return c.Send(alertsUsage)
+		case 1:
+			return sendAlertMenu(ctx, c, service, userID, args[0], log)
+		}
+
+		request, err := alerts.ParseRequest(args)
+		if err != nil {
+			return c.Send(alertsUsage)
+		}
+
+		alert, err := service.Create(ctx, userID, request)
+		if err != nil {
+			return c.Send(alertErrorMessage(log, userID, err))
+		}
+
+		return c.Send(alertCreatedMessage(ctx, service, userID, alert, log))
+	}
+}
+
+// NewAlertsHandler returns a handler for the /alerts command, which lists the
+// user's active alerts with buttons to delete them.
+func NewAlertsHandler(service *alerts.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("Price alerts are temporarily unavailable.")
+		}
+
+		userID := c.Sender().ID
+		list, err := service.List(context.Background(), userID)
+		if err != nil {
+			log.Error("alerts handler failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send("Unable to load your alerts right now. Please try again later.")
+		}
+
+		message, markup, err := renderAlerts(list)
+		if err != nil {
+			log.Error("alerts handler failed to render", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		return c.Send(message, markup)
+	}
+}
+
+// HandleAlertPreset creates the preset alert encoded in the callback.
+func HandleAlertPreset(service *alerts.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		tokenAddress, code, _ := strings.Cut(callbackPayload(c), keyboard.CallbackDataSeparator)
+		request, err := alertPresetRequest(tokenAddress, code)
+		if err != nil {
+			return respondCallback(c, "Unknown preset", true)
+		}
+
+		alert, err := service.Create(ctx, userID, request)
+		if err != nil {
+			return respondCallback(c, alertErrorMessage(log, userID, err), true)
+		}
+
+		if err := respondCallback(c, fmt.Sprintf("Alert #%d set", alert.ID), false); err != nil {
+			log.Warn("alerts: failed to answer preset callback", slog.Any("error", err))
+		}
+
+		return c.Send(alertCreatedMessage(ctx, service, userID, alert, log))
+	}
+}
+
+// HandleAlertDelete deletes the alert encoded in the callback and refreshes the list.
+func HandleAlertDelete(service *alerts.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		alertID, err := strconv.ParseInt(callbackPayload(c), 10, 64)
+		if err != nil {
+			return respondCallback(c, "Unknown alert", true)
+		}
+
+		if err := service.Delete(ctx, userID, alertID); err != nil {
+			if errors.Is(err, domain.ErrAlertNotFound) {
+				return respondCallback(c, "Unknown alert", true)
+			}
+			log.Error("alert delete failed", slog.Int64("telegram_id", userID), slog.Int64("alert_id", alertID), slog.Any("error", err))
+			return respondCallback(c, "Unable to delete the alert right now.", true)
+		}
+
+		if err := respondCallback(c, "Deleted", false); err != nil {
+			log.Warn("alerts: failed to answer delete callback", slog.Any("error", err))
+		}
+
+		list, err := service.List(ctx, userID)
+		if err != nil {
+			log.Error("alerts: failed to reload alerts", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		message, markup, err := renderAlerts(list)
+		if err != nil {
+			log.Error("alerts: failed to render alerts", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func sendAlertMenu(ctx context.Context, c telebot.Context, service *alerts.Service, userID int64, query string, log *slog.Logger) error {
+	token, price, err := service.Quote(ctx, query)
+	if err != nil {
+		return c.Send(alertErrorMessage(log, userID, err))
+	}
+
+	message := fmt.Sprintf("🔔 %s is at $%s.\n\nPick a preset below, or send /alert %s > <price> for a custom alert.\n\n%s",
+		tokenLabel(*token), formatPrice(price), tokenLabel(*token), alertsUsage)
+
+	markup, err := alertMenuMarkup(token.Address)
+	if err != nil {
+		// Addresses too long for callback data only get the usage.
+		log.Warn("alerts: failed to build preset menu", slog.String("token_address", token.Address), slog.Any("error", err))
+		return c.Send(message)
+	}
+
+	return c.Send(message, markup)
+}
+
+func alertMenuMarkup(tokenAddress string) (*telebot.ReplyMarkup, error) {
+	builder := keyboard.NewInlineKeyboard()
+	for _, presets := range alertPresets {
+		row := make([]keyboard.InlineButton, 0, len(presets))
+		for _, preset := range presets {
+			row = append(row, keyboard.InlineButton{
+				Text:   preset.label,
+				Unique: alertPresetAction,
+				Data:   tokenAddress + keyboard.CallbackDataSeparator + preset.code,
+			})
+		}
+		builder.AddRow(row...)
+	}
+
+	return builder.Build()
+}
+
+func alertPresetRequest(tokenAddress, code string) (alerts.Request, error) {
+	if tokenAddress == "" || len(code) < 2 {
+		return alerts.Request{}, alerts.ErrInvalidRequest
+	}
+
+	percent, err := strconv.Atoi(code[1:])
+	if err != nil || percent <= 0 {
+		return alerts.Request{}, alerts.ErrInvalidRequest
+	}
+
+	request := alerts.Request{Token: tokenAddress, Percent: percent}
+	switch code[0] {
+	case 'a':
+		request.Kind = domain.AlertKindAbove
+	case 'b':
+		request.Kind = domain.AlertKindBelow
+	case 'r':
+		request.Kind, request.Window = domain.AlertKindRise, alertPresetWindow
+	case 'f':
+		request.Kind, request.Window = domain.AlertKindFall, alertPresetWindow
+	default:
+		return alerts.Request{}, alerts.ErrInvalidRequest
+	}
+
+	return request, nil
+}
+
+func alertCreatedMessage(ctx context.Context, service *alerts.Service, userID int64, alert *domain.Alert, log *slog.Logger) string {
+	message := fmt.Sprintf("🔔 Alert #%d created: %s\n\nManage your alerts with /alerts.", alert.ID, alerts.Describe(alert))
+
+	enabled, err := service.NotificationsEnabled(ctx, userID)
+	if err != nil {
+		log.Warn("alerts: failed to check notification settings", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return message
+	}
+	if !enabled {
+		message += alertsMutedNotice
+	}
+
+	return message
+}
+
+func renderAlerts(list []*domain.Alert) (string, *telebot.ReplyMarkup, error) {
+	if len(list) == 0 {
+		return "You have no active alerts.\n\n" + alertsUsage, nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🔔 Price alerts\n\n")
+
+	builder := keyboard.NewInlineKeyboard()
+	for _, alert := range list {
+		fmt.Fprintf(&sb, "#%d %s\n", alert.ID, alerts.Describe(alert))
+		if alert.Repeating && !alert.Armed {
+			sb.WriteString("Fired; waits for the price to cross back\n")
+		}
+
+		builder.AddRow(keyboard.InlineButton{
+			Text:   fmt.Sprintf("Delete #%d ❌", alert.ID),
+			Unique: alertDeleteAction,
+			Data:   strconv.FormatInt(alert.ID, 10),
+		})
+	}
+	fmt.Fprintf(&sb, "\n%d of %d alert(s).", len(list), alerts.MaxActiveAlerts)
+
+	markup, err := builder.Build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sb.String(), markup, nil
+}
+
+func alertErrorMessage(log *slog.Logger, userID int64, err error) string {
+	switch {
+	case errors.Is(err, market.ErrTokenNotFound):
+		return "Token not found. Send a contract address or a symbol."
+	case errors.Is(err, alerts.ErrTooManyAlerts):
+		return fmt.Sprintf("You already have %d active alerts. Delete one in /alerts first.", alerts.MaxActiveAlerts)
+	case errors.Is(err, alerts.ErrAlreadyAbove):
+		return "The price is already above this level."
+	case errors.Is(err, alerts.ErrAlreadyBelow):
+		return "The price is already below this level."
+	case errors.Is(err, market.ErrPriceUnavailable):
+		return "The price for this token is unavailable right now. Please try again later."
+	case errors.Is(err, market.ErrStalePrice):
+		return "The latest price for this token is too old. Please try again in a minute."
+	default:
+		log.Error("price alert failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return defaultInternalErrorMessage
+	}
+}
@@ -0,0 +1,763 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/exits"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/internal/state"
+	"github.com/Proton-105/himera-bot/internal/tokens"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	buyAmountDataPrefix = "amount_"
+	buyPickAction       = "buy_pick"
+	buyExitAction       = "buy_exit"
+
+	buyContextTokenAddress = "token_address"
+	buyContextTokenSymbol  = "token_symbol"
+	buyContextTokenName    = "token_name"
+	buyContextAmountUSD    = "amount_usd"
+	// Exit presets picked on the confirm step are stored under buyContextExitPrefix
+	// and the level of their code, e.g. "exit_sl" holds "sl10".
+	buyContextExitPrefix = "exit_"
+)
+
+// buyExitLevels are the levels a buy can carry presets for, by code prefix.
+var buyExitLevels = []string{"sl", "tp", "tr"}
+
+// NewBuyHandler starts the /buy conversation by asking for a token.
+func NewBuyHandler(fsm state.StateMachine, kb *keyboard.Builder, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			log.Warn("buy handler invoked without sender")
+			return nil
+		}
+
+		if fsm == nil {
+			log.Error("state machine is not configured for buy handler")
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		if err := fsm.SetState(ctx, userID, state.StateBuyingSearch, map[string]interface{}{}); err != nil {
+			log.Error("buy: failed to enter search state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		return c.Send("🔎 Send the symbol, name or contract address of the token you want to buy.", cancelMarkup(kb))
+	}
+}
+
+// NewBuySearchHandler searches the token typed by the user while in
+// StateBuyingSearch. An exact address match or a single result opens the token
+// card; otherwise the best matches are offered as buttons. Without a token
+// search service the query is resolved by the trade service alone.
+func NewBuySearchHandler(fsm state.StateMachine, trades *trade.Service, search *tokens.Service, kb *keyboard.Builder, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		if search == nil {
+			token, err := trades.FindToken(ctx, c.Text())
+			if err != nil {
+				return c.Send(buyErrorMessage(log, userID, err), cancelMarkup(kb))
+			}
+			return showTokenCard(c, fsm, trades, kb, log, *token)
+		}
+
+		matches, err := search.Search(ctx, c.Text())
+		if err != nil {
+			return c.Send(buyErrorMessage(log, userID, err), cancelMarkup(kb))
+		}
+
+		if len(matches) == 1 || matches[0].Kind == tokens.MatchAddress {
+			return showTokenCard(c, fsm, trades, kb, log, matches[0].Token.Token)
+		}
+
+		markup, ok := tokenPickerMarkup(kb, matches, log)
+		if !ok {
+			return showTokenCard(c, fsm, trades, kb, log, matches[0].Token.Token)
+		}
+
+		return c.Send(fmt.Sprintf("🔎 %d tokens match your search. Pick one or send another query:", len(matches)), markup)
+	}
+}
+
+// HandleBuyPick opens the token card of a search result picked in StateBuyingSearch.
+func HandleBuyPick(fsm state.StateMachine, trades *trade.Service, search *tokens.Service, kb *keyboard.Builder, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil || search == nil {
+			return nil
+		}
+
+		if _, ok := requireState(c, fsm, log, state.StateBuyingSearch); !ok {
+			return nil
+		}
+
+		tokenAddress := callbackPayload(c)
+		if tokenAddress == "" {
+			return respondCallback(c, "Unknown token", true)
+		}
+
+		userID := c.Sender().ID
+		token, err := search.Get(context.Background(), tokenAddress)
+		if err != nil {
+			return respondCallback(c, buyErrorMessage(log, userID, err), true)
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("buy: failed to answer pick callback", slog.Any("error", err))
+		}
+
+		return showTokenCard(c, fsm, trades, kb, log, token.Token)
+	}
+}
+
+// showTokenCard prices the token, moves the user to StateBuyingAmount and
+// sends the token card with the quick amounts.
+func showTokenCard(
+	c telebot.Context,
+	fsm state.StateMachine,
+	trades *trade.Service,
+	kb *keyboard.Builder,
+	log *slog.Logger,
+	token domain.Token,
+) error {
+	ctx := context.Background()
+	userID := c.Sender().ID
+
+	price, err := trades.LatestPrice(ctx, token.Address)
+	if err != nil {
+		return c.Send(buyErrorMessage(log, userID, err), cancelMarkup(kb))
+	}
+
+	contextData := map[string]interface{}{
+		buyContextTokenAddress: token.Address,
+		buyContextTokenSymbol:  token.Symbol,
+		buyContextTokenName:    token.Name,
+	}
+	if err := fsm.TransitionWithContext(ctx, userID, state.StateBuyingAmount, contextData); err != nil {
+		log.Error("buy: failed to enter amount state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return c.Send(defaultInternalErrorMessage)
+	}
+
+	message := fmt.Sprintf(
+		"%s\nPrice: $%s\n\nChoose how much USD to spend or type a custom amount:",
+		tokenTitle(token),
+		formatPrice(price),
+	)
+
+	return c.Send(message, tokenCardMarkup(kb, token, log))
+}
+
+// NewBuyAmountHandler accepts a free-text USD amount while in StateBuyingAmount.
+func NewBuyAmountHandler(fsm state.StateMachine, trades *trade.Service, kb *keyboard.Builder, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		return proceedToBuyConfirm(c, fsm, trades, kb, log, c.Text())
+	}
+}
+
+// HandleBuyAmount handles the quick amount buttons built by keyboard.Builder.AmountButtons.
+func HandleBuyAmount(fsm state.StateMachine, trades *trade.Service, kb *keyboard.Builder, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		if _, ok := requireState(c, fsm, log, state.StateBuyingAmount); !ok {
+			return nil
+		}
+
+		data := ""
+		if cb := c.Callback(); cb != nil {
+			data = cb.Data
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("buy: failed to answer amount callback", slog.Any("error", err))
+		}
+
+		return proceedToBuyConfirm(c, fsm, trades, kb, log, strings.TrimPrefix(data, buyAmountDataPrefix))
+	}
+}
+
+// HandleBuyConfirm executes the purchase prepared in StateBuyingConfirm.
+func HandleBuyConfirm(fsm state.StateMachine, trades *trade.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		userState, ok := requireState(c, fsm, log, state.StateBuyingConfirm)
+		if !ok {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		token := tokenFromContext(userState.Context)
+		amountUSD, err := trade.ParseAmountUSD(contextString(userState.Context, buyContextAmountUSD))
+		if token.Address == "" || err != nil {
+			log.Error("buy: confirm state is missing order details", slog.Int64("telegram_id", userID))
+			resetToIdle(ctx, fsm, log, userID)
+			return respondCallback(c, "Order details expired. Start again with /buy.", true)
+		}
+
+		if err := respondCallback(c, "Placing order…", false); err != nil {
+			log.Warn("buy: failed to answer confirm callback", slog.Any("error", err))
+		}
+
+		order := trade.BuyOrder{
+			UserID:    userID,
+			Token:     token,
+			AmountUSD: amountUSD,
+		}
+		if update, ok := buyExitUpdate(userState.Context); ok {
+			order.Exit = exits.BuyLevels(update)
+		}
+
+		executed, err := trades.ExecuteBuy(ctx, order)
+		resetToIdle(ctx, fsm, log, userID)
+		if err != nil {
+			return c.Send(buyErrorMessage(log, userID, err))
+		}
+
+		message := fmt.Sprintf(
+			"✅ Bought %s %s at $%s\nTotal: $%s (fee $%s)",
+			formatTokenAmount(executed.Amount),
+			tokenLabel(token),
+			formatPrice(executed.PriceUSD),
+			formatUSD(executed.TotalUSD),
+			formatUSD(executed.FeeUSD),
+		)
+		if order.Exit != nil {
+			message += "\nExit levels set: " + buyExitSummary(userState.Context)
+		}
+
+		markup, err := boughtMarkup(ctx, trades, userID, token.Address)
+		if err != nil {
+			log.Warn("buy: failed to offer exit levels", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(message)
+		}
+
+		return c.Send(message, markup)
+	}
+}
+
+// boughtMarkup offers to attach a stop-loss or take-profit to the position the purchase went into.
+func boughtMarkup(ctx context.Context, trades *trade.Service, userID int64, tokenAddress string) (*telebot.ReplyMarkup, error) {
+	positions, err := trades.ListPositions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, position := range positions {
+		if position.TokenAddress == tokenAddress {
+			return keyboard.NewInlineKeyboard().
+				AddRow(exitMenuButton("🛡 Add stop-loss / take-profit", position.ID)).
+				Build()
+		}
+	}
+
+	return nil, domain.ErrPositionNotFound
+}
+
+// HandleBuyExit toggles an exit preset on the confirm step. Picked presets
+// are stored with the purchase when it is confirmed.
+func HandleBuyExit(fsm state.StateMachine, trades *trade.Service, kb *keyboard.Builder, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		userState, ok := requireState(c, fsm, log, state.StateBuyingConfirm)
+		if !ok {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		code := callbackPayload(c)
+		if _, err := exitPresetUpdate(code); err != nil || code == "off" {
+			return respondCallback(c, "Unknown preset", true)
+		}
+
+		contextData := make(map[string]interface{}, len(userState.Context)+1)
+		for key, value := range userState.Context {
+			contextData[key] = value
+		}
+		key := buyContextExitPrefix + code[:2]
+		if contextString(contextData, key) == code {
+			delete(contextData, key)
+		} else {
+			contextData[key] = code
+		}
+
+		token := tokenFromContext(contextData)
+		amountUSD, err := trade.ParseAmountUSD(contextString(contextData, buyContextAmountUSD))
+		if token.Address == "" || err != nil {
+			resetToIdle(ctx, fsm, log, userID)
+			return respondCallback(c, "Order details expired. Start again with /buy.", true)
+		}
+
+		message, err := buyConfirmMessage(ctx, trades, userID, token, amountUSD, contextData)
+		if err != nil {
+			return respondCallback(c, buyErrorMessage(log, userID, err), true)
+		}
+
+		markup, err := buyConfirmMarkup(kb, contextData)
+		if err != nil {
+			log.Error("buy: failed to build confirm keyboard", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return respondCallback(c, defaultInternalErrorMessage, true)
+		}
+
+		if err := fsm.SetState(ctx, userID, state.StateBuyingConfirm, contextData); err != nil {
+			log.Error("buy: failed to store exit preset", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return respondCallback(c, defaultInternalErrorMessage, true)
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("buy: failed to answer exit preset callback", slog.Any("error", err))
+		}
+
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// HandleBuyCancel aborts the purchase and returns the user to idle.
+func HandleBuyCancel(fsm state.StateMachine, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil {
+			return nil
+		}
+
+		resetToIdle(context.Background(), fsm, log, c.Sender().ID)
+
+		if err := respondCallback(c, "Purchase cancelled", false); err != nil {
+			log.Warn("buy: failed to answer cancel callback", slog.Any("error", err))
+		}
+
+		return c.Send("Purchase cancelled.")
+	}
+}
+
+func proceedToBuyConfirm(
+	c telebot.Context,
+	fsm state.StateMachine,
+	trades *trade.Service,
+	kb *keyboard.Builder,
+	log *slog.Logger,
+	rawAmount string,
+) error {
+	ctx := context.Background()
+	userID := c.Sender().ID
+
+	userState, err := fsm.GetState(ctx, userID)
+	if err != nil {
+		log.Error("buy: failed to load state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return c.Send(defaultInternalErrorMessage)
+	}
+
+	token := tokenFromContext(userState.Context)
+	if token.Address == "" {
+		resetToIdle(ctx, fsm, log, userID)
+		return c.Send("Token selection expired. Start again with /buy.")
+	}
+
+	amountUSD, err := trade.ParseAmountUSD(rawAmount)
+	if err != nil {
+		return c.Send(buyErrorMessage(log, userID, err), amountMarkup(kb))
+	}
+
+	contextData := map[string]interface{}{
+		buyContextTokenAddress: token.Address,
+		buyContextTokenSymbol:  token.Symbol,
+		buyContextTokenName:    token.Name,
+		buyContextAmountUSD:    amountUSD.Amount().String(),
+	}
+
+	message, err := buyConfirmMessage(ctx, trades, userID, token, amountUSD, contextData)
+	if err != nil {
+		return c.Send(buyErrorMessage(log, userID, err), amountMarkup(kb))
+	}
+
+	markup, err := buyConfirmMarkup(kb, contextData)
+	if err != nil {
+		log.Error("buy: failed to build confirm keyboard", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return c.Send(defaultInternalErrorMessage)
+	}
+
+	if err := fsm.TransitionWithContext(ctx, userID, state.StateBuyingConfirm, contextData); err != nil {
+		log.Error("buy: failed to enter confirm state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return c.Send(defaultInternalErrorMessage)
+	}
+
+	return c.Send(message, markup)
+}
+
+// buyConfirmMessage quotes the purchase and describes it with the exit
+// presets picked in contextData.
+func buyConfirmMessage(
+	ctx context.Context,
+	trades *trade.Service,
+	userID int64,
+	token domain.Token,
+	amountUSD money.Money,
+	contextData map[string]interface{},
+) (string, error) {
+	quote, err := trades.QuoteBuy(ctx, userID, token, amountUSD)
+	if err != nil {
+		return "", err
+	}
+
+	balanceAfter, err := quote.BalanceUSD.Sub(quote.AmountUSD)
+	if err != nil {
+		return "", fmt.Errorf("compute balance after purchase: %w", err)
+	}
+
+	exitLevels := "none, pick presets below"
+	if _, ok := buyExitUpdate(contextData); ok {
+		exitLevels = buyExitSummary(contextData)
+	}
+
+	return fmt.Sprintf(
+		"Confirm purchase\n\nToken: %s\nSpend: $%s\n%s\nYou receive ≈ %s %s\nBalance after: $%s\nExit levels: %s",
+		tokenLabel(token),
+		formatUSD(quote.AmountUSD),
+		fillBreakdown(quote.Fill),
+		formatTokenAmount(quote.TokenAmount),
+		tokenLabel(token),
+		formatUSD(balanceAfter),
+		exitLevels,
+	), nil
+}
+
+// buyConfirmMarkup lists the exit presets, picked ones ticked, above the
+// confirm and cancel buttons.
+func buyConfirmMarkup(kb *keyboard.Builder, contextData map[string]interface{}) (*telebot.ReplyMarkup, error) {
+	builder := keyboard.NewInlineKeyboard()
+	for _, presets := range exitPresets {
+		row := make([]keyboard.InlineButton, 0, len(presets))
+		for _, preset := range presets {
+			if preset.code == "off" {
+				continue
+			}
+			label := preset.label
+			if contextString(contextData, buyContextExitPrefix+preset.code[:2]) == preset.code {
+				label = "✓ " + label
+			}
+			row = append(row, keyboard.InlineButton{Text: label, Unique: buyExitAction, Data: preset.code})
+		}
+		builder.AddRow(row...)
+	}
+
+	markup, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	if kb != nil {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, kb.ConfirmButtons("buy").InlineKeyboard...)
+	}
+
+	return markup, nil
+}
+
+// buyExitUpdate merges the exit presets picked in contextData into one
+// update. ok is false when none was picked.
+func buyExitUpdate(contextData map[string]interface{}) (update exits.Update, ok bool) {
+	for _, level := range buyExitLevels {
+		preset, err := exitPresetUpdate(contextString(contextData, buyContextExitPrefix+level))
+		if err != nil {
+			continue
+		}
+		if preset.StopLoss != nil {
+			update.StopLoss = preset.StopLoss
+		}
+		if preset.TakeProfit != nil {
+			update.TakeProfit = preset.TakeProfit
+		}
+		if preset.TrailingPercent != nil {
+			update.TrailingPercent = preset.TrailingPercent
+		}
+		ok = true
+	}
+
+	return update, ok
+}
+
+// buyExitSummary names the exit presets picked in contextData, e.g. "SL −10% · TP +50%".
+func buyExitSummary(contextData map[string]interface{}) string {
+	labels := make([]string, 0, len(buyExitLevels))
+	for _, level := range buyExitLevels {
+		code := contextString(contextData, buyContextExitPrefix+level)
+		for _, presets := range exitPresets {
+			for _, preset := range presets {
+				if preset.code == code {
+					labels = append(labels, preset.label)
+				}
+			}
+		}
+	}
+
+	return strings.Join(labels, " · ")
+}
+
+// requireState loads the user's state and answers the callback when it does not match expected.
+func requireState(c telebot.Context, fsm state.StateMachine, log *slog.Logger, expected state.State) (*state.UserState, bool) {
+	userID := c.Sender().ID
+
+	userState, err := fsm.GetState(context.Background(), userID)
+	switch {
+	case err == nil && userState != nil && userState.CurrentState == expected:
+		return userState, true
+	case err != nil && !errors.Is(err, state.ErrStateNotFound):
+		log.Error("failed to load user state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+	}
+
+	if respErr := respondCallback(c, "This action is no longer available.", true); respErr != nil {
+		log.Warn("failed to answer stale callback", slog.Int64("telegram_id", userID), slog.Any("error", respErr))
+	}
+
+	return nil, false
+}
+
+func resetToIdle(ctx context.Context, fsm state.StateMachine, log *slog.Logger, userID int64) {
+	if err := fsm.TransitionTo(ctx, userID, state.StateIdle); err != nil {
+		log.Error("failed to reset user state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+	}
+}
+
+// fillBreakdown lists the market price of a quote and what filling it costs.
+func fillBreakdown(fill *trade.Fill) string {
+	return fmt.Sprintf(
+		"Market price: $%s\nFill price: $%s (impact %s%%)\nFee (%s%%): $%s\nSlippage: $%s",
+		formatPrice(fill.MidPriceUSD),
+		formatPrice(fill.PriceUSD),
+		fill.ImpactPct.StringFixed(2, money.RoundHalfEven),
+		money.New(int64(fill.FeeBps), 2).StringTrimmed(2, money.RoundHalfEven),
+		formatUSD(fill.FeeUSD),
+		formatUSD(fill.SlippageUSD),
+	)
+}
+
+// priceImpactMessage explains an order rejected for moving the price too far.
+func priceImpactMessage(err error, hint string) string {
+	var impactErr *trade.PriceImpactError
+	if errors.As(err, &impactErr) {
+		return fmt.Sprintf("This order would fill %s%% away from the market price, above the %s%% limit. %s",
+			impactErr.ImpactPct.StringFixed(2, money.RoundHalfEven), impactErr.MaxPct.StringTrimmed(2, money.RoundHalfEven), hint)
+	}
+	return "This order would move the price too much. " + hint
+}
+
+func buyErrorMessage(log *slog.Logger, userID int64, err error) string {
+	switch {
+	case errors.Is(err, market.ErrTokenNotFound):
+		return "Token not found. Send a contract address or a symbol."
+	case errors.Is(err, market.ErrPriceUnavailable):
+		return "The price for this token is unavailable right now. Please try again later."
+	case errors.Is(err, market.ErrStalePrice):
+		return "The latest price for this token is too old to trade on. Please try again in a minute."
+	case errors.Is(err, trade.ErrInvalidAmount):
+		return "Enter a positive USD amount, for example 100."
+	case errors.Is(err, domain.ErrInsufficientBalance):
+		return "Insufficient balance for this purchase."
+	case errors.Is(err, trade.ErrInsufficientLiquidity):
+		return "This token has no known liquidity to trade against."
+	case errors.Is(err, trade.ErrPriceImpactTooHigh):
+		return priceImpactMessage(err, "Try a smaller amount.")
+	case errors.Is(err, exits.ErrStopLossAbovePrice):
+		return "The stop-loss preset is above the current price, so nothing was bought. Pick a lower one."
+	case errors.Is(err, exits.ErrTakeProfitBelowPrice):
+		return "The take-profit preset is below the current price, so nothing was bought. Pick a higher one."
+	case errors.Is(err, sql.ErrNoRows):
+		return "Your account was not found. Send /start and try again."
+	default:
+		log.Error("buy flow failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return defaultInternalErrorMessage
+	}
+}
+
+func tokenFromContext(data map[string]interface{}) domain.Token {
+	return domain.Token{
+		Address: contextString(data, buyContextTokenAddress),
+		Symbol:  contextString(data, buyContextTokenSymbol),
+		Name:    contextString(data, buyContextTokenName),
+	}
+}
+
+func contextString(data map[string]interface{}, key string) string {
+	if data == nil {
+		return ""
+	}
+
+	value, _ := data[key].(string)
+	return value
+}
+
+func tokenLabel(token domain.Token) string {
+	if token.Symbol != "" {
+		return token.Symbol
+	}
+	return shortAddress(token.Address)
+}
+
+func tokenTitle(token domain.Token) string {
+	title := tokenLabel(token)
+	if token.Name != "" && token.Name != token.Symbol {
+		title = fmt.Sprintf("%s — %s", title, token.Name)
+	}
+	return fmt.Sprintf("%s\nAddress: %s", title, token.Address)
+}
+
+func shortAddress(address string) string {
+	if len(address) <= 12 {
+		return address
+	}
+	return address[:6] + "…" + address[len(address)-4:]
+}
+
+// tokenCardMarkup adds a watch button under the quick amounts of a token card.
+func tokenCardMarkup(kb *keyboard.Builder, token domain.Token, log *slog.Logger) *telebot.ReplyMarkup {
+	markup := amountMarkup(kb)
+
+	button, ok := watchButton(token.Address)
+	if !ok {
+		return markup
+	}
+
+	watchRow, err := keyboard.NewInlineKeyboard().AddRow(button).Build()
+	if err != nil {
+		log.Warn("buy: failed to build watch button", slog.String("token_address", token.Address), slog.Any("error", err))
+		return markup
+	}
+	if markup == nil {
+		return watchRow
+	}
+
+	markup.InlineKeyboard = append(markup.InlineKeyboard, watchRow.InlineKeyboard...)
+	return markup
+}
+
+// tokenPickerMarkup lists search matches as buttons above the cancel button.
+// ok is false when no match fits in callback data.
+func tokenPickerMarkup(kb *keyboard.Builder, matches []tokens.Match, log *slog.Logger) (markup *telebot.ReplyMarkup, ok bool) {
+	builder := keyboard.NewInlineKeyboard()
+	rows := 0
+	for _, match := range matches {
+		if _, err := keyboard.EncodeCallback(buyPickAction, match.Token.Address); err != nil {
+			continue
+		}
+		builder.AddRow(keyboard.InlineButton{Text: matchLabel(match.Token), Unique: buyPickAction, Data: match.Token.Address})
+		rows++
+	}
+	if rows == 0 {
+		return nil, false
+	}
+
+	markup, err := builder.Build()
+	if err != nil {
+		log.Warn("buy: failed to build token picker", slog.Any("error", err))
+		return nil, false
+	}
+	if cancel := cancelMarkup(kb); cancel != nil {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, cancel.InlineKeyboard...)
+	}
+
+	return markup, true
+}
+
+// matchLabel names a search result with enough detail to tell look-alike
+// tokens apart, e.g. "BONK — Bonk · solana · $5.9M liq".
+func matchLabel(token *domain.TokenInfo) string {
+	parts := []string{tokenLabel(token.Token)}
+	if token.Name != "" && token.Name != token.Symbol {
+		parts[0] += " — " + token.Name
+	}
+	if token.Chain != "" {
+		parts = append(parts, token.Chain)
+	}
+	if token.LiquidityUSD.Sign() > 0 {
+		parts = append(parts, "$"+formatCompactUSD(token.LiquidityUSD)+" liq")
+	}
+	return strings.Join(parts, " · ")
+}
+
+func amountMarkup(kb *keyboard.Builder) *telebot.ReplyMarkup {
+	if kb == nil {
+		return nil
+	}
+	return kb.AmountButtons()
+}
+
+func cancelMarkup(kb *keyboard.Builder) *telebot.ReplyMarkup {
+	if kb == nil {
+		return nil
+	}
+	return kb.CancelButton()
+}
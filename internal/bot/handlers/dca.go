@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/dca"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+)
+
+const (
+	dcaPauseAction  = "dca_pause"
+	dcaResumeAction = "dca_resume"
+	dcaDeleteAction = "dca_delete"
+	dcaTimeLayout   = "Mon 2006-01-02 15:04 MST"
+	dcaUsage        = "Usage:\n/dca <token> <usd amount> <daily|weekday> <HH:MM>\n/dca — list your plans\n\nFor example /dca PEPE 50 mon 09:00 buys $50 of PEPE every Monday at 09:00 in your timezone."
+)
+
+// NewDCAHandler returns a handler for the /dca command, which creates a
+// recurring buy plan, or lists the user's plans when called without arguments.
+func NewDCAHandler(service *dca.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("Recurring buys are temporarily unavailable.")
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		args := commandArgs(c.Text())
+		if len(args) == 0 {
+			return sendDCAPlans(ctx, c, service, userID, log)
+		}
+
+		request, err := dca.ParseRequest(args)
+		if err != nil {
+			return c.Send(dcaUsage)
+		}
+
+		plan, err := service.Create(ctx, userID, request)
+		if err != nil {
+			return c.Send(dcaErrorMessage(log, userID, err))
+		}
+
+		message := fmt.Sprintf("🔁 DCA plan #%d created: %s\nFirst run: %s\n\nManage your plans with /dca.",
+			plan.ID, dca.Describe(plan), plan.NextRunAt.In(service.Location(ctx, userID)).Format(dcaTimeLayout))
+		return c.Send(message)
+	}
+}
+
+// HandleDCAPause pauses the plan encoded in the callback and refreshes the list.
+func HandleDCAPause(service *dca.Service, log *slog.Logger) CallbackHandler {
+	return dcaPlanCallback(service, log, "Paused", func(ctx context.Context, userID, planID int64) error {
+		_, err := service.Pause(ctx, userID, planID)
+		return err
+	})
+}
+
+// HandleDCAResume resumes the plan encoded in the callback and refreshes the list.
+func HandleDCAResume(service *dca.Service, log *slog.Logger) CallbackHandler {
+	return dcaPlanCallback(service, log, "Resumed", func(ctx context.Context, userID, planID int64) error {
+		_, err := service.Resume(ctx, userID, planID)
+		return err
+	})
+}
+
+// HandleDCADelete deletes the plan encoded in the callback and refreshes the list.
+func HandleDCADelete(service *dca.Service, log *slog.Logger) CallbackHandler {
+	return dcaPlanCallback(service, log, "Deleted", func(ctx context.Context, userID, planID int64) error {
+		return service.Delete(ctx, userID, planID)
+	})
+}
+
+func dcaPlanCallback(service *dca.Service, log *slog.Logger, notice string, apply func(ctx context.Context, userID, planID int64) error) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		planID, err := strconv.ParseInt(callbackPayload(c), 10, 64)
+		if err != nil {
+			return respondCallback(c, "Unknown plan", true)
+		}
+
+		if err := apply(ctx, userID, planID); err != nil {
+			if errors.Is(err, domain.ErrPlanNotFound) {
+				return respondCallback(c, "Unknown plan", true)
+			}
+			log.Error("dca plan update failed", slog.Int64("telegram_id", userID), slog.Int64("plan_id", planID), slog.Any("error", err))
+			return respondCallback(c, "Unable to update the plan right now.", true)
+		}
+
+		if err := respondCallback(c, notice, false); err != nil {
+			log.Warn("dca: failed to answer plan callback", slog.Any("error", err))
+		}
+
+		plans, err := service.List(ctx, userID)
+		if err != nil {
+			log.Error("dca: failed to reload plans", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		message, markup, err := renderDCAPlans(plans, service.Location(ctx, userID))
+		if err != nil {
+			log.Error("dca: failed to render plans", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func sendDCAPlans(ctx context.Context, c telebot.Context, service *dca.Service, userID int64, log *slog.Logger) error {
+	plans, err := service.List(ctx, userID)
+	if err != nil {
+		log.Error("dca handler failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return c.Send("Unable to load your plans right now. Please try again later.")
+	}
+
+	message, markup, err := renderDCAPlans(plans, service.Location(ctx, userID))
+	if err != nil {
+		log.Error("dca handler failed to render", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return c.Send(defaultInternalErrorMessage)
+	}
+
+	return c.Send(message, markup)
+}
+
+func renderDCAPlans(plans []*domain.DCAPlan, loc *time.Location) (string, *telebot.ReplyMarkup, error) {
+	if len(plans) == 0 {
+		return "You have no DCA plans.\n\n" + dcaUsage, nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🔁 DCA plans\n\n")
+
+	builder := keyboard.NewInlineKeyboard()
+	for _, plan := range plans {
+		id := strconv.FormatInt(plan.ID, 10)
+		fmt.Fprintf(&sb, "#%d %s\n", plan.ID, dca.Describe(plan))
+
+		toggle := keyboard.InlineButton{Text: fmt.Sprintf("Pause #%d ⏸", plan.ID), Unique: dcaPauseAction, Data: id}
+		if plan.Status == domain.PlanStatusActive {
+			fmt.Fprintf(&sb, "Next run: %s\n\n", plan.NextRunAt.In(loc).Format(dcaTimeLayout))
+		} else {
+			sb.WriteString("Paused\n\n")
+			toggle = keyboard.InlineButton{Text: fmt.Sprintf("Resume #%d ▶️", plan.ID), Unique: dcaResumeAction, Data: id}
+		}
+
+		builder.AddRow(toggle, keyboard.InlineButton{
+			Text:   fmt.Sprintf("Delete #%d ❌", plan.ID),
+			Unique: dcaDeleteAction,
+			Data:   id,
+		})
+	}
+	fmt.Fprintf(&sb, "%d of %d plan(s).", len(plans), dca.MaxPlans)
+
+	markup, err := builder.Build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sb.String(), markup, nil
+}
+
+func dcaErrorMessage(log *slog.Logger, userID int64, err error) string {
+	switch {
+	case errors.Is(err, market.ErrTokenNotFound):
+		return "Token not found. Send a contract address or a symbol."
+	case errors.Is(err, dca.ErrTooManyPlans):
+		return fmt.Sprintf("You already have %d DCA plans. Delete one in /dca first.", dca.MaxPlans)
+	default:
+		log.Error("dca plan failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return defaultInternalErrorMessage
+	}
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/exits"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	exitMenuAction   = "exit_menu"
+	exitPresetAction = "exit_set"
+	exitsUsage       = "Usage:\n/sltp <token> [sl <price|%>] [tp <price|%>] [trail <%>]\n/sltp <token> off\n\nPercentages are measured from your average price, e.g. /sltp PEPE sl 10% tp 50% trail 15%. Use off for a single level to remove it."
+)
+
+// exitPresets are the shortcut buttons of the exit menu, one row per level.
+var exitPresets = [][]struct {
+	label string
+	code  string
+}{
+	{{"SL −5%", "sl5"}, {"SL −10%", "sl10"}, {"SL −20%", "sl20"}},
+	{{"TP +25%", "tp25"}, {"TP +50%", "tp50"}, {"TP +100%", "tp100"}},
+	{{"Trail 10%", "tr10"}, {"Trail 20%", "tr20"}},
+	{{"Remove all ❌", "off"}},
+}
+
+// NewExitsHandler returns a handler for the /sltp command, which sets the
+// stop-loss, take-profit and trailing stop of a position.
+func NewExitsHandler(service *exits.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("Stop-loss and take-profit are temporarily unavailable.")
+		}
+
+		args := commandArgs(c.Text())
+		if len(args) < 2 {
+			return c.Send(exitsUsage)
+		}
+
+		update, err := exits.ParseUpdate(args[1:])
+		if err != nil {
+			return c.Send(exitsUsage)
+		}
+
+		userID := c.Sender().ID
+		position, exit, err := service.ApplyToToken(context.Background(), userID, args[0], update)
+		if err != nil {
+			return c.Send(exitErrorMessage(log, userID, err))
+		}
+
+		return c.Send(renderExitLevels(position, exit))
+	}
+}
+
+// HandleExitMenu shows the exit levels of the position encoded in the
+// callback with preset buttons to change them.
+func HandleExitMenu(service *exits.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		userID := c.Sender().ID
+		positionID, err := strconv.ParseInt(callbackPayload(c), 10, 64)
+		if err != nil {
+			return respondCallback(c, "Unknown position", true)
+		}
+
+		position, exit, err := service.Get(context.Background(), userID, positionID)
+		if err != nil {
+			return respondCallback(c, exitErrorMessage(log, userID, err), true)
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("exits: failed to answer menu callback", slog.Any("error", err))
+		}
+
+		markup, err := exitMenuMarkup(position.ID)
+		if err != nil {
+			log.Error("exits: failed to build menu", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		return c.Send(renderExitMenu(position, exit), markup)
+	}
+}
+
+// HandleExitPreset applies the preset encoded in the callback and refreshes the menu.
+func HandleExitPreset(service *exits.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		userID := c.Sender().ID
+		rawID, code, _ := strings.Cut(callbackPayload(c), keyboard.CallbackDataSeparator)
+		positionID, err := strconv.ParseInt(rawID, 10, 64)
+		if err != nil {
+			return respondCallback(c, "Unknown position", true)
+		}
+
+		update, err := exitPresetUpdate(code)
+		if err != nil {
+			return respondCallback(c, "Unknown preset", true)
+		}
+
+		position, exit, err := service.Apply(context.Background(), userID, positionID, update)
+		if err != nil {
+			return respondCallback(c, exitErrorMessage(log, userID, err), true)
+		}
+
+		if err := respondCallback(c, "Saved", false); err != nil {
+			log.Warn("exits: failed to answer preset callback", slog.Any("error", err))
+		}
+
+		markup, err := exitMenuMarkup(position.ID)
+		if err != nil {
+			log.Error("exits: failed to build menu", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		message := renderExitMenu(position, exit)
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// exitMenuButton opens the exit menu of a position.
+func exitMenuButton(text string, positionID int64) keyboard.InlineButton {
+	return keyboard.InlineButton{Text: text, Unique: exitMenuAction, Data: strconv.FormatInt(positionID, 10)}
+}
+
+func exitMenuMarkup(positionID int64) (*telebot.ReplyMarkup, error) {
+	builder := keyboard.NewInlineKeyboard()
+	for _, presets := range exitPresets {
+		row := make([]keyboard.InlineButton, 0, len(presets))
+		for _, preset := range presets {
+			row = append(row, keyboard.InlineButton{
+				Text:   preset.label,
+				Unique: exitPresetAction,
+				Data:   strconv.FormatInt(positionID, 10) + keyboard.CallbackDataSeparator + preset.code,
+			})
+		}
+		builder.AddRow(row...)
+	}
+
+	return builder.Build()
+}
+
+func exitPresetUpdate(code string) (exits.Update, error) {
+	if code == "off" {
+		return exits.Update{Clear: true}, nil
+	}
+
+	if len(code) < 3 {
+		return exits.Update{}, exits.ErrInvalidUpdate
+	}
+	percent, err := strconv.Atoi(code[2:])
+	if err != nil || percent <= 0 {
+		return exits.Update{}, exits.ErrInvalidUpdate
+	}
+
+	switch code[:2] {
+	case "sl":
+		return exits.Update{StopLoss: &exits.Level{Percent: percent}}, nil
+	case "tp":
+		return exits.Update{TakeProfit: &exits.Level{Percent: percent}}, nil
+	case "tr":
+		return exits.Update{TrailingPercent: &percent}, nil
+	default:
+		return exits.Update{}, exits.ErrInvalidUpdate
+	}
+}
+
+func renderExitMenu(position *domain.Position, exit *domain.PositionExit) string {
+	return renderExitLevels(position, exit) + fmt.Sprintf(
+		"\n\nPick a preset below, or send /sltp %s sl <price|%%> tp <price|%%> trail <%%> for custom levels.",
+		positionLabel(position),
+	)
+}
+
+func renderExitLevels(position *domain.Position, exit *domain.PositionExit) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "🛡 %s — %s\nAvg. price: $%s\n\n",
+		positionLabel(position),
+		formatTokenAmount(position.Amount),
+		formatPrice(position.AvgPriceUSD),
+	)
+
+	sb.WriteString("Stop-loss: ")
+	sb.WriteString(exitPriceLabel(exit.StopLossUSD))
+	sb.WriteString("\nTake-profit: ")
+	sb.WriteString(exitPriceLabel(exit.TakeProfitUSD))
+	sb.WriteString("\nTrailing stop: ")
+	if exit.TrailingPercent == 0 {
+		sb.WriteString("not set")
+	} else {
+		fmt.Fprintf(&sb, "%d%% below peak $%s (currently $%s)",
+			exit.TrailingPercent,
+			formatPrice(exit.TrailingPeakUSD),
+			formatPrice(exit.TrailingStopUSD()),
+		)
+	}
+
+	return sb.String()
+}
+
+func exitPriceLabel(price money.Decimal) string {
+	if price.IsZero() {
+		return "not set"
+	}
+	return "$" + formatPrice(price)
+}
+
+func exitErrorMessage(log *slog.Logger, userID int64, err error) string {
+	switch {
+	case errors.Is(err, market.ErrTokenNotFound):
+		return "Token not found. Send a contract address or a symbol."
+	case errors.Is(err, domain.ErrPositionNotFound):
+		return "You have no open position in this token."
+	case errors.Is(err, exits.ErrStopLossAbovePrice):
+		return "The stop-loss must be below the current price."
+	case errors.Is(err, exits.ErrTakeProfitBelowPrice):
+		return "The take-profit must be above the current price."
+	case errors.Is(err, market.ErrPriceUnavailable):
+		return "The price for this token is unavailable right now. Please try again later."
+	case errors.Is(err, market.ErrStalePrice):
+		return "The latest price for this token is too old. Please try again in a minute."
+	default:
+		log.Error("exit levels update failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return defaultInternalErrorMessage
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import "github.com/Proton-105/himera-bot/pkg/money"
+
+// formatUSD renders a USD value with cents precision.
+func formatUSD(value money.Money) string {
+	return value.StringFixed(2, money.RoundHalfUp)
+}
+
+// formatPrice renders a unit price keeping enough digits for sub-cent tokens.
+func formatPrice(value money.Decimal) string {
+	return value.StringTrimmed(12, money.RoundHalfUp)
+}
+
+// formatTokenAmount renders a token quantity without trailing zeros.
+// Amounts are truncated so a holding is never displayed larger than it is.
+func formatTokenAmount(value money.Decimal) string {
+	return value.StringTrimmed(6, money.RoundDown)
+}
+
+// formatSignedUSD renders a USD delta such as PnL with an explicit sign.
+func formatSignedUSD(value money.Money) string {
+	if value.Sign() < 0 {
+		return "-$" + formatUSD(value.Neg())
+	}
+	return "+$" + formatUSD(value)
+}
+
+// compactUnits are the suffixes formatCompactUSD abbreviates to, largest first.
+var compactUnits = []struct {
+	suffix string
+	size   money.Decimal
+}{
+	{"B", money.NewFromInt(1_000_000_000)},
+	{"M", money.NewFromInt(1_000_000)},
+	{"K", money.NewFromInt(1_000)},
+}
+
+// formatCompactUSD renders a large USD figure such as liquidity or volume
+// with one decimal and a unit suffix, e.g. 5.9M. Figures are truncated.
+func formatCompactUSD(value money.Decimal) string {
+	for _, unit := range compactUnits {
+		if value.Abs().Cmp(unit.size) < 0 {
+			continue
+		}
+		scaled, err := value.Quo(unit.size, 1, money.RoundDown)
+		if err != nil {
+			break
+		}
+		return scaled.StringTrimmed(1, money.RoundDown) + unit.suffix
+	}
+	return value.StringFixed(0, money.RoundDown)
+}
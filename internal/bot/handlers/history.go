@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/exits"
+	"github.com/Proton-105/himera-bot/internal/history"
+	"github.com/Proton-105/himera-bot/internal/i18n"
+	"github.com/Proton-105/himera-bot/internal/user"
+)
+
+const (
+	historyPageAction = "history"
+	historyTimeLayout = "2006-01-02 15:04"
+	historyUsage      = "Usage: /history [buy|sell] [token] [YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD]"
+)
+
+// NewHistoryHandler returns a handler for the /history command. Arguments after the
+// command filter the listing by side, token and date range.
+func NewHistoryHandler(histories *history.Service, userService *user.Service, i18nManager *i18n.Manager, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if histories == nil {
+			return c.Send("Trade history is temporarily unavailable.")
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+		loc := userLocation(ctx, userService, userID, log)
+
+		query, err := history.ParseQuery(commandArgs(c.Text()), loc)
+		if err != nil {
+			return c.Send(historyUsage)
+		}
+
+		page, err := histories.Start(ctx, userID, query)
+		if err != nil {
+			log.Error("history handler failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send("Unable to load your trade history right now. Please try again later.")
+		}
+
+		message, markup, err := renderHistory(c, page, loc, i18nManager)
+		if err != nil {
+			log.Error("history handler failed to render", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		return c.Send(message, markup)
+	}
+}
+
+// HandleHistoryPage re-renders the history message for the page encoded in the callback.
+func HandleHistoryPage(histories *history.Service, userService *user.Service, i18nManager *i18n.Manager, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || histories == nil {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		number, err := strconv.Atoi(callbackPayload(c))
+		if err != nil {
+			number = 1
+		}
+
+		page, err := histories.Open(ctx, userID, number)
+		if err != nil {
+			if errors.Is(err, history.ErrSessionExpired) {
+				return respondCallback(c, "This list has expired. Send /history again.", true)
+			}
+			log.Error("history page failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return respondCallback(c, "Unable to load your trade history right now.", true)
+		}
+
+		loc := userLocation(ctx, userService, userID, log)
+		message, markup, err := renderHistory(c, page, loc, i18nManager)
+		if err != nil {
+			log.Error("history page failed to render", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return respondCallback(c, "Unable to load your trade history right now.", true)
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("history: failed to answer page callback", slog.Any("error", err))
+		}
+
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func renderHistory(c telebot.Context, page *history.Page, loc *time.Location, i18nManager *i18n.Manager) (string, *telebot.ReplyMarkup, error) {
+	var sb strings.Builder
+	sb.WriteString("📝 Trade history")
+	if filters := describeHistoryFilter(page, loc); filters != "" {
+		sb.WriteString(" — ")
+		sb.WriteString(filters)
+	}
+	sb.WriteString("\n\n")
+
+	if len(page.Trades) == 0 {
+		if page.Filter == (domain.TradeFilter{}) {
+			sb.WriteString("You have no trades yet. Use /buy to open a position.")
+		} else {
+			sb.WriteString("No trades match these filters.")
+		}
+		return sb.String(), nil, nil
+	}
+
+	for _, trade := range page.Trades {
+		sb.WriteString(formatHistoryTrade(trade, page, loc))
+		sb.WriteString("\n\n")
+	}
+	fmt.Fprintf(&sb, "Showing %d of %d trade(s).", len(page.Trades), page.Total)
+
+	if page.TotalPages <= 1 {
+		return sb.String(), nil, nil
+	}
+
+	translator := translatorFor(i18nManager, c.Sender().LanguageCode)
+	markup, err := keyboard.NewInlineKeyboard().
+		AddRow(keyboard.PaginationButtons(translator, historyPageAction, page.Number, page.TotalPages)...).
+		Build()
+	if err != nil {
+		return "", nil, fmt.Errorf("build pagination: %w", err)
+	}
+
+	return sb.String(), markup, nil
+}
+
+func formatHistoryTrade(trade *domain.Trade, page *history.Page, loc *time.Location) string {
+	token := domain.Token{Address: trade.TokenAddress, Symbol: trade.TokenSymbol}
+	if token.Symbol == "" && page.TokenLabel != "" && trade.TokenAddress == page.Filter.TokenAddress {
+		token.Symbol = page.TokenLabel
+	}
+
+	side := "🟢 BUY"
+	if trade.Type == domain.TradeTypeSell {
+		side = "🔴 SELL"
+	}
+	if trade.ExitReason != "" {
+		side += " · " + exits.ReasonLabel(trade.ExitReason)
+	}
+
+	line := fmt.Sprintf("%s %s %s\n%s @ $%s = $%s",
+		trade.CreatedAt.In(loc).Format(historyTimeLayout),
+		side,
+		tokenLabel(token),
+		formatTokenAmount(trade.Amount),
+		formatPrice(trade.PriceUSD),
+		formatUSD(trade.TotalUSD),
+	)
+	if trade.Type == domain.TradeTypeSell {
+		line += " · PnL: " + formatSignedUSD(trade.PnLUSD)
+	}
+
+	return line
+}
+
+func describeHistoryFilter(page *history.Page, loc *time.Location) string {
+	parts := make([]string, 0, 3)
+
+	if page.Filter.Type != "" {
+		parts = append(parts, string(page.Filter.Type))
+	}
+	if page.Filter.TokenAddress != "" {
+		label := page.TokenLabel
+		if label == "" {
+			label = shortAddress(page.Filter.TokenAddress)
+		}
+		parts = append(parts, label)
+	}
+
+	const dayLayout = "2006-01-02"
+	from, to := page.Filter.From, page.Filter.To
+	switch {
+	case !from.IsZero() && !to.IsZero():
+		parts = append(parts, fmt.Sprintf("%s..%s", from.In(loc).Format(dayLayout), to.In(loc).AddDate(0, 0, -1).Format(dayLayout)))
+	case !from.IsZero():
+		parts = append(parts, "from "+from.In(loc).Format(dayLayout))
+	case !to.IsZero():
+		parts = append(parts, "until "+to.In(loc).AddDate(0, 0, -1).Format(dayLayout))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// userLocation returns the timezone from the user's settings, falling back to UTC.
+func userLocation(ctx context.Context, userService *user.Service, userID int64, log *slog.Logger) *time.Location {
+	if userService == nil {
+		return time.UTC
+	}
+
+	settings, err := userService.GetSettings(ctx, userID)
+	if err != nil || settings == nil || strings.TrimSpace(settings.Timezone) == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(strings.TrimSpace(settings.Timezone))
+	if err != nil {
+		if log != nil {
+			log.Warn("unknown user timezone", slog.Int64("telegram_id", userID), slog.String("timezone", settings.Timezone))
+		}
+		return time.UTC
+	}
+
+	return loc
+}
+
+// commandArgs returns the whitespace-separated arguments following a /command.
+// Messages that are not commands, such as reply keyboard buttons, have no arguments.
+func commandArgs(text string) []string {
+	if !strings.HasPrefix(text, "/") {
+		return nil
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return nil
+	}
+
+	return fields[1:]
+}
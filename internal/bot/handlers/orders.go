@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/internal/orders"
+)
+
+const (
+	orderCancelAction = "order_cancel"
+	orderTimeLayout   = "2006-01-02 15:04 UTC"
+	limitUsage        = "Usage:\n/limit buy <token> <usd amount> <limit price> [expiry]\n/limit sell <token> <percent> <limit price> [expiry]\n\nExpiry is a duration such as 90m, 12h or 7d; without it the order stays open until cancelled."
+)
+
+// NewLimitHandler returns a handler for the /limit command, which places a limit order.
+func NewLimitHandler(service *orders.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("Limit orders are temporarily unavailable.")
+		}
+
+		request, err := orders.ParseRequest(commandArgs(c.Text()))
+		if err != nil {
+			return c.Send(limitUsage)
+		}
+
+		userID := c.Sender().ID
+		order, err := service.Place(context.Background(), userID, request)
+		if err != nil {
+			return c.Send(orderErrorMessage(log, userID, err))
+		}
+
+		message := fmt.Sprintf("📌 Order #%d placed: %s\n%s\n\nSee your open orders with /orders.", order.ID, orders.Describe(order), orderExpiry(order))
+		return c.Send(message)
+	}
+}
+
+// NewOrdersHandler returns a handler for the /orders command, which lists the
+// user's open orders with a cancel button for each.
+func NewOrdersHandler(service *orders.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("Limit orders are temporarily unavailable.")
+		}
+
+		userID := c.Sender().ID
+		open, err := service.List(context.Background(), userID)
+		if err != nil {
+			log.Error("orders handler failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send("Unable to load your orders right now. Please try again later.")
+		}
+
+		message, markup, err := renderOrders(open)
+		if err != nil {
+			log.Error("orders handler failed to render", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		return c.Send(message, markup)
+	}
+}
+
+// HandleOrderCancel cancels the order encoded in the callback and refreshes the list.
+func HandleOrderCancel(service *orders.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		orderID, err := strconv.ParseInt(callbackPayload(c), 10, 64)
+		if err != nil {
+			return respondCallback(c, "Unknown order", true)
+		}
+
+		notice := fmt.Sprintf("Order #%d cancelled.", orderID)
+		if _, err := service.Cancel(ctx, userID, orderID); err != nil {
+			switch {
+			case errors.Is(err, domain.ErrOrderNotFound):
+				return respondCallback(c, "Unknown order", true)
+			case errors.Is(err, domain.ErrOrderNotOpen):
+				notice = fmt.Sprintf("Order #%d is no longer open.", orderID)
+			default:
+				log.Error("order cancel failed", slog.Int64("telegram_id", userID), slog.Int64("order_id", orderID), slog.Any("error", err))
+				return respondCallback(c, "Unable to cancel the order right now.", true)
+			}
+		}
+
+		if err := respondCallback(c, notice, false); err != nil {
+			log.Warn("orders: failed to answer cancel callback", slog.Any("error", err))
+		}
+
+		open, err := service.List(ctx, userID)
+		if err != nil {
+			log.Error("orders: failed to reload list", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		message, markup, err := renderOrders(open)
+		if err != nil {
+			log.Error("orders: failed to render list", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func renderOrders(open []*domain.Order) (string, *telebot.ReplyMarkup, error) {
+	if len(open) == 0 {
+		return "You have no open orders. Place one with /limit.", nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📌 Open orders\n\n")
+
+	builder := keyboard.NewInlineKeyboard()
+	for _, order := range open {
+		fmt.Fprintf(&sb, "#%d %s\n%s\n\n", order.ID, orders.Describe(order), orderExpiry(order))
+		builder.AddRow(keyboard.InlineButton{
+			Text:   fmt.Sprintf("Cancel #%d ❌", order.ID),
+			Unique: orderCancelAction,
+			Data:   strconv.FormatInt(order.ID, 10),
+		})
+	}
+	fmt.Fprintf(&sb, "%d of %d open order(s).", len(open), orders.MaxOpenOrders)
+
+	markup, err := builder.Build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sb.String(), markup, nil
+}
+
+func orderExpiry(order *domain.Order) string {
+	if order.ExpiresAt == nil {
+		return "Good till cancelled"
+	}
+	return "Expires " + order.ExpiresAt.UTC().Format(orderTimeLayout)
+}
+
+func orderErrorMessage(log *slog.Logger, userID int64, err error) string {
+	switch {
+	case errors.Is(err, market.ErrTokenNotFound):
+		return "Token not found. Send a contract address or a symbol."
+	case errors.Is(err, domain.ErrPositionNotFound):
+		return "You have no open position in this token to sell."
+	case errors.Is(err, orders.ErrTooManyOrders):
+		return fmt.Sprintf("You already have %d open orders. Cancel one in /orders first.", orders.MaxOpenOrders)
+	default:
+		log.Error("limit order failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return defaultInternalErrorMessage
+	}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/i18n"
+	"github.com/Proton-105/himera-bot/internal/portfolio"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	portfolioPageAction = "portfolio"
+	portfolioPageSize   = 5
+)
+
+// NewPortfolioHandler returns a handler for the /portfolio command.
+func NewPortfolioHandler(portfolios *portfolio.Service, i18nManager *i18n.Manager, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if portfolios == nil {
+			return c.Send("Portfolio is temporarily unavailable.")
+		}
+
+		message, markup, err := renderPortfolio(c, portfolios, i18nManager, 1)
+		if err != nil {
+			log.Error("portfolio handler failed", slog.Int64("telegram_id", c.Sender().ID), slog.Any("error", err))
+			return c.Send("Unable to load your portfolio right now. Please try again later.")
+		}
+
+		return c.Send(message, markup)
+	}
+}
+
+// HandlePortfolioPage re-renders the portfolio message for the page encoded in the callback.
+func HandlePortfolioPage(portfolios *portfolio.Service, i18nManager *i18n.Manager, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || portfolios == nil {
+			return nil
+		}
+
+		page, err := strconv.Atoi(callbackPayload(c))
+		if err != nil {
+			page = 1
+		}
+
+		message, markup, err := renderPortfolio(c, portfolios, i18nManager, page)
+		if err != nil {
+			log.Error("portfolio page failed", slog.Int64("telegram_id", c.Sender().ID), slog.Any("error", err))
+			return respondCallback(c, "Unable to load your portfolio right now.", true)
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("portfolio: failed to answer page callback", slog.Any("error", err))
+		}
+
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func renderPortfolio(
+	c telebot.Context,
+	portfolios *portfolio.Service,
+	i18nManager *i18n.Manager,
+	page int,
+) (string, *telebot.ReplyMarkup, error) {
+	summary, err := portfolios.Summary(context.Background(), c.Sender().ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(summary.Holdings) == 0 {
+		return "📊 Your portfolio is empty. Use /buy to open a position.", nil, nil
+	}
+
+	totalPages := (len(summary.Holdings) + portfolioPageSize - 1) / portfolioPageSize
+	if page < 1 {
+		page = 1
+	}
+	if page > totalPages {
+		page = totalPages
+	}
+
+	start := (page - 1) * portfolioPageSize
+	end := start + portfolioPageSize
+	if end > len(summary.Holdings) {
+		end = len(summary.Holdings)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📊 Portfolio\n\n")
+	for _, holding := range summary.Holdings[start:end] {
+		sb.WriteString(formatHolding(holding))
+		sb.WriteString("\n\n")
+	}
+
+	fmt.Fprintf(&sb, "Value: $%s\nCost: $%s\nUnrealized PnL: %s%s",
+		formatUSD(summary.TotalValueUSD),
+		formatUSD(summary.TotalCostUSD),
+		formatSignedUSD(summary.TotalPnLUSD),
+		formatPercentSuffix(summary.TotalPnLPercent()),
+	)
+	if summary.Unpriced > 0 {
+		fmt.Fprintf(&sb, "\n⚠️ %d position(s) have no recent price and are excluded from totals.", summary.Unpriced)
+	}
+
+	builder := keyboard.NewInlineKeyboard()
+	for _, holding := range summary.Holdings[start:end] {
+		position := holding.Position
+		builder.AddRow(exitMenuButton("🛡 SL/TP · "+positionLabel(position), position.ID))
+	}
+	if totalPages > 1 {
+		translator := translatorFor(i18nManager, c.Sender().LanguageCode)
+		builder.AddRow(keyboard.PaginationButtons(translator, portfolioPageAction, page, totalPages)...)
+	}
+
+	markup, err := builder.Build()
+	if err != nil {
+		return "", nil, fmt.Errorf("build portfolio keyboard: %w", err)
+	}
+
+	return sb.String(), markup, nil
+}
+
+func formatHolding(holding portfolio.Holding) string {
+	position := holding.Position
+	label := tokenLabel(domain.Token{Address: position.TokenAddress, Symbol: position.TokenSymbol})
+
+	if !holding.Priced() {
+		return fmt.Sprintf("%s — %s\nAvg: $%s · Price unavailable",
+			label,
+			formatTokenAmount(position.Amount),
+			formatPrice(position.AvgPriceUSD),
+		)
+	}
+
+	return fmt.Sprintf("%s — %s\nAvg: $%s · Now: $%s\nValue: $%s · PnL: %s%s",
+		label,
+		formatTokenAmount(position.Amount),
+		formatPrice(position.AvgPriceUSD),
+		formatPrice(holding.PriceUSD),
+		formatUSD(holding.ValueUSD),
+		formatSignedUSD(holding.PnLUSD),
+		formatPercentSuffix(holding.PnLPercent, !holding.CostUSD.IsZero()),
+	)
+}
+
+func formatPercentSuffix(percent money.Decimal, ok bool) string {
+	if !ok {
+		return ""
+	}
+
+	sign := "+"
+	if percent.Sign() < 0 {
+		sign = ""
+	}
+
+	return fmt.Sprintf(" (%s%s%%)", sign, percent.StringFixed(2, money.RoundHalfUp))
+}
@@ -40,11 +40,10 @@ func NewProfileHandler(userService *user.Service, log *slog.Logger) Handler {
 			username = "@" + username
 		}
 
-		balanceUSD := float64(profile.Balance) / 100
 		message := fmt.Sprintf(
-			"Username: %s\nBalance: %.2f USD\nJoined: %s",
+			"Username: %s\nBalance: %s USD\nJoined: %s",
 			username,
-			balanceUSD,
+			formatUSD(profile.Balance),
 			profile.CreatedAt.Format("January 2, 2006"),
 		)
 
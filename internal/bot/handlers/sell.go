@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/i18n"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/internal/state"
+	"github.com/Proton-105/himera-bot/internal/trade"
+)
+
+const (
+	sellPositionAction = "sell_pos"
+	sellPercentAction  = "sell_pct"
+	sellCancelAction   = "sell_cancel"
+
+	sellContextPositionID = "position_id"
+	sellContextPercent    = "percent"
+)
+
+// NewSellHandler starts the /sell conversation by listing the user's open positions.
+func NewSellHandler(fsm state.StateMachine, trades *trade.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			log.Warn("sell handler invoked without sender")
+			return nil
+		}
+
+		if fsm == nil || trades == nil {
+			log.Error("sell handler is not fully configured")
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		positions, err := trades.ListPositions(ctx, userID)
+		if err != nil {
+			return c.Send(sellErrorMessage(log, userID, err))
+		}
+
+		if len(positions) == 0 {
+			return c.Send("You have no open positions. Use /buy to open one.")
+		}
+
+		if err := fsm.SetState(ctx, userID, state.StateSellingSelect, map[string]interface{}{}); err != nil {
+			log.Error("sell: failed to enter select state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		markup, err := positionsMarkup(positions)
+		if err != nil {
+			log.Error("sell: failed to build positions keyboard", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		return c.Send("📉 Choose the position you want to sell:", markup)
+	}
+}
+
+// HandleSellPosition stores the selected position and asks for the share to sell.
+func HandleSellPosition(fsm state.StateMachine, trades *trade.Service, i18nManager *i18n.Manager, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		if _, ok := requireState(c, fsm, log, state.StateSellingSelect); !ok {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		positionID, err := strconv.ParseInt(callbackPayload(c), 10, 64)
+		if err != nil {
+			return respondCallback(c, "Unknown position", true)
+		}
+
+		position, err := trades.GetPosition(ctx, userID, positionID)
+		if err != nil {
+			resetToIdle(ctx, fsm, log, userID)
+			return respondCallback(c, sellErrorMessage(log, userID, err), true)
+		}
+
+		contextData := map[string]interface{}{
+			sellContextPositionID: strconv.FormatInt(position.ID, 10),
+		}
+		if err := fsm.TransitionWithContext(ctx, userID, state.StateSellingPercent, contextData); err != nil {
+			log.Error("sell: failed to enter percent state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("sell: failed to answer position callback", slog.Any("error", err))
+		}
+
+		markup, err := percentMarkup(translatorFor(i18nManager, c.Sender().LanguageCode))
+		if err != nil {
+			log.Error("sell: failed to build percent keyboard", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		message := fmt.Sprintf(
+			"%s\nAmount: %s\nAvg. price: $%s\n\nHow much of the position do you want to sell?",
+			positionLabel(position),
+			formatTokenAmount(position.Amount),
+			formatPrice(position.AvgPriceUSD),
+		)
+
+		return c.Send(message, markup)
+	}
+}
+
+// HandleSellPercent handles the percentage shortcuts built by keyboard.PercentButtons.
+func HandleSellPercent(fsm state.StateMachine, trades *trade.Service, kb *keyboard.Builder, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		if _, ok := requireState(c, fsm, log, state.StateSellingPercent); !ok {
+			return nil
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("sell: failed to answer percent callback", slog.Any("error", err))
+		}
+
+		payload := callbackPayload(c)
+		if payload == keyboard.PercentCustomData {
+			return c.Send("Type the percentage to sell, from 1 to 100.", cancelMarkup(kb))
+		}
+
+		return proceedToSellConfirm(c, fsm, trades, kb, log, payload)
+	}
+}
+
+// NewSellPercentHandler accepts a free-text percentage while in StateSellingPercent.
+func NewSellPercentHandler(fsm state.StateMachine, trades *trade.Service, kb *keyboard.Builder, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		return proceedToSellConfirm(c, fsm, trades, kb, log, c.Text())
+	}
+}
+
+// HandleSellConfirm executes the sale prepared in StateSellingConfirm.
+func HandleSellConfirm(fsm state.StateMachine, trades *trade.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil {
+			return nil
+		}
+
+		userState, ok := requireState(c, fsm, log, state.StateSellingConfirm)
+		if !ok {
+			return nil
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		positionID, idErr := strconv.ParseInt(contextString(userState.Context, sellContextPositionID), 10, 64)
+		percent, pctErr := trade.ParsePercent(contextString(userState.Context, sellContextPercent))
+		if idErr != nil || pctErr != nil {
+			log.Error("sell: confirm state is missing order details", slog.Int64("telegram_id", userID))
+			resetToIdle(ctx, fsm, log, userID)
+			return respondCallback(c, "Order details expired. Start again with /sell.", true)
+		}
+
+		if err := respondCallback(c, "Placing order…", false); err != nil {
+			log.Warn("sell: failed to answer confirm callback", slog.Any("error", err))
+		}
+
+		executed, err := trades.ExecuteSell(ctx, trade.SellOrder{
+			UserID:     userID,
+			PositionID: positionID,
+			Percent:    percent,
+		})
+		resetToIdle(ctx, fsm, log, userID)
+		if err != nil {
+			return c.Send(sellErrorMessage(log, userID, err))
+		}
+
+		message := fmt.Sprintf(
+			"✅ Sold %s %s at $%s\nProceeds: $%s (fee $%s)\nPnL: %s",
+			formatTokenAmount(executed.Amount),
+			tokenLabel(domain.Token{Address: executed.TokenAddress, Symbol: executed.TokenSymbol}),
+			formatPrice(executed.PriceUSD),
+			formatUSD(executed.TotalUSD),
+			formatUSD(executed.FeeUSD),
+			formatSignedUSD(executed.PnLUSD),
+		)
+
+		return c.Send(message)
+	}
+}
+
+// HandleSellCancel aborts the sale and returns the user to idle.
+func HandleSellCancel(fsm state.StateMachine, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil {
+			return nil
+		}
+
+		resetToIdle(context.Background(), fsm, log, c.Sender().ID)
+
+		if err := respondCallback(c, "Sale cancelled", false); err != nil {
+			log.Warn("sell: failed to answer cancel callback", slog.Any("error", err))
+		}
+
+		return c.Send("Sale cancelled.")
+	}
+}
+
+func proceedToSellConfirm(
+	c telebot.Context,
+	fsm state.StateMachine,
+	trades *trade.Service,
+	kb *keyboard.Builder,
+	log *slog.Logger,
+	rawPercent string,
+) error {
+	ctx := context.Background()
+	userID := c.Sender().ID
+
+	userState, err := fsm.GetState(ctx, userID)
+	if err != nil {
+		log.Error("sell: failed to load state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return c.Send(defaultInternalErrorMessage)
+	}
+
+	positionID, err := strconv.ParseInt(contextString(userState.Context, sellContextPositionID), 10, 64)
+	if err != nil {
+		resetToIdle(ctx, fsm, log, userID)
+		return c.Send("Position selection expired. Start again with /sell.")
+	}
+
+	percent, err := trade.ParsePercent(rawPercent)
+	if err != nil {
+		return c.Send(sellErrorMessage(log, userID, err), cancelMarkup(kb))
+	}
+
+	quote, err := trades.QuoteSell(ctx, userID, positionID, percent)
+	if err != nil {
+		if errors.Is(err, domain.ErrPositionNotFound) {
+			resetToIdle(ctx, fsm, log, userID)
+		}
+		return c.Send(sellErrorMessage(log, userID, err))
+	}
+
+	contextData := map[string]interface{}{
+		sellContextPositionID: strconv.FormatInt(positionID, 10),
+		sellContextPercent:    strconv.Itoa(percent),
+	}
+	if err := fsm.TransitionWithContext(ctx, userID, state.StateSellingConfirm, contextData); err != nil {
+		log.Error("sell: failed to enter confirm state", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return c.Send(defaultInternalErrorMessage)
+	}
+
+	message := fmt.Sprintf(
+		"Confirm sale\n\nToken: %s\nSell: %d%% (%s)\n%s\nYou receive ≈ $%s\nEstimated PnL: %s",
+		positionLabel(quote.Position),
+		quote.Percent,
+		formatTokenAmount(quote.TokenAmount),
+		fillBreakdown(quote.Fill),
+		formatUSD(quote.ProceedsUSD),
+		formatSignedUSD(quote.PnLUSD),
+	)
+
+	if kb == nil {
+		return c.Send(message)
+	}
+
+	return c.Send(message, kb.ConfirmButtons("sell"))
+}
+
+func sellErrorMessage(log *slog.Logger, userID int64, err error) string {
+	switch {
+	case errors.Is(err, domain.ErrPositionNotFound):
+		return "This position is no longer open."
+	case errors.Is(err, trade.ErrInvalidPercent):
+		return "Enter a whole percentage from 1 to 100, for example 25."
+	case errors.Is(err, market.ErrPriceUnavailable):
+		return "The price for this token is unavailable right now. Please try again later."
+	case errors.Is(err, market.ErrStalePrice):
+		return "The latest price for this token is too old to trade on. Please try again in a minute."
+	case errors.Is(err, trade.ErrInsufficientLiquidity):
+		return "This token has no known liquidity to trade against."
+	case errors.Is(err, trade.ErrPriceImpactTooHigh):
+		return priceImpactMessage(err, "Try selling a smaller share.")
+	default:
+		log.Error("sell flow failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return defaultInternalErrorMessage
+	}
+}
+
+func positionsMarkup(positions []*domain.Position) (*telebot.ReplyMarkup, error) {
+	builder := keyboard.NewInlineKeyboard()
+	for _, position := range positions {
+		builder.AddRow(keyboard.InlineButton{
+			Text:   fmt.Sprintf("%s — %s", positionLabel(position), formatTokenAmount(position.Amount)),
+			Unique: sellPositionAction,
+			Data:   strconv.FormatInt(position.ID, 10),
+		})
+	}
+	builder.AddRow(keyboard.InlineButton{Text: "Cancel ❌", Unique: sellCancelAction})
+
+	return builder.Build()
+}
+
+func percentMarkup(t i18n.Translator) (*telebot.ReplyMarkup, error) {
+	builder := keyboard.NewInlineKeyboard()
+	for _, row := range keyboard.PercentButtons(t, sellPercentAction, sellCancelAction) {
+		builder.AddRow(row...)
+	}
+
+	return builder.Build()
+}
+
+func positionLabel(position *domain.Position) string {
+	return tokenLabel(domain.Token{Address: position.TokenAddress, Symbol: position.TokenSymbol})
+}
+
+// callbackPayload returns the data encoded after the callback action prefix.
+func callbackPayload(c telebot.Context) string {
+	cb := c.Callback()
+	if cb == nil {
+		return ""
+	}
+
+	_, data, err := keyboard.DecodeCallback(strings.TrimSpace(cb.Data))
+	if err != nil {
+		return ""
+	}
+
+	return data
+}
@@ -51,7 +51,7 @@ func NewSettingsHandler(userService *user.Service, kb *keyboard.Builder, log *sl
 		}
 
 		message := fmt.Sprintf(
-			"Notifications: %s\nLanguage: %s\nTimezone: %s",
+			"Notifications: %s\nLanguage: %s\nTimezone: %s\n\nNotifications control price alerts from /alert.",
 			boolLabel(settings.NotificationsEnabled, "On", "Off"),
 			strings.ToUpper(settings.Language),
 			settings.Timezone,
@@ -96,7 +96,7 @@ func HandleToggleNotifications(userService *user.Service, log *slog.Logger) Call
 			return respondCallback(c, "Unable to update settings", true)
 		}
 
-		statusText := boolLabel(settings.NotificationsEnabled, "Notifications enabled", "Notifications disabled")
+		statusText := boolLabel(settings.NotificationsEnabled, "Notifications enabled", "Notifications disabled. Price alerts are muted.")
 		return respondCallback(c, statusText, false)
 	}
 }
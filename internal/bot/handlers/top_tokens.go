@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/state"
+	"github.com/Proton-105/himera-bot/internal/tokens"
+	"github.com/Proton-105/himera-bot/internal/toptokens"
+	"github.com/Proton-105/himera-bot/internal/trade"
+)
+
+const (
+	topTabAction = "top_tab"
+	topBuyAction = "top_buy"
+)
+
+var topTabLabels = map[toptokens.Tab]string{
+	toptokens.TabVolume:  "🔥 Volume",
+	toptokens.TabGainers: "🚀 Gainers",
+	toptokens.TabLosers:  "📉 Losers",
+	toptokens.TabNew:     "🆕 New",
+}
+
+var topTabHeadings = map[toptokens.Tab]string{
+	toptokens.TabVolume:  "📈 Top tokens by 24h volume",
+	toptokens.TabGainers: "🚀 Top gainers, 24h",
+	toptokens.TabLosers:  "📉 Top losers, 24h",
+	toptokens.TabNew:     "🆕 Newly listed tokens",
+}
+
+var topTabEmpty = map[toptokens.Tab]string{
+	toptokens.TabVolume:  "No tokens were traded in the last 24 hours.",
+	toptokens.TabGainers: "No token rose in the last 24 hours.",
+	toptokens.TabLosers:  "No token fell in the last 24 hours.",
+	toptokens.TabNew:     "No token was listed in the last 30 days.",
+}
+
+// NewTopTokensHandler returns a handler for the /top command and the Top Tokens
+// menu button, which opens the top tokens screen on the volume tab.
+func NewTopTokensHandler(service *toptokens.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("Top tokens are temporarily unavailable.")
+		}
+
+		message, markup, err := loadTopTokens(context.Background(), service, toptokens.TabVolume)
+		if err != nil {
+			return c.Send(topTokensErrorMessage(log, c.Sender().ID, err))
+		}
+
+		return c.Send(message, markup)
+	}
+}
+
+// HandleTopTokensTab switches the top tokens screen to the tab encoded in the
+// callback, editing the message in place.
+func HandleTopTokensTab(service *toptokens.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		tab, ok := toptokens.ParseTab(callbackPayload(c))
+		if !ok {
+			return respondCallback(c, "Unknown tab", true)
+		}
+
+		message, markup, err := loadTopTokens(context.Background(), service, tab)
+		if err != nil {
+			return respondCallback(c, topTokensErrorMessage(log, c.Sender().ID, err), true)
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("top tokens: failed to answer tab callback", slog.Any("error", err))
+		}
+
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// HandleTopTokensBuy starts the buy flow at the token card of the picked
+// token, replacing any conversation in progress.
+func HandleTopTokensBuy(fsm state.StateMachine, trades *trade.Service, search *tokens.Service, kb *keyboard.Builder, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || fsm == nil || trades == nil || search == nil {
+			return nil
+		}
+
+		tokenAddress := callbackPayload(c)
+		if tokenAddress == "" {
+			return respondCallback(c, "Unknown token", true)
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		token, err := search.Get(ctx, tokenAddress)
+		if err != nil {
+			return respondCallback(c, buyErrorMessage(log, userID, err), true)
+		}
+
+		if err := fsm.SetState(ctx, userID, state.StateBuyingSearch, map[string]interface{}{}); err != nil {
+			log.Error("top tokens: failed to enter buy flow", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return respondCallback(c, defaultInternalErrorMessage, true)
+		}
+
+		if err := respondCallback(c, "", false); err != nil {
+			log.Warn("top tokens: failed to answer buy callback", slog.Any("error", err))
+		}
+
+		return showTokenCard(c, fsm, trades, kb, log, token.Token)
+	}
+}
+
+func loadTopTokens(ctx context.Context, service *toptokens.Service, tab toptokens.Tab) (string, *telebot.ReplyMarkup, error) {
+	entries, computedAt, err := service.Board(ctx, tab)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return renderTopTokens(tab, entries, computedAt)
+}
+
+func renderTopTokens(tab toptokens.Tab, entries []toptokens.Entry, computedAt time.Time) (string, *telebot.ReplyMarkup, error) {
+	var sb strings.Builder
+	sb.WriteString(topTabHeadings[tab])
+	sb.WriteString("\n\n")
+
+	builder := keyboard.NewInlineKeyboard()
+	if len(entries) == 0 {
+		sb.WriteString(topTabEmpty[tab])
+		sb.WriteString("\n")
+	}
+	for i, entry := range entries {
+		label := entry.Symbol
+		if label == "" {
+			label = shortAddress(entry.Address)
+		}
+
+		fmt.Fprintf(&sb, "%d. %s — $%s", i+1, label, formatPrice(entry.PriceUSD))
+		if entry.HasChange {
+			fmt.Fprintf(&sb, " (%s)", formatChange(entry.ChangePct))
+		}
+		switch tab {
+		case toptokens.TabVolume:
+			fmt.Fprintf(&sb, " · vol $%s", formatCompactUSD(entry.VolumeUSD))
+		case toptokens.TabNew:
+			fmt.Fprintf(&sb, " · listed %s ago", formatAge(computedAt.Sub(entry.ListedAt)))
+		}
+		sb.WriteString("\n")
+
+		if _, err := keyboard.EncodeCallback(topBuyAction, entry.Address); err == nil {
+			builder.AddRow(keyboard.InlineButton{
+				Text:   fmt.Sprintf("🛒 Buy %s", label),
+				Unique: topBuyAction,
+				Data:   entry.Address,
+			})
+		}
+	}
+	fmt.Fprintf(&sb, "\nUpdated %s UTC.", computedAt.UTC().Format("15:04"))
+
+	tabs := make([]keyboard.InlineButton, 0, len(toptokens.Tabs))
+	for _, candidate := range toptokens.Tabs {
+		text := topTabLabels[candidate]
+		if candidate == tab {
+			text = "• " + text
+		}
+		tabs = append(tabs, keyboard.InlineButton{Text: text, Unique: topTabAction, Data: string(candidate)})
+	}
+	builder.AddRow(tabs[:2]...).AddRow(tabs[2:]...)
+
+	markup, err := builder.Build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sb.String(), markup, nil
+}
+
+// formatAge renders a duration in its largest whole unit, e.g. 5h or 3d.
+func formatAge(age time.Duration) string {
+	switch {
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", max(int(age/time.Minute), 1))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(age/time.Hour))
+	default:
+		return fmt.Sprintf("%dd", int(age/(24*time.Hour)))
+	}
+}
+
+func topTokensErrorMessage(log *slog.Logger, userID int64, err error) string {
+	if errors.Is(err, toptokens.ErrNotReady) {
+		return "Top tokens are being computed. Please try again in a few minutes."
+	}
+
+	log.Error("top tokens handler failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+	return "Unable to load top tokens right now. Please try again later."
+}
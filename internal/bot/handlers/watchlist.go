@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/internal/watchlist"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	watchAddAction    = "watch_add"
+	watchRemoveAction = "watch_del"
+	watchlistUsage    = "Usage:\n/watch <token> — follow a token's price\n/unwatch <token> — stop following it\n/watchlist — prices and 24h changes of the tokens you follow"
+)
+
+// NewWatchHandler returns a handler for the /watch command, which adds a
+// token to the user's watchlist.
+func NewWatchHandler(service *watchlist.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("The watchlist is temporarily unavailable.")
+		}
+
+		args := commandArgs(c.Text())
+		if len(args) != 1 {
+			return c.Send(watchlistUsage)
+		}
+
+		userID := c.Sender().ID
+		token, err := service.Watch(context.Background(), userID, args[0])
+		if err != nil {
+			return c.Send(watchErrorMessage(log, userID, token, err))
+		}
+
+		return c.Send(fmt.Sprintf("⭐ %s added to your watchlist. See prices with /watchlist.", tokenLabel(*token)))
+	}
+}
+
+// NewUnwatchHandler returns a handler for the /unwatch command, which
+// removes a token from the user's watchlist.
+func NewUnwatchHandler(service *watchlist.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("The watchlist is temporarily unavailable.")
+		}
+
+		args := commandArgs(c.Text())
+		if len(args) != 1 {
+			return c.Send(watchlistUsage)
+		}
+
+		userID := c.Sender().ID
+		entry, err := service.Unwatch(context.Background(), userID, args[0])
+		if err != nil {
+			return c.Send(watchErrorMessage(log, userID, nil, err))
+		}
+
+		return c.Send(fmt.Sprintf("%s removed from your watchlist.", watchedLabel(entry)))
+	}
+}
+
+// NewWatchlistHandler returns a handler for the /watchlist command, which
+// shows the watched tokens with their cached prices and 24h changes.
+func NewWatchlistHandler(service *watchlist.Service, log *slog.Logger) Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil {
+			return nil
+		}
+
+		if service == nil {
+			return c.Send("The watchlist is temporarily unavailable.")
+		}
+
+		userID := c.Sender().ID
+		items, err := service.List(context.Background(), userID)
+		if err != nil {
+			log.Error("watchlist handler failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send("Unable to load your watchlist right now. Please try again later.")
+		}
+
+		message, markup, err := renderWatchlist(items)
+		if err != nil {
+			log.Error("watchlist handler failed to render", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return c.Send(defaultInternalErrorMessage)
+		}
+
+		return c.Send(message, markup)
+	}
+}
+
+// HandleWatchAdd adds the token encoded in the callback to the watchlist.
+func HandleWatchAdd(service *watchlist.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		tokenAddress := callbackPayload(c)
+		if tokenAddress == "" {
+			return respondCallback(c, "Unknown token", true)
+		}
+
+		userID := c.Sender().ID
+		token, err := service.Watch(context.Background(), userID, tokenAddress)
+		switch {
+		case errors.Is(err, domain.ErrAlreadyWatching):
+			return respondCallback(c, fmt.Sprintf("%s is already on your watchlist", tokenLabel(*token)), false)
+		case err != nil:
+			return respondCallback(c, watchErrorMessage(log, userID, token, err), true)
+		}
+
+		return respondCallback(c, fmt.Sprintf("⭐ %s added to your watchlist", tokenLabel(*token)), false)
+	}
+}
+
+// HandleWatchRemove removes the token encoded in the callback from the
+// watchlist and refreshes the list.
+func HandleWatchRemove(service *watchlist.Service, log *slog.Logger) CallbackHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(c telebot.Context) error {
+		if c == nil || c.Sender() == nil || service == nil {
+			return nil
+		}
+
+		tokenAddress := callbackPayload(c)
+		if tokenAddress == "" {
+			return respondCallback(c, "Unknown token", true)
+		}
+
+		ctx := context.Background()
+		userID := c.Sender().ID
+
+		if _, err := service.Unwatch(ctx, userID, tokenAddress); err != nil {
+			return respondCallback(c, watchErrorMessage(log, userID, nil, err), true)
+		}
+
+		if err := respondCallback(c, "Removed", false); err != nil {
+			log.Warn("watchlist: failed to answer remove callback", slog.Any("error", err))
+		}
+
+		items, err := service.List(ctx, userID)
+		if err != nil {
+			log.Error("watchlist: failed to reload watchlist", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		message, markup, err := renderWatchlist(items)
+		if err != nil {
+			log.Error("watchlist: failed to render watchlist", slog.Int64("telegram_id", userID), slog.Any("error", err))
+			return nil
+		}
+
+		if c.Message() == nil {
+			return c.Send(message, markup)
+		}
+
+		if err := c.Edit(message, markup); err != nil &&
+			!errors.Is(err, telebot.ErrMessageNotModified) && !errors.Is(err, telebot.ErrSameMessageContent) {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// watchButton adds the token to the watchlist. ok is false when the address
+// does not fit in callback data; Build sends the encoded data without a
+// telebot prefix, so the encoded size is the size Telegram checks.
+func watchButton(tokenAddress string) (button keyboard.InlineButton, ok bool) {
+	if _, err := keyboard.EncodeCallback(watchAddAction, tokenAddress); err != nil {
+		return keyboard.InlineButton{}, false
+	}
+	return keyboard.InlineButton{Text: "☆ Watch", Unique: watchAddAction, Data: tokenAddress}, true
+}
+
+func renderWatchlist(items []watchlist.Item) (string, *telebot.ReplyMarkup, error) {
+	if len(items) == 0 {
+		return "Your watchlist is empty.\n\n" + watchlistUsage, nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⭐ Watchlist\n\n")
+
+	builder := keyboard.NewInlineKeyboard()
+	for _, item := range items {
+		label := watchedLabel(item.Entry)
+		sb.WriteString(label)
+		sb.WriteString(" — ")
+		if item.PriceUSD.Sign() > 0 {
+			fmt.Fprintf(&sb, "$%s", formatPrice(item.PriceUSD))
+			if change, ok := item.Change(); ok {
+				fmt.Fprintf(&sb, " (%s 24h)", formatChange(change))
+			}
+		} else {
+			sb.WriteString("price pending")
+		}
+		sb.WriteString("\n")
+
+		if _, err := keyboard.EncodeCallback(watchRemoveAction, item.Entry.TokenAddress); err == nil {
+			builder.AddRow(keyboard.InlineButton{
+				Text:   fmt.Sprintf("Unwatch %s ✖", label),
+				Unique: watchRemoveAction,
+				Data:   item.Entry.TokenAddress,
+			})
+		}
+	}
+	fmt.Fprintf(&sb, "\n%d of %d token(s).", len(items), watchlist.MaxEntries)
+
+	markup, err := builder.Build()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return sb.String(), markup, nil
+}
+
+func watchedLabel(entry *domain.WatchlistEntry) string {
+	return tokenLabel(domain.Token{Address: entry.TokenAddress, Symbol: entry.TokenSymbol})
+}
+
+// formatChange writes a percentage with an explicit sign, e.g. +4.20%.
+func formatChange(change money.Decimal) string {
+	sign := ""
+	if change.Sign() > 0 {
+		sign = "+"
+	}
+	return sign + change.StringFixed(2, money.RoundHalfEven) + "%"
+}
+
+func watchErrorMessage(log *slog.Logger, userID int64, token *domain.Token, err error) string {
+	switch {
+	case errors.Is(err, market.ErrTokenNotFound):
+		return "Token not found. Send a contract address or a symbol."
+	case errors.Is(err, domain.ErrAlreadyWatching) && token != nil:
+		return fmt.Sprintf("%s is already on your watchlist.", tokenLabel(*token))
+	case errors.Is(err, domain.ErrNotWatching):
+		return "This token is not on your watchlist."
+	case errors.Is(err, watchlist.ErrWatchlistFull):
+		return fmt.Sprintf("You already watch %d tokens. Remove one with /unwatch first.", watchlist.MaxEntries)
+	default:
+		log.Error("watchlist update failed", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		return defaultInternalErrorMessage
+	}
+}
@@ -38,6 +38,9 @@ func (b *InlineKeyboardBuilder) AddRow(buttons ...InlineButton) *InlineKeyboardB
 }
 
 // Build finalizes inline markup encoding callback data using EncodeCallback.
+// The telebot button carries no Unique: telebot would prefix the data on the
+// wire with "\f<unique>|", which the router does not register, and the
+// encoded data already names the action.
 func (b *InlineKeyboardBuilder) Build() (*telebot.ReplyMarkup, error) {
 	if b.markup == nil {
 		b.markup = &telebot.ReplyMarkup{}
@@ -53,9 +56,8 @@ func (b *InlineKeyboardBuilder) Build() (*telebot.ReplyMarkup, error) {
 			}
 
 			inlineKeyboard[i][j] = telebot.InlineButton{
-				Text:   btn.Text,
-				Unique: btn.Unique,
-				Data:   callbackData,
+				Text: btn.Text,
+				Data: callbackData,
 			}
 		}
 	}
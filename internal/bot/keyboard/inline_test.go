@@ -33,6 +33,8 @@ func TestInlineKeyboardBuilder(t *testing.T) {
 		testutil.AssertEqual(t, 2, len(markup.InlineKeyboard[0]))
 		testutil.AssertEqual(t, 1, len(markup.InlineKeyboard[1]))
 		testutil.AssertEqual(t, "nav:2", markup.InlineKeyboard[0][1].Data)
+		// Without Unique telebot sends Data as is instead of "\fnav|nav:2".
+		testutil.AssertEqual(t, "", markup.InlineKeyboard[0][1].Unique)
 	})
 
 	t.Run("callback data overflow", func(t *testing.T) {
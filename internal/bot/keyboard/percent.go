@@ -0,0 +1,44 @@
+package keyboard
+
+import (
+	"strconv"
+
+	"github.com/Proton-105/himera-bot/internal/i18n"
+)
+
+// PercentCustomData is the payload of the button asking the user to type a percentage.
+const PercentCustomData = "custom"
+
+var percentOptions = []int{10, 25, 50, 100}
+
+// PercentButtons returns rows of percentage shortcuts (10/25/50/100%), a custom
+// input button and a cancel button. Percent buttons share the action prefix and
+// carry the percentage (or PercentCustomData) as payload.
+func PercentButtons(t i18n.Translator, action, cancelAction string) [][]InlineButton {
+	options := make([]InlineButton, 0, len(percentOptions))
+	for _, pct := range percentOptions {
+		value := strconv.Itoa(pct)
+		options = append(options, InlineButton{
+			Text:   translated(t, "amount_keyboard.pct_"+value, value+"%"),
+			Unique: action,
+			Data:   value,
+		})
+	}
+
+	return [][]InlineButton{
+		options,
+		{
+			{
+				Text:   translated(t, "amount_keyboard.custom", "✏️ Custom amount"),
+				Unique: action,
+				Data:   PercentCustomData,
+			},
+		},
+		{
+			{
+				Text:   translated(t, "amount_keyboard.cancel", "❌ Cancel"),
+				Unique: cancelAction,
+			},
+		},
+	}
+}
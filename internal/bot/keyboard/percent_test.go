@@ -0,0 +1,36 @@
+package keyboard_test
+
+import (
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/testutil"
+)
+
+func TestPercentButtons(t *testing.T) {
+	translator := &mockTranslator{
+		translations: map[string]string{
+			"amount_keyboard.pct_25": "25%",
+			"amount_keyboard.custom": "✏️ Своя сумма",
+		},
+	}
+
+	rows := keyboard.PercentButtons(translator, "sell_pct", "sell_cancel")
+	testutil.AssertEqual(t, 3, len(rows))
+	testutil.AssertEqual(t, 4, len(rows[0]))
+	testutil.AssertEqual(t, "25%", rows[0][1].Text)
+	testutil.AssertEqual(t, "10%", rows[0][0].Text)
+	testutil.AssertEqual(t, "✏️ Своя сумма", rows[1][0].Text)
+	testutil.AssertEqual(t, "❌ Cancel", rows[2][0].Text)
+
+	builder := keyboard.NewInlineKeyboard()
+	for _, row := range rows {
+		builder.AddRow(row...)
+	}
+
+	markup, err := builder.Build()
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, "sell_pct:100", markup.InlineKeyboard[0][3].Data)
+	testutil.AssertEqual(t, "sell_pct:"+keyboard.PercentCustomData, markup.InlineKeyboard[1][0].Data)
+	testutil.AssertEqual(t, "sell_cancel", markup.InlineKeyboard[2][0].Data)
+}
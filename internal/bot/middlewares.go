@@ -15,9 +15,11 @@ import (
 	errors "github.com/Proton-105/himera-bot/internal/errors"
 	"github.com/Proton-105/himera-bot/internal/repository"
 	"github.com/Proton-105/himera-bot/internal/user"
+	"github.com/Proton-105/himera-bot/pkg/money"
 )
 
-const defaultInitialBalance int64 = 10000
+// defaultInitialBalance is the paper-trading balance new users start with; it matches the users.balance column default.
+var defaultInitialBalance = money.NewMoney(money.NewFromInt(10000), money.USD, money.RoundDown)
 
 // RecoveryMiddleware catches panics, reports them via the centralized handler, and notifies the user.
 func RecoveryMiddleware(log *slog.Logger, errHandler *errors.Handler) handlers.Middleware {
@@ -199,3 +201,35 @@ func LastActiveMiddleware(userService *user.Service) handlers.Middleware {
 		}
 	}
 }
+
+// AdminOnlyMiddleware lets only the configured administrators through. Other
+// users get no reply, so admin commands stay invisible to them.
+func AdminOnlyMiddleware(adminIDs []int64, log *slog.Logger) handlers.Middleware {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	admins := make(map[int64]struct{}, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = struct{}{}
+	}
+
+	return func(next handlers.Handler) handlers.Handler {
+		if next == nil {
+			return nil
+		}
+
+		return func(c telebot.Context) error {
+			if c == nil || c.Sender() == nil {
+				return nil
+			}
+
+			if _, ok := admins[c.Sender().ID]; !ok {
+				log.Warn("admin command rejected", slog.Int64("user_id", c.Sender().ID), slog.String("text", c.Text()))
+				return nil
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	telebot "gopkg.in/telebot.v3"
+)
+
+// NotifyUser sends text to the user's private chat with the bot.
+func (b *Bot) NotifyUser(_ context.Context, telegramID int64, text string) error {
+	if b.telebot == nil {
+		return nil
+	}
+
+	if _, err := b.telebot.Send(&telebot.User{ID: telegramID}, text); err != nil {
+		return fmt.Errorf("notify user %d: %w", telegramID, err)
+	}
+
+	return nil
+}
@@ -17,6 +17,7 @@ import (
 type Router struct {
 	mu             sync.RWMutex
 	commands       map[string]handlers.Handler
+	texts          map[string]handlers.Handler
 	callbacks      map[string]handlers.CallbackHandler
 	dispatcher     *Dispatcher
 	defaultHandler handlers.Handler
@@ -32,6 +33,7 @@ func NewRouter(dispatcher *Dispatcher, log *slog.Logger) *Router {
 
 	return &Router{
 		commands:    make(map[string]handlers.Handler),
+		texts:       make(map[string]handlers.Handler),
 		callbacks:   make(map[string]handlers.CallbackHandler),
 		dispatcher:  dispatcher,
 		middlewares: make([]handlers.Middleware, 0),
@@ -46,6 +48,14 @@ func (r *Router) RegisterCommand(cmd string, h handlers.Handler) {
 	r.commands[cmd] = h
 }
 
+// RegisterText registers a handler for an exact message text, such as a reply keyboard button.
+// Text handlers take precedence over state handlers.
+func (r *Router) RegisterText(text string, h handlers.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.texts[strings.TrimSpace(text)] = h
+}
+
 // RegisterCallback registers a handler for callback data prefixes.
 func (r *Router) RegisterCallback(prefix string, h handlers.CallbackHandler) {
 	r.mu.Lock()
@@ -74,6 +84,8 @@ func (r *Router) Route(c telebot.Context) error {
 	}
 
 	if callback := c.Callback(); callback != nil {
+		// Handlers decode callback.Data themselves, so it is unwrapped in place.
+		callback.Data = unwrapCallbackData(callback.Data)
 		return r.handleCallback(c, callback.Data)
 	}
 
@@ -98,11 +110,15 @@ func (r *Router) handleMessage(c telebot.Context) error {
 	text := c.Text()
 
 	if strings.HasPrefix(text, "/") {
-		if handler := r.getCommandHandler(text); handler != nil {
+		if handler := r.getCommandHandler(commandName(text)); handler != nil {
 			return r.executeHandler(handler, c)
 		}
 	}
 
+	if handler := r.getTextHandler(text); handler != nil {
+		return r.executeHandler(handler, c)
+	}
+
 	stateHandled, err := r.dispatchState(c)
 	if err != nil {
 		return err
@@ -164,6 +180,22 @@ func (r *Router) executeHandler(h handlers.Handler, c telebot.Context) error {
 	return wrapped(c)
 }
 
+// unwrapCallbackData strips the "\f<unique>|" prefix telebot adds on the wire
+// to buttons that carry a Unique, as buttons sent before keyboard.Build
+// stopped setting it still do. Telebot only strips it itself for handlers
+// registered under "\f<unique>", and callbacks here go through the router.
+func unwrapCallbackData(data string) string {
+	if !strings.HasPrefix(data, "\f") {
+		return data
+	}
+
+	unique, payload, found := strings.Cut(data[1:], "|")
+	if !found {
+		return unique
+	}
+	return payload
+}
+
 func (r *Router) findCallbackHandler(data string) handlers.CallbackHandler {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -184,6 +216,24 @@ func (r *Router) getCommandHandler(cmd string) handlers.Handler {
 	return handler
 }
 
+func (r *Router) getTextHandler(text string) handlers.Handler {
+	r.mu.RLock()
+	handler := r.texts[strings.TrimSpace(text)]
+	r.mu.RUnlock()
+	return handler
+}
+
+// commandName extracts the command from a message such as "/history buy" or "/history@himera_bot".
+func commandName(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(fields[0], "@")
+	return name
+}
+
 func (r *Router) getDefaultHandler() handlers.Handler {
 	r.mu.RLock()
 	handler := r.defaultHandler
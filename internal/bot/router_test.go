@@ -0,0 +1,211 @@
+package bot
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	telebot "gopkg.in/telebot.v3"
+
+	"github.com/Proton-105/himera-bot/internal/bot/keyboard"
+	"github.com/Proton-105/himera-bot/internal/testutil"
+)
+
+func TestRouterRoutesBuiltKeyboardCallbacks(t *testing.T) {
+	router := NewRouter(nil, discardLogger())
+	var gotData []string
+	router.RegisterCallback(CallbackSellPosition, func(c telebot.Context) error {
+		gotData = append(gotData, c.Callback().Data)
+		return nil
+	})
+
+	markup, err := keyboard.NewInlineKeyboard().
+		AddRow(keyboard.InlineButton{Text: "BONK", Unique: CallbackSellPosition, Data: "42"}).
+		Build()
+	testutil.AssertNoError(t, err)
+
+	ctx := newCallbackContext(wireData(markup.InlineKeyboard[0][0]))
+	testutil.AssertNoError(t, router.Route(ctx))
+
+	testutil.AssertEqual(t, 1, len(gotData))
+	testutil.AssertEqual(t, "sell_pos:42", gotData[0])
+}
+
+func TestRouterUnwrapsTelebotCallbackPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		wire     string
+		prefix   string
+		expected string
+	}{
+		{name: "unique with data", wire: "\fsell_pos|sell_pos:42", prefix: CallbackSellPosition, expected: "sell_pos:42"},
+		{name: "unique without data", wire: "\fsell_cancel", prefix: CallbackSellCancel, expected: "sell_cancel"},
+		{name: "plain data", wire: "sell_pct:50", prefix: CallbackSellPercent, expected: "sell_pct:50"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			router := NewRouter(nil, discardLogger())
+			var gotData string
+			router.RegisterCallback(tc.prefix, func(c telebot.Context) error {
+				gotData = c.Callback().Data
+				return nil
+			})
+
+			testutil.AssertNoError(t, router.Route(newCallbackContext(tc.wire)))
+			testutil.AssertEqual(t, tc.expected, gotData)
+		})
+	}
+}
+
+func TestRouterRoutesPercentKeyboardCallbacks(t *testing.T) {
+	router := NewRouter(nil, discardLogger())
+	routed := make(map[string]string)
+	for _, prefix := range []string{CallbackSellPercent, CallbackSellCancel} {
+		prefix := prefix
+		router.RegisterCallback(prefix, func(c telebot.Context) error {
+			routed[prefix] = c.Callback().Data
+			return nil
+		})
+	}
+
+	builder := keyboard.NewInlineKeyboard()
+	for _, row := range keyboard.PercentButtons(nil, CallbackSellPercent, CallbackSellCancel) {
+		builder.AddRow(row...)
+	}
+	markup, err := builder.Build()
+	testutil.AssertNoError(t, err)
+
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			testutil.AssertNoError(t, router.Route(newCallbackContext(wireData(button))))
+		}
+	}
+
+	testutil.AssertEqual(t, "sell_cancel", routed[CallbackSellCancel])
+	testutil.AssertEqual(t, true, strings.HasPrefix(routed[CallbackSellPercent], "sell_pct:"))
+}
+
+// fakeContext implements the parts of telebot.Context the handlers use and
+// records what they send back.
+type fakeContext struct {
+	telebot.Context
+
+	sender    *telebot.User
+	callback  *telebot.Callback
+	text      string
+	sent      []sentMessage
+	responses []*telebot.CallbackResponse
+}
+
+type sentMessage struct {
+	what   any
+	markup *telebot.ReplyMarkup
+	edit   bool
+}
+
+func newCallbackContext(data string) *fakeContext {
+	sender := &telebot.User{ID: 1001}
+	return &fakeContext{
+		sender: sender,
+		callback: &telebot.Callback{
+			Sender:  sender,
+			Data:    data,
+			Message: &telebot.Message{ID: 7, Sender: sender},
+		},
+	}
+}
+
+func newTextContext(text string) *fakeContext {
+	return &fakeContext{sender: &telebot.User{ID: 1001}, text: text}
+}
+
+func (c *fakeContext) Sender() *telebot.User { return c.sender }
+
+func (c *fakeContext) Callback() *telebot.Callback { return c.callback }
+
+func (c *fakeContext) Text() string {
+	if c.callback != nil {
+		return ""
+	}
+	return c.text
+}
+
+func (c *fakeContext) Message() *telebot.Message {
+	if c.callback != nil {
+		return c.callback.Message
+	}
+	return &telebot.Message{ID: 7, Sender: c.sender, Text: c.text}
+}
+
+func (c *fakeContext) Send(what any, opts ...any) error {
+	c.sent = append(c.sent, sentMessage{what: what, markup: markupOf(opts)})
+	return nil
+}
+
+func (c *fakeContext) Edit(what any, opts ...any) error {
+	c.sent = append(c.sent, sentMessage{what: what, markup: markupOf(opts), edit: true})
+	return nil
+}
+
+func (c *fakeContext) Respond(resp ...*telebot.CallbackResponse) error {
+	c.responses = append(c.responses, resp...)
+	return nil
+}
+
+// lastMarkup returns the inline markup of the most recent message that had one.
+func (c *fakeContext) lastMarkup(t *testing.T) *telebot.ReplyMarkup {
+	t.Helper()
+	for i := len(c.sent) - 1; i >= 0; i-- {
+		if c.sent[i].markup != nil {
+			return c.sent[i].markup
+		}
+	}
+	t.Fatalf("no message with markup was sent")
+	return nil
+}
+
+func markupOf(opts []any) *telebot.ReplyMarkup {
+	for _, opt := range opts {
+		switch v := opt.(type) {
+		case *telebot.ReplyMarkup:
+			return v
+		case *telebot.SendOptions:
+			if v != nil && v.ReplyMarkup != nil {
+				return v.ReplyMarkup
+			}
+		}
+	}
+	return nil
+}
+
+// findButton returns the first button whose callback data starts with action.
+func findButton(t *testing.T, markup *telebot.ReplyMarkup, action string) telebot.InlineButton {
+	t.Helper()
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			if strings.HasPrefix(unwrapCallbackData(wireData(button)), action) {
+				return button
+			}
+		}
+	}
+	t.Fatalf("no button with action %q", action)
+	return telebot.InlineButton{}
+}
+
+// wireData returns the callback data Telegram hands back for button, applying
+// the "\f<unique>|" prefix telebot adds before sending a button with a Unique.
+func wireData(button telebot.InlineButton) string {
+	if button.Unique == "" {
+		return button.Data
+	}
+	if button.Data == "" {
+		return "\f" + button.Unique
+	}
+	return "\f" + button.Unique + "|" + button.Data
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -0,0 +1,69 @@
+package dca
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// ErrInvalidRequest indicates that the plan arguments could not be parsed.
+var ErrInvalidRequest = errors.New("invalid dca request")
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Request is a parsed /dca command. Token is the raw user input and is
+// resolved to an address by the Service. A nil Weekday means every day.
+type Request struct {
+	Token       string
+	AmountUSD   money.Money
+	Weekday     *time.Weekday
+	MinuteOfDay int
+}
+
+// ParseRequest parses /dca arguments:
+//
+//	<token> <usd amount> <daily|weekday> <HH:MM>
+//
+// Weekdays are English names or their first three letters, e.g. mon or
+// Monday; the time is on a 24-hour clock in the user's timezone.
+func ParseRequest(args []string) (Request, error) {
+	if len(args) != 4 {
+		return Request{}, fmt.Errorf("%w: expected 4 arguments, got %d", ErrInvalidRequest, len(args))
+	}
+
+	request := Request{Token: strings.TrimSpace(args[0])}
+
+	amount, err := trade.ParseAmountUSD(args[1])
+	if err != nil {
+		return Request{}, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	request.AmountUSD = amount
+
+	if day := strings.ToLower(strings.TrimSpace(args[2])); day != "daily" {
+		weekday, ok := weekdays[day]
+		if !ok {
+			return Request{}, fmt.Errorf("%w: unknown day %q", ErrInvalidRequest, args[2])
+		}
+		request.Weekday = &weekday
+	}
+
+	at, err := time.Parse("15:04", strings.TrimSpace(args[3]))
+	if err != nil {
+		return Request{}, fmt.Errorf("%w: time must look like 09:00, got %q", ErrInvalidRequest, args[3])
+	}
+	request.MinuteOfDay = at.Hour()*60 + at.Minute()
+
+	return request, nil
+}
@@ -0,0 +1,127 @@
+package dca
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func TestParseRequest(t *testing.T) {
+	weekly, err := ParseRequest([]string{"pepe", "$50", "Monday", "09:00"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if weekly.Token != "pepe" || weekly.Weekday == nil || *weekly.Weekday != time.Monday || weekly.MinuteOfDay != 9*60 {
+		t.Fatalf("unexpected weekly request: %+v", weekly)
+	}
+	if weekly.AmountUSD.StringFixed(2, money.RoundHalfEven) != "50.00" {
+		t.Errorf("amount = %s", weekly.AmountUSD)
+	}
+
+	daily, err := ParseRequest([]string{"bonk", "12,5", "DAILY", "7:45"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if daily.Weekday != nil || daily.MinuteOfDay != 7*60+45 {
+		t.Errorf("unexpected daily request: %+v", daily)
+	}
+
+	sunday, err := ParseRequest([]string{"wif", "10", "sun", "23:59"})
+	if err != nil || sunday.Weekday == nil || *sunday.Weekday != time.Sunday || sunday.MinuteOfDay != 1439 {
+		t.Errorf("sunday request = %+v, %v", sunday, err)
+	}
+}
+
+func TestParseRequestErrors(t *testing.T) {
+	testCases := [][]string{
+		nil,
+		{"pepe", "50", "mon"},
+		{"pepe", "0", "mon", "09:00"},
+		{"pepe", "50", "someday", "09:00"},
+		{"pepe", "50", "mon", "24:00"},
+		{"pepe", "50", "mon", "9am"},
+		{"pepe", "50", "mon", "09:00", "extra"},
+	}
+
+	for _, args := range testCases {
+		if _, err := ParseRequest(args); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("ParseRequest(%q) error = %v, want ErrInvalidRequest", args, err)
+		}
+	}
+}
+
+func TestPlanNextRun(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	monday := time.Monday
+	weekly := &domain.DCAPlan{Weekday: &monday, MinuteOfDay: 9 * 60}
+	daily := &domain.DCAPlan{MinuteOfDay: 2*60 + 30}
+
+	testCases := []struct {
+		name  string
+		plan  *domain.DCAPlan
+		loc   *time.Location
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "later the same week",
+			plan:  weekly,
+			loc:   berlin,
+			after: time.Date(2025, 3, 5, 12, 0, 0, 0, berlin), // Wednesday
+			want:  time.Date(2025, 3, 10, 9, 0, 0, 0, berlin),
+		},
+		{
+			name:  "exactly at the run moves to the next week",
+			plan:  weekly,
+			loc:   berlin,
+			after: time.Date(2025, 3, 10, 9, 0, 0, 0, berlin),
+			want:  time.Date(2025, 3, 17, 9, 0, 0, 0, berlin),
+		},
+		{
+			name:  "same day before the run",
+			plan:  weekly,
+			loc:   berlin,
+			after: time.Date(2025, 3, 10, 8, 59, 0, 0, berlin),
+			want:  time.Date(2025, 3, 10, 9, 0, 0, 0, berlin),
+		},
+		{
+			name:  "local wall clock across a DST change",
+			plan:  weekly,
+			loc:   berlin,
+			after: time.Date(2025, 3, 28, 12, 0, 0, 0, berlin),
+			want:  time.Date(2025, 3, 31, 7, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "daily time skipped by DST moves forward",
+			plan:  daily,
+			loc:   berlin,
+			after: time.Date(2025, 3, 29, 12, 0, 0, 0, berlin),
+			want:  time.Date(2025, 3, 30, 1, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "nil location is UTC",
+			plan:  daily,
+			after: time.Date(2025, 3, 5, 3, 0, 0, 0, time.UTC),
+			want:  time.Date(2025, 3, 6, 2, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.plan.NextRun(tc.after, tc.loc)
+			if !got.Equal(tc.want) {
+				t.Errorf("NextRun(%s) = %s, want %s", tc.after, got, tc.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("NextRun location = %s, want UTC", got.Location())
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package dca
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// Skip reasons stored on runs the runner could not buy.
+const (
+	skipInsufficientBalance = "insufficient balance"
+	skipNoLiquidity         = "insufficient liquidity"
+	skipPriceImpact         = "price impact too high"
+)
+
+// Executor confirms the market a run buys in and fills the purchase through
+// the execution model; trade.Service implements it.
+type Executor interface {
+	LatestMarket(ctx context.Context, tokenAddress string) (trade.Market, error)
+	BuyTrade(userID int64, token domain.Token, amountUSD money.Money, state trade.Market) (*domain.Trade, error)
+}
+
+// Notifier delivers a message to a user in Telegram.
+type Notifier interface {
+	NotifyUser(ctx context.Context, telegramID int64, text string) error
+}
+
+// Runner executes scheduled runs of DCA plans. It is called by the dca:buy
+// task handler; every run is recorded once, so retried tasks never buy twice.
+type Runner struct {
+	repo     repository.DCARepository
+	trades   Executor
+	notifier Notifier
+	log      *slog.Logger
+}
+
+// NewRunner constructs a Runner. notifier may be nil.
+func NewRunner(repo repository.DCARepository, trades Executor, notifier Notifier, log *slog.Logger) *Runner {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Runner{repo: repo, trades: trades, notifier: notifier, log: log}
+}
+
+// RunPlan buys the plan's amount at the confirmed price, through the
+// execution model, for its run at scheduledFor. Runs of deleted or paused
+// plans, and runs already recorded, do nothing; a run the balance does not
+// cover or the pools cannot absorb is recorded as skipped and the user is
+// told. Other errors are returned so that the task is retried.
+func (r *Runner) RunPlan(ctx context.Context, planID int64, scheduledFor time.Time) error {
+	plan, err := r.repo.Get(ctx, planID)
+	if errors.Is(err, domain.ErrPlanNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if plan.Status != domain.PlanStatusActive {
+		return nil
+	}
+
+	state, err := r.trades.LatestMarket(ctx, plan.TokenAddress)
+	if err != nil {
+		return fmt.Errorf("confirm price: %w", err)
+	}
+
+	executed, err := r.repo.Execute(ctx, planID, scheduledFor, func(locked *domain.DCAPlan) (*domain.Trade, error) {
+		token := domain.Token{Address: locked.TokenAddress, Symbol: locked.TokenSymbol}
+		return r.trades.BuyTrade(locked.TelegramID, token, locked.AmountUSD, state)
+	})
+
+	switch {
+	case err == nil:
+		r.log.InfoContext(ctx, "dca plan executed",
+			slog.Int64("telegram_id", executed.TelegramID),
+			slog.Int64("plan_id", planID),
+			slog.Int64("transaction_id", executed.ID),
+			slog.Time("scheduled_for", scheduledFor),
+			slog.String("price_usd", executed.PriceUSD.String()),
+		)
+		r.notify(ctx, executed.TelegramID, executedMessage(plan, executed))
+		return nil
+	case errors.Is(err, domain.ErrRunRecorded), errors.Is(err, domain.ErrPlanNotFound), errors.Is(err, domain.ErrPlanNotActive):
+		// Already run by an earlier attempt, or deleted or paused since.
+		return nil
+	case errors.Is(err, domain.ErrInsufficientBalance):
+		return r.skip(ctx, plan, scheduledFor, skipInsufficientBalance)
+	case errors.Is(err, trade.ErrInsufficientLiquidity):
+		return r.skip(ctx, plan, scheduledFor, skipNoLiquidity)
+	case errors.Is(err, trade.ErrPriceImpactTooHigh):
+		return r.skip(ctx, plan, scheduledFor, skipPriceImpact)
+	default:
+		return err
+	}
+}
+
+func (r *Runner) skip(ctx context.Context, plan *domain.DCAPlan, scheduledFor time.Time, reason string) error {
+	recorded, err := r.repo.RecordSkip(ctx, plan.ID, scheduledFor, reason)
+	if err != nil {
+		return err
+	}
+	if !recorded {
+		return nil
+	}
+
+	r.log.InfoContext(ctx, "dca plan run skipped",
+		slog.Int64("telegram_id", plan.TelegramID),
+		slog.Int64("plan_id", plan.ID),
+		slog.Time("scheduled_for", scheduledFor),
+		slog.String("reason", reason),
+	)
+
+	cause := fmt.Sprintf("$%s of %s could not be bought (%s)", plan.AmountUSD.StringFixed(2, money.RoundHalfUp), tokenLabel(plan), reason)
+	if reason == skipInsufficientBalance {
+		cause = fmt.Sprintf("your balance does not cover $%s of %s", plan.AmountUSD.StringFixed(2, money.RoundHalfUp), tokenLabel(plan))
+	}
+	r.notify(ctx, plan.TelegramID, fmt.Sprintf(
+		"⚠️ DCA plan #%d skipped: %s. The plan stays active and will try again at its next run.",
+		plan.ID, cause,
+	))
+
+	return nil
+}
+
+func (r *Runner) notify(ctx context.Context, telegramID int64, text string) {
+	if r.notifier == nil {
+		return
+	}
+
+	if err := r.notifier.NotifyUser(ctx, telegramID, text); err != nil {
+		r.log.WarnContext(ctx, "dca runner failed to notify user", slog.Int64("telegram_id", telegramID), slog.Any("error", err))
+	}
+}
+
+// Describe summarises a plan for users, e.g. "$50 of PEPE every Monday at 09:00".
+func Describe(plan *domain.DCAPlan) string {
+	day := "every day"
+	if plan.Weekday != nil {
+		day = "every " + plan.Weekday.String()
+	}
+
+	return fmt.Sprintf("$%s of %s %s at %02d:%02d",
+		plan.AmountUSD.Amount().StringTrimmed(2, money.RoundHalfUp),
+		tokenLabel(plan),
+		day,
+		plan.MinuteOfDay/60,
+		plan.MinuteOfDay%60,
+	)
+}
+
+func executedMessage(plan *domain.DCAPlan, executed *domain.Trade) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🔁 DCA plan #%d bought %s %s\n", plan.ID, executed.Amount.StringTrimmed(6, money.RoundDown), tokenLabel(plan))
+	fmt.Fprintf(&b, "Price: $%s\n", executed.PriceUSD.StringTrimmed(12, money.RoundHalfUp))
+	fmt.Fprintf(&b, "Total: $%s (fee $%s)", executed.TotalUSD.StringFixed(2, money.RoundHalfUp), executed.FeeUSD.StringFixed(2, money.RoundHalfUp))
+
+	return b.String()
+}
+
+func tokenLabel(plan *domain.DCAPlan) string {
+	if plan.TokenSymbol != "" {
+		return plan.TokenSymbol
+	}
+	return plan.TokenAddress
+}
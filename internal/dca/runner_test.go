@@ -0,0 +1,294 @@
+package dca
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type runKey struct {
+	planID       int64
+	scheduledFor time.Time
+}
+
+type fakeDCARepo struct {
+	repository.DCARepository
+
+	plans    map[int64]*domain.DCAPlan
+	balance  money.Money
+	runs     map[runKey]string
+	trades   []*domain.Trade
+	advanced map[int64]time.Time
+}
+
+func newFakeDCARepo(balance string, plans ...*domain.DCAPlan) *fakeDCARepo {
+	repo := &fakeDCARepo{
+		plans:    make(map[int64]*domain.DCAPlan),
+		runs:     make(map[runKey]string),
+		advanced: make(map[int64]time.Time),
+	}
+	repo.balance, _ = money.ParseMoney(balance, money.USD)
+	for _, plan := range plans {
+		repo.plans[plan.ID] = plan
+	}
+	return repo
+}
+
+func (r *fakeDCARepo) Get(_ context.Context, planID int64) (*domain.DCAPlan, error) {
+	plan, ok := r.plans[planID]
+	if !ok {
+		return nil, domain.ErrPlanNotFound
+	}
+	copied := *plan
+	return &copied, nil
+}
+
+func (r *fakeDCARepo) Execute(_ context.Context, planID int64, scheduledFor time.Time, buy repository.DCABuy) (*domain.Trade, error) {
+	plan, ok := r.plans[planID]
+	if !ok {
+		return nil, domain.ErrPlanNotFound
+	}
+	if plan.Status != domain.PlanStatusActive {
+		return nil, domain.ErrPlanNotActive
+	}
+	key := runKey{planID: planID, scheduledFor: scheduledFor}
+	if _, ok := r.runs[key]; ok {
+		return nil, domain.ErrRunRecorded
+	}
+
+	trade, err := buy(plan)
+	if err != nil {
+		return nil, err
+	}
+	if r.balance.Amount().Cmp(trade.TotalUSD.Amount()) < 0 {
+		return nil, domain.ErrInsufficientBalance
+	}
+
+	r.balance = money.NewMoney(r.balance.Amount().Sub(trade.TotalUSD.Amount()), money.USD, money.RoundDown)
+	trade.ID = int64(len(r.trades) + 1)
+	r.trades = append(r.trades, trade)
+	r.runs[key] = "executed"
+
+	return trade, nil
+}
+
+func (r *fakeDCARepo) RecordSkip(_ context.Context, planID int64, scheduledFor time.Time, reason string) (bool, error) {
+	key := runKey{planID: planID, scheduledFor: scheduledFor}
+	if _, ok := r.runs[key]; ok {
+		return false, nil
+	}
+	r.runs[key] = "skipped: " + reason
+	return true, nil
+}
+
+func (r *fakeDCARepo) ListDue(_ context.Context, now time.Time, _ int) ([]*domain.DCAPlan, error) {
+	var due []*domain.DCAPlan
+	for _, plan := range r.plans {
+		if plan.Status == domain.PlanStatusActive && !plan.NextRunAt.After(now) {
+			copied := *plan
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeDCARepo) Advance(_ context.Context, planID int64, from, next time.Time) (bool, error) {
+	plan, ok := r.plans[planID]
+	if !ok || !plan.NextRunAt.Equal(from) {
+		return false, nil
+	}
+	plan.NextRunAt = next
+	r.advanced[planID] = next
+	return true, nil
+}
+
+// fakeExecutor confirms a fixed price against pools of $200,000 and fills
+// through an execution model charging 0.3% and capping impact at 10%.
+type fakeExecutor struct {
+	price     money.Decimal
+	err       error
+	execution *trade.Service
+}
+
+func newFakeExecutor(t *testing.T, price money.Decimal, err error) *fakeExecutor {
+	t.Helper()
+
+	model, modelErr := trade.NewExecutionModel([]trade.FeeTier{{MinUSD: money.Zero, FeeBps: 30}}, 1000)
+	if modelErr != nil {
+		t.Fatalf("NewExecutionModel: %v", modelErr)
+	}
+	return &fakeExecutor{price: price, err: err, execution: trade.NewService(nil, nil, nil, nil, nil, model, nil)}
+}
+
+func (e *fakeExecutor) LatestMarket(context.Context, string) (trade.Market, error) {
+	return trade.Market{PriceUSD: e.price, LiquidityUSD: money.NewFromInt(200000)}, e.err
+}
+
+func (e *fakeExecutor) BuyTrade(userID int64, token domain.Token, amountUSD money.Money, state trade.Market) (*domain.Trade, error) {
+	return e.execution.BuyTrade(userID, token, amountUSD, state)
+}
+
+type fakeNotifier struct {
+	messages map[int64][]string
+}
+
+func (n *fakeNotifier) NotifyUser(_ context.Context, telegramID int64, text string) error {
+	if n.messages == nil {
+		n.messages = make(map[int64][]string)
+	}
+	n.messages[telegramID] = append(n.messages[telegramID], text)
+	return nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func testPlan(t *testing.T, id int64, amount string) *domain.DCAPlan {
+	t.Helper()
+
+	amountUSD, err := money.ParseMoney(amount, money.USD)
+	if err != nil {
+		t.Fatalf("parse %q: %v", amount, err)
+	}
+	monday := time.Monday
+
+	return &domain.DCAPlan{
+		ID:           id,
+		TelegramID:   7,
+		TokenAddress: "pepe-address",
+		TokenSymbol:  "PEPE",
+		AmountUSD:    amountUSD,
+		Weekday:      &monday,
+		MinuteOfDay:  9 * 60,
+		Status:       domain.PlanStatusActive,
+		NextRunAt:    time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRunnerBuysOncePerRun(t *testing.T) {
+	plan := testPlan(t, 1, "50")
+	repo := newFakeDCARepo("1000", plan)
+	notifier := &fakeNotifier{}
+	runner := NewRunner(repo, newFakeExecutor(t, money.New(25, 1), nil), notifier, discardLogger())
+
+	scheduledFor := plan.NextRunAt
+	for attempt := 0; attempt < 2; attempt++ {
+		if err := runner.RunPlan(context.Background(), plan.ID, scheduledFor); err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", attempt, err)
+		}
+	}
+
+	if len(repo.trades) != 1 {
+		t.Fatalf("expected 1 buy, got %d", len(repo.trades))
+	}
+	bought := repo.trades[0]
+	if bought.TelegramID != 7 || bought.TokenAddress != "pepe-address" || bought.Type != domain.TradeTypeBuy {
+		t.Errorf("unexpected trade: %+v", bought)
+	}
+	// $0.15 of the $50 pays the fee and the rest fills just above the price.
+	if bought.FeeUSD.StringFixed(2, money.RoundHalfUp) != "0.15" || bought.Amount.Cmp(money.New(1994, 2)) >= 0 || bought.Amount.Cmp(money.New(1993, 2)) <= 0 {
+		t.Errorf("bought %s with fee %s, want just under 19.94 with $0.15", bought.Amount, bought.FeeUSD)
+	}
+	if len(notifier.messages[7]) != 1 || !strings.Contains(notifier.messages[7][0], "DCA plan #1 bought 19.93") {
+		t.Errorf("unexpected notifications: %v", notifier.messages[7])
+	}
+}
+
+func TestRunnerSkipsWhenBalanceIsShort(t *testing.T) {
+	plan := testPlan(t, 1, "50")
+	repo := newFakeDCARepo("10", plan)
+	notifier := &fakeNotifier{}
+	runner := NewRunner(repo, newFakeExecutor(t, money.New(1, 0), nil), notifier, discardLogger())
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if err := runner.RunPlan(context.Background(), plan.ID, plan.NextRunAt); err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", attempt, err)
+		}
+	}
+
+	if len(repo.trades) != 0 {
+		t.Fatalf("expected no buys, got %d", len(repo.trades))
+	}
+	if got := repo.runs[runKey{planID: 1, scheduledFor: plan.NextRunAt}]; got != "skipped: "+skipInsufficientBalance {
+		t.Errorf("run = %q, want skipped", got)
+	}
+	if len(notifier.messages[7]) != 1 || !strings.Contains(notifier.messages[7][0], "skipped") {
+		t.Errorf("expected one skip notification, got %v", notifier.messages[7])
+	}
+}
+
+func TestRunnerSkipsWhenPriceImpactIsTooHigh(t *testing.T) {
+	plan := testPlan(t, 1, "20000")
+	repo := newFakeDCARepo("100000", plan)
+	notifier := &fakeNotifier{}
+	runner := NewRunner(repo, newFakeExecutor(t, money.New(1, 0), nil), notifier, discardLogger())
+
+	if err := runner.RunPlan(context.Background(), plan.ID, plan.NextRunAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(repo.trades) != 0 {
+		t.Fatalf("expected no buys, got %d", len(repo.trades))
+	}
+	if got := repo.runs[runKey{planID: 1, scheduledFor: plan.NextRunAt}]; got != "skipped: "+skipPriceImpact {
+		t.Errorf("run = %q, want skipped for price impact", got)
+	}
+	if len(notifier.messages[7]) != 1 || !strings.Contains(notifier.messages[7][0], "could not be bought (price impact too high)") {
+		t.Errorf("unexpected notifications: %v", notifier.messages[7])
+	}
+}
+
+func TestRunnerIgnoresPausedAndDeletedPlans(t *testing.T) {
+	paused := testPlan(t, 1, "50")
+	paused.Status = domain.PlanStatusPaused
+	repo := newFakeDCARepo("1000", paused)
+	runner := NewRunner(repo, newFakeExecutor(t, money.New(1, 0), nil), nil, discardLogger())
+
+	if err := runner.RunPlan(context.Background(), 1, paused.NextRunAt); err != nil {
+		t.Fatalf("paused plan: unexpected error: %v", err)
+	}
+	if err := runner.RunPlan(context.Background(), 2, paused.NextRunAt); err != nil {
+		t.Fatalf("deleted plan: unexpected error: %v", err)
+	}
+	if len(repo.trades) != 0 || len(repo.runs) != 0 {
+		t.Errorf("expected nothing recorded, got trades %d, runs %v", len(repo.trades), repo.runs)
+	}
+}
+
+func TestRunnerRetriesWithoutPrice(t *testing.T) {
+	plan := testPlan(t, 1, "50")
+	repo := newFakeDCARepo("1000", plan)
+	failure := errors.New("price unavailable")
+	runner := NewRunner(repo, newFakeExecutor(t, money.Zero, failure), nil, discardLogger())
+
+	if err := runner.RunPlan(context.Background(), plan.ID, plan.NextRunAt); !errors.Is(err, failure) {
+		t.Fatalf("error = %v, want %v", err, failure)
+	}
+	if len(repo.runs) != 0 {
+		t.Errorf("expected the run to stay unrecorded, got %v", repo.runs)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	plan := testPlan(t, 1, "50")
+	if got := Describe(plan); got != "$50 of PEPE every Monday at 09:00" {
+		t.Errorf("Describe(weekly) = %q", got)
+	}
+
+	plan.Weekday = nil
+	plan.MinuteOfDay = 18*60 + 5
+	plan.AmountUSD, _ = money.ParseMoney("12.5", money.USD)
+	if got := Describe(plan); got != "$12.5 of PEPE every day at 18:05" {
+		t.Errorf("Describe(daily) = %q", got)
+	}
+}
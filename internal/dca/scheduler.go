@@ -0,0 +1,146 @@
+package dca
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/jobs"
+	"github.com/Proton-105/himera-bot/internal/repository"
+)
+
+const (
+	// DefaultScheduleInterval is how often the scheduler looks for due plans.
+	DefaultScheduleInterval = time.Minute
+	// scheduleBatchSize bounds the plans enqueued per tick; the rest wait for the next one.
+	scheduleBatchSize = 500
+)
+
+// Leader confirms that the singleton running with a context still holds
+// the leadership term that started it; leader.Elector implements it.
+type Leader interface {
+	Verify(ctx context.Context) error
+}
+
+// Scheduler enqueues a dca:buy task on the critical queue for every plan
+// that is due and moves the plan to its next run. It must run on a single
+// instance at a time; a second one is still harmless, as tasks of the same
+// run share their ID and each run is recorded once, and a scheduler whose
+// leadership term ended enqueues nothing.
+type Scheduler struct {
+	repo     repository.DCARepository
+	manager  jobs.Manager
+	settings Settings
+	leader   Leader
+	log      *slog.Logger
+	now      func() time.Time
+	interval time.Duration
+}
+
+// NewScheduler constructs a Scheduler. settings may be nil, in which case
+// every schedule is read in UTC, and so may leader, in which case runs are
+// not fenced.
+func NewScheduler(repo repository.DCARepository, manager jobs.Manager, settings Settings, leader Leader, log *slog.Logger) *Scheduler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Scheduler{
+		repo:     repo,
+		manager:  manager,
+		settings: settings,
+		leader:   leader,
+		log:      log,
+		now:      time.Now,
+		interval: DefaultScheduleInterval,
+	}
+}
+
+// Run schedules due plans every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick enqueues the runs of every due plan and returns how many it enqueued.
+// Like the job scheduler, a plan that fell behind runs once and then
+// continues from its next occurrence after now.
+func (s *Scheduler) Tick(ctx context.Context) int {
+	now := s.now()
+
+	plans, err := s.repo.ListDue(ctx, now, scheduleBatchSize)
+	if err != nil {
+		s.log.ErrorContext(ctx, "dca scheduler failed to list due plans", slog.Any("error", err))
+		return 0
+	}
+
+	enqueued := 0
+	for _, plan := range plans {
+		if ctx.Err() != nil {
+			break
+		}
+		if s.schedule(ctx, plan, now) {
+			enqueued++
+		}
+	}
+
+	return enqueued
+}
+
+func (s *Scheduler) schedule(ctx context.Context, plan *domain.DCAPlan, now time.Time) bool {
+	scheduledFor := plan.NextRunAt
+
+	if s.leader != nil {
+		if err := s.leader.Verify(ctx); err != nil {
+			// Another instance leads now and schedules the plan itself.
+			s.log.WarnContext(ctx, "dca scheduler lost leadership, run skipped", slog.Int64("plan_id", plan.ID), slog.Any("error", err))
+			return false
+		}
+	}
+
+	task, err := jobs.NewDCABuyTask(plan.ID, scheduledFor)
+	if err != nil {
+		s.log.ErrorContext(ctx, "dca scheduler failed to build task", slog.Int64("plan_id", plan.ID), slog.Any("error", err))
+		return false
+	}
+
+	_, err = s.manager.Enqueue(ctx, task, asynq.Queue(jobs.QueueCritical), asynq.TaskID(jobs.DCABuyTaskID(plan.ID, scheduledFor)))
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		// The plan stays due and the next tick retries it.
+		s.log.ErrorContext(ctx, "dca scheduler failed to enqueue run", slog.Int64("plan_id", plan.ID), slog.Any("error", err))
+		return false
+	}
+
+	after := scheduledFor
+	if now.After(after) {
+		after = now
+	}
+	next := plan.NextRun(after, userLocation(ctx, s.settings, plan.TelegramID, s.log))
+
+	if _, err := s.repo.Advance(ctx, plan.ID, scheduledFor, next); err != nil {
+		// The enqueued run is safe to enqueue again on the next tick.
+		s.log.ErrorContext(ctx, "dca scheduler failed to advance plan", slog.Int64("plan_id", plan.ID), slog.Any("error", err))
+	}
+
+	s.log.DebugContext(ctx, "dca run enqueued",
+		slog.Int64("plan_id", plan.ID),
+		slog.Time("scheduled_for", scheduledFor),
+		slog.Time("next_run_at", next),
+	)
+
+	return true
+}
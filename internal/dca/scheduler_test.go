@@ -0,0 +1,147 @@
+package dca
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/jobs"
+	"github.com/Proton-105/himera-bot/internal/leader"
+)
+
+type fakeManager struct {
+	jobs.Manager
+
+	ids   map[string]*asynq.Task
+	queue string
+}
+
+func (m *fakeManager) Enqueue(_ context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	var id string
+	for _, opt := range opts {
+		switch opt.Type() {
+		case asynq.TaskIDOpt:
+			id = opt.Value().(string)
+		case asynq.QueueOpt:
+			m.queue = opt.Value().(string)
+		}
+	}
+
+	if m.ids == nil {
+		m.ids = make(map[string]*asynq.Task)
+	}
+	if _, exists := m.ids[id]; exists {
+		return nil, asynq.ErrTaskIDConflict
+	}
+	m.ids[id] = task
+
+	return &asynq.TaskInfo{ID: id}, nil
+}
+
+type fakeSettings struct {
+	timezone string
+}
+
+func (s fakeSettings) GetSettings(context.Context, int64) (*domain.UserSettings, error) {
+	return &domain.UserSettings{Timezone: s.timezone}, nil
+}
+
+type fakeLeader struct {
+	err error
+}
+
+func (l fakeLeader) Verify(context.Context) error {
+	return l.err
+}
+
+func TestSchedulerEnqueuesDuePlansOnce(t *testing.T) {
+	plan := testPlan(t, 1, "50")
+	plan.NextRunAt = time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC) // 09:00 in Berlin
+	later := testPlan(t, 2, "50")
+	later.NextRunAt = time.Date(2025, 3, 17, 8, 0, 0, 0, time.UTC)
+
+	repo := newFakeDCARepo("1000", plan, later)
+	manager := &fakeManager{}
+	scheduler := NewScheduler(repo, manager, fakeSettings{timezone: "Europe/Berlin"}, nil, discardLogger())
+	scheduler.now = func() time.Time { return time.Date(2025, 3, 10, 8, 0, 30, 0, time.UTC) }
+
+	if got := scheduler.Tick(context.Background()); got != 1 {
+		t.Fatalf("Tick enqueued %d runs, want 1", got)
+	}
+
+	id := jobs.DCABuyTaskID(1, time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC))
+	task, ok := manager.ids[id]
+	if !ok {
+		t.Fatalf("expected task %q, got %v", id, manager.ids)
+	}
+	if manager.queue != jobs.QueueCritical {
+		t.Errorf("queue = %q, want %q", manager.queue, jobs.QueueCritical)
+	}
+
+	var payload jobs.DCABuyPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.PlanID != 1 || !payload.ScheduledFor.Equal(time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+
+	if next := repo.advanced[1]; !next.Equal(time.Date(2025, 3, 17, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("next run = %s, want the following Monday 09:00 Berlin", next)
+	}
+	if got := scheduler.Tick(context.Background()); got != 0 {
+		t.Errorf("second Tick enqueued %d runs, want 0", got)
+	}
+}
+
+func TestSchedulerCatchesUpOnceAfterDowntime(t *testing.T) {
+	plan := testPlan(t, 1, "50")
+	plan.Weekday = nil
+	plan.NextRunAt = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	repo := newFakeDCARepo("1000", plan)
+	manager := &fakeManager{}
+	scheduler := NewScheduler(repo, manager, nil, nil, discardLogger())
+	scheduler.now = func() time.Time { return time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC) }
+
+	if got := scheduler.Tick(context.Background()); got != 1 {
+		t.Fatalf("Tick enqueued %d runs, want 1", got)
+	}
+	if next := repo.advanced[1]; !next.Equal(time.Date(2025, 3, 6, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("next run = %s, want the next day after now", next)
+	}
+}
+
+func TestSchedulerTreatsDuplicateTaskAsEnqueued(t *testing.T) {
+	plan := testPlan(t, 1, "50")
+	repo := newFakeDCARepo("1000", plan)
+	manager := &fakeManager{ids: map[string]*asynq.Task{jobs.DCABuyTaskID(1, plan.NextRunAt): nil}}
+	scheduler := NewScheduler(repo, manager, nil, nil, discardLogger())
+	scheduler.now = func() time.Time { return plan.NextRunAt }
+
+	if got := scheduler.Tick(context.Background()); got != 1 {
+		t.Fatalf("Tick enqueued %d runs, want 1", got)
+	}
+	if _, ok := repo.advanced[1]; !ok {
+		t.Error("expected the plan to advance past the already enqueued run")
+	}
+}
+
+func TestSchedulerSkipsRunsAfterLosingLeadership(t *testing.T) {
+	plan := testPlan(t, 1, "50")
+	repo := newFakeDCARepo("1000", plan)
+	manager := &fakeManager{}
+	scheduler := NewScheduler(repo, manager, nil, fakeLeader{err: leader.ErrNotLeader}, discardLogger())
+	scheduler.now = func() time.Time { return plan.NextRunAt }
+
+	if got := scheduler.Tick(context.Background()); got != 0 {
+		t.Fatalf("Tick enqueued %d runs, want 0", got)
+	}
+	if _, ok := repo.advanced[1]; ok || len(manager.ids) != 0 {
+		t.Error("plan scheduled by a former leader")
+	}
+}
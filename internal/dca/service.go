@@ -0,0 +1,173 @@
+// Package dca runs users' recurring buy plans (dollar-cost averaging).
+package dca
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+)
+
+// MaxPlans bounds how many plans a user may keep, paused ones included.
+const MaxPlans = 10
+
+// ErrTooManyPlans indicates that the user already keeps MaxPlans plans.
+var ErrTooManyPlans = errors.New("too many dca plans")
+
+// Trades is the subset of trade.Service used to create plans.
+type Trades interface {
+	FindToken(ctx context.Context, query string) (*domain.Token, error)
+}
+
+// Settings reads the user settings that hold each user's timezone;
+// user.Service implements it.
+type Settings interface {
+	GetSettings(ctx context.Context, telegramID int64) (*domain.UserSettings, error)
+}
+
+// Service creates, lists, pauses, resumes and deletes users' DCA plans.
+type Service struct {
+	repo     repository.DCARepository
+	trades   Trades
+	settings Settings
+	log      *slog.Logger
+	now      func() time.Time
+}
+
+// NewService constructs a dca Service. settings may be nil, in which case
+// every schedule is read in UTC.
+func NewService(repo repository.DCARepository, trades Trades, settings Settings, log *slog.Logger) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Service{repo: repo, trades: trades, settings: settings, log: log, now: time.Now}
+}
+
+// Create resolves the requested token and schedules the plan's first run in
+// the user's timezone. Whether the balance covers a run is checked when it runs.
+func (s *Service) Create(ctx context.Context, userID int64, request Request) (*domain.DCAPlan, error) {
+	token, err := s.trades.FindToken(ctx, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list dca plans: %w", err)
+	}
+	if len(plans) >= MaxPlans {
+		return nil, ErrTooManyPlans
+	}
+
+	plan := &domain.DCAPlan{
+		TelegramID:   userID,
+		TokenAddress: token.Address,
+		TokenSymbol:  token.Symbol,
+		AmountUSD:    request.AmountUSD,
+		Weekday:      request.Weekday,
+		MinuteOfDay:  request.MinuteOfDay,
+	}
+	plan.NextRunAt = plan.NextRun(s.now(), s.Location(ctx, userID))
+
+	if err := s.repo.Create(ctx, plan); err != nil {
+		return nil, fmt.Errorf("create dca plan: %w", err)
+	}
+
+	s.log.Info("dca plan created",
+		slog.Int64("telegram_id", userID),
+		slog.Int64("plan_id", plan.ID),
+		slog.String("token_address", plan.TokenAddress),
+		slog.String("amount_usd", plan.AmountUSD.String()),
+		slog.Time("next_run_at", plan.NextRunAt),
+	)
+
+	return plan, nil
+}
+
+// List returns the user's plans, oldest first.
+func (s *Service) List(ctx context.Context, userID int64) ([]*domain.DCAPlan, error) {
+	plans, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list dca plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+// Pause stops the user's plan until it is resumed.
+func (s *Service) Pause(ctx context.Context, userID, planID int64) (*domain.DCAPlan, error) {
+	plan, err := s.repo.Pause(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("dca plan paused", slog.Int64("telegram_id", userID), slog.Int64("plan_id", planID))
+
+	return plan, nil
+}
+
+// Resume reactivates the user's plan from its next occurrence; runs missed
+// while it was paused are not made up.
+func (s *Service) Resume(ctx context.Context, userID, planID int64) (*domain.DCAPlan, error) {
+	plan, err := s.repo.Get(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.TelegramID != userID {
+		return nil, domain.ErrPlanNotFound
+	}
+
+	plan, err = s.repo.Resume(ctx, userID, planID, plan.NextRun(s.now(), s.Location(ctx, userID)))
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("dca plan resumed",
+		slog.Int64("telegram_id", userID),
+		slog.Int64("plan_id", planID),
+		slog.Time("next_run_at", plan.NextRunAt),
+	)
+
+	return plan, nil
+}
+
+// Delete removes the user's plan.
+func (s *Service) Delete(ctx context.Context, userID, planID int64) error {
+	if err := s.repo.Delete(ctx, userID, planID); err != nil {
+		return err
+	}
+
+	s.log.Info("dca plan deleted", slog.Int64("telegram_id", userID), slog.Int64("plan_id", planID))
+
+	return nil
+}
+
+// Location returns the user's timezone, or UTC when it is unset or unknown.
+func (s *Service) Location(ctx context.Context, userID int64) *time.Location {
+	return userLocation(ctx, s.settings, userID, s.log)
+}
+
+func userLocation(ctx context.Context, settings Settings, userID int64, log *slog.Logger) *time.Location {
+	if settings == nil {
+		return time.UTC
+	}
+
+	userSettings, err := settings.GetSettings(ctx, userID)
+	if err != nil || userSettings == nil || strings.TrimSpace(userSettings.Timezone) == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(strings.TrimSpace(userSettings.Timezone))
+	if err != nil {
+		log.Warn("unknown user timezone", slog.Int64("telegram_id", userID), slog.String("timezone", userSettings.Timezone))
+		return time.UTC
+	}
+
+	return loc
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// ErrAlertNotFound indicates that the requested alert does not exist or is no longer active.
+var ErrAlertNotFound = errors.New("alert not found")
+
+// AlertKind is the condition an alert watches.
+type AlertKind string
+
+const (
+	// AlertKindAbove fires when the price crosses above PriceUSD.
+	AlertKindAbove AlertKind = "above"
+	// AlertKindBelow fires when the price crosses below PriceUSD.
+	AlertKindBelow AlertKind = "below"
+	// AlertKindRise fires when the price rose Percent or more over Window.
+	AlertKindRise AlertKind = "rise"
+	// AlertKindFall fires when the price fell Percent or more over Window.
+	AlertKindFall AlertKind = "fall"
+)
+
+// AlertStatus is the lifecycle state of an alert.
+type AlertStatus string
+
+const (
+	// AlertStatusActive marks an alert that is evaluated on price updates.
+	AlertStatusActive AlertStatus = "active"
+	// AlertStatusTriggered marks a one-shot alert that has fired.
+	AlertStatusTriggered AlertStatus = "triggered"
+)
+
+// Alert notifies its owner when a token's price crosses a level or moves by
+// a percentage over a window. A repeating alert is disarmed when it fires
+// and fires again only after its condition stopped holding and Cooldown
+// passed; a one-shot alert fires once.
+type Alert struct {
+	ID              int64
+	TelegramID      int64
+	TokenAddress    string
+	TokenSymbol     string
+	Kind            AlertKind
+	PriceUSD        money.Decimal
+	Percent         int
+	Window          time.Duration
+	Repeating       bool
+	Cooldown        time.Duration
+	Armed           bool
+	Status          AlertStatus
+	TriggerCount    int
+	LastTriggeredAt *time.Time
+	CreatedAt       time.Time
+}
+
+// IsMove reports whether the alert watches a percentage move over a window.
+func (a *Alert) IsMove() bool {
+	return a.Kind == AlertKindRise || a.Kind == AlertKindFall
+}
+
+// Holds reports whether the alert's condition holds at price. reference is
+// the price Window ago and is only used by move alerts; a zero reference
+// never holds.
+func (a *Alert) Holds(price, reference money.Decimal) bool {
+	switch a.Kind {
+	case AlertKindAbove:
+		return price.Cmp(a.PriceUSD) >= 0
+	case AlertKindBelow:
+		return price.Cmp(a.PriceUSD) <= 0
+	case AlertKindRise, AlertKindFall:
+		if reference.Sign() <= 0 {
+			return false
+		}
+		change := price.Sub(reference)
+		if a.Kind == AlertKindFall {
+			change = change.Neg()
+		}
+		// change / reference >= percent / 100, without dividing.
+		return change.Mul(money.New(100, 0)).Cmp(reference.Mul(money.New(int64(a.Percent), 0))) >= 0
+	default:
+		return false
+	}
+}
+
+// CoolingDown reports whether the alert fired less than Cooldown before now.
+func (a *Alert) CoolingDown(now time.Time) bool {
+	return a.LastTriggeredAt != nil && now.Before(a.LastTriggeredAt.Add(a.Cooldown))
+}
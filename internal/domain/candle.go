@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// CandleInterval is the width of an OHLCV candle.
+type CandleInterval string
+
+const (
+	// CandleInterval1m is rolled up from raw price ticks.
+	CandleInterval1m CandleInterval = "1m"
+	// CandleInterval1h is rolled up from 1m candles.
+	CandleInterval1h CandleInterval = "1h"
+	// CandleInterval1d is rolled up from 1h candles.
+	CandleInterval1d CandleInterval = "1d"
+)
+
+// CandleIntervals lists the supported intervals from finest to coarsest.
+var CandleIntervals = []CandleInterval{CandleInterval1m, CandleInterval1h, CandleInterval1d}
+
+// ParseCandleInterval validates a user or API supplied interval.
+func ParseCandleInterval(value string) (CandleInterval, error) {
+	interval := CandleInterval(value)
+	if interval.Duration() == 0 {
+		return "", fmt.Errorf("unsupported candle interval %q", value)
+	}
+	return interval, nil
+}
+
+// Duration returns the candle width, or zero for unsupported intervals.
+func (i CandleInterval) Duration() time.Duration {
+	switch i {
+	case CandleInterval1m:
+		return time.Minute
+	case CandleInterval1h:
+		return time.Hour
+	case CandleInterval1d:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// Truncate returns the start of the UTC bucket containing t.
+func (i CandleInterval) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(i.Duration())
+}
+
+// Candle is an OHLCV bar for one token and interval. VolumeUSD is the
+// paper-trading notional executed in the bucket. Filled candles were
+// synthesised to cover a gap and repeat the previous close.
+type Candle struct {
+	TokenAddress string
+	Interval     CandleInterval
+	OpenTime     time.Time
+	Open         money.Decimal
+	High         money.Decimal
+	Low          money.Decimal
+	Close        money.Decimal
+	VolumeUSD    money.Money
+	Ticks        int
+	Filled       bool
+}
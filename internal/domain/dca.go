@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+var (
+	// ErrPlanNotFound indicates that the requested DCA plan does not exist.
+	ErrPlanNotFound = errors.New("dca plan not found")
+	// ErrPlanNotActive indicates that the DCA plan is paused.
+	ErrPlanNotActive = errors.New("dca plan is not active")
+	// ErrRunRecorded indicates that the scheduled DCA run was already executed or skipped.
+	ErrRunRecorded = errors.New("dca run already recorded")
+)
+
+// PlanStatus is the state of a DCA plan.
+type PlanStatus string
+
+const (
+	// PlanStatusActive marks a plan that buys on schedule.
+	PlanStatusActive PlanStatus = "active"
+	// PlanStatusPaused marks a plan the user paused.
+	PlanStatusPaused PlanStatus = "paused"
+)
+
+// DCAPlan is a recurring buy of AmountUSD worth of a token at MinuteOfDay,
+// in the owner's timezone, every day or on Weekday only.
+type DCAPlan struct {
+	ID           int64
+	TelegramID   int64
+	TokenAddress string
+	TokenSymbol  string
+	AmountUSD    money.Money
+	// Weekday is nil for daily plans.
+	Weekday     *time.Weekday
+	MinuteOfDay int
+	Status      PlanStatus
+	NextRunAt   time.Time
+	LastRunAt   *time.Time
+	CreatedAt   time.Time
+}
+
+// NextRun returns the first scheduled time strictly after after, with the
+// schedule read in loc. Times skipped by a DST change move forward.
+func (p *DCAPlan) NextRun(after time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	local := after.In(loc)
+	hour, minute := p.MinuteOfDay/60, p.MinuteOfDay%60
+
+	for day := 0; day <= 7; day++ {
+		candidate := time.Date(local.Year(), local.Month(), local.Day()+day, hour, minute, 0, 0, loc)
+		if !candidate.After(after) {
+			continue
+		}
+		if p.Weekday != nil && candidate.Weekday() != *p.Weekday {
+			continue
+		}
+		return candidate.UTC()
+	}
+
+	// Unreachable: eight consecutive days always contain the weekday.
+	return local.AddDate(0, 0, 7).UTC()
+}
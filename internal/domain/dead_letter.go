@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// DeadLetter records a background task that failed after exhausting its retries.
+type DeadLetter struct {
+	ID       int64
+	TaskID   string
+	TaskType string
+	Queue    string
+	Payload  []byte
+	// Error is the final error message; ErrorChain lists it and every wrapped cause, outermost first.
+	Error      string
+	ErrorChain []string
+	Retried    int
+	MaxRetry   int
+	FailedAt   time.Time
+	// RequeuedAt and RequeuedTaskID are set once an administrator re-enqueues the task.
+	RequeuedAt     *time.Time
+	RequeuedTaskID string
+}
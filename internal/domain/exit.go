@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// ErrExitNotFound indicates that the position has no exit levels attached.
+var ErrExitNotFound = errors.New("position exit not found")
+
+// ExitReason records which level closed a position automatically.
+type ExitReason string
+
+const (
+	// ExitReasonStopLoss marks a close at or below the stop-loss price.
+	ExitReasonStopLoss ExitReason = "stop_loss"
+	// ExitReasonTakeProfit marks a close at or above the take-profit price.
+	ExitReasonTakeProfit ExitReason = "take_profit"
+	// ExitReasonTrailingStop marks a close after the price fell TrailingPercent below its peak.
+	ExitReasonTrailingStop ExitReason = "trailing_stop"
+)
+
+// PositionExit holds the automatic exit levels of a position. Zero prices
+// and a zero TrailingPercent mean the level is not set. TrailingPeakUSD is
+// the highest price seen since the trailing stop was attached.
+type PositionExit struct {
+	PositionID      int64
+	TelegramID      int64
+	TokenAddress    string
+	StopLossUSD     money.Decimal
+	TakeProfitUSD   money.Decimal
+	TrailingPercent int
+	TrailingPeakUSD money.Decimal
+	UpdatedAt       time.Time
+}
+
+// IsZero reports whether no level is set.
+func (e *PositionExit) IsZero() bool {
+	return e.StopLossUSD.IsZero() && e.TakeProfitUSD.IsZero() && e.TrailingPercent == 0
+}
+
+// TrailingStopUSD returns the price that triggers the trailing stop, or zero
+// when no trailing stop is set.
+func (e *PositionExit) TrailingStopUSD() money.Decimal {
+	if e.TrailingPercent <= 0 || e.TrailingPeakUSD.Sign() <= 0 {
+		return money.Zero
+	}
+
+	keep := money.New(int64(100-e.TrailingPercent), 2)
+	return e.TrailingPeakUSD.Mul(keep).Round(PricePrecision, money.RoundHalfEven)
+}
+
+// Triggered reports which level price reached, checking the stop-loss
+// first, then the trailing stop and the take-profit.
+func (e *PositionExit) Triggered(price money.Decimal) (ExitReason, bool) {
+	if price.Sign() <= 0 {
+		return "", false
+	}
+
+	if !e.StopLossUSD.IsZero() && price.Cmp(e.StopLossUSD) <= 0 {
+		return ExitReasonStopLoss, true
+	}
+	if stop := e.TrailingStopUSD(); !stop.IsZero() && price.Cmp(stop) <= 0 {
+		return ExitReasonTrailingStop, true
+	}
+	if !e.TakeProfitUSD.IsZero() && price.Cmp(e.TakeProfitUSD) >= 0 {
+		return ExitReasonTakeProfit, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// LedgerKind classifies why a user's balance changed.
+type LedgerKind string
+
+const (
+	// LedgerKindOpening records the balance a user started with.
+	LedgerKindOpening LedgerKind = "opening"
+	// LedgerKindTrade records the cash leg of a buy or sell.
+	LedgerKindTrade LedgerKind = "trade"
+	// LedgerKindBonus records promotional credits.
+	LedgerKindBonus LedgerKind = "bonus"
+	// LedgerKindAdjustment records manual corrections made by an administrator.
+	LedgerKindAdjustment LedgerKind = "adjustment"
+	// LedgerKindReset records a balance reset to a fixed amount.
+	LedgerKindReset LedgerKind = "reset"
+)
+
+// LedgerEntry is an immutable record of a single balance change.
+// Amount is signed; BalanceAfter is the user's balance once the entry applied.
+type LedgerEntry struct {
+	ID           int64
+	TelegramID   int64
+	Kind         LedgerKind
+	Amount       money.Money
+	BalanceAfter money.Money
+	Reference    string
+	Note         string
+	CreatedAt    time.Time
+}
+
+// LedgerDiscrepancy describes a user whose balance disagrees with their ledger.
+type LedgerDiscrepancy struct {
+	TelegramID  int64
+	Balance     money.Money
+	LedgerTotal money.Money
+}
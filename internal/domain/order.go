@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+var (
+	// ErrOrderNotFound indicates that the requested order does not exist.
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderNotOpen indicates that the order was already filled, cancelled or expired.
+	ErrOrderNotOpen = errors.New("order is not open")
+)
+
+// OrderStatus is the lifecycle state of a limit order.
+type OrderStatus string
+
+const (
+	// OrderStatusOpen marks an order waiting for its limit price.
+	OrderStatusOpen OrderStatus = "open"
+	// OrderStatusFilled marks an order executed as a trade.
+	OrderStatusFilled OrderStatus = "filled"
+	// OrderStatusCancelled marks an order withdrawn by the user or rejected at fill time.
+	OrderStatusCancelled OrderStatus = "cancelled"
+	// OrderStatusExpired marks an order whose expiry passed before it filled.
+	OrderStatusExpired OrderStatus = "expired"
+)
+
+// Order is a limit order. A buy spends AmountUSD once the price falls to
+// LimitPriceUSD or below; a sell gives up Percent of the position once the
+// price rises to LimitPriceUSD or above. Orders without ExpiresAt are good
+// till cancelled.
+type Order struct {
+	ID            int64
+	TelegramID    int64
+	Side          TradeType
+	TokenAddress  string
+	TokenSymbol   string
+	LimitPriceUSD money.Decimal
+	AmountUSD     money.Money
+	Percent       int
+	Status        OrderStatus
+	ExpiresAt     *time.Time
+	// TransactionID references the trade that filled the order.
+	TransactionID int64
+	// CloseReason explains why an order was cancelled without the user asking.
+	CloseReason string
+	CreatedAt   time.Time
+	ClosedAt    *time.Time
+}
+
+// Crossed reports whether price reached the order's limit.
+func (o *Order) Crossed(price money.Decimal) bool {
+	if price.Sign() <= 0 {
+		return false
+	}
+
+	cmp := price.Cmp(o.LimitPriceUSD)
+	if o.Side == TradeTypeSell {
+		return cmp >= 0
+	}
+	return cmp <= 0
+}
+
+// Expired reports whether the order's expiry passed at now.
+func (o *Order) Expired(now time.Time) bool {
+	return o.ExpiresAt != nil && !now.Before(*o.ExpiresAt)
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// TokenInfo is a token known to the token registry.
+type TokenInfo struct {
+	Token
+	// Decimals is nil while no provider reports the token's decimals.
+	Decimals *int
+	// LiquidityUSD is the pooled liquidity across the token's pairs.
+	LiquidityUSD money.Decimal
+	// ListedAt is when the token's first pair was created; zero when unknown.
+	ListedAt   time.Time
+	LastSeenAt time.Time
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// Scales of the DECIMAL columns that store trading values. USD amounts use
+// money.USD.Scale(), which matches users.balance and transactions.total_usd (DECIMAL(20,8)).
+const (
+	// TokenAmountPrecision matches positions.amount and transactions.amount (DECIMAL(30,18)).
+	TokenAmountPrecision int32 = 18
+	// PricePrecision matches positions.avg_price and transactions.price_usd (DECIMAL(30,18)).
+	PricePrecision int32 = 18
+)
+
+var (
+	// ErrInsufficientBalance indicates that the user cannot afford the requested trade.
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrPositionNotFound indicates that the requested position does not exist.
+	ErrPositionNotFound = errors.New("position not found")
+)
+
+// TradeType distinguishes buys from sells in the transactions ledger.
+type TradeType string
+
+const (
+	// TradeTypeBuy marks a purchase of tokens for USD.
+	TradeTypeBuy TradeType = "buy"
+	// TradeTypeSell marks a sale of tokens for USD.
+	TradeTypeSell TradeType = "sell"
+)
+
+// Token describes a tradable token.
+type Token struct {
+	Address string
+	Chain   string
+	Symbol  string
+	Name    string
+}
+
+// PriceQuote is a USD price observation for a token. Aggregated quotes list
+// the feeds that agreed on the price in Sources.
+type PriceQuote struct {
+	TokenAddress string
+	PriceUSD     money.Decimal
+	Source       string
+	Sources      []string
+	FetchedAt    time.Time
+}
+
+// Position is an open token holding of a user.
+type Position struct {
+	ID           int64
+	TelegramID   int64
+	TokenAddress string
+	TokenSymbol  string
+	Amount       money.Decimal
+	AvgPriceUSD  money.Decimal
+	CreatedAt    time.Time
+}
+
+// Trade is an executed paper trade stored in the transactions table.
+// Amount is a token quantity and PriceUSD a unit price; TotalUSD and PnLUSD are cash amounts.
+type Trade struct {
+	ID           int64
+	TelegramID   int64
+	Type         TradeType
+	TokenAddress string
+	TokenSymbol  string
+	Amount       money.Decimal
+	PriceUSD     money.Decimal
+	TotalUSD     money.Money
+	PnLUSD       money.Money
+	// FeeUSD is the fee charged on the trade; TotalUSD includes it on buys and
+	// has it deducted on sells. SlippageUSD is what the fill price cost compared
+	// to the market price.
+	FeeUSD      money.Money
+	SlippageUSD money.Money
+	// ExitReason is set on sells made by a stop-loss, take-profit or trailing stop.
+	ExitReason ExitReason
+	CreatedAt  time.Time
+}
+
+// CostPriceUSD is the unit price of a buy with its fee spread over the
+// tokens bought, which is what the position's average price accrues.
+func (t *Trade) CostPriceUSD() money.Decimal {
+	if t.FeeUSD.IsZero() || t.Amount.Sign() <= 0 {
+		return t.PriceUSD
+	}
+
+	fee, err := t.FeeUSD.Amount().Quo(t.Amount, PricePrecision, money.RoundUp)
+	if err != nil {
+		return t.PriceUSD
+	}
+	return t.PriceUSD.Add(fee)
+}
+
+// TradeFilter narrows a trade history query. Zero-valued fields match every trade;
+// From is inclusive and To is exclusive.
+type TradeFilter struct {
+	Type         TradeType
+	TokenAddress string
+	From         time.Time
+	To           time.Time
+}
+
+// TradeCursor is a keyset position in trade history ordered by created_at and id, newest first.
+type TradeCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
 
 // User represents an application user stored in the database.
 type User struct {
@@ -9,7 +13,7 @@ type User struct {
 	FirstName    string
 	LastName     string
 	Username     string
-	Balance      int64
+	Balance      money.Money
 	LastActiveAt time.Time
 	IsBlocked    bool
 	CreatedAt    time.Time
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAlreadyWatching indicates that the token is already on the user's watchlist.
+	ErrAlreadyWatching = errors.New("token is already on the watchlist")
+	// ErrNotWatching indicates that the token is not on the user's watchlist.
+	ErrNotWatching = errors.New("token is not on the watchlist")
+)
+
+// WatchlistEntry is a token a user follows without holding it.
+type WatchlistEntry struct {
+	TelegramID   int64
+	TokenAddress string
+	TokenSymbol  string
+	CreatedAt    time.Time
+}
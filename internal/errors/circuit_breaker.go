@@ -22,8 +22,10 @@ const (
 )
 
 var (
-	errCircuitOpen             = errors.New("circuit breaker is open")
-	errHalfOpenTooManyRequests = errors.New("too many requests in half-open")
+	// ErrCircuitOpen is returned without calling fn while the breaker is open.
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrHalfOpenTooManyRequests is returned when the half-open probe budget is used up.
+	ErrHalfOpenTooManyRequests = errors.New("too many requests in half-open")
 )
 
 type CircuitBreaker struct {
@@ -52,13 +54,13 @@ func (cb *CircuitBreaker) Call(fn func() error) error {
 			cb.transitionToHalfOpenLocked()
 		} else {
 			cb.mu.Unlock()
-			return errCircuitOpen
+			return ErrCircuitOpen
 		}
 	}
 
 	if cb.state == StateHalfOpen && cb.requests >= HalfOpenMaxRequests {
 		cb.mu.Unlock()
-		return errHalfOpenTooManyRequests
+		return ErrHalfOpenTooManyRequests
 	}
 	cb.mu.Unlock()
 
@@ -99,3 +99,20 @@ func NewRateLimitError(retryAfter int) *AppError {
 		cause:       nil,
 	}
 }
+
+// NewJobFailedError reports a background task that failed after its last retry.
+func NewJobFailedError(taskType string, cause error) *AppError {
+	var underlyingMsg string
+	if cause != nil {
+		underlyingMsg = cause.Error()
+	}
+
+	return &AppError{
+		Code:        "E600",
+		Message:     fmt.Sprintf("Background job %s failed: %s", taskType, underlyingMsg),
+		UserMessage: "Фоновая задача завершилась с ошибкой",
+		Severity:    SeverityHigh,
+		Retryable:   false,
+		cause:       cause,
+	}
+}
@@ -0,0 +1,262 @@
+package exits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/leader"
+	"github.com/Proton-105/himera-bot/internal/pricecache"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// errNotTriggered reports that the locked levels no longer trigger at the
+// confirmed price, because the user changed them in the meantime.
+var errNotTriggered = errors.New("exit levels no longer triggered")
+
+// PriceFeed delivers price cache updates; pricecache.Subscriber implements it.
+type PriceFeed interface {
+	Subscribe(listener pricecache.Listener) (unsubscribe func())
+}
+
+// Notifier delivers a message to a user in Telegram.
+type Notifier interface {
+	NotifyUser(ctx context.Context, telegramID int64, text string) error
+}
+
+// Leader confirms that the singleton running with a context still holds
+// the leadership term that started it; leader.Elector implements it.
+type Leader interface {
+	Verify(ctx context.Context) error
+}
+
+// Evaluator closes positions whose exit levels the price reached and
+// follows trailing-stop peaks. It must run on a single instance at a time;
+// closes are still safe if it does not, as each one locks its position, and
+// an evaluator whose leadership term ended closes nothing.
+type Evaluator struct {
+	repo     repository.PositionExitRepository
+	trades   Trades
+	feed     PriceFeed
+	notifier Notifier
+	leader   Leader
+	log      *slog.Logger
+}
+
+// NewEvaluator constructs an Evaluator. notifier may be nil, and so may
+// leader, in which case closes are not fenced.
+func NewEvaluator(repo repository.PositionExitRepository, trades Trades, feed PriceFeed, notifier Notifier, leader Leader, log *slog.Logger) *Evaluator {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Evaluator{repo: repo, trades: trades, feed: feed, notifier: notifier, leader: leader, log: log}
+}
+
+// Run evaluates exits on every price update until ctx is cancelled.
+func (e *Evaluator) Run(ctx context.Context) {
+	updates := pricecache.NewQueue()
+	unsubscribe := e.feed.Subscribe(updates.Push)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-updates.Ready():
+			for _, quote := range updates.Drain() {
+				if ctx.Err() != nil {
+					return
+				}
+				e.Evaluate(ctx, quote)
+			}
+		}
+	}
+}
+
+// Evaluate raises trailing-stop peaks to the quote, closes the positions in
+// the quote's token whose levels it reached and returns how many closed.
+// Like limit orders, closes use the price confirmed by the trade service and
+// fill through its execution model. A close the pools cannot absorb removes
+// the position's levels instead, so it is not retried on every update.
+func (e *Evaluator) Evaluate(ctx context.Context, quote *domain.PriceQuote) int {
+	if quote == nil || quote.TokenAddress == "" {
+		return 0
+	}
+
+	exits, err := e.repo.ListByToken(ctx, quote.TokenAddress)
+	if err != nil {
+		e.log.ErrorContext(ctx, "exit evaluator failed to list exits", slog.String("token_address", quote.TokenAddress), slog.Any("error", err))
+		return 0
+	}
+
+	triggered := make([]*domain.PositionExit, 0, len(exits))
+	for _, exit := range exits {
+		e.raisePeak(ctx, exit, quote.PriceUSD)
+		if _, ok := exit.Triggered(quote.PriceUSD); ok {
+			triggered = append(triggered, exit)
+		}
+	}
+	if len(triggered) == 0 {
+		return 0
+	}
+
+	state, err := e.trades.LatestMarket(ctx, quote.TokenAddress)
+	if err != nil {
+		e.log.WarnContext(ctx, "exit evaluator could not confirm price",
+			slog.String("token_address", quote.TokenAddress),
+			slog.Int("positions", len(triggered)),
+			slog.Any("error", err),
+		)
+		return 0
+	}
+
+	closed := 0
+	for _, exit := range triggered {
+		if _, ok := exit.Triggered(state.PriceUSD); ok && e.close(ctx, exit, state) {
+			closed++
+		}
+	}
+
+	return closed
+}
+
+func (e *Evaluator) raisePeak(ctx context.Context, exit *domain.PositionExit, price money.Decimal) {
+	if exit.TrailingPercent == 0 || price.Cmp(exit.TrailingPeakUSD) <= 0 {
+		return
+	}
+
+	raised, err := e.repo.RaisePeak(ctx, exit.PositionID, price)
+	if err != nil {
+		e.log.ErrorContext(ctx, "exit evaluator failed to raise trailing peak", slog.Int64("position_id", exit.PositionID), slog.Any("error", err))
+		return
+	}
+	if raised {
+		exit.TrailingPeakUSD = price
+	}
+}
+
+func (e *Evaluator) close(ctx context.Context, exit *domain.PositionExit, state trade.Market) bool {
+	executed, err := e.repo.Close(ctx, exit.PositionID, func(locked *domain.PositionExit, position *domain.Position) (*domain.Trade, error) {
+		if e.leader != nil {
+			if err := e.leader.Verify(ctx); err != nil {
+				return nil, err
+			}
+		}
+		reason, ok := locked.Triggered(state.PriceUSD)
+		if !ok {
+			return nil, errNotTriggered
+		}
+
+		sale, err := e.trades.SellTrade(position, 100, state)
+		if err != nil {
+			return nil, err
+		}
+		sale.ExitReason = reason
+		return sale, nil
+	})
+
+	switch {
+	case err == nil:
+		e.log.InfoContext(ctx, "position exit triggered",
+			slog.Int64("telegram_id", executed.TelegramID),
+			slog.Int64("position_id", exit.PositionID),
+			slog.Int64("transaction_id", executed.ID),
+			slog.String("reason", string(executed.ExitReason)),
+			slog.String("price_usd", executed.PriceUSD.String()),
+		)
+		e.notify(ctx, executed.TelegramID, triggeredMessage(executed))
+		return true
+	case errors.Is(err, domain.ErrExitNotFound), errors.Is(err, errNotTriggered):
+		// Closed, cleared or changed since it was listed.
+		return false
+	case errors.Is(err, trade.ErrInsufficientLiquidity), errors.Is(err, trade.ErrPriceImpactTooHigh):
+		e.abandon(ctx, exit, state.PriceUSD, err)
+		return false
+	case errors.Is(err, leader.ErrNotLeader):
+		// Another instance leads now and closes the position itself.
+		e.log.WarnContext(ctx, "exit evaluator lost leadership, close skipped", slog.Int64("position_id", exit.PositionID))
+		return false
+	default:
+		// Left in place; the next price update retries it.
+		e.log.ErrorContext(ctx, "exit evaluator failed to close position", slog.Int64("position_id", exit.PositionID), slog.Any("error", err))
+		return false
+	}
+}
+
+// abandon removes the levels of an exit whose close cannot be filled and
+// tells the user; the position itself stays open.
+func (e *Evaluator) abandon(ctx context.Context, exit *domain.PositionExit, price money.Decimal, cause error) {
+	if err := e.repo.Delete(ctx, exit.TelegramID, exit.PositionID); err != nil {
+		e.log.ErrorContext(ctx, "exit evaluator failed to remove unfillable exit", slog.Int64("position_id", exit.PositionID), slog.Any("error", err))
+		return
+	}
+
+	reason, _ := exit.Triggered(price)
+	e.log.InfoContext(ctx, "position exit removed at close",
+		slog.Int64("telegram_id", exit.TelegramID),
+		slog.Int64("position_id", exit.PositionID),
+		slog.String("reason", string(reason)),
+		slog.Any("error", cause),
+	)
+	e.notify(ctx, exit.TelegramID, fmt.Sprintf(
+		"⚠️ %s on position #%d could not be executed (%s), so its exit levels were removed. The position stays open.",
+		ReasonLabel(reason), exit.PositionID, closeFailure(cause),
+	))
+}
+
+func (e *Evaluator) notify(ctx context.Context, telegramID int64, text string) {
+	if e.notifier == nil {
+		return
+	}
+
+	if err := e.notifier.NotifyUser(ctx, telegramID, text); err != nil {
+		e.log.WarnContext(ctx, "exit evaluator failed to notify user", slog.Int64("telegram_id", telegramID), slog.Any("error", err))
+	}
+}
+
+// ReasonLabel names an exit reason for users, e.g. "Stop-loss".
+func ReasonLabel(reason domain.ExitReason) string {
+	switch reason {
+	case domain.ExitReasonStopLoss:
+		return "Stop-loss"
+	case domain.ExitReasonTakeProfit:
+		return "Take-profit"
+	case domain.ExitReasonTrailingStop:
+		return "Trailing stop"
+	default:
+		return string(reason)
+	}
+}
+
+func closeFailure(err error) string {
+	if errors.Is(err, trade.ErrPriceImpactTooHigh) {
+		return "price impact too high"
+	}
+	return "insufficient liquidity"
+}
+
+func triggeredMessage(executed *domain.Trade) string {
+	token := executed.TokenSymbol
+	if token == "" {
+		token = executed.TokenAddress
+	}
+
+	pnl, sign := executed.PnLUSD, "+"
+	if pnl.Sign() < 0 {
+		sign, pnl = "-", pnl.Neg()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🛑 %s triggered: sold %s %s\n", ReasonLabel(executed.ExitReason), executed.Amount.StringTrimmed(6, money.RoundDown), token)
+	fmt.Fprintf(&b, "Price: $%s\n", executed.PriceUSD.StringTrimmed(12, money.RoundHalfUp))
+	fmt.Fprintf(&b, "Total: $%s (fee $%s)\n", executed.TotalUSD.StringFixed(2, money.RoundHalfUp), executed.FeeUSD.StringFixed(2, money.RoundHalfUp))
+	fmt.Fprintf(&b, "PnL: %s$%s", sign, pnl.StringFixed(2, money.RoundHalfUp))
+
+	return b.String()
+}
@@ -0,0 +1,325 @@
+package exits
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/leader"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type fakeExitRepo struct {
+	repository.PositionExitRepository
+
+	exits     map[int64]*domain.PositionExit
+	positions map[int64]*domain.Position
+	closed    []*domain.Trade
+}
+
+func (r *fakeExitRepo) Save(_ context.Context, exit *domain.PositionExit) error {
+	position, ok := r.positions[exit.PositionID]
+	if !ok || position.TelegramID != exit.TelegramID {
+		return domain.ErrPositionNotFound
+	}
+
+	saved := *exit
+	saved.TokenAddress = position.TokenAddress
+	r.exits[exit.PositionID] = &saved
+	return nil
+}
+
+func (r *fakeExitRepo) Get(_ context.Context, userID, positionID int64) (*domain.PositionExit, error) {
+	exit, ok := r.exits[positionID]
+	if !ok || exit.TelegramID != userID {
+		return nil, domain.ErrExitNotFound
+	}
+	copied := *exit
+	return &copied, nil
+}
+
+func (r *fakeExitRepo) Delete(_ context.Context, _, positionID int64) error {
+	delete(r.exits, positionID)
+	return nil
+}
+
+func (r *fakeExitRepo) ListByToken(_ context.Context, tokenAddress string) ([]*domain.PositionExit, error) {
+	var exits []*domain.PositionExit
+	for _, exit := range r.exits {
+		if exit.TokenAddress == tokenAddress {
+			copied := *exit
+			exits = append(exits, &copied)
+		}
+	}
+	return exits, nil
+}
+
+func (r *fakeExitRepo) RaisePeak(_ context.Context, positionID int64, price money.Decimal) (bool, error) {
+	exit, ok := r.exits[positionID]
+	if !ok || exit.TrailingPercent == 0 || exit.TrailingPeakUSD.Cmp(price) >= 0 {
+		return false, nil
+	}
+	exit.TrailingPeakUSD = price
+	return true, nil
+}
+
+func (r *fakeExitRepo) Close(_ context.Context, positionID int64, sell repository.PositionClose) (*domain.Trade, error) {
+	exit, ok := r.exits[positionID]
+	position, held := r.positions[positionID]
+	if !ok || !held {
+		return nil, domain.ErrExitNotFound
+	}
+
+	trade, err := sell(exit, position)
+	if err != nil {
+		return nil, err
+	}
+
+	trade.ID = int64(len(r.closed) + 1)
+	r.closed = append(r.closed, trade)
+	delete(r.exits, positionID)
+	delete(r.positions, positionID)
+
+	return trade, nil
+}
+
+// fakeTrades confirms a fixed price against pools holding liquidity and
+// fills through an execution model charging 0.3%.
+type fakeTrades struct {
+	Trades
+
+	positions map[int64]*domain.Position
+	price     money.Decimal
+	priceErr  error
+	liquidity money.Decimal
+	execution *trade.Service
+}
+
+func (t *fakeTrades) GetPosition(_ context.Context, userID, positionID int64) (*domain.Position, error) {
+	position, ok := t.positions[positionID]
+	if !ok || position.TelegramID != userID {
+		return nil, domain.ErrPositionNotFound
+	}
+	return position, nil
+}
+
+func (t *fakeTrades) LatestPrice(context.Context, string) (money.Decimal, error) {
+	return t.price, t.priceErr
+}
+
+func (t *fakeTrades) LatestMarket(context.Context, string) (trade.Market, error) {
+	return trade.Market{PriceUSD: t.price, LiquidityUSD: t.liquidity}, t.priceErr
+}
+
+func (t *fakeTrades) SellTrade(position *domain.Position, percent int, state trade.Market) (*domain.Trade, error) {
+	return t.execution.SellTrade(position, percent, state)
+}
+
+type fakeNotifier struct {
+	messages map[int64][]string
+}
+
+func (n *fakeNotifier) NotifyUser(_ context.Context, telegramID int64, text string) error {
+	if n.messages == nil {
+		n.messages = make(map[int64][]string)
+	}
+	n.messages[telegramID] = append(n.messages[telegramID], text)
+	return nil
+}
+
+type fakeLeader struct {
+	err error
+}
+
+func (l fakeLeader) Verify(context.Context) error {
+	return l.err
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func mustDecimal(t *testing.T, value string) money.Decimal {
+	t.Helper()
+
+	d, err := money.Parse(value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return d
+}
+
+func newFixture(t *testing.T) (*fakeExitRepo, *fakeTrades) {
+	t.Helper()
+
+	positions := map[int64]*domain.Position{
+		1: {ID: 1, TelegramID: 10, TokenAddress: "token", TokenSymbol: "PEPE", Amount: mustDecimal(t, "100"), AvgPriceUSD: mustDecimal(t, "2")},
+		2: {ID: 2, TelegramID: 20, TokenAddress: "token", TokenSymbol: "PEPE", Amount: mustDecimal(t, "50"), AvgPriceUSD: mustDecimal(t, "1")},
+	}
+
+	model, err := trade.NewExecutionModel([]trade.FeeTier{{MinUSD: money.Zero, FeeBps: 30}}, 1000)
+	if err != nil {
+		t.Fatalf("NewExecutionModel: %v", err)
+	}
+
+	return &fakeExitRepo{exits: make(map[int64]*domain.PositionExit), positions: positions},
+		&fakeTrades{
+			positions: positions,
+			price:     mustDecimal(t, "2"),
+			liquidity: money.NewFromInt(200000),
+			execution: trade.NewService(nil, nil, nil, nil, nil, model, nil),
+		}
+}
+
+func TestServiceApplyResolvesPercentages(t *testing.T) {
+	repo, trades := newFixture(t)
+	service := NewService(repo, trades, discardLogger())
+
+	trail := 10
+	_, exit, err := service.Apply(context.Background(), 10, 1, Update{
+		StopLoss:        &Level{Percent: 10},
+		TakeProfit:      &Level{Percent: 50},
+		TrailingPercent: &trail,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !exit.StopLossUSD.Equal(mustDecimal(t, "1.8")) || !exit.TakeProfitUSD.Equal(mustDecimal(t, "3")) {
+		t.Errorf("levels = %s / %s, want 1.8 / 3", exit.StopLossUSD, exit.TakeProfitUSD)
+	}
+	if !exit.TrailingPeakUSD.Equal(trades.price) || !exit.TrailingStopUSD().Equal(mustDecimal(t, "1.8")) {
+		t.Errorf("trailing peak = %s, stop = %s", exit.TrailingPeakUSD, exit.TrailingStopUSD())
+	}
+
+	if _, _, err := service.Apply(context.Background(), 10, 1, Update{StopLoss: &Level{PriceUSD: mustDecimal(t, "2.5")}}); !errors.Is(err, ErrStopLossAbovePrice) {
+		t.Errorf("stop-loss above price error = %v", err)
+	}
+	if _, _, err := service.Apply(context.Background(), 20, 1, Update{Clear: true}); !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Errorf("foreign position error = %v", err)
+	}
+
+	if _, cleared, err := service.Apply(context.Background(), 10, 1, Update{Clear: true}); err != nil || !cleared.IsZero() {
+		t.Errorf("clear = %+v, %v", cleared, err)
+	}
+	if _, ok := repo.exits[1]; ok {
+		t.Error("cleared exit is still stored")
+	}
+}
+
+func TestEvaluatorClosesTriggeredPositions(t *testing.T) {
+	repo, trades := newFixture(t)
+	repo.exits[1] = &domain.PositionExit{PositionID: 1, TelegramID: 10, TokenAddress: "token", StopLossUSD: mustDecimal(t, "1.5")}
+	repo.exits[2] = &domain.PositionExit{PositionID: 2, TelegramID: 20, TokenAddress: "token", TakeProfitUSD: mustDecimal(t, "3")}
+	trades.price = mustDecimal(t, "1.4")
+	notifier := &fakeNotifier{}
+	evaluator := NewEvaluator(repo, trades, nil, notifier, nil, discardLogger())
+
+	closed := evaluator.Evaluate(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1.45")})
+	if closed != 1 {
+		t.Fatalf("closed = %d, want 1", closed)
+	}
+
+	sale := repo.closed[0]
+	if sale.ExitReason != domain.ExitReasonStopLoss || sale.Type != domain.TradeTypeSell || !sale.Amount.Equal(mustDecimal(t, "100")) {
+		t.Errorf("unexpected sale: %+v", sale)
+	}
+	// The sale fills below the confirmed price and pays the fee.
+	if sale.PriceUSD.Cmp(trades.price) >= 0 || sale.PriceUSD.Cmp(mustDecimal(t, "1.39")) <= 0 || sale.FeeUSD.Sign() <= 0 {
+		t.Errorf("sale filled at %s with fee %s, want just below %s with a fee", sale.PriceUSD, sale.FeeUSD, trades.price)
+	}
+	if _, ok := repo.exits[2]; !ok {
+		t.Error("untriggered take-profit was removed")
+	}
+	if msgs := notifier.messages[10]; len(msgs) != 1 || !strings.Contains(msgs[0], "Stop-loss triggered") || !strings.Contains(msgs[0], "PnL: -$60.62") {
+		t.Errorf("notifications = %q", msgs)
+	}
+}
+
+func TestEvaluatorFollowsTrailingPeak(t *testing.T) {
+	repo, trades := newFixture(t)
+	repo.exits[1] = &domain.PositionExit{PositionID: 1, TelegramID: 10, TokenAddress: "token", TrailingPercent: 10, TrailingPeakUSD: mustDecimal(t, "2")}
+	evaluator := NewEvaluator(repo, trades, nil, nil, nil, discardLogger())
+
+	if closed := evaluator.Evaluate(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "3")}); closed != 0 {
+		t.Fatalf("closed on a new peak: %d", closed)
+	}
+	if peak := repo.exits[1].TrailingPeakUSD; !peak.Equal(mustDecimal(t, "3")) {
+		t.Fatalf("peak = %s, want 3", peak)
+	}
+
+	// 2.8 is within 10% of the new peak; 2.6 is not.
+	if closed := evaluator.Evaluate(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "2.8")}); closed != 0 {
+		t.Fatalf("closed above the trailing stop: %d", closed)
+	}
+
+	trades.price = mustDecimal(t, "2.6")
+	if closed := evaluator.Evaluate(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "2.6")}); closed != 1 {
+		t.Fatalf("closed = %d, want 1", closed)
+	}
+	if reason := repo.closed[0].ExitReason; reason != domain.ExitReasonTrailingStop {
+		t.Errorf("reason = %s, want trailing_stop", reason)
+	}
+}
+
+func TestEvaluatorSkipsUnconfirmedPrices(t *testing.T) {
+	repo, trades := newFixture(t)
+	repo.exits[1] = &domain.PositionExit{PositionID: 1, TelegramID: 10, TokenAddress: "token", StopLossUSD: mustDecimal(t, "1.5")}
+	evaluator := NewEvaluator(repo, trades, nil, nil, nil, discardLogger())
+
+	// The live price recovered above the stop-loss.
+	trades.price = mustDecimal(t, "1.6")
+	if closed := evaluator.Evaluate(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1.4")}); closed != 0 {
+		t.Fatalf("closed = %d, want 0", closed)
+	}
+
+	trades.priceErr = errors.New("feeds disagree")
+	if closed := evaluator.Evaluate(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1.4")}); closed != 0 {
+		t.Fatalf("closed without a confirmed price: %d", closed)
+	}
+	if _, ok := repo.exits[1]; !ok {
+		t.Error("exit removed without closing")
+	}
+}
+
+func TestEvaluatorSkipsClosesAfterLosingLeadership(t *testing.T) {
+	repo, trades := newFixture(t)
+	repo.exits[1] = &domain.PositionExit{PositionID: 1, TelegramID: 10, TokenAddress: "token", StopLossUSD: mustDecimal(t, "1.5")}
+	trades.price = mustDecimal(t, "1.4")
+	evaluator := NewEvaluator(repo, trades, nil, nil, fakeLeader{err: leader.ErrNotLeader}, discardLogger())
+
+	if closed := evaluator.Evaluate(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1.4")}); closed != 0 {
+		t.Fatalf("closed = %d, want 0", closed)
+	}
+	if _, ok := repo.exits[1]; !ok || len(repo.closed) != 0 {
+		t.Error("position closed by a former leader")
+	}
+}
+
+func TestEvaluatorRemovesExitsThePoolsCannotAbsorb(t *testing.T) {
+	repo, trades := newFixture(t)
+	repo.exits[1] = &domain.PositionExit{PositionID: 1, TelegramID: 10, TokenAddress: "token", StopLossUSD: mustDecimal(t, "1.5")}
+	trades.price = mustDecimal(t, "1.4")
+	trades.liquidity = money.Zero
+	notifier := &fakeNotifier{}
+	evaluator := NewEvaluator(repo, trades, nil, notifier, nil, discardLogger())
+
+	if closed := evaluator.Evaluate(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1.4")}); closed != 0 {
+		t.Fatalf("closed = %d, want 0", closed)
+	}
+	if _, ok := repo.exits[1]; ok {
+		t.Error("unfillable exit was kept")
+	}
+	if _, ok := repo.positions[1]; !ok {
+		t.Error("position was removed")
+	}
+	if msgs := notifier.messages[10]; len(msgs) != 1 || !strings.Contains(msgs[0], "Stop-loss on position #1 could not be executed (insufficient liquidity)") {
+		t.Errorf("notifications = %q", msgs)
+	}
+}
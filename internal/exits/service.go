@@ -0,0 +1,190 @@
+// Package exits closes positions automatically at stop-loss, take-profit and
+// trailing-stop levels.
+package exits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+var (
+	// ErrStopLossAbovePrice indicates a stop-loss that would trigger immediately.
+	ErrStopLossAbovePrice = errors.New("stop-loss must be below the current price")
+	// ErrTakeProfitBelowPrice indicates a take-profit that would trigger immediately.
+	ErrTakeProfitBelowPrice = errors.New("take-profit must be above the current price")
+)
+
+// Trades is the subset of trade.Service used to attach and trigger exits.
+type Trades interface {
+	FindToken(ctx context.Context, query string) (*domain.Token, error)
+	ListPositions(ctx context.Context, userID int64) ([]*domain.Position, error)
+	GetPosition(ctx context.Context, userID, positionID int64) (*domain.Position, error)
+	LatestPrice(ctx context.Context, tokenAddress string) (money.Decimal, error)
+	LatestMarket(ctx context.Context, tokenAddress string) (trade.Market, error)
+	SellTrade(position *domain.Position, percent int, state trade.Market) (*domain.Trade, error)
+}
+
+// Service attaches exit levels to users' positions.
+type Service struct {
+	repo   repository.PositionExitRepository
+	trades Trades
+	log    *slog.Logger
+}
+
+// NewService constructs an exits Service.
+func NewService(repo repository.PositionExitRepository, trades Trades, log *slog.Logger) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Service{repo: repo, trades: trades, log: log}
+}
+
+// Get returns the user's position and its exit levels. A position without
+// levels comes with a zero PositionExit.
+func (s *Service) Get(ctx context.Context, userID, positionID int64) (*domain.Position, *domain.PositionExit, error) {
+	position, err := s.trades.GetPosition(ctx, userID, positionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exit, err := s.repo.Get(ctx, userID, positionID)
+	switch {
+	case errors.Is(err, domain.ErrExitNotFound):
+		exit = &domain.PositionExit{PositionID: position.ID, TelegramID: userID, TokenAddress: position.TokenAddress}
+	case err != nil:
+		return nil, nil, fmt.Errorf("get position exit: %w", err)
+	}
+
+	return position, exit, nil
+}
+
+// Apply changes the exit levels of the user's position. Percentage levels
+// are resolved against the position's average price; new levels must not
+// trigger at the current price, which also starts the trailing stop's peak.
+func (s *Service) Apply(ctx context.Context, userID, positionID int64, update Update) (*domain.Position, *domain.PositionExit, error) {
+	position, exit, err := s.Get(ctx, userID, positionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exit = change(exit, position, update)
+	if exit.IsZero() {
+		if err := s.repo.Delete(ctx, userID, positionID); err != nil {
+			return nil, nil, err
+		}
+		s.log.Info("position exits removed", slog.Int64("telegram_id", userID), slog.Int64("position_id", positionID))
+		return position, exit, nil
+	}
+
+	price, err := s.trades.LatestPrice(ctx, position.TokenAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := check(exit, update, price); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.repo.Save(ctx, exit); err != nil {
+		return nil, nil, err
+	}
+
+	s.log.Info("position exits updated",
+		slog.Int64("telegram_id", userID),
+		slog.Int64("position_id", positionID),
+		slog.String("stop_loss", exit.StopLossUSD.String()),
+		slog.String("take_profit", exit.TakeProfitUSD.String()),
+		slog.Int("trailing_percent", exit.TrailingPercent),
+	)
+
+	return position, exit, nil
+}
+
+// ApplyToToken resolves query to one of the user's positions and applies update to it.
+func (s *Service) ApplyToToken(ctx context.Context, userID int64, query string, update Update) (*domain.Position, *domain.PositionExit, error) {
+	token, err := s.trades.FindToken(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	positions, err := s.trades.ListPositions(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, position := range positions {
+		if position.TokenAddress == token.Address {
+			return s.Apply(ctx, userID, position.ID, update)
+		}
+	}
+
+	return nil, nil, domain.ErrPositionNotFound
+}
+
+// BuyLevels returns the trade.ExitLevels that apply update to the position a
+// buy opens or grows, exactly as Apply would right after the buy, so the
+// levels are stored with the buy itself.
+func BuyLevels(update Update) trade.ExitLevels {
+	return func(position *domain.Position, current *domain.PositionExit, price money.Decimal) (*domain.PositionExit, error) {
+		exit := change(current, position, update)
+		if exit.IsZero() {
+			return exit, nil
+		}
+		if err := check(exit, update, price); err != nil {
+			return nil, err
+		}
+		return exit, nil
+	}
+}
+
+// change applies update to exit, resolving percentages against the
+// position's average price. A new trailing stop has no peak yet.
+func change(exit *domain.PositionExit, position *domain.Position, update Update) *domain.PositionExit {
+	if update.Clear {
+		exit = &domain.PositionExit{PositionID: position.ID, TelegramID: position.TelegramID, TokenAddress: position.TokenAddress}
+	}
+	if update.StopLoss != nil {
+		exit.StopLossUSD = resolve(*update.StopLoss, position.AvgPriceUSD, -1)
+	}
+	if update.TakeProfit != nil {
+		exit.TakeProfitUSD = resolve(*update.TakeProfit, position.AvgPriceUSD, 1)
+	}
+	if update.TrailingPercent != nil {
+		exit.TrailingPercent = *update.TrailingPercent
+		exit.TrailingPeakUSD = money.Zero
+	}
+	return exit
+}
+
+// check rejects levels set by update that would trigger at price and starts
+// a trailing stop without a peak at price.
+func check(exit *domain.PositionExit, update Update, price money.Decimal) error {
+	if update.StopLoss != nil && !exit.StopLossUSD.IsZero() && exit.StopLossUSD.Cmp(price) >= 0 {
+		return ErrStopLossAbovePrice
+	}
+	if update.TakeProfit != nil && !exit.TakeProfitUSD.IsZero() && exit.TakeProfitUSD.Cmp(price) <= 0 {
+		return ErrTakeProfitBelowPrice
+	}
+	if exit.TrailingPercent > 0 && exit.TrailingPeakUSD.IsZero() {
+		exit.TrailingPeakUSD = price
+	}
+	return nil
+}
+
+// resolve turns level into a price; percentages move away from reference in
+// direction, -1 for below and 1 for above.
+func resolve(level Level, reference money.Decimal, direction int) money.Decimal {
+	if level.Percent == 0 {
+		return level.PriceUSD
+	}
+
+	factor := money.New(int64(100+direction*level.Percent), 2)
+	return reference.Mul(factor).Round(domain.PricePrecision, money.RoundHalfEven)
+}
@@ -0,0 +1,35 @@
+package exits
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func TestBuyLevels(t *testing.T) {
+	position := &domain.Position{ID: 3, TelegramID: 1, TokenAddress: "0xabc", AvgPriceUSD: money.NewFromInt(2)}
+	trailing := 10
+
+	levels := BuyLevels(Update{StopLoss: &Level{Percent: 10}, TrailingPercent: &trailing})
+	exit, err := levels(position, &domain.PositionExit{PositionID: 3, TelegramID: 1, TakeProfitUSD: money.NewFromInt(5)}, money.NewFromInt(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !exit.StopLossUSD.Equal(money.New(18, 1)) {
+		t.Errorf("stop-loss = %s, want 1.8", exit.StopLossUSD)
+	}
+	if !exit.TakeProfitUSD.Equal(money.NewFromInt(5)) {
+		t.Errorf("take-profit = %s, want the current 5", exit.TakeProfitUSD)
+	}
+	if exit.TrailingPercent != 10 || !exit.TrailingPeakUSD.Equal(money.NewFromInt(2)) {
+		t.Errorf("trailing stop = %d%% from %s, want 10%% from 2", exit.TrailingPercent, exit.TrailingPeakUSD)
+	}
+
+	_, err = levels(position, &domain.PositionExit{PositionID: 3, TelegramID: 1}, money.New(15, 1))
+	if !errors.Is(err, ErrStopLossAbovePrice) {
+		t.Errorf("stop-loss above the price: expected ErrStopLossAbovePrice, got %v", err)
+	}
+}
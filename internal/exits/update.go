@@ -0,0 +1,137 @@
+package exits
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// Bounds of percentage levels. A stop-loss or trailing stop of 100% or more
+// could never trigger.
+const (
+	MaxStopLossPercent   = 99
+	MaxTakeProfitPercent = 1000
+	MaxTrailingPercent   = 50
+)
+
+// ErrInvalidUpdate indicates that the exit arguments could not be parsed.
+var ErrInvalidUpdate = errors.New("invalid exit levels")
+
+// Level is an exit price given either as PriceUSD or as a Percent distance
+// from the position's average price: below it for a stop-loss, above it for
+// a take-profit. A zero Level removes the exit.
+type Level struct {
+	PriceUSD money.Decimal
+	Percent  int
+}
+
+// IsZero reports whether the level removes the exit.
+func (l Level) IsZero() bool {
+	return l.PriceUSD.IsZero() && l.Percent == 0
+}
+
+// Update changes a position's exit levels. Nil fields are left as they are;
+// Clear removes every level before the others apply.
+type Update struct {
+	Clear           bool
+	StopLoss        *Level
+	TakeProfit      *Level
+	TrailingPercent *int
+}
+
+// IsZero reports whether the update changes nothing.
+func (u Update) IsZero() bool {
+	return !u.Clear && u.StopLoss == nil && u.TakeProfit == nil && u.TrailingPercent == nil
+}
+
+// ParseUpdate parses /sltp arguments after the token: any of
+//
+//	sl <price|percent|off>
+//	tp <price|percent|off>
+//	trail <percent|off>
+//
+// or a single "off" that removes every level. Prices look like 0.0012 or
+// $0.0012 and percentages like 10%; the sign of a percentage is ignored.
+func ParseUpdate(args []string) (Update, error) {
+	if len(args) == 1 && strings.EqualFold(args[0], "off") {
+		return Update{Clear: true}, nil
+	}
+	if len(args) == 0 || len(args)%2 != 0 {
+		return Update{}, fmt.Errorf("%w: expected pairs of level and value", ErrInvalidUpdate)
+	}
+
+	var update Update
+	for i := 0; i < len(args); i += 2 {
+		kind, value := strings.ToLower(args[i]), args[i+1]
+
+		switch kind {
+		case "sl":
+			level, err := parseLevel(value, MaxStopLossPercent)
+			if err != nil {
+				return Update{}, err
+			}
+			update.StopLoss = &level
+		case "tp":
+			level, err := parseLevel(value, MaxTakeProfitPercent)
+			if err != nil {
+				return Update{}, err
+			}
+			update.TakeProfit = &level
+		case "trail":
+			percent := 0
+			if !strings.EqualFold(value, "off") {
+				var err error
+				if percent, err = parsePercent(value, MaxTrailingPercent); err != nil {
+					return Update{}, err
+				}
+			}
+			update.TrailingPercent = &percent
+		default:
+			return Update{}, fmt.Errorf("%w: unknown level %q", ErrInvalidUpdate, args[i])
+		}
+	}
+
+	return update, nil
+}
+
+func parseLevel(input string, maxPercent int) (Level, error) {
+	input = strings.TrimSpace(input)
+	if strings.EqualFold(input, "off") {
+		return Level{}, nil
+	}
+
+	if strings.HasSuffix(input, "%") {
+		percent, err := parsePercent(input, maxPercent)
+		if err != nil {
+			return Level{}, err
+		}
+		return Level{Percent: percent}, nil
+	}
+
+	cleaned := strings.ReplaceAll(strings.TrimPrefix(input, "$"), ",", ".")
+	price, err := money.Parse(cleaned)
+	if err != nil || price.Sign() <= 0 {
+		return Level{}, fmt.Errorf("%w: bad price %q", ErrInvalidUpdate, input)
+	}
+	if !price.Round(domain.PricePrecision, money.RoundHalfEven).Equal(price) {
+		return Level{}, fmt.Errorf("%w: price %q has more than %d decimals", ErrInvalidUpdate, input, domain.PricePrecision)
+	}
+
+	return Level{PriceUSD: price}, nil
+}
+
+func parsePercent(input string, maxPercent int) (int, error) {
+	cleaned := strings.TrimSuffix(strings.TrimSpace(input), "%")
+	cleaned = strings.TrimLeft(cleaned, "+-")
+
+	percent, err := strconv.Atoi(cleaned)
+	if err != nil || percent < 1 || percent > maxPercent {
+		return 0, fmt.Errorf("%w: percentage must be between 1%% and %d%%, got %q", ErrInvalidUpdate, maxPercent, input)
+	}
+
+	return percent, nil
+}
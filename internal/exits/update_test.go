@@ -0,0 +1,55 @@
+package exits
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseUpdate(t *testing.T) {
+	update, err := ParseUpdate([]string{"SL", "-10%", "tp", "$0,0025", "trail", "15%"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if update.Clear || update.StopLoss == nil || update.StopLoss.Percent != 10 {
+		t.Errorf("stop-loss = %+v", update.StopLoss)
+	}
+	if update.TakeProfit == nil || update.TakeProfit.PriceUSD.String() != "0.0025" || update.TakeProfit.Percent != 0 {
+		t.Errorf("take-profit = %+v", update.TakeProfit)
+	}
+	if update.TrailingPercent == nil || *update.TrailingPercent != 15 {
+		t.Errorf("trailing percent = %v", update.TrailingPercent)
+	}
+
+	off, err := ParseUpdate([]string{"tp", "off"})
+	if err != nil || off.TakeProfit == nil || !off.TakeProfit.IsZero() || off.StopLoss != nil {
+		t.Errorf("tp off = %+v, %v", off, err)
+	}
+
+	clear, err := ParseUpdate([]string{"OFF"})
+	if err != nil || !clear.Clear {
+		t.Errorf("off = %+v, %v", clear, err)
+	}
+}
+
+func TestParseUpdateErrors(t *testing.T) {
+	testCases := [][]string{
+		nil,
+		{"sl"},
+		{"sl", "10%", "tp"},
+		{"stop", "10%"},
+		{"sl", "100%"},
+		{"tp", "1001%"},
+		{"trail", "51%"},
+		{"trail", "0.5"},
+		{"sl", "0"},
+		{"sl", "cheap"},
+		{"tp", "0.0000000000000000001"},
+	}
+
+	for _, args := range testCases {
+		if _, err := ParseUpdate(args); !errors.Is(err, ErrInvalidUpdate) {
+			t.Errorf("ParseUpdate(%q) error = %v, want ErrInvalidUpdate", args, err)
+		}
+	}
+}
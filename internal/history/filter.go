@@ -0,0 +1,116 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+const dateLayout = "2006-01-02"
+
+// ErrInvalidQuery indicates that the history arguments could not be parsed.
+var ErrInvalidQuery = errors.New("invalid history query")
+
+// Query is a parsed /history request. Token is the raw user input and is
+// resolved to an address by the Service. From and To bound whole days in the
+// user's timezone; To is exclusive.
+type Query struct {
+	Type  domain.TradeType
+	Token string
+	From  time.Time
+	To    time.Time
+}
+
+// ParseQuery parses /history arguments. Each argument is one of:
+//
+//	buy | sell              trade side
+//	2026-01-31              a single day
+//	2026-01-01..2026-01-31  an inclusive range of days; either end may be omitted
+//	anything else           a token symbol or address
+//
+// Dates are interpreted in loc; a nil loc means UTC.
+func ParseQuery(args []string, loc *time.Location) (Query, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var query Query
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			continue
+		}
+
+		switch lower := strings.ToLower(arg); {
+		case lower == string(domain.TradeTypeBuy) || lower == string(domain.TradeTypeSell):
+			if query.Type != "" {
+				return Query{}, fmt.Errorf("%w: trade side given twice", ErrInvalidQuery)
+			}
+			query.Type = domain.TradeType(lower)
+		case looksLikeDateRange(arg):
+			if !query.From.IsZero() || !query.To.IsZero() {
+				return Query{}, fmt.Errorf("%w: date range given twice", ErrInvalidQuery)
+			}
+			from, to, err := parseDateRange(arg, loc)
+			if err != nil {
+				return Query{}, err
+			}
+			query.From, query.To = from, to
+		default:
+			if query.Token != "" {
+				return Query{}, fmt.Errorf("%w: token given twice", ErrInvalidQuery)
+			}
+			query.Token = arg
+		}
+	}
+
+	return query, nil
+}
+
+// IsZero reports whether the query has no filters.
+func (q Query) IsZero() bool {
+	return q.Type == "" && q.Token == "" && q.From.IsZero() && q.To.IsZero()
+}
+
+func looksLikeDateRange(arg string) bool {
+	if strings.Contains(arg, "..") {
+		return true
+	}
+	_, err := time.Parse(dateLayout, arg)
+	return err == nil
+}
+
+func parseDateRange(arg string, loc *time.Location) (time.Time, time.Time, error) {
+	start, end, isRange := strings.Cut(arg, "..")
+	if !isRange {
+		end = start
+	}
+
+	var from, to time.Time
+	if start != "" {
+		day, err := time.ParseInLocation(dateLayout, start, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: bad date %q", ErrInvalidQuery, start)
+		}
+		from = day
+	}
+	if end != "" {
+		day, err := time.ParseInLocation(dateLayout, end, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: bad date %q", ErrInvalidQuery, end)
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+
+	if from.IsZero() && to.IsZero() {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: empty date range", ErrInvalidQuery)
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: range ends before it starts", ErrInvalidQuery)
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,67 @@
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+func TestParseQuery(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+
+	query, err := ParseQuery([]string{"SELL", "bonk", "2026-01-01..2026-01-31"}, loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if query.Type != domain.TradeTypeSell || query.Token != "bonk" {
+		t.Fatalf("unexpected query: %+v", query)
+	}
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, loc); !query.From.Equal(want) {
+		t.Errorf("From = %s, want %s", query.From, want)
+	}
+	if want := time.Date(2026, 2, 1, 0, 0, 0, 0, loc); !query.To.Equal(want) {
+		t.Errorf("To = %s, want %s", query.To, want)
+	}
+}
+
+func TestParseQueryDates(t *testing.T) {
+	day, err := ParseQuery([]string{"2026-03-15"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !day.From.Equal(time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)) || !day.To.Equal(time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("single day = %s..%s", day.From, day.To)
+	}
+
+	openEnded, err := ParseQuery([]string{"2026-03-15.."}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if openEnded.From.IsZero() || !openEnded.To.IsZero() {
+		t.Errorf("open-ended range = %s..%s", openEnded.From, openEnded.To)
+	}
+
+	if empty, err := ParseQuery(nil, nil); err != nil || !empty.IsZero() {
+		t.Errorf("empty query = %+v, %v", empty, err)
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	testCases := [][]string{
+		{"buy", "sell"},
+		{"2026-02-01..2026-01-01"},
+		{"2026-13-01..2026-12-01"},
+		{".."},
+		{"2026-01-01", "2026-01-02"},
+		{"bonk", "wif"},
+	}
+
+	for _, args := range testCases {
+		if _, err := ParseQuery(args, nil); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("ParseQuery(%q) error = %v, want ErrInvalidQuery", args, err)
+		}
+	}
+}
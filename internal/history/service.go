@@ -0,0 +1,162 @@
+// Package history lists users' executed trades with filters and keyset pagination.
+package history
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+)
+
+// PageSize is the number of trades shown per history page.
+const PageSize = 10
+
+// TokenFinder resolves a token symbol or address entered by the user.
+type TokenFinder interface {
+	FindToken(ctx context.Context, query string) (*domain.Token, error)
+}
+
+// Page is one page of a user's trade history.
+type Page struct {
+	Number     int
+	TotalPages int
+	Total      int
+	Trades     []*domain.Trade
+	Filter     domain.TradeFilter
+	TokenLabel string
+}
+
+// Service builds paginated trade history listings.
+type Service struct {
+	trades   repository.TradeRepository
+	tokens   TokenFinder
+	sessions *SessionStore
+	log      *slog.Logger
+}
+
+// NewService constructs a history Service. tokens may be nil, in which case
+// token filters are matched against the raw address.
+func NewService(trades repository.TradeRepository, tokens TokenFinder, sessions *SessionStore, log *slog.Logger) *Service {
+	return &Service{trades: trades, tokens: tokens, sessions: sessions, log: log}
+}
+
+// Start begins a new listing for query and returns its first page.
+func (s *Service) Start(ctx context.Context, userID int64, query Query) (*Page, error) {
+	stored := &session{
+		Type: query.Type,
+		From: query.From,
+		To:   query.To,
+	}
+	if query.Token != "" {
+		stored.TokenAddress, stored.TokenLabel = s.resolveToken(ctx, query.Token)
+	}
+
+	total, err := s.trades.CountTrades(ctx, userID, stored.filter())
+	if err != nil {
+		s.logError("count_trades", userID, err)
+		return nil, fmt.Errorf("count trades: %w", err)
+	}
+	stored.Total = total
+
+	return s.page(ctx, userID, stored, 1)
+}
+
+// Open returns the requested page of the user's current listing. It returns
+// ErrSessionExpired when the listing is no longer available.
+func (s *Service) Open(ctx context.Context, userID int64, number int) (*Page, error) {
+	stored, err := s.sessions.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.page(ctx, userID, stored, number)
+}
+
+func (s *Service) page(ctx context.Context, userID int64, stored *session, number int) (*Page, error) {
+	totalPages := (stored.Total + PageSize - 1) / PageSize
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	// Pages are reached one step at a time, so only pages up to one past the
+	// last known cursor can be addressed.
+	if number > len(stored.Cursors)+1 {
+		number = len(stored.Cursors) + 1
+	}
+	if number > totalPages {
+		number = totalPages
+	}
+	if number < 1 {
+		number = 1
+	}
+
+	var after *domain.TradeCursor
+	if number > 1 {
+		bound := stored.Cursors[number-2]
+		after = &domain.TradeCursor{CreatedAt: bound.CreatedAt, ID: bound.ID}
+	}
+
+	trades, err := s.trades.ListTrades(ctx, userID, stored.filter(), after, PageSize)
+	if err != nil {
+		s.logError("list_trades", userID, err)
+		return nil, fmt.Errorf("list trades: %w", err)
+	}
+
+	if len(trades) > 0 {
+		last := trades[len(trades)-1]
+		next := cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if number <= len(stored.Cursors) {
+			stored.Cursors[number-1] = next
+		} else {
+			stored.Cursors = append(stored.Cursors, next)
+		}
+	}
+
+	if err := s.sessions.save(ctx, userID, stored); err != nil {
+		s.logError("save_session", userID, err)
+		return nil, err
+	}
+
+	return &Page{
+		Number:     number,
+		TotalPages: totalPages,
+		Total:      stored.Total,
+		Trades:     trades,
+		Filter:     stored.filter(),
+		TokenLabel: stored.TokenLabel,
+	}, nil
+}
+
+func (s *Service) resolveToken(ctx context.Context, input string) (address, label string) {
+	input = strings.TrimSpace(input)
+	if s.tokens == nil {
+		return input, input
+	}
+
+	token, err := s.tokens.FindToken(ctx, input)
+	if err != nil || token == nil {
+		return input, input
+	}
+
+	label = token.Symbol
+	if label == "" {
+		label = token.Address
+	}
+
+	return token.Address, label
+}
+
+func (s *Service) logError(operation string, telegramID int64, err error) {
+	if s == nil || s.log == nil || err == nil {
+		return
+	}
+
+	s.log.Error("history service operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", telegramID),
+		slog.Any("error", err),
+	)
+}
@@ -0,0 +1,174 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+)
+
+// stubTrades serves trades from memory with the same ordering and keyset
+// semantics as the SQL repository.
+type stubTrades struct {
+	repository.TradeRepository
+	trades  []*domain.Trade
+	filters []domain.TradeFilter
+}
+
+func (s *stubTrades) ListTrades(_ context.Context, _ int64, filter domain.TradeFilter, after *domain.TradeCursor, limit int) ([]*domain.Trade, error) {
+	s.filters = append(s.filters, filter)
+
+	matched := make([]*domain.Trade, 0)
+	for _, trade := range s.sorted() {
+		if filter.Type != "" && trade.Type != filter.Type {
+			continue
+		}
+		if after != nil && !olderThan(trade, after) {
+			continue
+		}
+		matched = append(matched, trade)
+		if len(matched) == limit {
+			break
+		}
+	}
+	return matched, nil
+}
+
+func (s *stubTrades) CountTrades(_ context.Context, _ int64, filter domain.TradeFilter) (int, error) {
+	count := 0
+	for _, trade := range s.trades {
+		if filter.Type == "" || trade.Type == filter.Type {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *stubTrades) sorted() []*domain.Trade {
+	sorted := append([]*domain.Trade(nil), s.trades...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return !olderThan(sorted[i], &domain.TradeCursor{CreatedAt: sorted[j].CreatedAt, ID: sorted[j].ID})
+	})
+	return sorted
+}
+
+func olderThan(trade *domain.Trade, cursor *domain.TradeCursor) bool {
+	if trade.CreatedAt.Equal(cursor.CreatedAt) {
+		return trade.ID < cursor.ID
+	}
+	return trade.CreatedAt.Before(cursor.CreatedAt)
+}
+
+type stubTokens struct{}
+
+func (stubTokens) FindToken(_ context.Context, query string) (*domain.Token, error) {
+	return &domain.Token{Address: "addr-" + query, Symbol: "BONK"}, nil
+}
+
+func newTestStore(t *testing.T) *SessionStore {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewSessionStore(client, time.Minute)
+}
+
+func seedTrades(count int) []*domain.Trade {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	trades := make([]*domain.Trade, 0, count)
+	for i := 1; i <= count; i++ {
+		tradeType := domain.TradeTypeBuy
+		if i%2 == 0 {
+			tradeType = domain.TradeTypeSell
+		}
+		// Pairs of trades share a timestamp so the id tie-breaker is exercised.
+		trades = append(trades, &domain.Trade{ID: int64(i), Type: tradeType, CreatedAt: base.Add(time.Duration(i/2) * time.Minute)})
+	}
+	return trades
+}
+
+func TestServicePagination(t *testing.T) {
+	repo := &stubTrades{trades: seedTrades(25)}
+	svc := NewService(repo, nil, newTestStore(t), nil)
+	ctx := context.Background()
+
+	first, err := svc.Start(ctx, 1, Query{})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if first.TotalPages != 3 || first.Total != 25 || len(first.Trades) != PageSize || first.Trades[0].ID != 25 {
+		t.Fatalf("unexpected first page: %d pages, %d trades, top id %d", first.TotalPages, len(first.Trades), first.Trades[0].ID)
+	}
+
+	second, err := svc.Open(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("Open(2): %v", err)
+	}
+	if second.Trades[0].ID != 15 {
+		t.Fatalf("second page starts at %d, want 15", second.Trades[0].ID)
+	}
+
+	third, err := svc.Open(ctx, 1, 3)
+	if err != nil {
+		t.Fatalf("Open(3): %v", err)
+	}
+	if len(third.Trades) != 5 || third.Trades[4].ID != 1 {
+		t.Fatalf("unexpected last page: %d trades", len(third.Trades))
+	}
+
+	back, err := svc.Open(ctx, 1, 2)
+	if err != nil || back.Trades[0].ID != 15 {
+		t.Fatalf("going back returned %+v, %v", back, err)
+	}
+}
+
+func TestServiceOpenClampsToKnownPages(t *testing.T) {
+	svc := NewService(&stubTrades{trades: seedTrades(40)}, nil, newTestStore(t), nil)
+	ctx := context.Background()
+
+	if _, err := svc.Start(ctx, 1, Query{}); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	page, err := svc.Open(ctx, 1, 4)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if page.Number != 2 {
+		t.Fatalf("page = %d, want 2", page.Number)
+	}
+}
+
+func TestServiceFilters(t *testing.T) {
+	repo := &stubTrades{trades: seedTrades(6)}
+	svc := NewService(repo, stubTokens{}, newTestStore(t), nil)
+
+	page, err := svc.Start(context.Background(), 1, Query{Type: domain.TradeTypeSell, Token: "bonk"})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if page.Total != 3 || page.TokenLabel != "BONK" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	if filter := repo.filters[0]; filter.TokenAddress != "addr-bonk" || filter.Type != domain.TradeTypeSell {
+		t.Fatalf("unexpected filter: %+v", filter)
+	}
+}
+
+func TestServiceOpenWithoutSession(t *testing.T) {
+	svc := NewService(&stubTrades{}, nil, newTestStore(t), nil)
+
+	if _, err := svc.Open(context.Background(), 1, 2); !errors.Is(err, ErrSessionExpired) {
+		t.Fatalf("expected ErrSessionExpired, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+// ErrSessionExpired indicates that the user's history listing is no longer cached
+// and must be restarted with /history.
+var ErrSessionExpired = errors.New("history session expired")
+
+const sessionKeyPrefix = "history:session:"
+
+// session is the state of a paginated history listing. Cursors[i] is the last
+// trade shown on page i+1, i.e. the keyset bound for page i+2.
+type session struct {
+	Type         domain.TradeType `json:"type,omitempty"`
+	TokenAddress string           `json:"token_address,omitempty"`
+	TokenLabel   string           `json:"token_label,omitempty"`
+	From         time.Time        `json:"from,omitempty"`
+	To           time.Time        `json:"to,omitempty"`
+	Total        int              `json:"total"`
+	Cursors      []cursor         `json:"cursors"`
+}
+
+type cursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+func (s *session) filter() domain.TradeFilter {
+	return domain.TradeFilter{
+		Type:         s.Type,
+		TokenAddress: s.TokenAddress,
+		From:         s.From,
+		To:           s.To,
+	}
+}
+
+// SessionStore keeps per-user history listings in Redis so that pagination
+// callbacks only need to carry a page number.
+type SessionStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewSessionStore constructs a SessionStore whose entries expire after ttl.
+func NewSessionStore(client *redis.Client, ttl time.Duration) *SessionStore {
+	return &SessionStore{client: client, ttl: ttl}
+}
+
+func (s *SessionStore) load(ctx context.Context, userID int64) (*session, error) {
+	if s == nil || s.client == nil {
+		return nil, ErrSessionExpired
+	}
+
+	data, err := s.client.Get(ctx, sessionKey(userID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrSessionExpired
+		}
+		return nil, fmt.Errorf("get history session: %w", err)
+	}
+
+	var stored session
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode history session: %w", err)
+	}
+
+	return &stored, nil
+}
+
+func (s *SessionStore) save(ctx context.Context, userID int64, stored *session) error {
+	if s == nil || s.client == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("encode history session: %w", err)
+	}
+
+	if err := s.client.Set(ctx, sessionKey(userID), payload, s.ttl).Err(); err != nil {
+		return fmt.Errorf("set history session: %w", err)
+	}
+
+	return nil
+}
+
+func sessionKey(userID int64) string {
+	return sessionKeyPrefix + strconv.FormatInt(userID, 10)
+}
@@ -2,12 +2,20 @@ package idempotency
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	// defaultRetention bounds the TTL of idempotency keys swept by Run.
+	defaultRetention = 25 * time.Hour
+	// defaultScanCount is the SCAN page size used by Run.
+	defaultScanCount = 100
+)
+
 type Cleaner struct {
 	client   *redis.Client
 	log      *slog.Logger
@@ -45,19 +53,38 @@ func (c *Cleaner) Run(ctx context.Context) {
 }
 
 func (c *Cleaner) cleanup(ctx context.Context) {
+	if _, err := c.Purge(ctx, time.Now().Add(-defaultRetention), defaultScanCount); err != nil {
+		c.log.Error("idempotency cleaner scan failed", slog.Any("error", err))
+	}
+}
+
+// Purge deletes idempotency keys that would outlive the cutoff: keys without a
+// TTL and keys whose remaining TTL exceeds the time since cutoff. Keys are
+// scanned and deleted in batches of at most batchSize. It returns the number
+// of keys removed.
+func (c *Cleaner) Purge(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	if c == nil || c.client == nil {
+		return 0, nil
+	}
+	if batchSize <= 0 {
+		batchSize = defaultScanCount
+	}
+
+	retention := time.Since(cutoff)
+
 	var (
-		cursor uint64
-		err    error
+		cursor  uint64
+		deleted int64
 	)
 
 	for {
-		var keys []string
-		keys, cursor, err = c.client.Scan(ctx, cursor, "idempotency:*", 100).Result()
+		keys, next, err := c.client.Scan(ctx, cursor, "idempotency:*", int64(batchSize)).Result()
 		if err != nil {
-			c.log.Error("idempotency cleaner scan failed", slog.Any("error", err))
-			return
+			return deleted, fmt.Errorf("scan idempotency keys: %w", err)
 		}
+		cursor = next
 
+		expired := make([]string, 0, len(keys))
 		for _, key := range keys {
 			ttl, err := c.client.TTL(ctx, key).Result()
 			if err != nil {
@@ -65,15 +92,21 @@ func (c *Cleaner) cleanup(ctx context.Context) {
 				continue
 			}
 
-			if ttl < 0 || ttl > 25*time.Hour {
-				if err := c.client.Del(ctx, key).Err(); err != nil {
-					c.log.Warn("failed to delete stale idempotency key", slog.String("key", key), slog.Any("error", err))
-				}
+			if ttl < 0 || ttl > retention {
+				expired = append(expired, key)
+			}
+		}
+
+		if len(expired) > 0 {
+			removed, err := c.client.Del(ctx, expired...).Result()
+			if err != nil {
+				return deleted, fmt.Errorf("delete stale idempotency keys: %w", err)
 			}
+			deleted += removed
 		}
 
 		if cursor == 0 {
-			break
+			return deleted, nil
 		}
 	}
 }
@@ -0,0 +1,343 @@
+package jobs
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// AdminPathPrefix is where the admin handler expects to be mounted.
+const AdminPathPrefix = "/admin/jobs/"
+
+const (
+	defaultTaskPageSize = 50
+	maxTaskPageSize     = 500
+)
+
+// Inspector is the subset of asynq.Inspector used by the admin endpoints.
+type Inspector interface {
+	Queues() ([]string, error)
+	GetQueueInfo(queue string) (*asynq.QueueInfo, error)
+	GetTaskInfo(queue, id string) (*asynq.TaskInfo, error)
+	ListPendingTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListActiveTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListScheduledTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListRetryTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListArchivedTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	ListCompletedTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	RunTask(queue, id string) error
+	DeleteTask(queue, id string) error
+	ArchiveTask(queue, id string) error
+	PauseQueue(queue string) error
+	UnpauseQueue(queue string) error
+}
+
+var _ Inspector = (*asynq.Inspector)(nil)
+
+type adminHandler struct {
+	inspector Inspector
+	log       *slog.Logger
+}
+
+// NewAdminHandler exposes queue and task inspection under AdminPathPrefix.
+// Every request must carry "Authorization: Bearer <token>"; an empty token
+// rejects all requests.
+func NewAdminHandler(inspector Inspector, token string, log *slog.Logger) http.Handler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	h := &adminHandler{inspector: inspector, log: log}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+AdminPathPrefix+"queues", h.listQueues)
+	mux.HandleFunc("POST "+AdminPathPrefix+"queues/{queue}/pause", h.pauseQueue)
+	mux.HandleFunc("POST "+AdminPathPrefix+"queues/{queue}/unpause", h.unpauseQueue)
+	mux.HandleFunc("GET "+AdminPathPrefix+"queues/{queue}/tasks", h.listTasks)
+	mux.HandleFunc("GET "+AdminPathPrefix+"queues/{queue}/tasks/{id}", h.getTask)
+	mux.HandleFunc("DELETE "+AdminPathPrefix+"queues/{queue}/tasks/{id}", h.deleteTask)
+	mux.HandleFunc("POST "+AdminPathPrefix+"queues/{queue}/tasks/{id}/run", h.runTask)
+	mux.HandleFunc("POST "+AdminPathPrefix+"queues/{queue}/tasks/{id}/archive", h.archiveTask)
+
+	return requireToken(token, mux)
+}
+
+// QueueView is the JSON representation of a queue.
+type QueueView struct {
+	Queue     string `json:"queue"`
+	Paused    bool   `json:"paused"`
+	Size      int    `json:"size"`
+	Pending   int    `json:"pending"`
+	Active    int    `json:"active"`
+	Scheduled int    `json:"scheduled"`
+	Retry     int    `json:"retry"`
+	Archived  int    `json:"archived"`
+	Completed int    `json:"completed"`
+	Processed int    `json:"processed_today"`
+	Failed    int    `json:"failed_today"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// TaskView is the JSON representation of a task.
+type TaskView struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	Queue         string          `json:"queue"`
+	State         string          `json:"state"`
+	Payload       json.RawMessage `json:"payload,omitempty"`
+	Retried       int             `json:"retried"`
+	MaxRetry      int             `json:"max_retry"`
+	LastError     string          `json:"last_error,omitempty"`
+	LastFailedAt  *time.Time      `json:"last_failed_at,omitempty"`
+	NextProcessAt *time.Time      `json:"next_process_at,omitempty"`
+	CompletedAt   *time.Time      `json:"completed_at,omitempty"`
+}
+
+func (h *adminHandler) listQueues(w http.ResponseWriter, r *http.Request) {
+	names, err := h.inspector.Queues()
+	if err != nil {
+		h.fail(w, r, "list queues", err)
+		return
+	}
+
+	queues := make([]QueueView, 0, len(names))
+	for _, name := range names {
+		info, err := h.inspector.GetQueueInfo(name)
+		if err != nil {
+			h.fail(w, r, "get queue info", err)
+			return
+		}
+		queues = append(queues, newQueueView(info))
+	}
+
+	h.respond(w, r, http.StatusOK, map[string]any{"queues": queues})
+}
+
+func (h *adminHandler) listTasks(w http.ResponseWriter, r *http.Request) {
+	queue := r.PathValue("queue")
+	query := r.URL.Query()
+
+	state := query.Get("state")
+	if state == "" {
+		state = "pending"
+	}
+	list, ok := h.listerFor(state)
+	if !ok {
+		h.respondError(w, r, http.StatusBadRequest, fmt.Sprintf("unknown task state %q", state))
+		return
+	}
+
+	page, err := positiveParam(query.Get("page"), 1)
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, "page: "+err.Error())
+		return
+	}
+	size, err := positiveParam(query.Get("size"), defaultTaskPageSize)
+	if err != nil {
+		h.respondError(w, r, http.StatusBadRequest, "size: "+err.Error())
+		return
+	}
+	if size > maxTaskPageSize {
+		size = maxTaskPageSize
+	}
+
+	infos, err := list(queue, asynq.Page(page), asynq.PageSize(size))
+	if err != nil {
+		h.fail(w, r, "list tasks", err)
+		return
+	}
+
+	tasks := make([]TaskView, 0, len(infos))
+	for _, info := range infos {
+		tasks = append(tasks, newTaskView(info))
+	}
+
+	h.respond(w, r, http.StatusOK, map[string]any{
+		"queue": queue,
+		"state": state,
+		"page":  page,
+		"size":  size,
+		"tasks": tasks,
+	})
+}
+
+func (h *adminHandler) getTask(w http.ResponseWriter, r *http.Request) {
+	info, err := h.inspector.GetTaskInfo(r.PathValue("queue"), r.PathValue("id"))
+	if err != nil {
+		h.fail(w, r, "get task", err)
+		return
+	}
+
+	h.respond(w, r, http.StatusOK, newTaskView(info))
+}
+
+func (h *adminHandler) runTask(w http.ResponseWriter, r *http.Request) {
+	h.mutateTask(w, r, "run task", h.inspector.RunTask)
+}
+
+func (h *adminHandler) deleteTask(w http.ResponseWriter, r *http.Request) {
+	h.mutateTask(w, r, "delete task", h.inspector.DeleteTask)
+}
+
+func (h *adminHandler) archiveTask(w http.ResponseWriter, r *http.Request) {
+	h.mutateTask(w, r, "archive task", h.inspector.ArchiveTask)
+}
+
+func (h *adminHandler) pauseQueue(w http.ResponseWriter, r *http.Request) {
+	h.mutateQueue(w, r, "pause queue", h.inspector.PauseQueue)
+}
+
+func (h *adminHandler) unpauseQueue(w http.ResponseWriter, r *http.Request) {
+	h.mutateQueue(w, r, "unpause queue", h.inspector.UnpauseQueue)
+}
+
+func (h *adminHandler) mutateTask(w http.ResponseWriter, r *http.Request, operation string, mutate func(queue, id string) error) {
+	queue, id := r.PathValue("queue"), r.PathValue("id")
+	if err := mutate(queue, id); err != nil {
+		h.fail(w, r, operation, err)
+		return
+	}
+
+	h.log.InfoContext(r.Context(), "jobs admin: "+operation, slog.String("queue", queue), slog.String("task_id", id))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *adminHandler) mutateQueue(w http.ResponseWriter, r *http.Request, operation string, mutate func(queue string) error) {
+	queue := r.PathValue("queue")
+	if err := mutate(queue); err != nil {
+		h.fail(w, r, operation, err)
+		return
+	}
+
+	h.log.InfoContext(r.Context(), "jobs admin: "+operation, slog.String("queue", queue))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *adminHandler) listerFor(state string) (func(string, ...asynq.ListOption) ([]*asynq.TaskInfo, error), bool) {
+	switch state {
+	case "pending":
+		return h.inspector.ListPendingTasks, true
+	case "active":
+		return h.inspector.ListActiveTasks, true
+	case "scheduled":
+		return h.inspector.ListScheduledTasks, true
+	case "retry":
+		return h.inspector.ListRetryTasks, true
+	case "archived":
+		return h.inspector.ListArchivedTasks, true
+	case "completed":
+		return h.inspector.ListCompletedTasks, true
+	default:
+		return nil, false
+	}
+}
+
+// fail maps inspector errors to HTTP statuses. asynq reports invalid state
+// transitions (such as running an active task) only as plain errors, so those
+// surface as 500 with the inspector's message.
+func (h *adminHandler) fail(w http.ResponseWriter, r *http.Request, operation string, err error) {
+	switch {
+	case errors.Is(err, asynq.ErrQueueNotFound), errors.Is(err, asynq.ErrTaskNotFound):
+		h.respondError(w, r, http.StatusNotFound, err.Error())
+	default:
+		h.log.ErrorContext(r.Context(), "jobs admin operation failed", slog.String("operation", operation), slog.Any("error", err))
+		h.respondError(w, r, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *adminHandler) respondError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	h.respond(w, r, status, map[string]string{"error": message})
+}
+
+func (h *adminHandler) respond(w http.ResponseWriter, r *http.Request, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.log.ErrorContext(r.Context(), "failed to write jobs admin response", slog.Any("error", err))
+	}
+}
+
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func positiveParam(raw string, fallback int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("must be a positive integer, got %q", raw)
+	}
+
+	return value, nil
+}
+
+func newQueueView(info *asynq.QueueInfo) QueueView {
+	return QueueView{
+		Queue:     info.Queue,
+		Paused:    info.Paused,
+		Size:      info.Size,
+		Pending:   info.Pending,
+		Active:    info.Active,
+		Scheduled: info.Scheduled,
+		Retry:     info.Retry,
+		Archived:  info.Archived,
+		Completed: info.Completed,
+		Processed: info.Processed,
+		Failed:    info.Failed,
+		LatencyMS: info.Latency.Milliseconds(),
+	}
+}
+
+func newTaskView(info *asynq.TaskInfo) TaskView {
+	view := TaskView{
+		ID:            info.ID,
+		Type:          info.Type,
+		Queue:         info.Queue,
+		State:         info.State.String(),
+		Retried:       info.Retried,
+		MaxRetry:      info.MaxRetry,
+		LastError:     info.LastErr,
+		LastFailedAt:  optionalTime(info.LastFailedAt),
+		NextProcessAt: optionalTime(info.NextProcessAt),
+		CompletedAt:   optionalTime(info.CompletedAt),
+	}
+
+	// Payloads are JSON for every registered task; anything else is returned as a string.
+	switch {
+	case len(info.Payload) == 0:
+	case json.Valid(info.Payload):
+		view.Payload = json.RawMessage(info.Payload)
+	default:
+		view.Payload, _ = json.Marshal(string(info.Payload))
+	}
+
+	return view
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
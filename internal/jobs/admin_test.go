@@ -0,0 +1,168 @@
+package jobs
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hibiken/asynq"
+)
+
+type stubInspector struct {
+	Inspector
+
+	tasks  map[string]*asynq.TaskInfo
+	paused map[string]bool
+	ran    []string
+}
+
+func (s *stubInspector) Queues() ([]string, error) { return []string{QueueDefault}, nil }
+
+func (s *stubInspector) GetQueueInfo(queue string) (*asynq.QueueInfo, error) {
+	return &asynq.QueueInfo{Queue: queue, Size: len(s.tasks), Retry: len(s.tasks), Paused: s.paused[queue]}, nil
+}
+
+func (s *stubInspector) GetTaskInfo(_, id string) (*asynq.TaskInfo, error) {
+	info, ok := s.tasks[id]
+	if !ok {
+		return nil, asynq.ErrTaskNotFound
+	}
+	return info, nil
+}
+
+func (s *stubInspector) ListRetryTasks(string, ...asynq.ListOption) ([]*asynq.TaskInfo, error) {
+	infos := make([]*asynq.TaskInfo, 0, len(s.tasks))
+	for _, info := range s.tasks {
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (s *stubInspector) RunTask(_, id string) error {
+	if _, ok := s.tasks[id]; !ok {
+		return asynq.ErrTaskNotFound
+	}
+	s.ran = append(s.ran, id)
+	return nil
+}
+
+func (s *stubInspector) PauseQueue(queue string) error {
+	s.paused[queue] = true
+	return nil
+}
+
+func newTestAdmin(t *testing.T) (*stubInspector, http.Handler) {
+	t.Helper()
+
+	inspector := &stubInspector{
+		tasks: map[string]*asynq.TaskInfo{
+			"t1": {
+				ID:      "t1",
+				Type:    TaskTypePriceUpdate,
+				Queue:   QueueDefault,
+				State:   asynq.TaskStateRetry,
+				Payload: []byte(`{"token_addresses":["ALL"]}`),
+				Retried: 2,
+				LastErr: "fetch prices: timeout",
+			},
+		},
+		paused: map[string]bool{},
+	}
+
+	return inspector, NewAdminHandler(inspector, "secret", slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func serve(h http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminHandlerRequiresToken(t *testing.T) {
+	_, h := newTestAdmin(t)
+
+	for _, token := range []string{"", "wrong"} {
+		if rec := serve(h, http.MethodGet, AdminPathPrefix+"queues", token); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("token %q: status = %d, want 401", token, rec.Code)
+		}
+	}
+
+	disabled := NewAdminHandler(&stubInspector{}, "", nil)
+	if rec := serve(disabled, http.MethodGet, AdminPathPrefix+"queues", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("empty token: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestAdminHandlerInspectsTasks(t *testing.T) {
+	_, h := newTestAdmin(t)
+
+	rec := serve(h, http.MethodGet, AdminPathPrefix+"queues", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("queues: status = %d, body %s", rec.Code, rec.Body)
+	}
+	var queues struct {
+		Queues []QueueView `json:"queues"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &queues); err != nil {
+		t.Fatalf("decode queues: %v", err)
+	}
+	if len(queues.Queues) != 1 || queues.Queues[0].Retry != 1 {
+		t.Fatalf("unexpected queues: %+v", queues.Queues)
+	}
+
+	rec = serve(h, http.MethodGet, AdminPathPrefix+"queues/default/tasks?state=retry", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("tasks: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(h, http.MethodGet, AdminPathPrefix+"queues/default/tasks/t1", "secret")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("task: status = %d, body %s", rec.Code, rec.Body)
+	}
+	var task TaskView
+	if err := json.Unmarshal(rec.Body.Bytes(), &task); err != nil {
+		t.Fatalf("decode task: %v", err)
+	}
+	if task.State != "retry" || task.LastError != "fetch prices: timeout" || string(task.Payload) != `{"token_addresses":["ALL"]}` {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+
+	if rec := serve(h, http.MethodGet, AdminPathPrefix+"queues/default/tasks/missing", "secret"); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing task: status = %d, want 404", rec.Code)
+	}
+	if rec := serve(h, http.MethodGet, AdminPathPrefix+"queues/default/tasks?state=lost", "secret"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown state: status = %d, want 400", rec.Code)
+	}
+	if rec := serve(h, http.MethodGet, AdminPathPrefix+"queues/default/tasks?state=retry&size=0", "secret"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("zero size: status = %d, want 400", rec.Code)
+	}
+}
+
+func TestAdminHandlerControlsTasksAndQueues(t *testing.T) {
+	inspector, h := newTestAdmin(t)
+
+	if rec := serve(h, http.MethodPost, AdminPathPrefix+"queues/default/tasks/t1/run", "secret"); rec.Code != http.StatusNoContent {
+		t.Fatalf("run: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if len(inspector.ran) != 1 || inspector.ran[0] != "t1" {
+		t.Fatalf("expected t1 to run, got %v", inspector.ran)
+	}
+
+	if rec := serve(h, http.MethodPost, AdminPathPrefix+"queues/default/pause", "secret"); rec.Code != http.StatusNoContent {
+		t.Fatalf("pause: status = %d, body %s", rec.Code, rec.Body)
+	}
+	if !inspector.paused[QueueDefault] {
+		t.Fatal("expected default queue to be paused")
+	}
+
+	if rec := serve(h, http.MethodGet, AdminPathPrefix+"queues/default/pause", "secret"); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET pause: status = %d, want 405", rec.Code)
+	}
+}
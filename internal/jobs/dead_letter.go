@@ -0,0 +1,253 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+)
+
+// maxNotifiedErrorLen keeps admin notifications well below Telegram's message limit.
+const maxNotifiedErrorLen = 1000
+
+var (
+	// ErrDeadLetterNotFound indicates that no dead letter has the requested ID.
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrAlreadyRequeued indicates that the dead letter was re-enqueued before.
+	ErrAlreadyRequeued = errors.New("dead letter already requeued")
+)
+
+// DeadLetterStore persists tasks that exhausted their retries.
+type DeadLetterStore interface {
+	Record(ctx context.Context, letter *domain.DeadLetter) error
+	Get(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	MarkRequeued(ctx context.Context, id int64, taskID string, at time.Time) error
+}
+
+// ErrorReporter forwards errors to logs, metrics and Sentry; errors.Handler implements it.
+type ErrorReporter interface {
+	Handle(ctx context.Context, err error) (string, bool)
+}
+
+// AdminNotifier delivers operational alerts to the bot administrators.
+type AdminNotifier interface {
+	NotifyAdmins(ctx context.Context, text string) error
+}
+
+// DeadLetterHandler is the worker's asynq error hook. It ignores failures
+// that asynq will retry and dead-letters the rest.
+type DeadLetterHandler struct {
+	log      *slog.Logger
+	store    DeadLetterStore
+	reporter ErrorReporter
+	notifier AdminNotifier
+	now      func() time.Time
+}
+
+// NewDeadLetterHandler constructs the hook. reporter and notifier may be nil.
+func NewDeadLetterHandler(log *slog.Logger, store DeadLetterStore, reporter ErrorReporter, notifier AdminNotifier) *DeadLetterHandler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &DeadLetterHandler{
+		log:      log,
+		store:    store,
+		reporter: reporter,
+		notifier: notifier,
+		now:      time.Now,
+	}
+}
+
+var _ asynq.ErrorHandler = (*DeadLetterHandler)(nil)
+
+// HandleError records a terminal failure, reports it and alerts the administrators.
+// Each step runs even if an earlier one fails, so a database outage still pages someone.
+func (h *DeadLetterHandler) HandleError(ctx context.Context, task *asynq.Task, err error) {
+	if err == nil || outcome(ctx, err) != OutcomeFailed {
+		return
+	}
+
+	md, _ := TaskMetadataFromContext(ctx)
+
+	letter := &domain.DeadLetter{
+		TaskID:     md.ID,
+		TaskType:   task.Type(),
+		Queue:      md.Queue,
+		Payload:    task.Payload(),
+		Error:      err.Error(),
+		ErrorChain: errorChain(err),
+		Retried:    md.RetryCount,
+		MaxRetry:   md.MaxRetry,
+		FailedAt:   h.now().UTC(),
+	}
+
+	if h.store != nil {
+		if recordErr := h.store.Record(ctx, letter); recordErr != nil {
+			h.log.ErrorContext(ctx, "failed to record dead letter",
+				slog.String("task_type", letter.TaskType),
+				slog.String("task_id", letter.TaskID),
+				slog.Any("error", recordErr),
+			)
+		}
+	}
+
+	if h.reporter != nil {
+		h.reporter.Handle(ctx, apperrors.NewJobFailedError(letter.TaskType, err))
+	}
+
+	if h.notifier != nil {
+		if notifyErr := h.notifier.NotifyAdmins(ctx, deadLetterMessage(letter)); notifyErr != nil {
+			h.log.ErrorContext(ctx, "failed to notify admins about dead letter",
+				slog.String("task_id", letter.TaskID),
+				slog.Any("error", notifyErr),
+			)
+		}
+	}
+}
+
+// TaskRunner moves an archived task back to pending; asynq.Inspector implements it.
+type TaskRunner interface {
+	RunTask(queue, id string) error
+}
+
+// DeadLetterRequeuer re-enqueues dead-lettered tasks on request of an administrator.
+type DeadLetterRequeuer struct {
+	log     *slog.Logger
+	store   DeadLetterStore
+	runner  TaskRunner
+	manager Manager
+	now     func() time.Time
+}
+
+// NewDeadLetterRequeuer constructs a requeuer.
+func NewDeadLetterRequeuer(log *slog.Logger, store DeadLetterStore, runner TaskRunner, manager Manager) *DeadLetterRequeuer {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &DeadLetterRequeuer{
+		log:     log,
+		store:   store,
+		runner:  runner,
+		manager: manager,
+		now:     time.Now,
+	}
+}
+
+// Requeue runs the dead letter again and returns the ID of the task that will
+// process it. The archived task is moved back to pending when asynq still has
+// it; otherwise a new task is enqueued from the stored payload.
+func (r *DeadLetterRequeuer) Requeue(ctx context.Context, id int64) (string, error) {
+	letter, err := r.store.Get(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("load dead letter: %w", err)
+	}
+	if letter == nil {
+		return "", ErrDeadLetterNotFound
+	}
+	if letter.RequeuedAt != nil {
+		return "", fmt.Errorf("%w as task %s", ErrAlreadyRequeued, letter.RequeuedTaskID)
+	}
+
+	taskID, err := r.enqueue(ctx, letter)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.store.MarkRequeued(ctx, letter.ID, taskID, r.now()); err != nil {
+		// The task is already queued; a missing marker only allows a duplicate requeue.
+		r.log.ErrorContext(ctx, "failed to mark dead letter requeued",
+			slog.Int64("dead_letter_id", letter.ID),
+			slog.String("task_id", taskID),
+			slog.Any("error", err),
+		)
+	}
+
+	r.log.InfoContext(ctx, "dead letter requeued",
+		slog.Int64("dead_letter_id", letter.ID),
+		slog.String("task_type", letter.TaskType),
+		slog.String("task_id", taskID),
+	)
+
+	return taskID, nil
+}
+
+func (r *DeadLetterRequeuer) enqueue(ctx context.Context, letter *domain.DeadLetter) (string, error) {
+	if r.runner != nil {
+		err := r.runner.RunTask(letter.Queue, letter.TaskID)
+		if err == nil {
+			return letter.TaskID, nil
+		}
+		if !errors.Is(err, asynq.ErrTaskNotFound) && !errors.Is(err, asynq.ErrQueueNotFound) {
+			return "", fmt.Errorf("run archived task: %w", err)
+		}
+	}
+
+	if r.manager == nil {
+		return "", fmt.Errorf("requeue dead letter %d: no queue client", letter.ID)
+	}
+
+	info, err := r.manager.Enqueue(ctx,
+		asynq.NewTask(letter.TaskType, letter.Payload),
+		asynq.Queue(letter.Queue),
+		asynq.MaxRetry(letter.MaxRetry),
+	)
+	if err != nil {
+		return "", fmt.Errorf("enqueue dead letter: %w", err)
+	}
+
+	return info.ID, nil
+}
+
+// errorChain lists err and every error it wraps, depth first, skipping
+// wrappers whose message matches the error they wrap.
+func errorChain(err error) []string {
+	var chain []string
+
+	var walk func(error)
+	walk = func(err error) {
+		if err == nil {
+			return
+		}
+		if message := err.Error(); len(chain) == 0 || chain[len(chain)-1] != message {
+			chain = append(chain, message)
+		}
+
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, inner := range wrapped.Unwrap() {
+				walk(inner)
+			}
+		case interface{ Unwrap() error }:
+			walk(wrapped.Unwrap())
+		}
+	}
+	walk(err)
+
+	return chain
+}
+
+func deadLetterMessage(letter *domain.DeadLetter) string {
+	message := letter.Error
+	if runes := []rune(message); len(runes) > maxNotifiedErrorLen {
+		message = string(runes[:maxNotifiedErrorLen]) + "…"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚠️ Job %s failed after %d retries\n", letter.TaskType, letter.Retried)
+	fmt.Fprintf(&b, "Task: %s (queue %s)\n", letter.TaskID, letter.Queue)
+	fmt.Fprintf(&b, "Error: %s", message)
+	if letter.ID != 0 {
+		fmt.Fprintf(&b, "\nRequeue: /requeue %d", letter.ID)
+	}
+
+	return b.String()
+}
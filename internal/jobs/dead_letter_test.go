@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+)
+
+type memoryDeadLetters struct {
+	letters map[int64]*domain.DeadLetter
+}
+
+func (m *memoryDeadLetters) Record(_ context.Context, letter *domain.DeadLetter) error {
+	letter.ID = int64(len(m.letters) + 1)
+	m.letters[letter.ID] = letter
+	return nil
+}
+
+func (m *memoryDeadLetters) Get(_ context.Context, id int64) (*domain.DeadLetter, error) {
+	return m.letters[id], nil
+}
+
+func (m *memoryDeadLetters) MarkRequeued(_ context.Context, id int64, taskID string, at time.Time) error {
+	m.letters[id].RequeuedAt = &at
+	m.letters[id].RequeuedTaskID = taskID
+	return nil
+}
+
+type recordingReporter struct{ errs []error }
+
+func (r *recordingReporter) Handle(_ context.Context, err error) (string, bool) {
+	r.errs = append(r.errs, err)
+	return "", false
+}
+
+type recordingNotifier struct{ messages []string }
+
+func (n *recordingNotifier) NotifyAdmins(_ context.Context, text string) error {
+	n.messages = append(n.messages, text)
+	return nil
+}
+
+type stubRunner struct{ err error }
+
+func (s stubRunner) RunTask(string, string) error { return s.err }
+
+type stubManager struct{ enqueued []*asynq.Task }
+
+func (m *stubManager) Enqueue(_ context.Context, task *asynq.Task, _ ...asynq.Option) (*asynq.TaskInfo, error) {
+	m.enqueued = append(m.enqueued, task)
+	return &asynq.TaskInfo{ID: "fresh"}, nil
+}
+
+func (m *stubManager) Close() error { return nil }
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestDeadLetterHandlerRecordsTerminalFailures(t *testing.T) {
+	store := &memoryDeadLetters{letters: map[int64]*domain.DeadLetter{}}
+	reporter := &recordingReporter{}
+	notifier := &recordingNotifier{}
+	h := NewDeadLetterHandler(discardLogger(), store, reporter, notifier)
+
+	task := asynq.NewTask(TaskTypePriceUpdate, []byte(`{"token_addresses":["ALL"]}`))
+
+	h.HandleError(context.Background(), task, errors.New("temporary"))
+	if len(store.letters) != 0 || len(notifier.messages) != 0 {
+		t.Fatal("expected retryable failures to be ignored")
+	}
+
+	cause := errors.New("connection refused")
+	h.HandleError(context.Background(), task, fmt.Errorf("fetch prices: %w: %w", cause, asynq.SkipRetry))
+
+	letter := store.letters[1]
+	if letter == nil {
+		t.Fatal("expected a dead letter to be recorded")
+	}
+	if letter.TaskType != TaskTypePriceUpdate || string(letter.Payload) != `{"token_addresses":["ALL"]}` {
+		t.Fatalf("unexpected dead letter: %+v", letter)
+	}
+	if len(letter.ErrorChain) != 3 || letter.ErrorChain[1] != "connection refused" {
+		t.Fatalf("unexpected error chain: %q", letter.ErrorChain)
+	}
+
+	var appErr *apperrors.AppError
+	if len(reporter.errs) != 1 || !errors.As(reporter.errs[0], &appErr) || appErr.Severity != apperrors.SeverityHigh {
+		t.Fatalf("expected one high severity report, got %v", reporter.errs)
+	}
+	if len(notifier.messages) != 1 || !strings.Contains(notifier.messages[0], "/requeue 1") {
+		t.Fatalf("unexpected notifications: %q", notifier.messages)
+	}
+}
+
+func TestDeadLetterRequeuer(t *testing.T) {
+	newStore := func() *memoryDeadLetters {
+		return &memoryDeadLetters{letters: map[int64]*domain.DeadLetter{
+			1: {ID: 1, TaskID: "archived", TaskType: TaskTypeCleanupData, Queue: QueueLow, Payload: []byte(`{}`), MaxRetry: 1},
+		}}
+	}
+
+	t.Run("runs archived task", func(t *testing.T) {
+		store, manager := newStore(), &stubManager{}
+		r := NewDeadLetterRequeuer(discardLogger(), store, stubRunner{}, manager)
+
+		taskID, err := r.Requeue(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if taskID != "archived" || len(manager.enqueued) != 0 {
+			t.Fatalf("expected archived task to run in place, got %q and %d enqueued", taskID, len(manager.enqueued))
+		}
+
+		if _, err := r.Requeue(context.Background(), 1); !errors.Is(err, ErrAlreadyRequeued) {
+			t.Fatalf("expected ErrAlreadyRequeued, got %v", err)
+		}
+	})
+
+	t.Run("enqueues copy when archive is gone", func(t *testing.T) {
+		store, manager := newStore(), &stubManager{}
+		r := NewDeadLetterRequeuer(discardLogger(), store, stubRunner{err: asynq.ErrTaskNotFound}, manager)
+
+		taskID, err := r.Requeue(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if taskID != "fresh" || len(manager.enqueued) != 1 || manager.enqueued[0].Type() != TaskTypeCleanupData {
+			t.Fatalf("expected a fresh copy, got %q and %v", taskID, manager.enqueued)
+		}
+		if store.letters[1].RequeuedTaskID != "fresh" {
+			t.Fatalf("requeued task ID = %q, want fresh", store.letters[1].RequeuedTaskID)
+		}
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		r := NewDeadLetterRequeuer(discardLogger(), newStore(), stubRunner{}, &stubManager{})
+		if _, err := r.Requeue(context.Background(), 42); !errors.Is(err, ErrDeadLetterNotFound) {
+			t.Fatalf("expected ErrDeadLetterNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+// defaultRollupLookback is used when a rollup task does not specify a window.
+const defaultRollupLookback = 15 * time.Minute
+
+// CandleRoller recomputes the candles of one interval from finer data.
+type CandleRoller interface {
+	RollupCandles(ctx context.Context, interval domain.CandleInterval, from, to time.Time) (int64, error)
+}
+
+type CandleRollupHandler struct {
+	log    *slog.Logger
+	roller CandleRoller
+	now    func() time.Time
+}
+
+// NewCandleRollupHandler constructs a handler that rolls price ticks up into candles.
+func NewCandleRollupHandler(log *slog.Logger, roller CandleRoller) *CandleRollupHandler {
+	return &CandleRollupHandler{
+		log:    log,
+		roller: roller,
+		now:    time.Now,
+	}
+}
+
+// ProcessTask rolls up every interval from finest to coarsest, so each level
+// reads candles that were refreshed earlier in the same run.
+func (h *CandleRollupHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload jobs.CandleRollupPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		if h.log != nil {
+			h.log.ErrorContext(ctx, "candle rollup: failed to decode payload", slog.Any("task_type", t.Type()), slog.String("error", err.Error()))
+		}
+		return err
+	}
+
+	if h.roller == nil {
+		return fmt.Errorf("candle rollup: roller is required")
+	}
+
+	lookback := payload.Lookback
+	if lookback <= 0 {
+		lookback = defaultRollupLookback
+	}
+
+	now := h.now().UTC()
+	from := now.Add(-lookback)
+
+	for _, interval := range domain.CandleIntervals {
+		written, err := h.roller.RollupCandles(ctx, interval, from, now)
+		if err != nil {
+			return fmt.Errorf("rollup %s candles: %w", interval, err)
+		}
+
+		if h.log != nil {
+			h.log.DebugContext(ctx, "candles rolled up",
+				slog.String("interval", string(interval)),
+				slog.Time("from", interval.Truncate(from)),
+				slog.Int64("written", written),
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+type rollupCall struct {
+	interval domain.CandleInterval
+	from, to time.Time
+}
+
+type stubRoller struct {
+	calls []rollupCall
+}
+
+func (s *stubRoller) RollupCandles(_ context.Context, interval domain.CandleInterval, from, to time.Time) (int64, error) {
+	s.calls = append(s.calls, rollupCall{interval: interval, from: from, to: to})
+	return 1, nil
+}
+
+func TestCandleRollupHandlerRollsFinestFirst(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 7, 30, 0, time.UTC)
+	roller := &stubRoller{}
+	handler := NewCandleRollupHandler(nil, roller)
+	handler.now = func() time.Time { return now }
+
+	task, err := jobs.NewCandleRollupTask(10 * time.Minute)
+	if err != nil {
+		t.Fatalf("NewCandleRollupTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+
+	want := []domain.CandleInterval{domain.CandleInterval1m, domain.CandleInterval1h, domain.CandleInterval1d}
+	if len(roller.calls) != len(want) {
+		t.Fatalf("expected %d rollups, got %d", len(want), len(roller.calls))
+	}
+	for i, call := range roller.calls {
+		if call.interval != want[i] {
+			t.Errorf("rollup %d interval = %s, want %s", i, call.interval, want[i])
+		}
+		if !call.from.Equal(now.Add(-10*time.Minute)) || !call.to.Equal(now) {
+			t.Errorf("rollup %d window = [%s, %s)", i, call.from, call.to)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/jobs"
+	"github.com/Proton-105/himera-bot/pkg/metrics"
+)
+
+// defaultCleanupBatchSize is used when the configured batch size is zero.
+const defaultCleanupBatchSize = 1000
+
+// Purger removes records older than cutoff in batches of at most batchSize
+// and returns how many were removed.
+type Purger interface {
+	Purge(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+}
+
+type retentionPolicy struct {
+	name      string
+	retention time.Duration
+	purger    Purger
+}
+
+type CleanupHandler struct {
+	log       *slog.Logger
+	policies  []retentionPolicy
+	batchSize int
+	now       func() time.Time
+}
+
+// NewCleanupHandler constructs a handler that applies retention policies.
+// Every policy name must have a purger in targets.
+func NewCleanupHandler(log *slog.Logger, targets map[string]Purger, retention map[string]time.Duration, batchSize int) (*CleanupHandler, error) {
+	if batchSize <= 0 {
+		batchSize = defaultCleanupBatchSize
+	}
+
+	policies := make([]retentionPolicy, 0, len(retention))
+	for name, keep := range retention {
+		purger, ok := targets[name]
+		if !ok || purger == nil {
+			return nil, fmt.Errorf("cleanup: no purger for retention policy %q", name)
+		}
+		if keep <= 0 {
+			return nil, fmt.Errorf("cleanup: retention for %q must be positive, got %s", name, keep)
+		}
+		policies = append(policies, retentionPolicy{name: name, retention: keep, purger: purger})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].name < policies[j].name })
+
+	return &CleanupHandler{
+		log:       log,
+		policies:  policies,
+		batchSize: batchSize,
+		now:       time.Now,
+	}, nil
+}
+
+// ProcessTask purges every policy. A failing policy does not stop the others;
+// the task fails afterwards so that asynq retries it.
+func (h *CleanupHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload jobs.CleanupDataPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		if h.log != nil {
+			h.log.ErrorContext(ctx, "cleanup: failed to decode payload", slog.Any("task_type", t.Type()), slog.String("error", err.Error()))
+		}
+		return err
+	}
+
+	now := h.now()
+
+	var errs []error
+	for _, policy := range h.policies {
+		retention := policy.retention
+		if payload.OlderThan > 0 {
+			retention = payload.OlderThan
+		}
+
+		deleted, err := policy.purger.Purge(ctx, now.Add(-retention), h.batchSize)
+		metrics.AddCleanupRowsDeleted(policy.name, deleted)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cleanup %s: %w", policy.name, err))
+		}
+
+		if h.log != nil {
+			h.log.InfoContext(ctx, "cleanup: policy applied",
+				slog.String("table", policy.name),
+				slog.Duration("retention", retention),
+				slog.Int64("deleted", deleted),
+				slog.Bool("failed", err != nil),
+			)
+		}
+	}
+
+	return errors.Join(errs...)
+}
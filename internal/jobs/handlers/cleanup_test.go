@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+type stubPurger struct {
+	cutoff    time.Time
+	batchSize int
+	err       error
+}
+
+func (s *stubPurger) Purge(_ context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	s.cutoff, s.batchSize = cutoff, batchSize
+	return 3, s.err
+}
+
+func TestCleanupHandlerAppliesRetention(t *testing.T) {
+	now := time.Date(2025, 3, 1, 3, 0, 0, 0, time.UTC)
+	ticks := &stubPurger{err: errors.New("db down")}
+	keys := &stubPurger{}
+
+	handler, err := NewCleanupHandler(nil,
+		map[string]Purger{"price_ticks": ticks, "idempotency": keys},
+		map[string]time.Duration{"price_ticks": 720 * time.Hour, "idempotency": 25 * time.Hour},
+		500,
+	)
+	if err != nil {
+		t.Fatalf("NewCleanupHandler: %v", err)
+	}
+	handler.now = func() time.Time { return now }
+
+	task, err := jobs.NewCleanupDataTask(0)
+	if err != nil {
+		t.Fatalf("NewCleanupDataTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err == nil {
+		t.Fatal("expected the failing policy to fail the task")
+	}
+
+	if !ticks.cutoff.Equal(now.Add(-720*time.Hour)) || ticks.batchSize != 500 {
+		t.Fatalf("unexpected price_ticks purge: cutoff %s, batch %d", ticks.cutoff, ticks.batchSize)
+	}
+	if !keys.cutoff.Equal(now.Add(-25 * time.Hour)) {
+		t.Fatalf("a failing policy must not stop the others, idempotency cutoff %s", keys.cutoff)
+	}
+}
+
+func TestCleanupHandlerOlderThanOverride(t *testing.T) {
+	now := time.Date(2025, 3, 1, 3, 0, 0, 0, time.UTC)
+	ticks := &stubPurger{}
+
+	handler, err := NewCleanupHandler(nil, map[string]Purger{"price_ticks": ticks}, map[string]time.Duration{"price_ticks": 720 * time.Hour}, 0)
+	if err != nil {
+		t.Fatalf("NewCleanupHandler: %v", err)
+	}
+	handler.now = func() time.Time { return now }
+
+	task, err := jobs.NewCleanupDataTask(48 * time.Hour)
+	if err != nil {
+		t.Fatalf("NewCleanupDataTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+	if !ticks.cutoff.Equal(now.Add(-48*time.Hour)) || ticks.batchSize != defaultCleanupBatchSize {
+		t.Fatalf("unexpected purge: cutoff %s, batch %d", ticks.cutoff, ticks.batchSize)
+	}
+}
+
+func TestNewCleanupHandlerRejectsUnknownPolicy(t *testing.T) {
+	_, err := NewCleanupHandler(nil, map[string]Purger{}, map[string]time.Duration{"sessions": time.Hour}, 100)
+	if err == nil {
+		t.Fatal("expected an error for a policy without a purger")
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+// DCARunner executes one scheduled run of a DCA plan. Running the same run
+// twice must not buy twice.
+type DCARunner interface {
+	RunPlan(ctx context.Context, planID int64, scheduledFor time.Time) error
+}
+
+type DCABuyHandler struct {
+	log    *slog.Logger
+	runner DCARunner
+}
+
+// NewDCABuyHandler constructs a handler that executes DCA plan runs.
+func NewDCABuyHandler(log *slog.Logger, runner DCARunner) *DCABuyHandler {
+	return &DCABuyHandler{
+		log:    log,
+		runner: runner,
+	}
+}
+
+// ProcessTask runs the plan occurrence named in the payload. Runner errors
+// fail the task so that asynq retries it.
+func (h *DCABuyHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload jobs.DCABuyPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		if h.log != nil {
+			h.log.ErrorContext(ctx, "dca buy: failed to decode payload", slog.Any("task_type", t.Type()), slog.String("error", err.Error()))
+		}
+		return err
+	}
+
+	if h.runner == nil {
+		return fmt.Errorf("dca buy: runner is required")
+	}
+	if payload.PlanID <= 0 || payload.ScheduledFor.IsZero() {
+		return fmt.Errorf("dca buy: plan_id and scheduled_for are required: %w", asynq.SkipRetry)
+	}
+
+	if err := h.runner.RunPlan(ctx, payload.PlanID, payload.ScheduledFor); err != nil {
+		return fmt.Errorf("dca buy: plan %d: %w", payload.PlanID, err)
+	}
+
+	return nil
+}
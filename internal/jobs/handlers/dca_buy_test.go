@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+type stubDCARunner struct {
+	planID       int64
+	scheduledFor time.Time
+	err          error
+}
+
+func (s *stubDCARunner) RunPlan(_ context.Context, planID int64, scheduledFor time.Time) error {
+	s.planID, s.scheduledFor = planID, scheduledFor
+	return s.err
+}
+
+func TestDCABuyHandlerRunsPlan(t *testing.T) {
+	scheduledFor := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	runner := &stubDCARunner{}
+	handler := NewDCABuyHandler(nil, runner)
+
+	task, err := jobs.NewDCABuyTask(42, scheduledFor)
+	if err != nil {
+		t.Fatalf("NewDCABuyTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+	if runner.planID != 42 || !runner.scheduledFor.Equal(scheduledFor) {
+		t.Errorf("runner got plan %d at %s", runner.planID, runner.scheduledFor)
+	}
+
+	runner.err = errors.New("price unavailable")
+	if err := handler.ProcessTask(context.Background(), task); !errors.Is(err, runner.err) {
+		t.Errorf("ProcessTask error = %v, want the runner error", err)
+	}
+}
+
+func TestDCABuyHandlerSkipsRetryWithoutPlan(t *testing.T) {
+	handler := NewDCABuyHandler(nil, &stubDCARunner{})
+
+	err := handler.ProcessTask(context.Background(), asynq.NewTask(jobs.TaskTypeDCABuy, []byte(`{}`)))
+	if !errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("ProcessTask error = %v, want SkipRetry", err)
+	}
+}
+
+func TestDCABuyTaskIDIsStable(t *testing.T) {
+	scheduledFor := time.Date(2025, 3, 10, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	if jobs.DCABuyTaskID(42, scheduledFor) != jobs.DCABuyTaskID(42, scheduledFor.UTC()) {
+		t.Error("task ID depends on the time zone of scheduledFor")
+	}
+	if jobs.DCABuyTaskID(42, scheduledFor) == jobs.DCABuyTaskID(42, scheduledFor.Add(7*24*time.Hour)) {
+		t.Error("different runs share a task ID")
+	}
+}
@@ -3,21 +3,75 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/hibiken/asynq"
 
+	"github.com/Proton-105/himera-bot/internal/domain"
 	"github.com/Proton-105/himera-bot/internal/jobs"
 )
 
+// PriceFetcher loads the latest USD prices for a batch of tokens.
+type PriceFetcher interface {
+	Prices(ctx context.Context, tokenAddresses []string) (map[string]*domain.PriceQuote, error)
+}
+
+// PriceWriter stores fetched quotes for readers such as the portfolio.
+type PriceWriter interface {
+	Set(ctx context.Context, quote *domain.PriceQuote, ttl time.Duration) error
+}
+
+// TickRecorder appends fetched quotes to the price history.
+type TickRecorder interface {
+	InsertTicks(ctx context.Context, quotes []*domain.PriceQuote) error
+}
+
+// TokenLister returns the tokens that currently need prices.
+type TokenLister interface {
+	ListTokenAddresses(ctx context.Context) ([]string, error)
+}
+
+// TokenListers combines several listers, such as held and watched tokens.
+// The handler drops addresses listed more than once.
+type TokenListers []TokenLister
+
+// ListTokenAddresses concatenates the addresses of every lister.
+func (l TokenListers) ListTokenAddresses(ctx context.Context) ([]string, error) {
+	var addresses []string
+	for _, lister := range l {
+		listed, err := lister.ListTokenAddresses(ctx)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, listed...)
+	}
+
+	return addresses, nil
+}
+
 type PriceUpdateHandler struct {
-	log *slog.Logger
-	// attach other dependencies here (API clients, services, etc.) when needed
+	log     *slog.Logger
+	fetcher PriceFetcher
+	writer  PriceWriter
+	ticks   TickRecorder
+	tokens  TokenLister
+	ttl     time.Duration
 }
 
-func NewPriceUpdateHandler(log *slog.Logger) *PriceUpdateHandler {
-	return &PriceUpdateHandler{log: log}
+// NewPriceUpdateHandler constructs a handler that fetches prices and writes them with the given TTL.
+// When ticks is not nil every fetched quote is also recorded in the price history.
+func NewPriceUpdateHandler(log *slog.Logger, fetcher PriceFetcher, writer PriceWriter, ticks TickRecorder, tokens TokenLister, ttl time.Duration) *PriceUpdateHandler {
+	return &PriceUpdateHandler{
+		log:     log,
+		fetcher: fetcher,
+		writer:  writer,
+		ticks:   ticks,
+		tokens:  tokens,
+		ttl:     ttl,
+	}
 }
 
 func (h *PriceUpdateHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
@@ -29,24 +83,96 @@ func (h *PriceUpdateHandler) ProcessTask(ctx context.Context, t *asynq.Task) err
 		return err
 	}
 
+	addresses, err := h.resolveAddresses(ctx, payload.TokenAddresses)
+	if err != nil {
+		return err
+	}
+
 	if h.log != nil {
-		traceID, _ := ctx.Value("trace_id").(string)
-		attrs := []slog.Attr{
-			slog.String("task_type", t.Type()),
+		h.log.InfoContext(ctx, "updating prices",
 			slog.Any("addresses", payload.TokenAddresses),
-			slog.Int("addresses_len", len(payload.TokenAddresses)),
+			slog.Int("addresses_len", len(addresses)),
+		)
+	}
+
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	if h.fetcher == nil || h.writer == nil {
+		return fmt.Errorf("price update: fetcher and writer are required")
+	}
+
+	quotes, err := h.fetcher.Prices(ctx, addresses)
+	if err != nil {
+		return fmt.Errorf("fetch prices: %w", err)
+	}
+
+	fetched := make([]*domain.PriceQuote, 0, len(quotes))
+	for _, address := range addresses {
+		quote, ok := quotes[address]
+		if !ok {
+			continue
 		}
-		if traceID != "" {
-			attrs = append(attrs, slog.String("trace_id", traceID))
+
+		if err := h.writer.Set(ctx, quote, h.ttl); err != nil {
+			return fmt.Errorf("store price for %s: %w", address, err)
 		}
-		args := make([]any, 0, len(attrs))
-		for _, attr := range attrs {
-			args = append(args, attr)
+		fetched = append(fetched, quote)
+	}
+
+	if h.ticks != nil {
+		if err := h.ticks.InsertTicks(ctx, fetched); err != nil {
+			return fmt.Errorf("record price ticks: %w", err)
 		}
-		h.log.InfoContext(ctx, "updating prices", args...)
 	}
 
-	time.Sleep(1 * time.Second)
+	if h.log != nil {
+		h.log.InfoContext(ctx, "prices updated",
+			slog.Int("requested", len(addresses)),
+			slog.Int("updated", len(fetched)),
+			slog.Int("missing", len(addresses)-len(fetched)),
+		)
+	}
 
 	return nil
 }
+
+// resolveAddresses expands jobs.AllTokens and drops blanks and duplicates while keeping order.
+func (h *PriceUpdateHandler) resolveAddresses(ctx context.Context, requested []string) ([]string, error) {
+	addresses := make([]string, 0, len(requested))
+	seen := make(map[string]struct{}, len(requested))
+
+	add := func(address string) {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			return
+		}
+		if _, ok := seen[address]; ok {
+			return
+		}
+		seen[address] = struct{}{}
+		addresses = append(addresses, address)
+	}
+
+	for _, address := range requested {
+		if !strings.EqualFold(strings.TrimSpace(address), jobs.AllTokens) {
+			add(address)
+			continue
+		}
+
+		if h.tokens == nil {
+			return nil, fmt.Errorf("price update: %q requested but no token lister is configured", jobs.AllTokens)
+		}
+
+		listed, err := h.tokens.ListTokenAddresses(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list tokens: %w", err)
+		}
+		for _, token := range listed {
+			add(token)
+		}
+	}
+
+	return addresses, nil
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/jobs"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type stubFetcher struct {
+	requested []string
+	err       error
+}
+
+func (s *stubFetcher) Prices(_ context.Context, addresses []string) (map[string]*domain.PriceQuote, error) {
+	s.requested = addresses
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	quotes := make(map[string]*domain.PriceQuote)
+	for _, address := range addresses {
+		if address == "unpriced" {
+			continue
+		}
+		quotes[address] = &domain.PriceQuote{TokenAddress: address, PriceUSD: money.NewFromInt(1)}
+	}
+	return quotes, nil
+}
+
+type stubWriter struct {
+	written map[string]time.Duration
+}
+
+func (s *stubWriter) Set(_ context.Context, quote *domain.PriceQuote, ttl time.Duration) error {
+	if s.written == nil {
+		s.written = make(map[string]time.Duration)
+	}
+	s.written[quote.TokenAddress] = ttl
+	return nil
+}
+
+type stubTicks struct {
+	recorded []*domain.PriceQuote
+}
+
+func (s *stubTicks) InsertTicks(_ context.Context, quotes []*domain.PriceQuote) error {
+	s.recorded = append(s.recorded, quotes...)
+	return nil
+}
+
+type stubTokens []string
+
+func (s stubTokens) ListTokenAddresses(context.Context) ([]string, error) {
+	return s, nil
+}
+
+func TestPriceUpdateHandlerExpandsAll(t *testing.T) {
+	fetcher := &stubFetcher{}
+	writer := &stubWriter{}
+	ticks := &stubTicks{}
+	handler := NewPriceUpdateHandler(nil, fetcher, writer, ticks, stubTokens{"tokA", "tokB", "unpriced"}, time.Hour)
+
+	task, err := jobs.NewPriceUpdateTask([]string{jobs.AllTokens, "tokA", "tokC"})
+	if err != nil {
+		t.Fatalf("NewPriceUpdateTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+
+	if got := len(fetcher.requested); got != 4 {
+		t.Fatalf("expected 4 distinct addresses, got %v", fetcher.requested)
+	}
+	if len(writer.written) != 3 || writer.written["tokA"] != time.Hour {
+		t.Fatalf("unexpected writes: %v", writer.written)
+	}
+	if _, ok := writer.written["unpriced"]; ok {
+		t.Fatal("unpriced token must not be written")
+	}
+	if len(ticks.recorded) != 3 {
+		t.Fatalf("expected a tick per written price, got %d", len(ticks.recorded))
+	}
+}
+
+func TestPriceUpdateHandlerCombinesListers(t *testing.T) {
+	fetcher := &stubFetcher{}
+	listers := TokenListers{stubTokens{"tokA", "tokB"}, stubTokens{"tokB", "watched"}}
+	handler := NewPriceUpdateHandler(nil, fetcher, &stubWriter{}, nil, listers, time.Hour)
+
+	task, err := jobs.NewPriceUpdateTask([]string{jobs.AllTokens})
+	if err != nil {
+		t.Fatalf("NewPriceUpdateTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+
+	if got := fetcher.requested; len(got) != 3 || got[2] != "watched" {
+		t.Fatalf("expected tokA, tokB and watched once each, got %v", got)
+	}
+}
+
+func TestPriceUpdateHandlerFetchError(t *testing.T) {
+	handler := NewPriceUpdateHandler(nil, &stubFetcher{err: errors.New("api down")}, &stubWriter{}, nil, nil, time.Hour)
+
+	task, err := jobs.NewPriceUpdateTask([]string{"tokA"})
+	if err != nil {
+		t.Fatalf("NewPriceUpdateTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err == nil {
+		t.Fatal("expected an error so the task is retried")
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/jobs"
+	"github.com/Proton-105/himera-bot/internal/market"
+)
+
+// TokenStore saves tokens to the token registry.
+type TokenStore interface {
+	Upsert(ctx context.Context, tokens []*domain.TokenInfo) error
+}
+
+type TokenSyncHandler struct {
+	log       *slog.Logger
+	directory market.TokenDirectory
+	store     TokenStore
+	tokens    TokenLister
+}
+
+// NewTokenSyncHandler constructs a handler that refreshes the registry entries
+// of the tokens listed by tokens, typically held, watched and registered ones.
+func NewTokenSyncHandler(log *slog.Logger, directory market.TokenDirectory, store TokenStore, tokens TokenLister) *TokenSyncHandler {
+	return &TokenSyncHandler{
+		log:       log,
+		directory: directory,
+		store:     store,
+		tokens:    tokens,
+	}
+}
+
+// ProcessTask describes every listed token and registers the results of the
+// payload's queries. A failed query is skipped; a failed refresh of the
+// listed tokens fails the task so that it is retried.
+func (h *TokenSyncHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload jobs.TokenSyncPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		if h.log != nil {
+			h.log.ErrorContext(ctx, "token sync: failed to decode payload", slog.Any("task_type", t.Type()), slog.String("error", err.Error()))
+		}
+		return err
+	}
+
+	if h.directory == nil || h.store == nil {
+		return fmt.Errorf("token sync: directory and store are required")
+	}
+
+	var addresses []string
+	if h.tokens != nil {
+		listed, err := h.tokens.ListTokenAddresses(ctx)
+		if err != nil {
+			return fmt.Errorf("list tokens: %w", err)
+		}
+		addresses = uniqueAddresses(listed)
+	}
+
+	tokens := make([]*domain.TokenInfo, 0, len(addresses))
+	if len(addresses) > 0 {
+		described, err := h.directory.DescribeTokens(ctx, addresses)
+		if err != nil {
+			return fmt.Errorf("describe tokens: %w", err)
+		}
+		for _, address := range addresses {
+			if token, ok := described[address]; ok {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	refreshed := len(tokens)
+
+	for _, query := range payload.Queries {
+		query = strings.TrimSpace(query)
+		if query == "" {
+			continue
+		}
+
+		found, err := h.directory.SearchTokens(ctx, query)
+		if err != nil {
+			if h.log != nil {
+				h.log.WarnContext(ctx, "token sync: search failed", slog.String("query", query), slog.Any("error", err))
+			}
+			continue
+		}
+		tokens = append(tokens, found...)
+	}
+
+	if err := h.store.Upsert(ctx, tokens); err != nil {
+		return fmt.Errorf("store tokens: %w", err)
+	}
+
+	if h.log != nil {
+		h.log.InfoContext(ctx, "tokens synced",
+			slog.Int("listed", len(addresses)),
+			slog.Int("refreshed", refreshed),
+			slog.Int("discovered", len(tokens)-refreshed),
+		)
+	}
+
+	return nil
+}
+
+// uniqueAddresses drops blanks and duplicates while keeping order.
+func uniqueAddresses(addresses []string) []string {
+	unique := make([]string, 0, len(addresses))
+	seen := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		unique = append(unique, address)
+	}
+	return unique
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+type stubDirectory struct {
+	described []string
+	searchErr error
+}
+
+func (s *stubDirectory) DescribeTokens(_ context.Context, addresses []string) (map[string]*domain.TokenInfo, error) {
+	s.described = addresses
+	tokens := make(map[string]*domain.TokenInfo)
+	for _, address := range addresses {
+		if address == "delisted" {
+			continue
+		}
+		tokens[address] = &domain.TokenInfo{Token: domain.Token{Address: address, Symbol: address}}
+	}
+	return tokens, nil
+}
+
+func (s *stubDirectory) SearchTokens(_ context.Context, query string) ([]*domain.TokenInfo, error) {
+	if s.searchErr != nil {
+		return nil, s.searchErr
+	}
+	return []*domain.TokenInfo{{Token: domain.Token{Address: query + "-address", Symbol: query}}}, nil
+}
+
+type stubTokenStore struct {
+	stored []*domain.TokenInfo
+}
+
+func (s *stubTokenStore) Upsert(_ context.Context, tokens []*domain.TokenInfo) error {
+	s.stored = append(s.stored, tokens...)
+	return nil
+}
+
+func TestTokenSyncHandlerRefreshesAndDiscovers(t *testing.T) {
+	directory := &stubDirectory{}
+	store := &stubTokenStore{}
+	listers := TokenListers{stubTokens{"tokA", "delisted"}, stubTokens{"tokA", "tokB"}}
+	handler := NewTokenSyncHandler(nil, directory, store, listers)
+
+	task, err := jobs.NewTokenSyncTask([]string{"bonk", " "})
+	if err != nil {
+		t.Fatalf("NewTokenSyncTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+
+	if len(directory.described) != 3 {
+		t.Fatalf("expected tokA, delisted and tokB once each, got %v", directory.described)
+	}
+	var addresses []string
+	for _, token := range store.stored {
+		addresses = append(addresses, token.Address)
+	}
+	if len(addresses) != 3 || addresses[0] != "tokA" || addresses[1] != "tokB" || addresses[2] != "bonk-address" {
+		t.Fatalf("unexpected stored tokens: %v", addresses)
+	}
+}
+
+func TestTokenSyncHandlerSkipsFailedQueries(t *testing.T) {
+	store := &stubTokenStore{}
+	handler := NewTokenSyncHandler(nil, &stubDirectory{searchErr: errors.New("api down")}, store, stubTokens{"tokA"})
+
+	task, err := jobs.NewTokenSyncTask([]string{"bonk"})
+	if err != nil {
+		t.Fatalf("NewTokenSyncTask: %v", err)
+	}
+
+	if err := handler.ProcessTask(context.Background(), task); err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+	if len(store.stored) != 1 {
+		t.Fatalf("expected the listed token to be stored, got %d tokens", len(store.stored))
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+// TopTokensRefresher recomputes the top tokens screen.
+type TopTokensRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+type TopTokensHandler struct {
+	log       *slog.Logger
+	refresher TopTokensRefresher
+}
+
+// NewTopTokensHandler constructs a handler that recomputes the top tokens screen.
+func NewTopTokensHandler(log *slog.Logger, refresher TopTokensRefresher) *TopTokensHandler {
+	return &TopTokensHandler{
+		log:       log,
+		refresher: refresher,
+	}
+}
+
+// ProcessTask refreshes the boards. Refresh errors fail the task so that
+// asynq retries it; the previous boards stay cached meanwhile.
+func (h *TopTokensHandler) ProcessTask(ctx context.Context, t *asynq.Task) error {
+	var payload jobs.TopTokensPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		if h.log != nil {
+			h.log.ErrorContext(ctx, "top tokens: failed to decode payload", slog.Any("task_type", t.Type()), slog.String("error", err.Error()))
+		}
+		return err
+	}
+
+	if h.refresher == nil {
+		return fmt.Errorf("top tokens: refresher is required")
+	}
+
+	if err := h.refresher.Refresh(ctx); err != nil {
+		return fmt.Errorf("top tokens: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/jobs"
+)
+
+type stubTopTokens struct {
+	calls int
+	err   error
+}
+
+func (s *stubTopTokens) Refresh(context.Context) error {
+	s.calls++
+	return s.err
+}
+
+func TestTopTokensHandlerRefreshes(t *testing.T) {
+	task, err := jobs.NewTopTokensTask()
+	if err != nil {
+		t.Fatalf("NewTopTokensTask: %v", err)
+	}
+
+	refresher := &stubTopTokens{}
+	if err := NewTopTokensHandler(nil, refresher).ProcessTask(context.Background(), task); err != nil {
+		t.Fatalf("ProcessTask: %v", err)
+	}
+	if refresher.calls != 1 {
+		t.Errorf("refresh calls = %d, want 1", refresher.calls)
+	}
+
+	failing := &stubTopTokens{err: errors.New("redis down")}
+	if err := NewTopTokensHandler(nil, failing).ProcessTask(context.Background(), task); err == nil {
+		t.Fatal("expected an error so the task is retried")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"log/slog"
 
 	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/pkg/logger"
 )
 
 // Manager describes the minimal queue operations needed by the application.
@@ -28,7 +30,21 @@ func NewManager(redisOpt asynq.RedisConnOpt, log *slog.Logger) Manager {
 	}
 }
 
+// Enqueue submits the task. When ctx carries a correlation ID it is written
+// into the JSON payload so that CorrelationMiddleware can restore it in the
+// worker. asynq does not expose the options a task was built with, so a
+// rebuilt task gets its registered default queue; pass asynq.Queue in opts to
+// override it.
 func (m *manager) Enqueue(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	if correlationID := logger.CorrelationIDFromContext(ctx); correlationID != "" {
+		if payload, ok := withCorrelationID(task.Payload(), correlationID); ok {
+			if definition, registered := taskRegistry[task.Type()]; registered {
+				opts = append([]asynq.Option{asynq.Queue(definition.queue)}, opts...)
+			}
+			task = asynq.NewTask(task.Type(), payload)
+		}
+	}
+
 	return m.client.EnqueueContext(ctx, task, opts...)
 }
 
@@ -0,0 +1,509 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
+
+	"github.com/Proton-105/himera-bot/pkg/logger"
+)
+
+// Defaults mirrored from asynq so that both backends treat tasks alike.
+const (
+	defaultMemoryMaxRetry     = 25
+	defaultMemoryTimeout      = 30 * time.Minute
+	defaultMemoryPollInterval = time.Second
+)
+
+// MemoryOptions configures a MemoryBackend. Zero values fall back to asynq's defaults.
+type MemoryOptions struct {
+	// Queues maps queue names to weights, as in JobsQueuesConfig.ToMap.
+	// Tasks in queues without a weight are kept but never processed.
+	Queues map[string]int
+	// Now is the clock used for delays, retries and cron schedules.
+	Now func() time.Time
+	// RetryDelay computes the backoff before a retry.
+	RetryDelay asynq.RetryDelayFunc
+	// PollInterval is how often Worker.Run looks for due tasks.
+	PollInterval time.Duration
+	Log          *slog.Logger
+}
+
+// MemoryBackend runs tasks in process without Redis. It provides Manager,
+// Worker and Scheduler implementations with asynq's semantics for queue
+// weights, delays, retries with backoff, timeouts and cron entries. Unique,
+// Group and Retention options are ignored, and tasks are lost on restart.
+//
+// Tests drive it deterministically with an injected clock and RunDue; in
+// development Worker.Run polls RunDue on a ticker.
+type MemoryBackend struct {
+	now          func() time.Time
+	retryDelay   asynq.RetryDelayFunc
+	pollInterval time.Duration
+	log          *slog.Logger
+	mux          *asynq.ServeMux
+
+	mu           sync.Mutex
+	errorHandler asynq.ErrorHandler
+	queues       []*memoryQueue
+	tasks        map[string]*memoryTask
+	archived     []*memoryTask
+	seq          uint64
+	entries      []*memoryEntry
+	scheduling   bool
+
+	// processing serializes RunDue so tasks run one at a time, in order.
+	processing sync.Mutex
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+type memoryQueue struct {
+	name    string
+	weight  int
+	current int
+}
+
+type memoryTask struct {
+	id        string
+	queue     string
+	task      *asynq.Task
+	seq       uint64
+	processAt time.Time
+	maxRetry  int
+	retried   int
+	timeout   time.Duration
+	deadline  time.Time
+	lastErr   string
+}
+
+type memoryEntry struct {
+	schedule cron.Schedule
+	task     *asynq.Task
+	opts     []asynq.Option
+	next     time.Time
+}
+
+// NewMemoryBackend constructs an in-process job backend.
+func NewMemoryBackend(opts MemoryOptions) *MemoryBackend {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.RetryDelay == nil {
+		opts.RetryDelay = asynq.DefaultRetryDelayFunc
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultMemoryPollInterval
+	}
+	if opts.Log == nil {
+		opts.Log = slog.Default()
+	}
+	if len(opts.Queues) == 0 {
+		opts.Queues = map[string]int{QueueDefault: 1}
+	}
+
+	queues := make([]*memoryQueue, 0, len(opts.Queues))
+	for name, weight := range opts.Queues {
+		if weight > 0 {
+			queues = append(queues, &memoryQueue{name: name, weight: weight})
+		}
+	}
+	// A fixed order keeps weighted selection deterministic.
+	sort.Slice(queues, func(i, j int) bool { return queues[i].name < queues[j].name })
+
+	return &MemoryBackend{
+		now:          opts.Now,
+		retryDelay:   opts.RetryDelay,
+		pollInterval: opts.PollInterval,
+		log:          opts.Log,
+		mux:          asynq.NewServeMux(),
+		queues:       queues,
+		tasks:        make(map[string]*memoryTask),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Manager returns a Manager that enqueues into the backend.
+func (b *MemoryBackend) Manager() Manager {
+	return memoryManager{backend: b}
+}
+
+// Worker returns the Worker that processes the backend's tasks.
+// errorHandler, if not nil, is called whenever a task fails.
+func (b *MemoryBackend) Worker(errorHandler asynq.ErrorHandler) Worker {
+	b.mu.Lock()
+	b.errorHandler = errorHandler
+	b.mu.Unlock()
+
+	return memoryWorker{backend: b}
+}
+
+// Scheduler returns a Scheduler whose entries fire from RunDue while it runs.
+func (b *MemoryBackend) Scheduler() Scheduler {
+	return memoryScheduler{backend: b}
+}
+
+// Len returns the number of tasks waiting to be processed, including scheduled retries.
+func (b *MemoryBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.tasks)
+}
+
+// Archived returns the tasks that failed for the last time, oldest first.
+func (b *MemoryBackend) Archived() []*asynq.TaskInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	infos := make([]*asynq.TaskInfo, 0, len(b.archived))
+	for _, t := range b.archived {
+		infos = append(infos, t.info(asynq.TaskStateArchived))
+	}
+	return infos
+}
+
+// RunDue fires cron entries that are due and then processes every task whose
+// time has come, one at a time, until none is left. Retries scheduled for a
+// later time are left for a later call. It returns the number of tasks processed.
+func (b *MemoryBackend) RunDue(ctx context.Context) int {
+	b.processing.Lock()
+	defer b.processing.Unlock()
+
+	b.fireEntries(ctx)
+
+	processed := 0
+	for ctx.Err() == nil {
+		t := b.nextDue()
+		if t == nil {
+			break
+		}
+		b.process(ctx, t)
+		processed++
+	}
+
+	return processed
+}
+
+func (b *MemoryBackend) enqueue(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	if task == nil {
+		return nil, errors.New("task is nil")
+	}
+
+	if correlationID := logger.CorrelationIDFromContext(ctx); correlationID != "" {
+		if payload, ok := withCorrelationID(task.Payload(), correlationID); ok {
+			task = asynq.NewTask(task.Type(), payload)
+		}
+	}
+	// Task-level options are not readable, so the registered queue stands in for them.
+	if definition, ok := taskRegistry[task.Type()]; ok {
+		opts = append([]asynq.Option{asynq.Queue(definition.queue)}, opts...)
+	}
+
+	now := b.now()
+	t := &memoryTask{
+		id:        uuid.NewString(),
+		queue:     QueueDefault,
+		task:      task,
+		processAt: now,
+		maxRetry:  defaultMemoryMaxRetry,
+	}
+	for _, opt := range opts {
+		switch value := opt.Value().(type) {
+		case int:
+			if opt.Type() == asynq.MaxRetryOpt {
+				t.maxRetry = value
+			}
+		case string:
+			switch opt.Type() {
+			case asynq.QueueOpt:
+				t.queue = value
+			case asynq.TaskIDOpt:
+				t.id = value
+			}
+		case time.Duration:
+			switch opt.Type() {
+			case asynq.TimeoutOpt:
+				t.timeout = value
+			case asynq.ProcessInOpt:
+				t.processAt = now.Add(value)
+			}
+		case time.Time:
+			switch opt.Type() {
+			case asynq.DeadlineOpt:
+				t.deadline = value
+			case asynq.ProcessAtOpt:
+				t.processAt = value
+			}
+		}
+	}
+	if t.maxRetry < 0 {
+		t.maxRetry = 0
+	}
+	if t.timeout == 0 && t.deadline.IsZero() {
+		t.timeout = defaultMemoryTimeout
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.tasks[t.id]; exists {
+		return nil, asynq.ErrTaskIDConflict
+	}
+	b.seq++
+	t.seq = b.seq
+	b.tasks[t.id] = t
+
+	state := asynq.TaskStatePending
+	if t.processAt.After(now) {
+		state = asynq.TaskStateScheduled
+	}
+	return t.info(state), nil
+}
+
+// nextDue removes and returns the next task to process. Queues are chosen by
+// smooth weighted round-robin among those with a due task; within a queue
+// tasks run in order of due time, then enqueue order.
+func (b *MemoryBackend) nextDue() *memoryTask {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	heads := make(map[string]*memoryTask, len(b.queues))
+	for _, t := range b.tasks {
+		if t.processAt.After(now) {
+			continue
+		}
+		head, ok := heads[t.queue]
+		if !ok || t.processAt.Before(head.processAt) || (t.processAt.Equal(head.processAt) && t.seq < head.seq) {
+			heads[t.queue] = t
+		}
+	}
+
+	var (
+		chosen *memoryQueue
+		total  int
+	)
+	for _, q := range b.queues {
+		if heads[q.name] == nil {
+			continue
+		}
+		q.current += q.weight
+		total += q.weight
+		if chosen == nil || q.current > chosen.current {
+			chosen = q
+		}
+	}
+	if chosen == nil {
+		return nil
+	}
+	chosen.current -= total
+
+	t := heads[chosen.name]
+	delete(b.tasks, t.id)
+	return t
+}
+
+func (b *MemoryBackend) process(ctx context.Context, t *memoryTask) {
+	md := TaskMetadata{ID: t.id, Queue: t.queue, RetryCount: t.retried, MaxRetry: t.maxRetry}
+	ctx = withTaskMetadata(ctx, md)
+
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	if !t.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, t.deadline)
+		defer cancel()
+	}
+
+	err := b.handle(ctx, t.task)
+	if err == nil {
+		return
+	}
+
+	b.mu.Lock()
+	errorHandler := b.errorHandler
+	b.mu.Unlock()
+	if errorHandler != nil {
+		errorHandler.HandleError(ctx, t.task, err)
+	}
+
+	t.lastErr = err.Error()
+	if t.retried >= t.maxRetry || errors.Is(err, asynq.SkipRetry) {
+		b.mu.Lock()
+		b.archived = append(b.archived, t)
+		b.mu.Unlock()
+		return
+	}
+
+	delay := b.retryDelay(t.retried, err, t.task)
+	t.retried++
+	t.processAt = b.now().Add(delay)
+
+	b.mu.Lock()
+	b.tasks[t.id] = t
+	b.mu.Unlock()
+}
+
+// handle runs the task through the worker's mux. Panics become errors, as in asynq.
+func (b *MemoryBackend) handle(ctx context.Context, task *asynq.Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic [recovered]: %v", r)
+		}
+	}()
+
+	return b.mux.ProcessTask(ctx, task)
+}
+
+func (b *MemoryBackend) fireEntries(ctx context.Context) {
+	b.mu.Lock()
+	if !b.scheduling {
+		b.mu.Unlock()
+		return
+	}
+
+	now := b.now()
+	var due []*memoryEntry
+	for _, entry := range b.entries {
+		// Like asynq, missed runs are not caught up: a late tick enqueues once.
+		if !entry.next.After(now) {
+			due = append(due, entry)
+			entry.next = entry.schedule.Next(now)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, entry := range due {
+		if _, err := b.enqueue(ctx, entry.task, entry.opts...); err != nil {
+			b.log.ErrorContext(ctx, "memory scheduler: enqueue failed",
+				slog.String("task_type", entry.task.Type()),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+func (t *memoryTask) info(state asynq.TaskState) *asynq.TaskInfo {
+	return &asynq.TaskInfo{
+		ID:            t.id,
+		Queue:         t.queue,
+		Type:          t.task.Type(),
+		Payload:       t.task.Payload(),
+		State:         state,
+		MaxRetry:      t.maxRetry,
+		Retried:       t.retried,
+		LastErr:       t.lastErr,
+		Timeout:       t.timeout,
+		Deadline:      t.deadline,
+		NextProcessAt: t.processAt,
+	}
+}
+
+type memoryManager struct {
+	backend *MemoryBackend
+}
+
+func (m memoryManager) Enqueue(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	return m.backend.enqueue(ctx, task, opts...)
+}
+
+func (m memoryManager) Close() error {
+	return nil
+}
+
+type memoryWorker struct {
+	backend *MemoryBackend
+}
+
+func (w memoryWorker) Use(mws ...Middleware) {
+	for _, mw := range mws {
+		w.backend.mux.Use(asynq.MiddlewareFunc(mw))
+	}
+}
+
+func (w memoryWorker) RegisterHandler(taskType string, handler asynq.Handler) {
+	w.backend.mux.Handle(taskType, handler)
+}
+
+// Run processes due tasks every poll interval until Shutdown is called.
+func (w memoryWorker) Run() error {
+	b := w.backend
+	b.log.InfoContext(context.Background(), "jobs worker: starting in-memory processing loop", slog.Duration("poll_interval", b.pollInterval))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-b.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(b.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		b.RunDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w memoryWorker) Shutdown() {
+	w.backend.log.InfoContext(context.Background(), "jobs worker: shutting down")
+	w.backend.stopOnce.Do(func() { close(w.backend.stop) })
+}
+
+type memoryScheduler struct {
+	backend *MemoryBackend
+}
+
+func (s memoryScheduler) Apply(schedules []Schedule) error {
+	b := s.backend
+	now := b.now()
+
+	entries := make([]*memoryEntry, 0, len(schedules))
+	for _, schedule := range schedules {
+		parsed, err := cron.ParseStandard(schedule.Cron)
+		if err != nil {
+			return fmt.Errorf("register %s (%s): %w", schedule.Task.Type(), schedule.Cron, err)
+		}
+		entries = append(entries, &memoryEntry{
+			schedule: parsed,
+			task:     schedule.Task,
+			opts:     schedule.Opts,
+			next:     parsed.Next(now),
+		})
+	}
+
+	b.mu.Lock()
+	b.entries = entries
+	b.mu.Unlock()
+
+	return nil
+}
+
+func (s memoryScheduler) Run() {
+	s.backend.mu.Lock()
+	s.backend.scheduling = true
+	s.backend.mu.Unlock()
+}
+
+func (s memoryScheduler) Shutdown() {
+	s.backend.mu.Lock()
+	s.backend.scheduling = false
+	s.backend.mu.Unlock()
+}
@@ -0,0 +1,191 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/config"
+	"github.com/Proton-105/himera-bot/pkg/logger"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBackend(clock *fakeClock, queues map[string]int) *MemoryBackend {
+	return NewMemoryBackend(MemoryOptions{
+		Queues:     queues,
+		Now:        clock.Now,
+		RetryDelay: func(n int, _ error, _ *asynq.Task) time.Duration { return time.Duration(n+1) * time.Minute },
+		Log:        discardLogger(),
+	})
+}
+
+func TestMemoryBackendWeightsQueues(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	backend := newTestBackend(clock, map[string]int{QueueCritical: 2, QueueLow: 1})
+
+	var order []string
+	worker := backend.Worker(nil)
+	worker.RegisterHandler("test:job", asynq.HandlerFunc(func(ctx context.Context, _ *asynq.Task) error {
+		md, _ := TaskMetadataFromContext(ctx)
+		order = append(order, md.Queue)
+		return nil
+	}))
+
+	manager := backend.Manager()
+	for i := 0; i < 3; i++ {
+		for _, queue := range []string{QueueLow, QueueCritical} {
+			if _, err := manager.Enqueue(context.Background(), asynq.NewTask("test:job", nil), asynq.Queue(queue)); err != nil {
+				t.Fatalf("enqueue: %v", err)
+			}
+		}
+	}
+
+	if processed := backend.RunDue(context.Background()); processed != 6 {
+		t.Fatalf("processed = %d, want 6", processed)
+	}
+
+	want := []string{QueueCritical, QueueLow, QueueCritical, QueueCritical, QueueLow, QueueLow}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestMemoryBackendDelaysAndRetries(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	backend := newTestBackend(clock, nil)
+
+	store := &memoryDeadLetters{letters: map[int64]*domain.DeadLetter{}}
+	worker := backend.Worker(NewDeadLetterHandler(discardLogger(), store, nil, nil))
+
+	attempts := 0
+	worker.RegisterHandler("test:flaky", asynq.HandlerFunc(func(context.Context, *asynq.Task) error {
+		attempts++
+		return errors.New("upstream unavailable")
+	}))
+
+	info, err := backend.Manager().Enqueue(context.Background(), asynq.NewTask("test:flaky", nil), asynq.ProcessIn(time.Minute), asynq.MaxRetry(2))
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if info.State != asynq.TaskStateScheduled {
+		t.Fatalf("state = %s, want scheduled", info.State)
+	}
+
+	steps := []struct {
+		advance  time.Duration
+		attempts int
+	}{
+		{advance: 0, attempts: 0},
+		{advance: time.Minute, attempts: 1},      // first run
+		{advance: 30 * time.Second, attempts: 1}, // backoff of 1m not elapsed
+		{advance: 30 * time.Second, attempts: 2}, // first retry
+		{advance: 2 * time.Minute, attempts: 3},  // second and last retry
+		{advance: time.Hour, attempts: 3},
+	}
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		backend.RunDue(context.Background())
+		if attempts != step.attempts {
+			t.Fatalf("step %d: attempts = %d, want %d", i, attempts, step.attempts)
+		}
+	}
+
+	if backend.Len() != 0 {
+		t.Fatalf("expected no queued tasks, got %d", backend.Len())
+	}
+	archived := backend.Archived()
+	if len(archived) != 1 || archived[0].Retried != 2 || archived[0].LastErr != "upstream unavailable" {
+		t.Fatalf("unexpected archive: %+v", archived)
+	}
+	if letter := store.letters[1]; letter == nil || letter.TaskID != info.ID || letter.Retried != 2 {
+		t.Fatalf("expected the last failure to be dead-lettered, got %+v", store.letters)
+	}
+	if len(store.letters) != 1 {
+		t.Fatalf("expected only the terminal failure to be recorded, got %d", len(store.letters))
+	}
+}
+
+func TestMemoryBackendRunsCronEntries(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC)}
+	backend := newTestBackend(clock, map[string]int{QueueLow: 1})
+
+	var lookbacks []string
+	backend.Worker(nil).RegisterHandler(TaskTypeCandleRollup, asynq.HandlerFunc(func(_ context.Context, t *asynq.Task) error {
+		lookbacks = append(lookbacks, string(t.Payload()))
+		return nil
+	}))
+
+	schedules, err := ParseSchedules([]config.JobScheduleConfig{
+		{Task: TaskTypeCandleRollup, Cron: "*/5 * * * *", Payload: map[string]any{"lookback": "15m"}},
+	})
+	if err != nil {
+		t.Fatalf("parse schedules: %v", err)
+	}
+
+	scheduler := backend.Scheduler()
+	if err := scheduler.Apply(schedules); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	clock.Advance(5 * time.Minute)
+	if processed := backend.RunDue(context.Background()); processed != 0 {
+		t.Fatalf("expected no runs before the scheduler starts, got %d", processed)
+	}
+
+	scheduler.Run()
+	if processed := backend.RunDue(context.Background()); processed != 1 {
+		t.Fatalf("processed = %d, want 1", processed)
+	}
+	if processed := backend.RunDue(context.Background()); processed != 0 {
+		t.Fatalf("expected the entry to wait for its next slot, got %d runs", processed)
+	}
+
+	clock.Advance(5 * time.Minute)
+	backend.RunDue(context.Background())
+	if len(lookbacks) != 2 || lookbacks[0] != `{"lookback":900000000000}` {
+		t.Fatalf("unexpected runs: %v", lookbacks)
+	}
+}
+
+func TestMemoryBackendPropagatesCorrelationID(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	backend := newTestBackend(clock, map[string]int{QueueDefault: 1})
+
+	worker := backend.Worker(nil)
+	worker.Use(CorrelationMiddleware())
+
+	var got string
+	worker.RegisterHandler(TaskTypePriceUpdate, asynq.HandlerFunc(func(ctx context.Context, _ *asynq.Task) error {
+		got = logger.CorrelationIDFromContext(ctx)
+		return nil
+	}))
+
+	task, err := NewPriceUpdateTask([]string{AllTokens})
+	if err != nil {
+		t.Fatalf("new task: %v", err)
+	}
+	if _, err := backend.Manager().Enqueue(logger.WithCorrelationID(context.Background(), "req-7"), task); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := backend.Manager().Enqueue(context.Background(), task, asynq.TaskID("fixed")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := backend.Manager().Enqueue(context.Background(), task, asynq.TaskID("fixed")); !errors.Is(err, asynq.ErrTaskIDConflict) {
+		t.Fatalf("expected ErrTaskIDConflict, got %v", err)
+	}
+
+	backend.RunDue(context.Background())
+	if got == "" {
+		t.Fatal("expected a correlation ID in the handler context")
+	}
+}
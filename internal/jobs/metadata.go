@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+)
+
+// TaskMetadata describes the task a handler is processing.
+type TaskMetadata struct {
+	ID         string
+	Queue      string
+	RetryCount int
+	MaxRetry   int
+}
+
+type taskMetadataKey struct{}
+
+func withTaskMetadata(ctx context.Context, md TaskMetadata) context.Context {
+	return context.WithValue(ctx, taskMetadataKey{}, md)
+}
+
+// TaskMetadataFromContext returns the metadata of the task being processed.
+// It works with both the asynq worker and the in-memory backend, whose
+// contexts asynq.GetTaskID and friends cannot read.
+func TaskMetadataFromContext(ctx context.Context) (TaskMetadata, bool) {
+	if md, ok := ctx.Value(taskMetadataKey{}).(TaskMetadata); ok {
+		return md, true
+	}
+
+	id, ok := asynq.GetTaskID(ctx)
+	if !ok {
+		return TaskMetadata{}, false
+	}
+
+	md := TaskMetadata{ID: id}
+	md.Queue, _ = asynq.GetQueueName(ctx)
+	md.RetryCount, _ = asynq.GetRetryCount(ctx)
+	md.MaxRetry, _ = asynq.GetMaxRetry(ctx)
+
+	return md, true
+}
@@ -0,0 +1,217 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/pkg/logger"
+	"github.com/Proton-105/himera-bot/pkg/metrics"
+)
+
+// correlationField is the payload field that carries the enqueuer's correlation ID.
+const correlationField = "correlation_id"
+
+// Job outcomes reported in logs and metrics.
+const (
+	OutcomeSuccess = "success"
+	// OutcomeRetry marks a failure that asynq will retry.
+	OutcomeRetry = "retry"
+	// OutcomeFailed marks a failure with no retries left; asynq archives the task.
+	OutcomeFailed = "failed"
+)
+
+// Middleware wraps task handlers with additional behavior.
+type Middleware func(asynq.Handler) asynq.Handler
+
+// RecoveryMiddleware turns handler panics into errors so that the task is retried.
+func RecoveryMiddleware(log *slog.Logger) Middleware {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.ErrorContext(ctx, "panic recovered in job handler",
+						slog.String("task_type", t.Type()),
+						slog.Any("panic", r),
+						slog.String("stack", string(debug.Stack())),
+					)
+					err = fmt.Errorf("job handler panic: %v", r)
+				}
+			}()
+
+			return next.ProcessTask(ctx, t)
+		})
+	}
+}
+
+// CorrelationMiddleware restores the correlation ID written into the payload
+// by Manager.Enqueue, or starts a new one for tasks enqueued without one
+// (such as cron entries).
+func CorrelationMiddleware() Middleware {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			correlationID := correlationIDFromPayload(t.Payload())
+			if correlationID == "" {
+				correlationID = uuid.NewString()
+			}
+
+			return next.ProcessTask(logger.WithCorrelationID(ctx, correlationID), t)
+		})
+	}
+}
+
+// LoggingMiddleware logs the start and outcome of every task with its ID and retry count.
+func LoggingMiddleware(log *slog.Logger) Middleware {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			md, _ := TaskMetadataFromContext(ctx)
+
+			attrs := []any{
+				slog.String("task_type", t.Type()),
+				slog.String("task_id", md.ID),
+				slog.String("queue", md.Queue),
+				slog.Int("retry", md.RetryCount),
+			}
+			if correlationID := logger.CorrelationIDFromContext(ctx); correlationID != "" {
+				attrs = append(attrs, slog.String("correlation_id", correlationID))
+			}
+
+			log.InfoContext(ctx, "processing task", attrs...)
+
+			start := time.Now()
+			err := next.ProcessTask(ctx, t)
+
+			attrs = append(attrs,
+				slog.String("outcome", outcome(ctx, err)),
+				slog.Duration("duration", time.Since(start)),
+			)
+			if err != nil {
+				log.ErrorContext(ctx, "task failed", append(attrs, slog.Any("error", err))...)
+				return err
+			}
+
+			log.InfoContext(ctx, "task processed", attrs...)
+			return nil
+		})
+	}
+}
+
+// MetricsMiddleware records task duration and outcome per task type.
+func MetricsMiddleware() Middleware {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			start := time.Now()
+			err := next.ProcessTask(ctx, t)
+			metrics.RecordJob(t.Type(), outcome(ctx, err), time.Since(start))
+			return err
+		})
+	}
+}
+
+// TimeoutMiddleware bounds each task type by its configured timeout.
+// Task types without an entry keep the deadline asynq provides.
+func TimeoutMiddleware(timeouts map[string]time.Duration) Middleware {
+	return func(next asynq.Handler) asynq.Handler {
+		return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+			timeout, ok := timeouts[t.Type()]
+			if !ok || timeout <= 0 {
+				return next.ProcessTask(ctx, t)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			return next.ProcessTask(ctx, t)
+		})
+	}
+}
+
+// ParseTimeouts validates per-type handler timeouts against the task registry.
+func ParseTimeouts(timeouts map[string]time.Duration) (map[string]time.Duration, error) {
+	parsed := make(map[string]time.Duration, len(timeouts))
+	for taskType, timeout := range timeouts {
+		if _, ok := taskRegistry[taskType]; !ok {
+			return nil, fmt.Errorf("timeout for unknown task type %q", taskType)
+		}
+		if timeout <= 0 {
+			return nil, fmt.Errorf("timeout for %q must be positive, got %s", taskType, timeout)
+		}
+		parsed[taskType] = timeout
+	}
+
+	return parsed, nil
+}
+
+// outcome classifies a handler result for logs and metrics.
+func outcome(ctx context.Context, err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	if errors.Is(err, asynq.SkipRetry) {
+		return OutcomeFailed
+	}
+
+	if md, ok := TaskMetadataFromContext(ctx); ok && md.RetryCount >= md.MaxRetry {
+		return OutcomeFailed
+	}
+
+	return OutcomeRetry
+}
+
+// withCorrelationID adds the correlation ID to a JSON object payload and
+// reports whether it did. Other payloads, and payloads that already carry an
+// ID, are left alone.
+func withCorrelationID(payload []byte, correlationID string) ([]byte, bool) {
+	if correlationID == "" {
+		return payload, false
+	}
+
+	fields := map[string]json.RawMessage{}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &fields); err != nil || fields == nil {
+			return payload, false
+		}
+	}
+	if _, ok := fields[correlationField]; ok {
+		return payload, false
+	}
+
+	encoded, err := json.Marshal(correlationID)
+	if err != nil {
+		return payload, false
+	}
+	fields[correlationField] = encoded
+
+	tagged, err := json.Marshal(fields)
+	if err != nil {
+		return payload, false
+	}
+
+	return tagged, true
+}
+
+func correlationIDFromPayload(payload []byte) string {
+	var envelope struct {
+		CorrelationID string `json:"correlation_id"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return ""
+	}
+
+	return envelope.CorrelationID
+}
@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/Proton-105/himera-bot/pkg/logger"
+)
+
+func chain(h asynq.Handler, mws ...Middleware) asynq.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+func TestRecoveryMiddlewareConvertsPanic(t *testing.T) {
+	h := chain(asynq.HandlerFunc(func(context.Context, *asynq.Task) error {
+		panic("boom")
+	}), RecoveryMiddleware(slog.New(slog.NewTextHandler(io.Discard, nil))))
+
+	if err := h.ProcessTask(context.Background(), asynq.NewTask(TaskTypePriceUpdate, nil)); err == nil {
+		t.Fatal("expected panic to be returned as an error")
+	}
+}
+
+func TestCorrelationIDRoundTrip(t *testing.T) {
+	task, err := NewCandleRollupTask(time.Minute)
+	if err != nil {
+		t.Fatalf("new task: %v", err)
+	}
+
+	payload, ok := withCorrelationID(task.Payload(), "req-42")
+	if !ok {
+		t.Fatal("expected correlation ID to be added")
+	}
+
+	var decoded CandleRollupPayload
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if decoded.Lookback != time.Minute {
+		t.Fatalf("lookback = %s, want 1m", decoded.Lookback)
+	}
+
+	if _, ok := withCorrelationID(payload, "req-43"); ok {
+		t.Fatal("expected existing correlation ID to be kept")
+	}
+	if _, ok := withCorrelationID([]byte("raw"), "req-42"); ok {
+		t.Fatal("expected non-JSON payload to be left alone")
+	}
+
+	var got string
+	h := chain(asynq.HandlerFunc(func(ctx context.Context, _ *asynq.Task) error {
+		got = logger.CorrelationIDFromContext(ctx)
+		return nil
+	}), CorrelationMiddleware())
+
+	if err := h.ProcessTask(context.Background(), asynq.NewTask(TaskTypeCandleRollup, payload)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "req-42" {
+		t.Fatalf("correlation ID = %q, want req-42", got)
+	}
+
+	if err := h.ProcessTask(context.Background(), asynq.NewTask(TaskTypeCandleRollup, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == "" {
+		t.Fatal("expected a generated correlation ID for untagged tasks")
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	var remaining time.Duration
+	var hasDeadline bool
+	h := chain(asynq.HandlerFunc(func(ctx context.Context, _ *asynq.Task) error {
+		var deadline time.Time
+		deadline, hasDeadline = ctx.Deadline()
+		remaining = time.Until(deadline)
+		return nil
+	}), TimeoutMiddleware(map[string]time.Duration{TaskTypePriceUpdate: time.Second}))
+
+	if err := h.ProcessTask(context.Background(), asynq.NewTask(TaskTypePriceUpdate, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasDeadline || remaining > time.Second {
+		t.Fatalf("expected a deadline within 1s, got %v (set %t)", remaining, hasDeadline)
+	}
+
+	if err := h.ProcessTask(context.Background(), asynq.NewTask(TaskTypeCleanupData, nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasDeadline {
+		t.Fatal("expected no deadline for task types without a timeout")
+	}
+}
+
+func TestParseTimeoutsRejectsUnknownTaskType(t *testing.T) {
+	if _, err := ParseTimeouts(map[string]time.Duration{"mail:send": time.Second}); err == nil {
+		t.Fatal("expected unknown task type to be rejected")
+	}
+	if _, err := ParseTimeouts(map[string]time.Duration{TaskTypePriceUpdate: 0}); err == nil {
+		t.Fatal("expected non-positive timeout to be rejected")
+	}
+}
+
+func TestOutcome(t *testing.T) {
+	failure := errors.New("failure")
+
+	if got := outcome(context.Background(), nil); got != OutcomeSuccess {
+		t.Fatalf("outcome(nil) = %q", got)
+	}
+	if got := outcome(context.Background(), failure); got != OutcomeRetry {
+		t.Fatalf("outcome(err) = %q", got)
+	}
+	if got := outcome(context.Background(), errors.Join(failure, asynq.SkipRetry)); got != OutcomeFailed {
+		t.Fatalf("outcome(skip retry) = %q", got)
+	}
+}
@@ -0,0 +1,144 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
+
+	"github.com/Proton-105/himera-bot/pkg/config"
+)
+
+// ErrInvalidSchedule indicates that a configured schedule entry cannot be registered.
+var ErrInvalidSchedule = errors.New("invalid job schedule")
+
+// taskDefinition describes a task type that may be scheduled from configuration.
+type taskDefinition struct {
+	queue   string
+	payload func() any
+}
+
+// taskRegistry lists the task types known to the scheduler and their payload types.
+var taskRegistry = map[string]taskDefinition{
+	TaskTypePriceUpdate:  {queue: QueueDefault, payload: func() any { return &PriceUpdatePayload{} }},
+	TaskTypeCandleRollup: {queue: QueueLow, payload: func() any { return &CandleRollupPayload{} }},
+	TaskTypeCleanupData:  {queue: QueueLow, payload: func() any { return &CleanupDataPayload{} }},
+	TaskTypeDCABuy:       {queue: QueueCritical, payload: func() any { return &DCABuyPayload{} }},
+	TaskTypeTokenSync:    {queue: QueueLow, payload: func() any { return &TokenSyncPayload{} }},
+	TaskTypeTopTokens:    {queue: QueueLow, payload: func() any { return &TopTokensPayload{} }},
+}
+
+var knownQueues = map[string]struct{}{
+	QueueCritical: {},
+	QueueDefault:  {},
+	QueueLow:      {},
+}
+
+// KnownTaskTypes returns the task types that may appear in job schedules.
+func KnownTaskTypes() []string {
+	types := make([]string, 0, len(taskRegistry))
+	for taskType := range taskRegistry {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Schedule is a validated cron entry ready to be registered with the scheduler.
+// Opts are kept apart from Task because asynq does not expose the options a
+// task was built with, and every backend must apply them.
+type Schedule struct {
+	Cron string
+	Task *asynq.Task
+	Opts []asynq.Option
+}
+
+// ParseSchedules validates configured entries against the task registry and
+// builds their tasks. It fails on the first invalid entry so that a bad
+// configuration never replaces a working one.
+func ParseSchedules(entries []config.JobScheduleConfig) ([]Schedule, error) {
+	schedules := make([]Schedule, 0, len(entries))
+	for i, entry := range entries {
+		schedule, err := parseSchedule(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d (%s): %v", ErrInvalidSchedule, i, entry.Task, err)
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nil
+}
+
+func parseSchedule(entry config.JobScheduleConfig) (Schedule, error) {
+	definition, ok := taskRegistry[entry.Task]
+	if !ok {
+		return Schedule{}, fmt.Errorf("unknown task type %q", entry.Task)
+	}
+
+	if _, err := cron.ParseStandard(entry.Cron); err != nil {
+		return Schedule{}, fmt.Errorf("cron %q: %w", entry.Cron, err)
+	}
+
+	queue := definition.queue
+	if entry.Queue != "" {
+		if _, ok := knownQueues[entry.Queue]; !ok {
+			return Schedule{}, fmt.Errorf("unknown queue %q", entry.Queue)
+		}
+		queue = entry.Queue
+	}
+
+	payload, err := encodePayload(definition.payload(), entry.Payload)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	opts := []asynq.Option{asynq.Queue(queue)}
+	if entry.Timeout < 0 {
+		return Schedule{}, fmt.Errorf("timeout must not be negative, got %s", entry.Timeout)
+	}
+	if entry.Timeout > 0 {
+		opts = append(opts, asynq.Timeout(entry.Timeout))
+	}
+	if entry.MaxRetries != nil {
+		if *entry.MaxRetries < 0 {
+			return Schedule{}, fmt.Errorf("max_retries must not be negative, got %d", *entry.MaxRetries)
+		}
+		opts = append(opts, asynq.MaxRetry(*entry.MaxRetries))
+	}
+
+	return Schedule{
+		Cron: entry.Cron,
+		Task: asynq.NewTask(entry.Task, payload),
+		Opts: opts,
+	}, nil
+}
+
+// encodePayload decodes the configured payload into the task's payload type,
+// rejecting unknown fields, and returns its JSON encoding. Durations may be
+// written as strings such as "15m".
+func encodePayload(target any, raw map[string]any) ([]byte, error) {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName:     "json",
+		DecodeHook:  mapstructure.StringToTimeDurationHookFunc(),
+		ErrorUnused: true,
+		Result:      target,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("payload decoder: %w", err)
+	}
+
+	if err := decoder.Decode(raw); err != nil {
+		return nil, fmt.Errorf("payload: %w", err)
+	}
+
+	payload, err := json.Marshal(target)
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/Proton-105/himera-bot/pkg/config"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestParseSchedules(t *testing.T) {
+	schedules, err := ParseSchedules([]config.JobScheduleConfig{
+		{
+			Task:       TaskTypeCandleRollup,
+			Cron:       "*/5 * * * *",
+			Queue:      QueueDefault,
+			Payload:    map[string]any{"lookback": "15m"},
+			Timeout:    time.Minute,
+			MaxRetries: intPtr(2),
+		},
+		{Task: TaskTypeCleanupData, Cron: "@daily"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(schedules) != 2 || schedules[0].Cron != "*/5 * * * *" {
+		t.Fatalf("unexpected schedules: %+v", schedules)
+	}
+
+	var payload CandleRollupPayload
+	if err := json.Unmarshal(schedules[0].Task.Payload(), &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Lookback != 15*time.Minute {
+		t.Fatalf("lookback = %s, want 15m", payload.Lookback)
+	}
+	if schedules[1].Task.Type() != TaskTypeCleanupData {
+		t.Fatalf("unexpected task type %q", schedules[1].Task.Type())
+	}
+}
+
+func TestParseSchedulesRejectsInvalidEntries(t *testing.T) {
+	testCases := []struct {
+		name  string
+		entry config.JobScheduleConfig
+	}{
+		{name: "unknown task", entry: config.JobScheduleConfig{Task: "mail:send", Cron: "* * * * *"}},
+		{name: "bad cron", entry: config.JobScheduleConfig{Task: TaskTypeCleanupData, Cron: "every minute"}},
+		{name: "unknown queue", entry: config.JobScheduleConfig{Task: TaskTypeCleanupData, Cron: "* * * * *", Queue: "urgent"}},
+		{name: "unknown payload field", entry: config.JobScheduleConfig{Task: TaskTypeCleanupData, Cron: "* * * * *", Payload: map[string]any{"tables": "all"}}},
+		{name: "bad payload value", entry: config.JobScheduleConfig{Task: TaskTypeCandleRollup, Cron: "* * * * *", Payload: map[string]any{"lookback": "soon"}}},
+		{name: "negative retries", entry: config.JobScheduleConfig{Task: TaskTypeCleanupData, Cron: "* * * * *", MaxRetries: intPtr(-1)}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseSchedules([]config.JobScheduleConfig{tc.entry}); !errors.Is(err, ErrInvalidSchedule) {
+				t.Fatalf("expected ErrInvalidSchedule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestShippedJobSchedulesAreValid(t *testing.T) {
+	v := viper.New()
+	v.SetConfigFile("../../configs/jobs.yaml")
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("read jobs.yaml: %v", err)
+	}
+
+	var entries []config.JobScheduleConfig
+	if err := v.UnmarshalKey("schedules", &entries); err != nil {
+		t.Fatalf("decode schedules: %v", err)
+	}
+
+	schedules, err := ParseSchedules(entries)
+	if err != nil {
+		t.Fatalf("configs/jobs.yaml: %v", err)
+	}
+	if len(schedules) == 0 {
+		t.Fatal("expected configs/jobs.yaml to schedule at least one task")
+	}
+
+	var timeouts map[string]time.Duration
+	if err := v.UnmarshalKey("timeouts", &timeouts); err != nil {
+		t.Fatalf("decode timeouts: %v", err)
+	}
+	if _, err := ParseTimeouts(timeouts); err != nil {
+		t.Fatalf("configs/jobs.yaml: %v", err)
+	}
+}
@@ -2,62 +2,123 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
 
 	"github.com/hibiken/asynq"
 )
 
 type Scheduler interface {
-	RegisterTasks() error
+	// Apply replaces the registered cron entries with schedules. It is safe to
+	// call while the scheduler runs, e.g. after a configuration reload.
+	Apply(schedules []Schedule) error
+	// Run and Shutdown may alternate, e.g. as leadership moves between replicas.
 	Run()
 	Shutdown()
 }
 
 type scheduler struct {
+	redisOpt asynq.RedisConnOpt
+	log      *slog.Logger
+
+	mu             sync.Mutex
 	asynqScheduler *asynq.Scheduler
-	log            *slog.Logger
+	schedules      []Schedule
+	entryIDs       []string
+	running        bool
 }
 
 func NewScheduler(redisOpt asynq.RedisConnOpt, log *slog.Logger) Scheduler {
 	return &scheduler{
+		redisOpt:       redisOpt,
 		asynqScheduler: asynq.NewScheduler(redisOpt, nil),
 		log:            log,
 	}
 }
 
-func (s *scheduler) RegisterTasks() error {
-	task, err := NewPriceUpdateTask([]string{"ALL"})
-	if err != nil {
-		return err
-	}
+func (s *scheduler) Apply(schedules []Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if _, err := s.asynqScheduler.Register("*/30 * * * *", task); err != nil {
-		return err
+	var errs []error
+	for _, id := range s.entryIDs {
+		if err := s.asynqScheduler.Unregister(id); err != nil {
+			errs = append(errs, fmt.Errorf("unregister entry %s: %w", id, err))
+		}
 	}
+	s.entryIDs = s.entryIDs[:0]
+	s.schedules = append([]Schedule(nil), schedules...)
 
-	if s.log != nil {
-		s.log.InfoContext(context.Background(), "scheduler: registered price update task")
+	return errors.Join(append(errs, s.register())...)
+}
+
+// register adds s.schedules to the current asynq scheduler. The caller holds s.mu.
+func (s *scheduler) register() error {
+	var errs []error
+	for _, schedule := range s.schedules {
+		id, err := s.asynqScheduler.Register(schedule.Cron, schedule.Task, schedule.Opts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("register %s (%s): %w", schedule.Task.Type(), schedule.Cron, err))
+			continue
+		}
+		s.entryIDs = append(s.entryIDs, id)
+
+		if s.log != nil {
+			s.log.InfoContext(context.Background(), "scheduler: registered task",
+				slog.String("task_type", schedule.Task.Type()),
+				slog.String("cron", schedule.Cron),
+				slog.String("entry_id", id),
+			)
+		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func (s *scheduler) Run() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
 	if s.log != nil {
 		s.log.InfoContext(context.Background(), "scheduler: starting")
 	}
 
-	go func() {
-		if err := s.asynqScheduler.Run(); err != nil && s.log != nil {
+	if err := s.asynqScheduler.Start(); err != nil {
+		if s.log != nil {
 			s.log.ErrorContext(context.Background(), "scheduler: run failed", "error", err)
 		}
-	}()
+		return
+	}
+	s.running = true
 }
 
+// Shutdown stops enqueueing cron entries. asynq schedulers cannot be started
+// again, so a fresh one with the applied schedules replaces the stopped one;
+// this lets a replica that regains leadership call Run again.
 func (s *scheduler) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return
+	}
+
 	if s.log != nil {
 		s.log.InfoContext(context.Background(), "scheduler: shutting down")
 	}
 
 	s.asynqScheduler.Shutdown()
+	s.running = false
+
+	s.asynqScheduler = asynq.NewScheduler(s.redisOpt, nil)
+	s.entryIDs = s.entryIDs[:0]
+	if err := s.register(); err != nil && s.log != nil {
+		s.log.ErrorContext(context.Background(), "scheduler: re-register failed", "error", err)
+	}
 }
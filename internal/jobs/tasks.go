@@ -2,14 +2,19 @@ package jobs
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/hibiken/asynq"
 )
 
 const (
-	TaskTypePriceUpdate = "price:update"
-	TaskTypeCleanupData = "data:cleanup"
+	TaskTypePriceUpdate  = "price:update"
+	TaskTypeCleanupData  = "data:cleanup"
+	TaskTypeCandleRollup = "price:rollup"
+	TaskTypeDCABuy       = "dca:buy"
+	TaskTypeTokenSync    = "tokens:sync"
+	TaskTypeTopTokens    = "tokens:top"
 )
 
 const (
@@ -18,14 +23,41 @@ const (
 	QueueLow      = "low"
 )
 
+// AllTokens is a PriceUpdatePayload address that expands to every token held
+// in an open position, on a watchlist or recently seen in the token registry.
+const AllTokens = "ALL"
+
 type PriceUpdatePayload struct {
 	TokenAddresses []string `json:"token_addresses"`
 }
 
+// CandleRollupPayload asks for candles overlapping the last Lookback to be recomputed.
+type CandleRollupPayload struct {
+	Lookback time.Duration `json:"lookback"`
+}
+
+// CleanupDataPayload triggers the retention policies. A positive OlderThan
+// replaces the configured retention of every policy for this run.
 type CleanupDataPayload struct {
 	OlderThan time.Duration `json:"older_than"`
 }
 
+// DCABuyPayload runs one scheduled occurrence of a DCA plan.
+type DCABuyPayload struct {
+	PlanID       int64     `json:"plan_id"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+// TokenSyncPayload refreshes the token registry. Queries are searched on the
+// providers and their results registered, which seeds the registry with
+// tokens nobody has looked up yet.
+type TokenSyncPayload struct {
+	Queries []string `json:"queries"`
+}
+
+// TopTokensPayload recomputes the top tokens screen. It has no options.
+type TopTokensPayload struct{}
+
 func NewPriceUpdateTask(addresses []string) (*asynq.Task, error) {
 	payload, err := json.Marshal(PriceUpdatePayload{TokenAddresses: addresses})
 	if err != nil {
@@ -43,3 +75,46 @@ func NewCleanupDataTask(olderThan time.Duration) (*asynq.Task, error) {
 
 	return asynq.NewTask(TaskTypeCleanupData, payload, asynq.Queue(QueueLow)), nil
 }
+
+func NewCandleRollupTask(lookback time.Duration) (*asynq.Task, error) {
+	payload, err := json.Marshal(CandleRollupPayload{Lookback: lookback})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TaskTypeCandleRollup, payload, asynq.Queue(QueueLow)), nil
+}
+
+// NewDCABuyTask builds the buy task for the plan's run at scheduledFor. Its
+// task ID, from DCABuyTaskID, keeps the run from being enqueued twice.
+func NewDCABuyTask(planID int64, scheduledFor time.Time) (*asynq.Task, error) {
+	payload, err := json.Marshal(DCABuyPayload{PlanID: planID, ScheduledFor: scheduledFor.UTC()})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TaskTypeDCABuy, payload, asynq.Queue(QueueCritical), asynq.TaskID(DCABuyTaskID(planID, scheduledFor))), nil
+}
+
+// DCABuyTaskID identifies the plan's run at scheduledFor.
+func DCABuyTaskID(planID int64, scheduledFor time.Time) string {
+	return fmt.Sprintf("%s:%d:%d", TaskTypeDCABuy, planID, scheduledFor.Unix())
+}
+
+func NewTokenSyncTask(queries []string) (*asynq.Task, error) {
+	payload, err := json.Marshal(TokenSyncPayload{Queries: queries})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TaskTypeTokenSync, payload, asynq.Queue(QueueLow)), nil
+}
+
+func NewTopTokensTask() (*asynq.Task, error) {
+	payload, err := json.Marshal(TopTokensPayload{})
+	if err != nil {
+		return nil, err
+	}
+
+	return asynq.NewTask(TaskTypeTopTokens, payload, asynq.Queue(QueueLow)), nil
+}
@@ -9,6 +9,7 @@ import (
 
 // Worker provides APIs to register handlers and control the background worker lifecycle.
 type Worker interface {
+	Use(mws ...Middleware)
 	RegisterHandler(taskType string, handler asynq.Handler)
 	Run() error
 	Shutdown()
@@ -23,11 +24,13 @@ type worker struct {
 var _ Worker = (*worker)(nil)
 
 // NewWorker constructs a Worker backed by an asynq.Server instance.
-func NewWorker(redisOpt asynq.RedisConnOpt, queues map[string]int, log *slog.Logger) Worker {
+// errorHandler, if not nil, is called whenever a task fails.
+func NewWorker(redisOpt asynq.RedisConnOpt, queues map[string]int, errorHandler asynq.ErrorHandler, log *slog.Logger) Worker {
 	server := asynq.NewServer(redisOpt, asynq.Config{
 		Queues:         queues,
 		Concurrency:    10,
 		RetryDelayFunc: asynq.DefaultRetryDelayFunc,
+		ErrorHandler:   errorHandler,
 	})
 
 	mux := asynq.NewServeMux()
@@ -39,6 +42,13 @@ func NewWorker(redisOpt asynq.RedisConnOpt, queues map[string]int, log *slog.Log
 	}
 }
 
+// Use appends middlewares applied to every handler. The first middleware is the outermost.
+func (w *worker) Use(mws ...Middleware) {
+	for _, mw := range mws {
+		w.mux.Use(asynq.MiddlewareFunc(mw))
+	}
+}
+
 // RegisterHandler wires a task type to the provided handler.
 func (w *worker) RegisterHandler(taskType string, handler asynq.Handler) {
 	w.mux.Handle(taskType, handler)
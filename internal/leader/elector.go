@@ -0,0 +1,328 @@
+// Package leader elects one process among the bot replicas to run singleton
+// background loops.
+//
+// The leader holds a Redis lease that it renews periodically. Every
+// acquisition increments a fencing counter; the resulting token is stored in
+// the lease and exposed to singletons through their context so that writes
+// from a leader that lost its lease can be told apart from the current one.
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Proton-105/himera-bot/pkg/metrics"
+)
+
+const (
+	keyPrefix = "leader:"
+	// DefaultLeaseTTL is used when NewElector receives a non-positive TTL.
+	DefaultLeaseTTL = 15 * time.Second
+	// releaseTimeout bounds the lease release on shutdown.
+	releaseTimeout = 5 * time.Second
+)
+
+// ErrNotLeader indicates that the lease is no longer held with the given token.
+var ErrNotLeader = errors.New("not the leader")
+
+// acquireScript takes the lease when it is free and returns the new fencing
+// token, or 0 when another instance holds it.
+var acquireScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+local token = redis.call("INCR", KEYS[2])
+redis.call("SET", KEYS[1], ARGV[1] .. ":" .. token, "PX", ARGV[2])
+return token
+`)
+
+// renewScript extends the lease if it still carries our value.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lease if it still carries our value.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+type singleton struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+type tokenKey struct{}
+
+// Elector campaigns for a named lease and runs the registered singletons while
+// it is the leader. Singletons are started with a context that is cancelled
+// when leadership ends, and the elector waits for them to return before it
+// releases the lease or campaigns again.
+type Elector struct {
+	client        *redis.Client
+	name          string
+	key           string
+	fenceKey      string
+	id            string
+	ttl           time.Duration
+	renewInterval time.Duration
+	retryInterval time.Duration
+	log           *slog.Logger
+	now           func() time.Time
+
+	mu         sync.Mutex
+	singletons []singleton
+	token      int64
+	done       chan struct{}
+}
+
+// NewElector creates an elector for the lease called name. Instances that
+// share the name compete for the same lease.
+func NewElector(client *redis.Client, name string, ttl time.Duration, log *slog.Logger) *Elector {
+	if log == nil {
+		log = slog.Default()
+	}
+	if ttl <= 0 {
+		ttl = DefaultLeaseTTL
+	}
+
+	return &Elector{
+		client:        client,
+		name:          name,
+		key:           keyPrefix + name,
+		fenceKey:      keyPrefix + name + ":fence",
+		id:            instanceID(),
+		ttl:           ttl,
+		renewInterval: ttl / 3,
+		retryInterval: ttl / 3,
+		log:           log.With(slog.String("lease", name)),
+		now:           time.Now,
+		done:          make(chan struct{}),
+	}
+}
+
+// Add registers a singleton. It must be called before Run.
+func (e *Elector) Add(name string, run func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.singletons = append(e.singletons, singleton{name: name, run: run})
+}
+
+// IsLeader reports whether the elector currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.token != 0
+}
+
+// Run campaigns until ctx is cancelled. On cancellation it stops the
+// singletons and releases the lease so another replica can take over
+// without waiting for the TTL.
+func (e *Elector) Run(ctx context.Context) {
+	defer close(e.done)
+
+	e.log.Info("leader election started", slog.String("instance_id", e.id), slog.Duration("ttl", e.ttl))
+
+	ticker := time.NewTicker(e.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		token, err := e.acquire(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			e.log.Warn("failed to acquire leader lease", slog.Any("error", err))
+		case token != 0:
+			e.lead(ctx, token)
+		}
+
+		select {
+		case <-ctx.Done():
+			e.log.Info("leader election stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Wait blocks until Run has returned or ctx is done.
+func (e *Elector) Wait(ctx context.Context) error {
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Check verifies that the lease is still held with token. Singletons can call
+// it before side effects that must not be repeated by a newer leader.
+func (e *Elector) Check(ctx context.Context, token int64) error {
+	value, err := e.client.Get(ctx, e.key).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrNotLeader
+	}
+	if err != nil {
+		return fmt.Errorf("get leader lease: %w", err)
+	}
+	if value != e.value(token) {
+		return ErrNotLeader
+	}
+
+	return nil
+}
+
+// Verify checks that the singleton running with ctx still belongs to the
+// current leadership term, using the fencing token of its context. A context
+// that carries no token was not started by the elector and fails the check.
+func (e *Elector) Verify(ctx context.Context) error {
+	token, ok := FencingToken(ctx)
+	if !ok {
+		return ErrNotLeader
+	}
+
+	return e.Check(ctx, token)
+}
+
+// FencingToken returns the fencing token of the leadership term that started
+// the singleton running with ctx.
+func FencingToken(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(tokenKey{}).(int64)
+	return token, ok
+}
+
+// lead runs the singletons until ctx is cancelled or the lease is lost.
+func (e *Elector) lead(ctx context.Context, token int64) {
+	e.setToken(token)
+	e.log.Info("acquired leader lease", slog.Int64("fencing_token", token))
+
+	termCtx, cancel := context.WithCancel(context.WithValue(ctx, tokenKey{}, token))
+	var wg sync.WaitGroup
+
+	e.mu.Lock()
+	singletons := append([]singleton(nil), e.singletons...)
+	e.mu.Unlock()
+
+	for _, s := range singletons {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.log.Info("singleton started", slog.String("singleton", s.name))
+			s.run(termCtx)
+			e.log.Info("singleton stopped", slog.String("singleton", s.name))
+		}()
+	}
+
+	e.renew(termCtx, token)
+
+	e.setToken(0)
+	cancel()
+	wg.Wait()
+
+	// Release only after every singleton returned so that terms never overlap.
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancelRelease()
+	if err := e.release(releaseCtx, token); err != nil {
+		e.log.Warn("failed to release leader lease", slog.Int64("fencing_token", token), slog.Any("error", err))
+		return
+	}
+	e.log.Info("released leader lease", slog.Int64("fencing_token", token))
+}
+
+// renew extends the lease until ctx is done or the lease is lost. When Redis
+// is unreachable the elector steps down before the lease could have expired,
+// leaving the singletons one renew interval to stop before a rival acquires it.
+func (e *Elector) renew(ctx context.Context, token int64) {
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	renewed := e.now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := e.extend(ctx, token)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			if e.now().Sub(renewed) >= e.ttl-e.renewInterval {
+				e.log.Error("lost leader lease: renewal failing", slog.Int64("fencing_token", token), slog.Any("error", err))
+				return
+			}
+			e.log.Warn("failed to renew leader lease", slog.Int64("fencing_token", token), slog.Any("error", err))
+		case !ok:
+			e.log.Error("lost leader lease: held by another instance", slog.Int64("fencing_token", token))
+			return
+		default:
+			renewed = e.now()
+		}
+	}
+}
+
+func (e *Elector) acquire(ctx context.Context) (int64, error) {
+	token, err := acquireScript.Run(ctx, e.client, []string{e.key, e.fenceKey}, e.id, e.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("acquire lease: %w", err)
+	}
+
+	return token, nil
+}
+
+func (e *Elector) extend(ctx context.Context, token int64) (bool, error) {
+	extended, err := renewScript.Run(ctx, e.client, []string{e.key}, e.value(token), e.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("renew lease: %w", err)
+	}
+
+	return extended == 1, nil
+}
+
+func (e *Elector) release(ctx context.Context, token int64) error {
+	if err := releaseScript.Run(ctx, e.client, []string{e.key}, e.value(token)).Err(); err != nil {
+		return fmt.Errorf("release lease: %w", err)
+	}
+
+	return nil
+}
+
+func (e *Elector) setToken(token int64) {
+	e.mu.Lock()
+	e.token = token
+	e.mu.Unlock()
+
+	metrics.SetLeader(e.name, token != 0)
+}
+
+func (e *Elector) value(token int64) string {
+	return e.id + ":" + strconv.FormatInt(token, 10)
+}
+
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+
+	return strings.ReplaceAll(host, ":", "_") + "-" + uuid.NewString()[:8]
+}
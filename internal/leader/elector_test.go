@@ -0,0 +1,165 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const testTTL = 300 * time.Millisecond
+
+func setupTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return mr, client
+}
+
+func newTestElector(client *redis.Client) *Elector {
+	return NewElector(client, "test", testTTL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// term reports each leadership term of a singleton by sending its fencing
+// token on start and closing stopped when the term ends.
+type term struct {
+	token   int64
+	stopped chan struct{}
+}
+
+func trackTerms(e *Elector) <-chan term {
+	terms := make(chan term, 4)
+	e.Add("tracker", func(ctx context.Context) {
+		token, _ := FencingToken(ctx)
+		stopped := make(chan struct{})
+		terms <- term{token: token, stopped: stopped}
+		<-ctx.Done()
+		close(stopped)
+	})
+	return terms
+}
+
+func waitTerm(t *testing.T, terms <-chan term) term {
+	t.Helper()
+
+	select {
+	case tm := <-terms:
+		return tm
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for leadership")
+		return term{}
+	}
+}
+
+func waitStopped(t *testing.T, tm term) {
+	t.Helper()
+
+	select {
+	case <-tm.stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the singleton to stop")
+	}
+}
+
+func TestElectorRunsSingletonsOnOneInstanceAndHandsOver(t *testing.T) {
+	_, client := setupTestRedis(t)
+
+	first, second := newTestElector(client), newTestElector(client)
+	firstTerms, secondTerms := trackTerms(first), trackTerms(second)
+
+	firstCtx, stopFirst := context.WithCancel(context.Background())
+	defer stopFirst()
+	go first.Run(firstCtx)
+
+	leading := waitTerm(t, firstTerms)
+	if leading.token != 1 || !first.IsLeader() {
+		t.Fatalf("expected first elector to lead with token 1, got %d", leading.token)
+	}
+
+	secondCtx, stopSecond := context.WithCancel(context.Background())
+	defer stopSecond()
+	go second.Run(secondCtx)
+
+	select {
+	case <-secondTerms:
+		t.Fatal("second elector started singletons while the first holds the lease")
+	case <-time.After(2 * testTTL):
+	}
+
+	stopFirst()
+	waitStopped(t, leading)
+	if err := first.Wait(context.Background()); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+
+	// The released lease is taken on the next retry rather than after the TTL.
+	start := time.Now()
+	next := waitTerm(t, secondTerms)
+	if next.token != 2 {
+		t.Fatalf("expected fencing token 2 for the next term, got %d", next.token)
+	}
+	if elapsed := time.Since(start); elapsed > testTTL {
+		t.Fatalf("handover took %s, want less than the lease TTL", elapsed)
+	}
+
+	if err := second.Check(context.Background(), leading.token); !errors.Is(err, ErrNotLeader) {
+		t.Fatalf("expected stale token to be rejected, got %v", err)
+	}
+	if err := second.Check(context.Background(), next.token); err != nil {
+		t.Fatalf("expected current token to pass, got %v", err)
+	}
+}
+
+func TestElectorStepsDownWhenLeaseIsLost(t *testing.T) {
+	mr, client := setupTestRedis(t)
+
+	elector := newTestElector(client)
+	terms := trackTerms(elector)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go elector.Run(ctx)
+
+	leading := waitTerm(t, terms)
+
+	// Another instance took over after the lease expired, e.g. during a long GC pause.
+	mr.Set(elector.key, "other:7")
+
+	waitStopped(t, leading)
+	if elector.IsLeader() {
+		t.Fatal("expected elector to step down")
+	}
+	if value, _ := mr.Get(elector.key); value != "other:7" {
+		t.Fatalf("lease of the new leader was modified: %q", value)
+	}
+
+	mr.Del(elector.key)
+	if next := waitTerm(t, terms); next.token <= leading.token {
+		t.Fatalf("expected a larger fencing token after re-election, got %d then %d", leading.token, next.token)
+	}
+}
+
+func TestElectorVerifyUsesContextToken(t *testing.T) {
+	mr, client := setupTestRedis(t)
+
+	elector := newTestElector(client)
+	mr.Set(elector.key, elector.value(3))
+
+	if err := elector.Verify(context.WithValue(context.Background(), tokenKey{}, int64(3))); err != nil {
+		t.Fatalf("expected current term to pass, got %v", err)
+	}
+	if err := elector.Verify(context.WithValue(context.Background(), tokenKey{}, int64(2))); !errors.Is(err, ErrNotLeader) {
+		t.Fatalf("expected stale term to be rejected, got %v", err)
+	}
+	if err := elector.Verify(context.Background()); !errors.Is(err, ErrNotLeader) {
+		t.Fatalf("expected context without token to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package ledger verifies that user balances agree with the balance ledger.
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/metrics"
+)
+
+// Reconciler periodically compares users.balance with the sum of ledger entries.
+type Reconciler struct {
+	repo     repository.LedgerRepository
+	log      *slog.Logger
+	interval time.Duration
+}
+
+// NewReconciler constructs a Reconciler that runs every interval.
+func NewReconciler(repo repository.LedgerRepository, log *slog.Logger, interval time.Duration) *Reconciler {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Reconciler{
+		repo:     repo,
+		log:      log,
+		interval: interval,
+	}
+}
+
+// Run reconciles once immediately and then on every tick until ctx is cancelled.
+func (r *Reconciler) Run(ctx context.Context) {
+	if r == nil || r.repo == nil || r.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Reconcile(ctx); err != nil {
+			r.log.ErrorContext(ctx, "ledger reconciliation failed", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile returns every user whose balance disagrees with their ledger and logs each mismatch.
+func (r *Reconciler) Reconcile(ctx context.Context) ([]domain.LedgerDiscrepancy, error) {
+	discrepancies, err := r.repo.Discrepancies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load ledger discrepancies: %w", err)
+	}
+
+	metrics.SetLedgerDiscrepancies(len(discrepancies))
+
+	for _, item := range discrepancies {
+		r.log.ErrorContext(ctx, "ledger discrepancy detected",
+			slog.Int64("telegram_id", item.TelegramID),
+			slog.String("balance", item.Balance.Amount().String()),
+			slog.String("ledger_total", item.LedgerTotal.Amount().String()),
+		)
+	}
+
+	if len(discrepancies) == 0 {
+		r.log.DebugContext(ctx, "ledger reconciled")
+	}
+
+	return discrepancies, nil
+}
@@ -0,0 +1,46 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/testutil"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type stubLedger struct {
+	repository.LedgerRepository
+	discrepancies []domain.LedgerDiscrepancy
+	err           error
+}
+
+func (s *stubLedger) Discrepancies(context.Context) ([]domain.LedgerDiscrepancy, error) {
+	return s.discrepancies, s.err
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("reports discrepancies", func(t *testing.T) {
+		repo := &stubLedger{discrepancies: []domain.LedgerDiscrepancy{
+			{TelegramID: 7, Balance: money.NewMoney(money.NewFromInt(100), money.USD, money.RoundDown), LedgerTotal: money.NewMoney(money.NewFromInt(90), money.USD, money.RoundDown)},
+		}}
+
+		got, err := NewReconciler(repo, log, 0).Reconcile(context.Background())
+		testutil.AssertNoError(t, err)
+		testutil.AssertEqual(t, 1, len(got))
+		testutil.AssertEqual(t, int64(7), got[0].TelegramID)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		repo := &stubLedger{err: errors.New("db down")}
+
+		_, err := NewReconciler(repo, log, 0).Reconcile(context.Background())
+		testutil.AssertError(t, err)
+	})
+}
@@ -0,0 +1,214 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// AggregateSource is the PriceQuote.Source of quotes produced by an Aggregator.
+const AggregateSource = "aggregate"
+
+// DefaultMaxDeviation is the largest relative distance from the median (5%)
+// at which a feed's price still counts as agreeing.
+var DefaultMaxDeviation = money.New(5, 2)
+
+// AggregatorConfig tunes how feeds are combined.
+type AggregatorConfig struct {
+	// MinSources is the number of agreeing feeds required to publish a price.
+	MinSources int
+	// MaxDeviation is the relative distance from the median beyond which a
+	// feed's price is dropped as an outlier. Zero means DefaultMaxDeviation.
+	MaxDeviation money.Decimal
+	// Timeout bounds each feed's request. Zero leaves the caller's deadline in charge.
+	Timeout time.Duration
+}
+
+// Aggregator queries several PriceProviders concurrently and publishes the
+// median of the prices that agree with each other. Feeds that fail, including
+// those whose circuit breaker is open, are skipped for the current request.
+type Aggregator struct {
+	providers []PriceProvider
+	cfg       AggregatorConfig
+	log       *slog.Logger
+}
+
+var _ PriceProvider = (*Aggregator)(nil)
+
+// NewAggregator constructs an Aggregator over providers.
+func NewAggregator(providers []PriceProvider, cfg AggregatorConfig, log *slog.Logger) *Aggregator {
+	if cfg.MinSources < 1 {
+		cfg.MinSources = 1
+	}
+	if cfg.MaxDeviation.Sign() <= 0 {
+		cfg.MaxDeviation = DefaultMaxDeviation
+	}
+
+	return &Aggregator{providers: providers, cfg: cfg, log: log}
+}
+
+// Name identifies aggregated quotes.
+func (a *Aggregator) Name() string {
+	return AggregateSource
+}
+
+type observation struct {
+	source string
+	price  money.Decimal
+}
+
+type feedResult struct {
+	name   string
+	quotes map[string]*domain.PriceQuote
+	err    error
+}
+
+// Prices returns a quote for every token on which at least MinSources feeds agree.
+// It fails only when every feed fails.
+func (a *Aggregator) Prices(ctx context.Context, tokenAddresses []string) (map[string]*domain.PriceQuote, error) {
+	if len(a.providers) == 0 {
+		return nil, errors.New("price aggregator has no providers")
+	}
+
+	results := a.query(ctx, tokenAddresses)
+
+	observations := make(map[string][]observation, len(tokenAddresses))
+	var failures []error
+	for _, result := range results {
+		if result.err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", result.name, result.err))
+			a.logFailure(ctx, result.name, result.err)
+			continue
+		}
+
+		for address, quote := range result.quotes {
+			if quote == nil || quote.PriceUSD.Sign() <= 0 {
+				continue
+			}
+			observations[address] = append(observations[address], observation{source: result.name, price: quote.PriceUSD})
+		}
+	}
+
+	if len(failures) == len(results) {
+		return nil, fmt.Errorf("all price sources failed: %w", errors.Join(failures...))
+	}
+
+	fetchedAt := time.Now().UTC()
+	quotes := make(map[string]*domain.PriceQuote, len(tokenAddresses))
+	for _, address := range tokenAddresses {
+		price, sources, ok := a.combine(observations[address])
+		if !ok {
+			if a.log != nil && len(observations[address]) > 0 {
+				a.log.WarnContext(ctx, "price sources disagree or are too few",
+					slog.String("token_address", address),
+					slog.Int("observations", len(observations[address])),
+					slog.Int("min_sources", a.cfg.MinSources),
+				)
+			}
+			continue
+		}
+
+		quotes[address] = &domain.PriceQuote{
+			TokenAddress: address,
+			PriceUSD:     price,
+			Source:       AggregateSource,
+			Sources:      sources,
+			FetchedAt:    fetchedAt,
+		}
+	}
+
+	return quotes, nil
+}
+
+func (a *Aggregator) query(ctx context.Context, tokenAddresses []string) []feedResult {
+	results := make([]feedResult, len(a.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range a.providers {
+		wg.Add(1)
+		go func(i int, provider PriceProvider) {
+			defer wg.Done()
+
+			feedCtx := ctx
+			if a.cfg.Timeout > 0 {
+				var cancel context.CancelFunc
+				feedCtx, cancel = context.WithTimeout(ctx, a.cfg.Timeout)
+				defer cancel()
+			}
+
+			quotes, err := provider.Prices(feedCtx, tokenAddresses)
+			results[i] = feedResult{name: provider.Name(), quotes: quotes, err: err}
+		}(i, provider)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// combine drops observations further than MaxDeviation from the median and
+// returns the median of the rest with the names of the feeds that contributed.
+func (a *Aggregator) combine(observations []observation) (money.Decimal, []string, bool) {
+	if len(observations) < a.cfg.MinSources {
+		return money.Zero, nil, false
+	}
+
+	center := median(observations)
+	inliers := make([]observation, 0, len(observations))
+	for _, obs := range observations {
+		deviation, err := obs.price.Sub(center).Abs().Quo(center, domain.PricePrecision, money.RoundHalfEven)
+		if err != nil || deviation.Cmp(a.cfg.MaxDeviation) > 0 {
+			continue
+		}
+		inliers = append(inliers, obs)
+	}
+
+	if len(inliers) < a.cfg.MinSources {
+		return money.Zero, nil, false
+	}
+
+	sources := make([]string, 0, len(inliers))
+	for _, obs := range inliers {
+		sources = append(sources, obs.source)
+	}
+	sort.Strings(sources)
+
+	return median(inliers), sources, true
+}
+
+// median returns the middle price, averaging the two middle prices of an even set.
+func median(observations []observation) money.Decimal {
+	prices := make([]money.Decimal, 0, len(observations))
+	for _, obs := range observations {
+		prices = append(prices, obs.price)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+
+	mid := len(prices) / 2
+	if len(prices)%2 == 1 {
+		return prices[mid]
+	}
+
+	mean, _ := prices[mid-1].Add(prices[mid]).Quo(money.NewFromInt(2), domain.PricePrecision, money.RoundHalfEven)
+	return mean
+}
+
+func (a *Aggregator) logFailure(ctx context.Context, source string, err error) {
+	if a.log == nil {
+		return
+	}
+
+	if errors.Is(err, apperrors.ErrCircuitOpen) || errors.Is(err, apperrors.ErrHalfOpenTooManyRequests) {
+		a.log.InfoContext(ctx, "price source skipped: circuit open", slog.String("source", source))
+		return
+	}
+
+	a.log.WarnContext(ctx, "price source failed", slog.String("source", source), slog.Any("error", err))
+}
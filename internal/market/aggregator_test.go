@@ -0,0 +1,128 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type stubProvider struct {
+	name   string
+	prices map[string]string
+	err    error
+}
+
+func (s *stubProvider) Name() string { return s.name }
+
+func (s *stubProvider) Prices(_ context.Context, addresses []string) (map[string]*domain.PriceQuote, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	quotes := make(map[string]*domain.PriceQuote)
+	for _, address := range addresses {
+		raw, ok := s.prices[address]
+		if !ok {
+			continue
+		}
+		price, err := money.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		quotes[address] = &domain.PriceQuote{TokenAddress: address, PriceUSD: price, Source: s.name}
+	}
+	return quotes, nil
+}
+
+func TestAggregatorDropsOutliers(t *testing.T) {
+	aggregator := NewAggregator([]PriceProvider{
+		&stubProvider{name: "a", prices: map[string]string{"tok": "1.00"}},
+		&stubProvider{name: "b", prices: map[string]string{"tok": "1.02"}},
+		&stubProvider{name: "c", prices: map[string]string{"tok": "9.99"}},
+	}, AggregatorConfig{MinSources: 2}, nil)
+
+	quotes, err := aggregator.Prices(context.Background(), []string{"tok"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	quote := quotes["tok"]
+	if quote == nil {
+		t.Fatal("expected a quote")
+	}
+	if got := quote.PriceUSD.StringFixed(4, money.RoundHalfEven); got != "1.0100" {
+		t.Errorf("price = %s, want 1.0100", got)
+	}
+	if !reflect.DeepEqual(quote.Sources, []string{"a", "b"}) || quote.Source != AggregateSource {
+		t.Errorf("unexpected sources: %s %v", quote.Source, quote.Sources)
+	}
+}
+
+func TestAggregatorRequiresAgreement(t *testing.T) {
+	aggregator := NewAggregator([]PriceProvider{
+		&stubProvider{name: "a", prices: map[string]string{"tok": "1.00"}},
+		&stubProvider{name: "b", prices: map[string]string{"tok": "2.00"}},
+	}, AggregatorConfig{MinSources: 2}, nil)
+
+	quotes, err := aggregator.Prices(context.Background(), []string{"tok"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := quotes["tok"]; ok {
+		t.Fatal("disagreeing feeds must not produce a price")
+	}
+}
+
+func TestAggregatorFallsBackWhenCircuitOpen(t *testing.T) {
+	open := fmt.Errorf("external API error: %w", apperrors.ErrCircuitOpen)
+	providers := []PriceProvider{
+		&stubProvider{name: "a", err: open},
+		&stubProvider{name: "b", prices: map[string]string{"tok": "3.5"}},
+	}
+
+	lenient := NewAggregator(providers, AggregatorConfig{MinSources: 1}, nil)
+	quotes, err := lenient.Prices(context.Background(), []string{"tok"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quote := quotes["tok"]; quote == nil || !reflect.DeepEqual(quote.Sources, []string{"b"}) {
+		t.Fatalf("expected fallback to source b, got %+v", quote)
+	}
+
+	strict := NewAggregator(providers, AggregatorConfig{MinSources: 2}, nil)
+	quotes, err = strict.Prices(context.Background(), []string{"tok"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := quotes["tok"]; ok {
+		t.Fatal("a single surviving feed must not satisfy MinSources 2")
+	}
+}
+
+func TestAggregatorAllSourcesFail(t *testing.T) {
+	aggregator := NewAggregator([]PriceProvider{
+		&stubProvider{name: "a", err: errors.New("timeout")},
+		&stubProvider{name: "b", err: errors.New("bad gateway")},
+	}, AggregatorConfig{}, nil)
+
+	if _, err := aggregator.Prices(context.Background(), []string{"tok"}); err == nil {
+		t.Fatal("expected an error when every source fails")
+	}
+}
+
+func TestSourceLatestPrice(t *testing.T) {
+	source := NewSource(nil, &stubProvider{name: "a", prices: map[string]string{"tok": "1"}})
+
+	if quote, err := source.LatestPrice(context.Background(), "tok"); err != nil || quote.PriceUSD.String() != "1" {
+		t.Fatalf("LatestPrice = %+v, %v", quote, err)
+	}
+	if _, err := source.LatestPrice(context.Background(), "missing"); !errors.Is(err, ErrPriceUnavailable) {
+		t.Fatalf("expected ErrPriceUnavailable, got %v", err)
+	}
+}
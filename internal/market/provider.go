@@ -0,0 +1,54 @@
+package market
+
+import (
+	"context"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+// PriceProvider is a feed of USD token prices.
+type PriceProvider interface {
+	// Name identifies the feed in quotes, logs and metrics.
+	Name() string
+	// Prices returns the latest quotes keyed by token address.
+	// Tokens the feed does not know are omitted from the result.
+	Prices(ctx context.Context, tokenAddresses []string) (map[string]*domain.PriceQuote, error)
+}
+
+// TokenFinder resolves a token by address or symbol.
+type TokenFinder interface {
+	FindToken(ctx context.Context, query string) (*domain.Token, error)
+}
+
+type source struct {
+	TokenFinder
+	prices PriceProvider
+}
+
+// NewSource combines a token finder with a price provider, typically an Aggregator.
+func NewSource(finder TokenFinder, prices PriceProvider) Source {
+	return &source{TokenFinder: finder, prices: prices}
+}
+
+// LatestPrice returns the provider's quote for the token or ErrPriceUnavailable.
+func (s *source) LatestPrice(ctx context.Context, tokenAddress string) (*domain.PriceQuote, error) {
+	quotes, err := s.prices.Prices(ctx, []string{tokenAddress})
+	if err != nil {
+		return nil, err
+	}
+
+	quote, ok := quotes[tokenAddress]
+	if !ok || quote == nil {
+		return nil, ErrPriceUnavailable
+	}
+
+	return quote, nil
+}
+
+// TokenDirectory describes tokens for the token registry.
+type TokenDirectory interface {
+	// DescribeTokens returns the tokens it knows, keyed by the requested address.
+	DescribeTokens(ctx context.Context, tokenAddresses []string) (map[string]*domain.TokenInfo, error)
+	// SearchTokens returns the tokens whose symbol, name or address matches query.
+	SearchTokens(ctx context.Context, query string) ([]*domain.TokenInfo, error)
+}
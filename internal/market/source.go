@@ -0,0 +1,45 @@
+// Package market defines how trading flows resolve tokens and their prices.
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+var (
+	// ErrTokenNotFound indicates that no token matches the search query.
+	ErrTokenNotFound = errors.New("token not found")
+	// ErrPriceUnavailable indicates that no usable price exists for the token.
+	ErrPriceUnavailable = errors.New("price unavailable")
+	// ErrStalePrice indicates that the only known price is too old to trade on.
+	ErrStalePrice = errors.New("stale price")
+)
+
+// StalePriceError reports a cached price older than the staleness threshold.
+// It matches ErrStalePrice with errors.Is.
+type StalePriceError struct {
+	TokenAddress string
+	FetchedAt    time.Time
+	Age          time.Duration
+}
+
+func (e *StalePriceError) Error() string {
+	return fmt.Sprintf("stale price for %s: fetched %s ago", e.TokenAddress, e.Age.Round(time.Second))
+}
+
+// Is reports whether target is ErrStalePrice.
+func (e *StalePriceError) Is(target error) bool {
+	return target == ErrStalePrice
+}
+
+// Source resolves tokens by user input and reports their latest USD price.
+type Source interface {
+	// FindToken resolves a token by address or symbol.
+	FindToken(ctx context.Context, query string) (*domain.Token, error)
+	// LatestPrice returns the most recent USD price for the token.
+	LatestPrice(ctx context.Context, tokenAddress string) (*domain.PriceQuote, error)
+}
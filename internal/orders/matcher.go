@@ -0,0 +1,275 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/leader"
+	"github.com/Proton-105/himera-bot/internal/pricecache"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// DefaultExpiryInterval is how often the matcher closes expired orders.
+const DefaultExpiryInterval = 30 * time.Second
+
+// Close reasons stored on orders cancelled by the matcher.
+const (
+	reasonInsufficientBalance = "insufficient balance"
+	reasonNoPosition          = "position closed"
+	reasonNoLiquidity         = "insufficient liquidity"
+	reasonPriceImpact         = "price impact too high"
+)
+
+// PriceFeed delivers price cache updates; pricecache.Subscriber implements it.
+type PriceFeed interface {
+	Subscribe(listener pricecache.Listener) (unsubscribe func())
+}
+
+// Notifier delivers a message to a user in Telegram.
+type Notifier interface {
+	NotifyUser(ctx context.Context, telegramID int64, text string) error
+}
+
+// Leader confirms that the singleton running with a context still holds
+// the leadership term that started it; leader.Elector implements it.
+type Leader interface {
+	Verify(ctx context.Context) error
+}
+
+// Matcher fills open orders whose limit the price crossed and expires
+// orders past their expiry. It must run on a single instance at a time;
+// fills are still safe if it does not, as each one locks its order, and a
+// matcher whose leadership term ended fills nothing.
+type Matcher struct {
+	repo           repository.OrderRepository
+	trades         Trades
+	feed           PriceFeed
+	notifier       Notifier
+	leader         Leader
+	log            *slog.Logger
+	now            func() time.Time
+	expiryInterval time.Duration
+}
+
+// NewMatcher constructs a Matcher. notifier may be nil, and so may leader,
+// in which case fills are not fenced.
+func NewMatcher(repo repository.OrderRepository, trades Trades, feed PriceFeed, notifier Notifier, leader Leader, log *slog.Logger) *Matcher {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Matcher{
+		repo:           repo,
+		trades:         trades,
+		feed:           feed,
+		notifier:       notifier,
+		leader:         leader,
+		log:            log,
+		now:            time.Now,
+		expiryInterval: DefaultExpiryInterval,
+	}
+}
+
+// Run matches orders against price updates until ctx is cancelled. Updates
+// that arrive while a token is being matched are coalesced to the latest one.
+func (m *Matcher) Run(ctx context.Context) {
+	updates := pricecache.NewQueue()
+	unsubscribe := m.feed.Subscribe(updates.Push)
+	defer unsubscribe()
+
+	ticker := time.NewTicker(m.expiryInterval)
+	defer ticker.Stop()
+
+	m.ExpireDue(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.ExpireDue(ctx)
+		case <-updates.Ready():
+			for _, quote := range updates.Drain() {
+				if ctx.Err() != nil {
+					return
+				}
+				m.Match(ctx, quote)
+			}
+		}
+	}
+}
+
+// Match fills the open orders in the quote's token whose limit the quote
+// crossed and returns how many filled. The market price is confirmed through
+// the trade service so that orders obey the same source requirements as
+// market trades, and orders fill through its execution model: the limit is
+// checked against the market price, fees and slippage apply on top, and an
+// order the pools cannot absorb is cancelled.
+func (m *Matcher) Match(ctx context.Context, quote *domain.PriceQuote) int {
+	if quote == nil || quote.TokenAddress == "" {
+		return 0
+	}
+
+	open, err := m.repo.ListOpenByToken(ctx, quote.TokenAddress)
+	if err != nil {
+		m.log.ErrorContext(ctx, "order matcher failed to list orders", slog.String("token_address", quote.TokenAddress), slog.Any("error", err))
+		return 0
+	}
+
+	crossed := make([]*domain.Order, 0, len(open))
+	for _, order := range open {
+		if order.Crossed(quote.PriceUSD) {
+			crossed = append(crossed, order)
+		}
+	}
+	if len(crossed) == 0 {
+		return 0
+	}
+
+	state, err := m.trades.LatestMarket(ctx, quote.TokenAddress)
+	if err != nil {
+		m.log.WarnContext(ctx, "order matcher could not confirm price",
+			slog.String("token_address", quote.TokenAddress),
+			slog.Int("orders", len(crossed)),
+			slog.Any("error", err),
+		)
+		return 0
+	}
+
+	filled := 0
+	for _, order := range crossed {
+		if order.Crossed(state.PriceUSD) && m.fill(ctx, order, state) {
+			filled++
+		}
+	}
+
+	return filled
+}
+
+// ExpireDue closes orders past their expiry, notifies their owners and
+// returns how many expired.
+func (m *Matcher) ExpireDue(ctx context.Context) int {
+	expired, err := m.repo.ExpireDue(ctx, m.now())
+	if err != nil {
+		m.log.ErrorContext(ctx, "order matcher failed to expire orders", slog.Any("error", err))
+		return 0
+	}
+
+	for _, order := range expired {
+		m.log.InfoContext(ctx, "limit order expired", slog.Int64("telegram_id", order.TelegramID), slog.Int64("order_id", order.ID))
+		m.notify(ctx, order.TelegramID, fmt.Sprintf("⌛ Order #%d expired: %s", order.ID, Describe(order)))
+	}
+
+	return len(expired)
+}
+
+func (m *Matcher) fill(ctx context.Context, order *domain.Order, state trade.Market) bool {
+	filled, executed, err := m.repo.Fill(ctx, order.ID, func(locked *domain.Order, position *domain.Position) (*domain.Trade, error) {
+		if m.leader != nil {
+			if err := m.leader.Verify(ctx); err != nil {
+				return nil, err
+			}
+		}
+		if locked.Side == domain.TradeTypeSell {
+			return m.trades.SellTrade(position, locked.Percent, state)
+		}
+		token := domain.Token{Address: locked.TokenAddress, Symbol: locked.TokenSymbol}
+		return m.trades.BuyTrade(locked.TelegramID, token, locked.AmountUSD, state)
+	})
+
+	switch {
+	case err == nil:
+		m.log.InfoContext(ctx, "limit order filled",
+			slog.Int64("telegram_id", filled.TelegramID),
+			slog.Int64("order_id", filled.ID),
+			slog.Int64("transaction_id", executed.ID),
+			slog.String("price_usd", executed.PriceUSD.String()),
+		)
+		m.notify(ctx, filled.TelegramID, filledMessage(filled, executed))
+		return true
+	case errors.Is(err, domain.ErrOrderNotOpen), errors.Is(err, domain.ErrOrderNotFound):
+		// Cancelled or expired since it was listed.
+		return false
+	case errors.Is(err, leader.ErrNotLeader):
+		// Another instance leads now and fills the order itself.
+		m.log.WarnContext(ctx, "order matcher lost leadership, fill skipped", slog.Int64("order_id", order.ID))
+		return false
+	case errors.Is(err, domain.ErrInsufficientBalance):
+		m.reject(ctx, order, reasonInsufficientBalance)
+		return false
+	case errors.Is(err, domain.ErrPositionNotFound):
+		m.reject(ctx, order, reasonNoPosition)
+		return false
+	case errors.Is(err, trade.ErrInsufficientLiquidity):
+		m.reject(ctx, order, reasonNoLiquidity)
+		return false
+	case errors.Is(err, trade.ErrPriceImpactTooHigh):
+		m.reject(ctx, order, reasonPriceImpact)
+		return false
+	default:
+		// Left open; the next price update retries it.
+		m.log.ErrorContext(ctx, "order matcher failed to fill order", slog.Int64("order_id", order.ID), slog.Any("error", err))
+		return false
+	}
+}
+
+func (m *Matcher) reject(ctx context.Context, order *domain.Order, reason string) {
+	cancelled, err := m.repo.Cancel(ctx, order.TelegramID, order.ID, reason)
+	if err != nil {
+		if !errors.Is(err, domain.ErrOrderNotOpen) {
+			m.log.ErrorContext(ctx, "order matcher failed to cancel order", slog.Int64("order_id", order.ID), slog.Any("error", err))
+		}
+		return
+	}
+
+	m.log.InfoContext(ctx, "limit order cancelled at fill", slog.Int64("order_id", order.ID), slog.String("reason", reason))
+	m.notify(ctx, cancelled.TelegramID, fmt.Sprintf("⚠️ Order #%d was cancelled (%s): %s", cancelled.ID, reason, Describe(cancelled)))
+}
+
+func (m *Matcher) notify(ctx context.Context, telegramID int64, text string) {
+	if m.notifier == nil {
+		return
+	}
+
+	if err := m.notifier.NotifyUser(ctx, telegramID, text); err != nil {
+		m.log.WarnContext(ctx, "order matcher failed to notify user", slog.Int64("telegram_id", telegramID), slog.Any("error", err))
+	}
+}
+
+// Describe renders an order such as "BUY $100.00 of PEPE at ≤ $0.0001".
+func Describe(order *domain.Order) string {
+	token := order.TokenSymbol
+	if token == "" {
+		token = order.TokenAddress
+	}
+
+	if order.Side == domain.TradeTypeSell {
+		return fmt.Sprintf("SELL %d%% of %s at ≥ $%s", order.Percent, token, order.LimitPriceUSD.StringTrimmed(12, money.RoundHalfUp))
+	}
+	return fmt.Sprintf("BUY $%s of %s at ≤ $%s", order.AmountUSD.StringFixed(2, money.RoundHalfUp), token, order.LimitPriceUSD.StringTrimmed(12, money.RoundHalfUp))
+}
+
+func filledMessage(order *domain.Order, executed *domain.Trade) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "✅ Order #%d filled: %s\n", order.ID, Describe(order))
+	fmt.Fprintf(&b, "Price: $%s\n", executed.PriceUSD.StringTrimmed(12, money.RoundHalfUp))
+	fmt.Fprintf(&b, "Amount: %s\n", executed.Amount.StringTrimmed(6, money.RoundDown))
+	fmt.Fprintf(&b, "Total: $%s (fee $%s)", executed.TotalUSD.StringFixed(2, money.RoundHalfUp), executed.FeeUSD.StringFixed(2, money.RoundHalfUp))
+	if executed.Type == domain.TradeTypeSell {
+		pnl := executed.PnLUSD
+		sign := "+"
+		if pnl.Sign() < 0 {
+			sign, pnl = "-", pnl.Neg()
+		}
+		fmt.Fprintf(&b, "\nPnL: %s$%s", sign, pnl.StringFixed(2, money.RoundHalfUp))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,324 @@
+package orders
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/leader"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type fakeOrderRepo struct {
+	repository.OrderRepository
+
+	open      []*domain.Order
+	position  *domain.Position
+	fillErr   error
+	filled    []*domain.Trade
+	cancelled map[int64]string
+}
+
+func (r *fakeOrderRepo) ListOpenByToken(_ context.Context, tokenAddress string) ([]*domain.Order, error) {
+	var orders []*domain.Order
+	for _, order := range r.open {
+		if order.TokenAddress == tokenAddress && order.Status == domain.OrderStatusOpen {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *fakeOrderRepo) Fill(_ context.Context, orderID int64, fill repository.OrderFill) (*domain.Order, *domain.Trade, error) {
+	order := r.find(orderID)
+	if order == nil || order.Status != domain.OrderStatusOpen {
+		return nil, nil, domain.ErrOrderNotOpen
+	}
+	if r.fillErr != nil {
+		return nil, nil, r.fillErr
+	}
+
+	var position *domain.Position
+	if order.Side == domain.TradeTypeSell {
+		position = r.position
+	}
+	trade, err := fill(order, position)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	trade.ID = int64(len(r.filled) + 1)
+	r.filled = append(r.filled, trade)
+	order.Status = domain.OrderStatusFilled
+	order.TransactionID = trade.ID
+
+	return order, trade, nil
+}
+
+func (r *fakeOrderRepo) Cancel(_ context.Context, userID, orderID int64, reason string) (*domain.Order, error) {
+	order := r.find(orderID)
+	if order == nil || order.TelegramID != userID {
+		return nil, domain.ErrOrderNotFound
+	}
+	if order.Status != domain.OrderStatusOpen {
+		return nil, domain.ErrOrderNotOpen
+	}
+
+	order.Status = domain.OrderStatusCancelled
+	order.CloseReason = reason
+	if r.cancelled == nil {
+		r.cancelled = make(map[int64]string)
+	}
+	r.cancelled[orderID] = reason
+
+	return order, nil
+}
+
+func (r *fakeOrderRepo) ExpireDue(_ context.Context, now time.Time) ([]*domain.Order, error) {
+	var expired []*domain.Order
+	for _, order := range r.open {
+		if order.Status == domain.OrderStatusOpen && order.Expired(now) {
+			order.Status = domain.OrderStatusExpired
+			expired = append(expired, order)
+		}
+	}
+	return expired, nil
+}
+
+func (r *fakeOrderRepo) find(orderID int64) *domain.Order {
+	for _, order := range r.open {
+		if order.ID == orderID {
+			return order
+		}
+	}
+	return nil
+}
+
+// fakeTrades confirms a fixed price against pools of $200,000 and fills
+// through an execution model charging 0.3% and capping impact at 10%.
+type fakeTrades struct {
+	Trades
+
+	price     money.Decimal
+	execution *trade.Service
+}
+
+func newFakeTrades(t *testing.T, price string) *fakeTrades {
+	t.Helper()
+
+	model, err := trade.NewExecutionModel([]trade.FeeTier{{MinUSD: money.Zero, FeeBps: 30}}, 1000)
+	if err != nil {
+		t.Fatalf("NewExecutionModel: %v", err)
+	}
+	return &fakeTrades{price: mustDecimal(t, price), execution: trade.NewService(nil, nil, nil, nil, nil, model, nil)}
+}
+
+func (t *fakeTrades) LatestMarket(context.Context, string) (trade.Market, error) {
+	return trade.Market{PriceUSD: t.price, LiquidityUSD: money.NewFromInt(200000)}, nil
+}
+
+func (t *fakeTrades) BuyTrade(userID int64, token domain.Token, amountUSD money.Money, state trade.Market) (*domain.Trade, error) {
+	return t.execution.BuyTrade(userID, token, amountUSD, state)
+}
+
+func (t *fakeTrades) SellTrade(position *domain.Position, percent int, state trade.Market) (*domain.Trade, error) {
+	return t.execution.SellTrade(position, percent, state)
+}
+
+type fakeNotifier struct {
+	messages map[int64][]string
+}
+
+func (n *fakeNotifier) NotifyUser(_ context.Context, telegramID int64, text string) error {
+	if n.messages == nil {
+		n.messages = make(map[int64][]string)
+	}
+	n.messages[telegramID] = append(n.messages[telegramID], text)
+	return nil
+}
+
+type fakeLeader struct {
+	err error
+}
+
+func (l fakeLeader) Verify(context.Context) error {
+	return l.err
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func mustDecimal(t *testing.T, value string) money.Decimal {
+	t.Helper()
+
+	d, err := money.Parse(value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return d
+}
+
+func mustUSD(t *testing.T, value string) money.Money {
+	t.Helper()
+
+	m, err := money.ParseMoney(value, money.USD)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return m
+}
+
+func TestMatcherFillsCrossedOrders(t *testing.T) {
+	const token = "So11111111111111111111111111111111111111112"
+
+	repo := &fakeOrderRepo{
+		open: []*domain.Order{
+			{ID: 1, TelegramID: 10, Side: domain.TradeTypeBuy, TokenAddress: token, TokenSymbol: "SOL", LimitPriceUSD: mustDecimal(t, "2"), AmountUSD: mustUSD(t, "100"), Status: domain.OrderStatusOpen},
+			{ID: 2, TelegramID: 11, Side: domain.TradeTypeBuy, TokenAddress: token, LimitPriceUSD: mustDecimal(t, "1"), AmountUSD: mustUSD(t, "50"), Status: domain.OrderStatusOpen},
+			{ID: 3, TelegramID: 12, Side: domain.TradeTypeSell, TokenAddress: token, LimitPriceUSD: mustDecimal(t, "1.5"), Percent: 50, Status: domain.OrderStatusOpen},
+		},
+		position: &domain.Position{ID: 7, TelegramID: 12, TokenAddress: token, Amount: mustDecimal(t, "10"), AvgPriceUSD: mustDecimal(t, "1")},
+	}
+	notifier := &fakeNotifier{}
+	matcher := NewMatcher(repo, newFakeTrades(t, "1.6"), nil, notifier, nil, discardLogger())
+
+	filled := matcher.Match(context.Background(), &domain.PriceQuote{TokenAddress: token, PriceUSD: mustDecimal(t, "1.6")})
+	if filled != 2 {
+		t.Fatalf("filled = %d, want 2", filled)
+	}
+
+	if repo.open[0].Status != domain.OrderStatusFilled || repo.open[2].Status != domain.OrderStatusFilled {
+		t.Errorf("crossed orders not filled: %s, %s", repo.open[0].Status, repo.open[2].Status)
+	}
+	if repo.open[1].Status != domain.OrderStatusOpen {
+		t.Errorf("buy below the price was filled: %s", repo.open[1].Status)
+	}
+
+	// Both fills pay the fee and move the price against the order.
+	buy, sell := repo.filled[0], repo.filled[1]
+	if buy.Type != domain.TradeTypeBuy || !buy.PriceUSD.Equal(mustDecimal(t, "1.6015952")) || buy.FeeUSD.StringFixed(2, money.RoundHalfUp) != "0.30" {
+		t.Errorf("unexpected buy trade: %+v", buy)
+	}
+	if sell.Type != domain.TradeTypeSell || !sell.Amount.Equal(mustDecimal(t, "5")) || sell.PriceUSD.Cmp(mustDecimal(t, "1.6")) >= 0 || sell.FeeUSD.Sign() <= 0 {
+		t.Errorf("unexpected sell trade: %+v", sell)
+	}
+
+	if msgs := notifier.messages[10]; len(msgs) != 1 || !strings.Contains(msgs[0], "Order #1 filled") {
+		t.Errorf("buyer notifications = %q", msgs)
+	}
+	if msgs := notifier.messages[12]; len(msgs) != 1 || !strings.Contains(msgs[0], "PnL: +$2.98") {
+		t.Errorf("seller notifications = %q", msgs)
+	}
+	if len(notifier.messages[11]) != 0 {
+		t.Errorf("unfilled order owner was notified: %q", notifier.messages[11])
+	}
+}
+
+func TestMatcherConfirmsPriceBeforeFilling(t *testing.T) {
+	repo := &fakeOrderRepo{
+		open: []*domain.Order{
+			{ID: 1, TelegramID: 10, Side: domain.TradeTypeBuy, TokenAddress: "token", LimitPriceUSD: mustDecimal(t, "2"), AmountUSD: mustUSD(t, "100"), Status: domain.OrderStatusOpen},
+		},
+	}
+	matcher := NewMatcher(repo, newFakeTrades(t, "2.1"), nil, nil, nil, discardLogger())
+
+	if filled := matcher.Match(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1.9")}); filled != 0 {
+		t.Fatalf("filled = %d, want 0", filled)
+	}
+	if repo.open[0].Status != domain.OrderStatusOpen {
+		t.Errorf("status = %s, want open", repo.open[0].Status)
+	}
+}
+
+func TestMatcherCancelsUnfillableOrders(t *testing.T) {
+	repo := &fakeOrderRepo{
+		open: []*domain.Order{
+			{ID: 1, TelegramID: 10, Side: domain.TradeTypeBuy, TokenAddress: "token", LimitPriceUSD: mustDecimal(t, "2"), AmountUSD: mustUSD(t, "100"), Status: domain.OrderStatusOpen},
+		},
+		fillErr: domain.ErrInsufficientBalance,
+	}
+	notifier := &fakeNotifier{}
+	matcher := NewMatcher(repo, newFakeTrades(t, "1"), nil, notifier, nil, discardLogger())
+
+	if filled := matcher.Match(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1")}); filled != 0 {
+		t.Fatalf("filled = %d, want 0", filled)
+	}
+	if reason := repo.cancelled[1]; reason != reasonInsufficientBalance {
+		t.Errorf("cancel reason = %q, want %q", reason, reasonInsufficientBalance)
+	}
+	if msgs := notifier.messages[10]; len(msgs) != 1 || !strings.Contains(msgs[0], "cancelled (insufficient balance)") {
+		t.Errorf("notifications = %q", msgs)
+	}
+}
+
+func TestMatcherCancelsOrdersThePoolsCannotAbsorb(t *testing.T) {
+	repo := &fakeOrderRepo{
+		open: []*domain.Order{
+			{ID: 1, TelegramID: 10, Side: domain.TradeTypeBuy, TokenAddress: "token", LimitPriceUSD: mustDecimal(t, "2"), AmountUSD: mustUSD(t, "20000"), Status: domain.OrderStatusOpen},
+		},
+	}
+	notifier := &fakeNotifier{}
+	matcher := NewMatcher(repo, newFakeTrades(t, "1"), nil, notifier, nil, discardLogger())
+
+	if filled := matcher.Match(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1")}); filled != 0 {
+		t.Fatalf("filled = %d, want 0", filled)
+	}
+	if reason := repo.cancelled[1]; reason != reasonPriceImpact {
+		t.Errorf("cancel reason = %q, want %q", reason, reasonPriceImpact)
+	}
+	if msgs := notifier.messages[10]; len(msgs) != 1 || !strings.Contains(msgs[0], "cancelled (price impact too high)") {
+		t.Errorf("notifications = %q", msgs)
+	}
+}
+
+func TestMatcherExpiresDueOrders(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	repo := &fakeOrderRepo{
+		open: []*domain.Order{
+			{ID: 1, TelegramID: 10, Side: domain.TradeTypeBuy, TokenAddress: "token", LimitPriceUSD: mustDecimal(t, "2"), AmountUSD: mustUSD(t, "100"), Status: domain.OrderStatusOpen, ExpiresAt: &past},
+			{ID: 2, TelegramID: 10, Side: domain.TradeTypeBuy, TokenAddress: "token", LimitPriceUSD: mustDecimal(t, "2"), AmountUSD: mustUSD(t, "100"), Status: domain.OrderStatusOpen, ExpiresAt: &future},
+		},
+	}
+	notifier := &fakeNotifier{}
+	matcher := NewMatcher(repo, newFakeTrades(t, "1"), nil, notifier, nil, discardLogger())
+	matcher.now = func() time.Time { return now }
+
+	if expired := matcher.ExpireDue(context.Background()); expired != 1 {
+		t.Fatalf("expired = %d, want 1", expired)
+	}
+	if repo.open[0].Status != domain.OrderStatusExpired || repo.open[1].Status != domain.OrderStatusOpen {
+		t.Errorf("statuses = %s, %s", repo.open[0].Status, repo.open[1].Status)
+	}
+	if msgs := notifier.messages[10]; len(msgs) != 1 || !strings.Contains(msgs[0], "Order #1 expired") {
+		t.Errorf("notifications = %q", msgs)
+	}
+}
+
+func TestMatcherSkipsFillsAfterLosingLeadership(t *testing.T) {
+	repo := &fakeOrderRepo{
+		open: []*domain.Order{
+			{ID: 1, TelegramID: 10, Side: domain.TradeTypeBuy, TokenAddress: "token", LimitPriceUSD: mustDecimal(t, "2"), AmountUSD: mustUSD(t, "100"), Status: domain.OrderStatusOpen},
+		},
+	}
+	notifier := &fakeNotifier{}
+	matcher := NewMatcher(repo, newFakeTrades(t, "1"), nil, notifier, fakeLeader{err: leader.ErrNotLeader}, discardLogger())
+
+	if filled := matcher.Match(context.Background(), &domain.PriceQuote{TokenAddress: "token", PriceUSD: mustDecimal(t, "1")}); filled != 0 {
+		t.Fatalf("filled = %d, want 0", filled)
+	}
+	if repo.open[0].Status != domain.OrderStatusOpen || len(repo.filled) != 0 || len(repo.cancelled) != 0 {
+		t.Errorf("order touched by a former leader: %s", repo.open[0].Status)
+	}
+	if len(notifier.messages) != 0 {
+		t.Errorf("notifications = %q", notifier.messages)
+	}
+}
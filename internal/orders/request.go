@@ -0,0 +1,118 @@
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// MaxTTL bounds how long an expiring order may stay open.
+const MaxTTL = 30 * 24 * time.Hour
+
+// ErrInvalidRequest indicates that the order arguments could not be parsed.
+var ErrInvalidRequest = errors.New("invalid order request")
+
+// Request is a parsed /limit command. Token is the raw user input and is
+// resolved to an address by the Service. A zero TTL means good till cancelled.
+type Request struct {
+	Side          domain.TradeType
+	Token         string
+	AmountUSD     money.Money
+	Percent       int
+	LimitPriceUSD money.Decimal
+	TTL           time.Duration
+}
+
+// ParseRequest parses /limit arguments:
+//
+//	buy <token> <usd amount> <limit price> [ttl]
+//	sell <token> <percent> <limit price> [ttl]
+//
+// The ttl is a duration such as 90m, 12h or 7d; without it the order is good
+// till cancelled.
+func ParseRequest(args []string) (Request, error) {
+	if len(args) != 4 && len(args) != 5 {
+		return Request{}, fmt.Errorf("%w: expected 4 or 5 arguments, got %d", ErrInvalidRequest, len(args))
+	}
+
+	request := Request{
+		Side:  domain.TradeType(strings.ToLower(args[0])),
+		Token: strings.TrimSpace(args[1]),
+	}
+
+	switch request.Side {
+	case domain.TradeTypeBuy:
+		amount, err := trade.ParseAmountUSD(args[2])
+		if err != nil {
+			return Request{}, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		request.AmountUSD = amount
+	case domain.TradeTypeSell:
+		percent, err := trade.ParsePercent(args[2])
+		if err != nil {
+			return Request{}, fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+		}
+		request.Percent = percent
+	default:
+		return Request{}, fmt.Errorf("%w: side must be buy or sell", ErrInvalidRequest)
+	}
+
+	price, err := parsePrice(args[3])
+	if err != nil {
+		return Request{}, err
+	}
+	request.LimitPriceUSD = price
+
+	if len(args) == 5 {
+		ttl, err := parseTTL(args[4])
+		if err != nil {
+			return Request{}, err
+		}
+		request.TTL = ttl
+	}
+
+	return request, nil
+}
+
+func parsePrice(input string) (money.Decimal, error) {
+	cleaned := strings.TrimPrefix(strings.TrimSpace(input), "$")
+	cleaned = strings.ReplaceAll(cleaned, ",", ".")
+
+	price, err := money.Parse(cleaned)
+	if err != nil || price.Sign() <= 0 {
+		return money.Zero, fmt.Errorf("%w: bad limit price %q", ErrInvalidRequest, input)
+	}
+	if !price.Round(domain.PricePrecision, money.RoundHalfEven).Equal(price) {
+		return money.Zero, fmt.Errorf("%w: limit price %q has more than %d decimals", ErrInvalidRequest, input, domain.PricePrecision)
+	}
+
+	return price, nil
+}
+
+func parseTTL(input string) (time.Duration, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	var (
+		ttl time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(input, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		ttl, err = time.ParseDuration(input)
+	}
+
+	if err != nil || ttl < time.Minute || ttl > MaxTTL {
+		return 0, fmt.Errorf("%w: expiry must be between 1m and 30d, got %q", ErrInvalidRequest, input)
+	}
+
+	return ttl, nil
+}
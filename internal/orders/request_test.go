@@ -0,0 +1,59 @@
+package orders
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func TestParseRequest(t *testing.T) {
+	buy, err := ParseRequest([]string{"BUY", "pepe", "$100", "0,0000012"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buy.Side != domain.TradeTypeBuy || buy.Token != "pepe" || buy.TTL != 0 {
+		t.Fatalf("unexpected buy request: %+v", buy)
+	}
+	if buy.AmountUSD.StringFixed(2, money.RoundHalfEven) != "100.00" || buy.LimitPriceUSD.String() != "0.0000012" {
+		t.Errorf("buy amount = %s, price = %s", buy.AmountUSD, buy.LimitPriceUSD)
+	}
+
+	sell, err := ParseRequest([]string{"sell", "bonk", "50%", "0.00003", "7d"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sell.Side != domain.TradeTypeSell || sell.Percent != 50 || sell.TTL != 7*24*time.Hour {
+		t.Errorf("unexpected sell request: %+v", sell)
+	}
+
+	minutes, err := ParseRequest([]string{"buy", "wif", "10", "2", "90m"})
+	if err != nil || minutes.TTL != 90*time.Minute {
+		t.Errorf("ttl 90m = %s, %v", minutes.TTL, err)
+	}
+}
+
+func TestParseRequestErrors(t *testing.T) {
+	testCases := [][]string{
+		nil,
+		{"buy", "pepe", "100"},
+		{"hold", "pepe", "100", "1"},
+		{"buy", "pepe", "0", "1"},
+		{"sell", "pepe", "150", "1"},
+		{"buy", "pepe", "100", "-1"},
+		{"buy", "pepe", "100", "0"},
+		{"buy", "pepe", "100", "0.0000000000000000001"},
+		{"buy", "pepe", "100", "1", "30s"},
+		{"buy", "pepe", "100", "1", "31d"},
+		{"buy", "pepe", "100", "1", "soon"},
+		{"buy", "pepe", "100", "1", "1d", "extra"},
+	}
+
+	for _, args := range testCases {
+		if _, err := ParseRequest(args); !errors.Is(err, ErrInvalidRequest) {
+			t.Errorf("ParseRequest(%q) error = %v, want ErrInvalidRequest", args, err)
+		}
+	}
+}
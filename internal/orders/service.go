@@ -0,0 +1,135 @@
+// Package orders places limit orders and fills them when the price crosses their limit.
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/internal/trade"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// MaxOpenOrders bounds how many open orders a user may hold at once.
+const MaxOpenOrders = 20
+
+// ErrTooManyOrders indicates that the user already holds MaxOpenOrders open orders.
+var ErrTooManyOrders = errors.New("too many open orders")
+
+// Trades is the subset of trade.Service used to place and fill orders.
+type Trades interface {
+	FindToken(ctx context.Context, query string) (*domain.Token, error)
+	ListPositions(ctx context.Context, userID int64) ([]*domain.Position, error)
+	LatestMarket(ctx context.Context, tokenAddress string) (trade.Market, error)
+	BuyTrade(userID int64, token domain.Token, amountUSD money.Money, state trade.Market) (*domain.Trade, error)
+	SellTrade(position *domain.Position, percent int, state trade.Market) (*domain.Trade, error)
+}
+
+// Service places, lists and cancels users' limit orders.
+type Service struct {
+	repo   repository.OrderRepository
+	trades Trades
+	log    *slog.Logger
+	now    func() time.Time
+}
+
+// NewService constructs an orders Service.
+func NewService(repo repository.OrderRepository, trades Trades, log *slog.Logger) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Service{repo: repo, trades: trades, log: log, now: time.Now}
+}
+
+// Place resolves the requested token and opens the order. Sell orders need an
+// open position in the token; whether it still covers the order, and whether
+// the balance covers a buy, is checked again when the order fills.
+func (s *Service) Place(ctx context.Context, userID int64, request Request) (*domain.Order, error) {
+	token, err := s.trades.FindToken(ctx, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Side == domain.TradeTypeSell {
+		positions, err := s.trades.ListPositions(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !holds(positions, token.Address) {
+			return nil, domain.ErrPositionNotFound
+		}
+	}
+
+	open, err := s.repo.ListOpenByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list open orders: %w", err)
+	}
+	if len(open) >= MaxOpenOrders {
+		return nil, ErrTooManyOrders
+	}
+
+	order := &domain.Order{
+		TelegramID:    userID,
+		Side:          request.Side,
+		TokenAddress:  token.Address,
+		TokenSymbol:   token.Symbol,
+		LimitPriceUSD: request.LimitPriceUSD,
+		AmountUSD:     request.AmountUSD,
+		Percent:       request.Percent,
+	}
+	if request.TTL > 0 {
+		expiresAt := s.now().Add(request.TTL).UTC()
+		order.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Create(ctx, order); err != nil {
+		return nil, fmt.Errorf("create order: %w", err)
+	}
+
+	s.log.Info("limit order placed",
+		slog.Int64("telegram_id", userID),
+		slog.Int64("order_id", order.ID),
+		slog.String("side", string(order.Side)),
+		slog.String("token_address", order.TokenAddress),
+		slog.String("limit_price", order.LimitPriceUSD.String()),
+	)
+
+	return order, nil
+}
+
+// List returns the user's open orders, oldest first.
+func (s *Service) List(ctx context.Context, userID int64) ([]*domain.Order, error) {
+	orders, err := s.repo.ListOpenByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list open orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+// Cancel withdraws an open order of the user. It returns domain.ErrOrderNotFound
+// or domain.ErrOrderNotOpen when there is nothing to cancel.
+func (s *Service) Cancel(ctx context.Context, userID, orderID int64) (*domain.Order, error) {
+	order, err := s.repo.Cancel(ctx, userID, orderID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Info("limit order cancelled", slog.Int64("telegram_id", userID), slog.Int64("order_id", orderID))
+
+	return order, nil
+}
+
+func holds(positions []*domain.Position, tokenAddress string) bool {
+	for _, position := range positions {
+		if position.TokenAddress == tokenAddress {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+// Package portfolio values users' open positions against cached market prices.
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// percentScale is the number of fraction digits kept for P&L percentages.
+const percentScale = 2
+
+// PriceReader returns the latest cached quotes keyed by token address.
+// Tokens without a known price are omitted from the result.
+type PriceReader interface {
+	GetMany(ctx context.Context, tokenAddresses []string) (map[string]*domain.PriceQuote, error)
+}
+
+// Holding is a position valued at the latest cached price. PriceUSD,
+// ValueUSD, PnLUSD and PnLPercent are only meaningful when Priced reports true.
+type Holding struct {
+	Position   *domain.Position
+	PriceUSD   money.Decimal
+	CostUSD    money.Money
+	ValueUSD   money.Money
+	PnLUSD     money.Money
+	PnLPercent money.Decimal
+	priced     bool
+}
+
+// Priced reports whether the holding could be valued.
+func (h Holding) Priced() bool {
+	return h.priced
+}
+
+// Summary aggregates the user's holdings. Totals only include priced holdings.
+type Summary struct {
+	Holdings      []Holding
+	TotalCostUSD  money.Money
+	TotalValueUSD money.Money
+	TotalPnLUSD   money.Money
+	Unpriced      int
+}
+
+// TotalPnLPercent returns the unrealized return of priced holdings; ok is
+// false when nothing could be valued.
+func (s *Summary) TotalPnLPercent() (percent money.Decimal, ok bool) {
+	return percentOf(s.TotalPnLUSD, s.TotalCostUSD)
+}
+
+// Service computes portfolio valuations.
+type Service struct {
+	positions repository.PositionRepository
+	prices    PriceReader
+	log       *slog.Logger
+}
+
+// NewService constructs a portfolio Service.
+func NewService(positions repository.PositionRepository, prices PriceReader, log *slog.Logger) *Service {
+	return &Service{positions: positions, prices: prices, log: log}
+}
+
+// Summary loads the user's positions and values them. A price cache failure
+// degrades to an unpriced summary instead of failing the request.
+func (s *Service) Summary(ctx context.Context, userID int64) (*Summary, error) {
+	positions, err := s.positions.ListByUser(ctx, userID)
+	if err != nil {
+		s.logError("list_positions", userID, err)
+		return nil, fmt.Errorf("list positions: %w", err)
+	}
+
+	quotes := s.loadPrices(ctx, userID, positions)
+
+	summary, err := summarize(positions, quotes)
+	if err != nil {
+		s.logError("summarize", userID, err)
+		return nil, fmt.Errorf("summarize portfolio: %w", err)
+	}
+
+	return summary, nil
+}
+
+func (s *Service) loadPrices(ctx context.Context, userID int64, positions []*domain.Position) map[string]*domain.PriceQuote {
+	if s.prices == nil || len(positions) == 0 {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(positions))
+	seen := make(map[string]struct{}, len(positions))
+	for _, position := range positions {
+		if _, ok := seen[position.TokenAddress]; ok {
+			continue
+		}
+		seen[position.TokenAddress] = struct{}{}
+		addresses = append(addresses, position.TokenAddress)
+	}
+
+	quotes, err := s.prices.GetMany(ctx, addresses)
+	if err != nil {
+		s.logError("load_prices", userID, err)
+		return nil
+	}
+
+	return quotes
+}
+
+func summarize(positions []*domain.Position, quotes map[string]*domain.PriceQuote) (*Summary, error) {
+	summary := &Summary{
+		Holdings:      make([]Holding, 0, len(positions)),
+		TotalCostUSD:  money.ZeroOf(money.USD),
+		TotalValueUSD: money.ZeroOf(money.USD),
+		TotalPnLUSD:   money.ZeroOf(money.USD),
+	}
+
+	for _, position := range positions {
+		holding := Holding{
+			Position: position,
+			CostUSD:  money.NewMoney(position.Amount.Mul(position.AvgPriceUSD), money.USD, money.RoundHalfEven),
+		}
+
+		quote := quotes[position.TokenAddress]
+		if quote == nil || quote.PriceUSD.Sign() <= 0 {
+			summary.Unpriced++
+			summary.Holdings = append(summary.Holdings, holding)
+			continue
+		}
+
+		holding.priced = true
+		holding.PriceUSD = quote.PriceUSD
+		holding.ValueUSD = money.NewMoney(position.Amount.Mul(quote.PriceUSD), money.USD, money.RoundHalfEven)
+
+		var err error
+		if holding.PnLUSD, err = holding.ValueUSD.Sub(holding.CostUSD); err != nil {
+			return nil, err
+		}
+		holding.PnLPercent, _ = percentOf(holding.PnLUSD, holding.CostUSD)
+
+		if summary.TotalCostUSD, err = summary.TotalCostUSD.Add(holding.CostUSD); err != nil {
+			return nil, err
+		}
+		if summary.TotalValueUSD, err = summary.TotalValueUSD.Add(holding.ValueUSD); err != nil {
+			return nil, err
+		}
+		if summary.TotalPnLUSD, err = summary.TotalPnLUSD.Add(holding.PnLUSD); err != nil {
+			return nil, err
+		}
+		summary.Holdings = append(summary.Holdings, holding)
+	}
+
+	return summary, nil
+}
+
+func percentOf(part, whole money.Money) (money.Decimal, bool) {
+	percent, err := part.Amount().Mul(money.NewFromInt(100)).Quo(whole.Amount(), percentScale, money.RoundHalfEven)
+	if err != nil {
+		return money.Zero, false
+	}
+	return percent, true
+}
+
+func (s *Service) logError(operation string, telegramID int64, err error) {
+	if s == nil || s.log == nil || err == nil {
+		return
+	}
+
+	s.log.Error("portfolio service operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", telegramID),
+		slog.Any("error", err),
+	)
+}
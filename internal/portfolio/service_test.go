@@ -0,0 +1,109 @@
+package portfolio
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type stubPositions struct {
+	positions []*domain.Position
+	err       error
+}
+
+func (s *stubPositions) ListByUser(context.Context, int64) ([]*domain.Position, error) {
+	return s.positions, s.err
+}
+
+func (s *stubPositions) GetByID(context.Context, int64, int64) (*domain.Position, error) {
+	return nil, domain.ErrPositionNotFound
+}
+
+func (s *stubPositions) ListTokenAddresses(context.Context) ([]string, error) {
+	return nil, nil
+}
+
+type stubPrices struct {
+	quotes map[string]*domain.PriceQuote
+	err    error
+	calls  int
+}
+
+func (s *stubPrices) GetMany(context.Context, []string) (map[string]*domain.PriceQuote, error) {
+	s.calls++
+	return s.quotes, s.err
+}
+
+func TestServiceSummary(t *testing.T) {
+	positions := []*domain.Position{
+		{ID: 1, TokenAddress: "0xaaa", Amount: money.NewFromInt(10), AvgPriceUSD: money.NewFromInt(2)},
+		{ID: 2, TokenAddress: "0xbbb", Amount: money.NewFromInt(4), AvgPriceUSD: money.NewFromInt(5)},
+		{ID: 3, TokenAddress: "0xccc", Amount: money.NewFromInt(1), AvgPriceUSD: money.NewFromInt(1)},
+	}
+	prices := &stubPrices{quotes: map[string]*domain.PriceQuote{
+		"0xaaa": {TokenAddress: "0xaaa", PriceUSD: money.NewFromInt(3)},
+		"0xbbb": {TokenAddress: "0xbbb", PriceUSD: money.NewFromInt(4)},
+	}}
+
+	svc := NewService(&stubPositions{positions: positions}, prices, nil)
+	summary, err := svc.Summary(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if prices.calls != 1 {
+		t.Fatalf("expected prices to be loaded in one call, got %d", prices.calls)
+	}
+	if len(summary.Holdings) != 3 || summary.Unpriced != 1 {
+		t.Fatalf("unexpected holdings: %d holdings, %d unpriced", len(summary.Holdings), summary.Unpriced)
+	}
+	if summary.Holdings[2].Priced() {
+		t.Fatalf("expected third holding to be unpriced")
+	}
+
+	totalPercent, ok := summary.TotalPnLPercent()
+	if !ok {
+		t.Fatalf("expected total pnl percent")
+	}
+
+	assertDecimal(t, "holding pnl", summary.Holdings[0].PnLUSD.Amount(), "10.00")
+	assertDecimal(t, "holding pnl %", summary.Holdings[0].PnLPercent, "50.00")
+	assertDecimal(t, "total cost", summary.TotalCostUSD.Amount(), "40.00")
+	assertDecimal(t, "total value", summary.TotalValueUSD.Amount(), "46.00")
+	assertDecimal(t, "total pnl", summary.TotalPnLUSD.Amount(), "6.00")
+	assertDecimal(t, "total pnl %", totalPercent, "15.00")
+}
+
+func TestServiceSummaryPriceFailure(t *testing.T) {
+	positions := []*domain.Position{
+		{ID: 1, TokenAddress: "0xaaa", Amount: money.NewFromInt(1), AvgPriceUSD: money.NewFromInt(1)},
+	}
+
+	svc := NewService(&stubPositions{positions: positions}, &stubPrices{err: errors.New("redis down")}, nil)
+	summary, err := svc.Summary(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := summary.TotalPnLPercent(); summary.Unpriced != 1 || ok {
+		t.Fatalf("expected an unpriced summary, got %+v", summary)
+	}
+}
+
+func TestServiceSummaryRepositoryError(t *testing.T) {
+	svc := NewService(&stubPositions{err: errors.New("db down")}, &stubPrices{}, nil)
+	if _, err := svc.Summary(context.Background(), 1); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func assertDecimal(t *testing.T, name string, got money.Decimal, want string) {
+	t.Helper()
+
+	if formatted := got.StringFixed(2, money.RoundHalfEven); formatted != want {
+		t.Errorf("%s = %s, want %s", name, formatted, want)
+	}
+}
@@ -0,0 +1,174 @@
+// Package pricecache stores the latest known token prices in Redis.
+package pricecache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// UpdatesChannel is the Redis pub/sub channel that announces every cached price.
+const UpdatesChannel = "prices:updates"
+
+type cachedQuote struct {
+	PriceUSD   money.Decimal `json:"price_usd"`
+	Source     string        `json:"source"`
+	Sources    []string      `json:"sources,omitempty"`
+	FetchedAt  time.Time     `json:"fetched_at"`
+	TTLSeconds int64         `json:"ttl_seconds"`
+}
+
+// priceEvent is the payload published on UpdatesChannel.
+type priceEvent struct {
+	TokenAddress string `json:"token_address"`
+	cachedQuote
+}
+
+// Cache provides Redis-backed caching for token price quotes.
+type Cache struct {
+	client     *redis.Client
+	staleAfter time.Duration
+	now        func() time.Time
+}
+
+// NewCache constructs a price cache backed by the provided Redis client.
+// Get reports quotes fetched more than staleAfter ago as stale; zero disables the check.
+func NewCache(client *redis.Client, staleAfter time.Duration) *Cache {
+	return &Cache{client: client, staleAfter: staleAfter, now: time.Now}
+}
+
+// Get fetches the cached quote for a token, returning nil when it is missing.
+// A quote older than the staleness threshold is returned together with a
+// *market.StalePriceError so callers can still show it but refuse to trade on it.
+func (c *Cache) Get(ctx context.Context, tokenAddress string) (*domain.PriceQuote, error) {
+	quotes, err := c.GetMany(ctx, []string{tokenAddress})
+	if err != nil {
+		return nil, err
+	}
+
+	quote := quotes[normalizeAddress(tokenAddress)]
+	if quote == nil {
+		return nil, nil
+	}
+
+	if age := c.now().Sub(quote.FetchedAt); c.staleAfter > 0 && age > c.staleAfter {
+		return quote, &market.StalePriceError{
+			TokenAddress: quote.TokenAddress,
+			FetchedAt:    quote.FetchedAt,
+			Age:          age,
+		}
+	}
+
+	return quote, nil
+}
+
+// GetMany fetches cached quotes for several tokens in a single round trip.
+// The result is keyed by token address and omits tokens without a cached price.
+// Staleness is not checked; callers that care compare FetchedAt themselves.
+func (c *Cache) GetMany(ctx context.Context, tokenAddresses []string) (map[string]*domain.PriceQuote, error) {
+	quotes := make(map[string]*domain.PriceQuote, len(tokenAddresses))
+	if c == nil || c.client == nil || len(tokenAddresses) == 0 {
+		return quotes, nil
+	}
+
+	keys := make([]string, len(tokenAddresses))
+	for i, address := range tokenAddresses {
+		keys[i] = cacheKey(address)
+	}
+
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return quotes, nil
+		}
+		return nil, fmt.Errorf("get cached prices: %w", err)
+	}
+
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		quote, err := decodeQuote(tokenAddresses[i], raw)
+		if err != nil {
+			return nil, err
+		}
+		quotes[normalizeAddress(tokenAddresses[i])] = quote
+	}
+
+	return quotes, nil
+}
+
+// Set stores the quote for the provided TTL and publishes it on UpdatesChannel.
+// Both commands run in one MULTI/EXEC so subscribers never see a price the cache lacks.
+func (c *Cache) Set(ctx context.Context, quote *domain.PriceQuote, ttl time.Duration) error {
+	if c == nil || c.client == nil || quote == nil {
+		return nil
+	}
+
+	cached := cachedQuote{
+		PriceUSD:   quote.PriceUSD.Round(domain.PricePrecision, money.RoundHalfEven),
+		Source:     quote.Source,
+		Sources:    quote.Sources,
+		FetchedAt:  quote.FetchedAt.UTC(),
+		TTLSeconds: int64(ttl / time.Second),
+	}
+
+	payload, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("encode price for cache: %w", err)
+	}
+
+	event, err := json.Marshal(priceEvent{TokenAddress: normalizeAddress(quote.TokenAddress), cachedQuote: cached})
+	if err != nil {
+		return fmt.Errorf("encode price event: %w", err)
+	}
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, cacheKey(quote.TokenAddress), payload, ttl)
+		pipe.Publish(ctx, UpdatesChannel, event)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("set cached price: %w", err)
+	}
+
+	return nil
+}
+
+func decodeQuote(tokenAddress, raw string) (*domain.PriceQuote, error) {
+	var cached cachedQuote
+	if err := json.Unmarshal([]byte(raw), &cached); err != nil {
+		return nil, fmt.Errorf("decode cached price: %w", err)
+	}
+
+	return cached.toQuote(tokenAddress), nil
+}
+
+func (q cachedQuote) toQuote(tokenAddress string) *domain.PriceQuote {
+	return &domain.PriceQuote{
+		TokenAddress: tokenAddress,
+		PriceUSD:     q.PriceUSD,
+		Source:       q.Source,
+		Sources:      q.Sources,
+		FetchedAt:    q.FetchedAt,
+	}
+}
+
+func normalizeAddress(tokenAddress string) string {
+	return strings.TrimSpace(tokenAddress)
+}
+
+func cacheKey(tokenAddress string) string {
+	return "price:" + normalizeAddress(tokenAddress)
+}
@@ -0,0 +1,140 @@
+package pricecache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func newTestCache(t *testing.T, staleAfter time.Duration) (*Cache, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewCache(client, staleAfter), server
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	cache, server := newTestCache(t, time.Minute)
+	ctx := context.Background()
+	fetchedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return fetchedAt.Add(30 * time.Second) }
+
+	err := cache.Set(ctx, &domain.PriceQuote{
+		TokenAddress: "0xaaa",
+		PriceUSD:     money.New(15, 1),
+		Source:       "aggregate",
+		Sources:      []string{"coingecko", "dexscreener"},
+		FetchedAt:    fetchedAt,
+	}, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ttl := server.TTL("price:0xaaa"); ttl != time.Hour {
+		t.Fatalf("expected one hour TTL, got %s", ttl)
+	}
+
+	quote, err := cache.Get(ctx, "0xaaa")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !quote.PriceUSD.Equal(money.New(15, 1)) || !quote.FetchedAt.Equal(fetchedAt) {
+		t.Fatalf("unexpected quote: %+v", quote)
+	}
+	if len(quote.Sources) != 2 || quote.Sources[0] != "coingecko" {
+		t.Fatalf("expected sources to round-trip, got %v", quote.Sources)
+	}
+
+	missing, err := cache.Get(ctx, "0xbbb")
+	if err != nil || missing != nil {
+		t.Fatalf("expected a miss, got %+v, %v", missing, err)
+	}
+}
+
+func TestCacheGetStale(t *testing.T) {
+	cache, _ := newTestCache(t, time.Minute)
+	ctx := context.Background()
+	fetchedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return fetchedAt.Add(5 * time.Minute) }
+
+	if err := cache.Set(ctx, &domain.PriceQuote{TokenAddress: "0xaaa", PriceUSD: money.NewFromInt(2), FetchedAt: fetchedAt}, time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	quote, err := cache.Get(ctx, "0xaaa")
+	if !errors.Is(err, market.ErrStalePrice) {
+		t.Fatalf("expected ErrStalePrice, got %v", err)
+	}
+
+	var stale *market.StalePriceError
+	if !errors.As(err, &stale) || stale.Age != 5*time.Minute {
+		t.Fatalf("expected a five minute old price, got %v", err)
+	}
+	if quote == nil || !quote.PriceUSD.Equal(money.NewFromInt(2)) {
+		t.Fatalf("expected the stale quote alongside the error, got %+v", quote)
+	}
+
+	quotes, err := cache.GetMany(ctx, []string{"0xaaa"})
+	if err != nil || quotes["0xaaa"] == nil {
+		t.Fatalf("expected GetMany to ignore staleness, got %v, %v", quotes, err)
+	}
+}
+
+func TestSubscriberFanOut(t *testing.T) {
+	cache, server := newTestCache(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscriber := NewSubscriber(cache.client, nil)
+	first := make(chan *domain.PriceQuote, 1)
+	second := make(chan *domain.PriceQuote, 1)
+	subscriber.Subscribe(func(_ context.Context, quote *domain.PriceQuote) { first <- quote })
+	unsubscribe := subscriber.Subscribe(func(_ context.Context, quote *domain.PriceQuote) { second <- quote })
+	unsubscribe()
+
+	done := make(chan error, 1)
+	go func() { done <- subscriber.Run(ctx) }()
+
+	deadline := time.Now().Add(time.Second)
+	for server.PubSubNumSub(UpdatesChannel)[UpdatesChannel] == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber did not subscribe")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := cache.Set(ctx, &domain.PriceQuote{TokenAddress: "0xaaa", PriceUSD: money.NewFromInt(3), Source: "dexscreener", FetchedAt: time.Now()}, time.Hour); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case quote := <-first:
+		if quote.TokenAddress != "0xaaa" || !quote.PriceUSD.Equal(money.NewFromInt(3)) || quote.Source != "dexscreener" {
+			t.Fatalf("unexpected quote: %+v", quote)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("listener was not notified")
+	}
+
+	select {
+	case quote := <-second:
+		t.Fatalf("unsubscribed listener received %+v", quote)
+	default:
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected run error: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package pricecache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+// Queue buffers price updates for a consumer that is slower than the feed.
+// Updates are coalesced per token, so the consumer only sees the latest
+// quote of each token. Push is a non-blocking Listener.
+type Queue struct {
+	mu      sync.Mutex
+	pending map[string]*domain.PriceQuote
+	ready   chan struct{}
+}
+
+// NewQueue constructs an empty Queue.
+func NewQueue() *Queue {
+	return &Queue{
+		pending: make(map[string]*domain.PriceQuote),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// Push stores quote, replacing any pending quote of the same token.
+func (q *Queue) Push(_ context.Context, quote *domain.PriceQuote) {
+	if quote == nil {
+		return
+	}
+
+	q.mu.Lock()
+	q.pending[quote.TokenAddress] = quote
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready receives a value when quotes are pending.
+func (q *Queue) Ready() <-chan struct{} {
+	return q.ready
+}
+
+// Drain removes and returns every pending quote.
+func (q *Queue) Drain() []*domain.PriceQuote {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	quotes := make([]*domain.PriceQuote, 0, len(q.pending))
+	for address, quote := range q.pending {
+		quotes = append(quotes, quote)
+		delete(q.pending, address)
+	}
+
+	return quotes
+}
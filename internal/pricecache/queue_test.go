@@ -0,0 +1,37 @@
+package pricecache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func TestQueueCoalescesPerToken(t *testing.T) {
+	queue := NewQueue()
+	ctx := context.Background()
+
+	queue.Push(ctx, &domain.PriceQuote{TokenAddress: "a", PriceUSD: money.NewFromInt(1)})
+	queue.Push(ctx, &domain.PriceQuote{TokenAddress: "b", PriceUSD: money.NewFromInt(5)})
+	queue.Push(ctx, &domain.PriceQuote{TokenAddress: "a", PriceUSD: money.NewFromInt(2)})
+	queue.Push(ctx, nil)
+
+	select {
+	case <-queue.Ready():
+	default:
+		t.Fatal("queue is not ready after pushes")
+	}
+
+	latest := make(map[string]string)
+	for _, quote := range queue.Drain() {
+		latest[quote.TokenAddress] = quote.PriceUSD.String()
+	}
+	if len(latest) != 2 || latest["a"] != "2" || latest["b"] != "5" {
+		t.Errorf("drained = %v, want a=2 b=5", latest)
+	}
+
+	if quotes := queue.Drain(); len(quotes) != 0 {
+		t.Errorf("second drain = %d quotes, want 0", len(quotes))
+	}
+}
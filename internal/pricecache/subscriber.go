@@ -0,0 +1,108 @@
+package pricecache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+// Listener reacts to a freshly cached price. Listeners run on the
+// subscriber's goroutine and must not block.
+type Listener func(ctx context.Context, quote *domain.PriceQuote)
+
+// Subscriber fans price updates published on UpdatesChannel out to
+// in-process listeners, so every bot instance sees prices written by any worker.
+type Subscriber struct {
+	client *redis.Client
+	log    *slog.Logger
+
+	mu        sync.RWMutex
+	nextID    int
+	listeners map[int]Listener
+}
+
+// NewSubscriber constructs a Subscriber backed by the provided Redis client.
+func NewSubscriber(client *redis.Client, log *slog.Logger) *Subscriber {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Subscriber{
+		client:    client,
+		log:       log,
+		listeners: make(map[int]Listener),
+	}
+}
+
+// Subscribe registers a listener and returns a function that removes it.
+func (s *Subscriber) Subscribe(listener Listener) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.listeners[id] = listener
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.listeners, id)
+	}
+}
+
+// Run listens on UpdatesChannel and dispatches every update until ctx is cancelled.
+func (s *Subscriber) Run(ctx context.Context) error {
+	if s == nil || s.client == nil {
+		return nil
+	}
+
+	pubsub := s.client.Subscribe(ctx, UpdatesChannel)
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("subscribe to price updates: %w", err)
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			s.dispatch(ctx, message.Payload)
+		}
+	}
+}
+
+func (s *Subscriber) dispatch(ctx context.Context, payload string) {
+	var event priceEvent
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		s.log.WarnContext(ctx, "invalid price update event", slog.Any("error", err))
+		return
+	}
+
+	quote := event.cachedQuote.toQuote(event.TokenAddress)
+
+	s.mu.RLock()
+	listeners := make([]Listener, 0, len(s.listeners))
+	for _, listener := range s.listeners {
+		listeners = append(listeners, listener)
+	}
+	s.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(ctx, quote)
+	}
+}
@@ -0,0 +1,124 @@
+// Package pricehistory serves OHLCV candles built from recorded price ticks.
+package pricehistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// MaxCandles bounds the number of candles a single query may return.
+const MaxCandles = 1000
+
+var (
+	// ErrInvalidRange indicates that from is not before to or the interval is unsupported.
+	ErrInvalidRange = errors.New("invalid candle range")
+	// ErrRangeTooLarge indicates that the range spans more than MaxCandles buckets.
+	ErrRangeTooLarge = errors.New("candle range too large")
+)
+
+// Service answers candle queries.
+type Service struct {
+	repo repository.PriceHistoryRepository
+	log  *slog.Logger
+	now  func() time.Time
+}
+
+// NewService constructs a price history Service.
+func NewService(repo repository.PriceHistoryRepository, log *slog.Logger) *Service {
+	return &Service{repo: repo, log: log, now: time.Now}
+}
+
+// Candles returns one candle per interval bucket in [from, to), oldest first.
+// from is rounded down to the bucket start and to is capped at the current bucket.
+//
+// Buckets without ticks are filled with a flat candle at the previous close
+// (Filled is true, volume and ticks are zero). Buckets before the first known
+// price are omitted, so the result may start later than from.
+func (s *Service) Candles(ctx context.Context, tokenAddress string, interval domain.CandleInterval, from, to time.Time) ([]*domain.Candle, error) {
+	step := interval.Duration()
+	if step == 0 {
+		return nil, fmt.Errorf("%w: unsupported interval %q", ErrInvalidRange, interval)
+	}
+
+	from = interval.Truncate(from)
+	if limit := interval.Truncate(s.now()).Add(step); to.After(limit) {
+		to = limit
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidRange
+	}
+	if buckets := int64(to.Sub(from) / step); buckets > MaxCandles {
+		return nil, fmt.Errorf("%w: %d buckets, at most %d", ErrRangeTooLarge, buckets, MaxCandles)
+	}
+
+	stored, err := s.repo.Candles(ctx, tokenAddress, interval, from, to)
+	if err != nil {
+		s.logError("candles", tokenAddress, err)
+		return nil, fmt.Errorf("load candles: %w", err)
+	}
+
+	previous, err := s.repo.LastCandleBefore(ctx, tokenAddress, interval, from)
+	if err != nil {
+		s.logError("last_candle_before", tokenAddress, err)
+		return nil, fmt.Errorf("load previous candle: %w", err)
+	}
+
+	return fillGaps(stored, previous, interval, from, to), nil
+}
+
+// fillGaps walks every bucket in [from, to) and inserts flat candles where
+// stored has none, carrying the close of the latest known candle forward.
+func fillGaps(stored []*domain.Candle, previous *domain.Candle, interval domain.CandleInterval, from, to time.Time) []*domain.Candle {
+	step := interval.Duration()
+	candles := make([]*domain.Candle, 0, int(to.Sub(from)/step))
+
+	next := 0
+	for bucket := from; bucket.Before(to); bucket = bucket.Add(step) {
+		for next < len(stored) && stored[next].OpenTime.Before(bucket) {
+			next++
+		}
+		if next < len(stored) && stored[next].OpenTime.Equal(bucket) {
+			previous = stored[next]
+			candles = append(candles, previous)
+			next++
+			continue
+		}
+
+		if previous == nil {
+			continue
+		}
+
+		candles = append(candles, &domain.Candle{
+			TokenAddress: previous.TokenAddress,
+			Interval:     interval,
+			OpenTime:     bucket,
+			Open:         previous.Close,
+			High:         previous.Close,
+			Low:          previous.Close,
+			Close:        previous.Close,
+			VolumeUSD:    money.ZeroOf(money.USD),
+			Filled:       true,
+		})
+	}
+
+	return candles
+}
+
+func (s *Service) logError(operation, tokenAddress string, err error) {
+	if s.log == nil {
+		return
+	}
+
+	s.log.Error("price history operation failed",
+		slog.String("operation", operation),
+		slog.String("token_address", tokenAddress),
+		slog.Any("error", err),
+	)
+}
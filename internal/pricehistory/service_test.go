@@ -0,0 +1,115 @@
+package pricehistory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type stubHistory struct {
+	repository.PriceHistoryRepository
+	candles  []*domain.Candle
+	previous *domain.Candle
+	from, to time.Time
+}
+
+func (s *stubHistory) Candles(_ context.Context, _ string, _ domain.CandleInterval, from, to time.Time) ([]*domain.Candle, error) {
+	s.from, s.to = from, to
+	return s.candles, nil
+}
+
+func (s *stubHistory) LastCandleBefore(context.Context, string, domain.CandleInterval, time.Time) (*domain.Candle, error) {
+	return s.previous, nil
+}
+
+func candleAt(openTime time.Time, close int64) *domain.Candle {
+	price := money.NewFromInt(close)
+	return &domain.Candle{
+		TokenAddress: "0xabc",
+		Interval:     domain.CandleInterval1h,
+		OpenTime:     openTime,
+		Open:         price,
+		High:         price,
+		Low:          price,
+		Close:        price,
+		VolumeUSD:    money.ZeroOf(money.USD),
+		Ticks:        1,
+	}
+}
+
+func newTestService(repo *stubHistory, now time.Time) *Service {
+	svc := NewService(repo, nil)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func TestCandlesFillsGaps(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := &stubHistory{
+		previous: candleAt(base.Add(-3*time.Hour), 5),
+		candles:  []*domain.Candle{candleAt(base.Add(time.Hour), 7), candleAt(base.Add(3*time.Hour), 9)},
+	}
+	svc := newTestService(repo, base.Add(24*time.Hour))
+
+	candles, err := svc.Candles(context.Background(), "0xabc", domain.CandleInterval1h, base.Add(20*time.Minute), base.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !repo.from.Equal(base) {
+		t.Fatalf("expected from to be rounded down to %s, got %s", base, repo.from)
+	}
+
+	wantClose := []int64{5, 7, 7, 9}
+	wantFilled := []bool{true, false, true, false}
+	if len(candles) != len(wantClose) {
+		t.Fatalf("expected %d candles, got %d", len(wantClose), len(candles))
+	}
+	for i, candle := range candles {
+		if !candle.OpenTime.Equal(base.Add(time.Duration(i) * time.Hour)) {
+			t.Errorf("candle %d opens at %s", i, candle.OpenTime)
+		}
+		if !candle.Close.Equal(money.NewFromInt(wantClose[i])) || candle.Filled != wantFilled[i] {
+			t.Errorf("candle %d: close %s filled %t, want %d filled %t", i, candle.Close, candle.Filled, wantClose[i], wantFilled[i])
+		}
+	}
+}
+
+func TestCandlesOmitsLeadingGapAndFuture(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := &stubHistory{candles: []*domain.Candle{candleAt(base.Add(2*time.Hour), 3)}}
+	svc := newTestService(repo, base.Add(3*time.Hour+10*time.Minute))
+
+	candles, err := svc.Candles(context.Background(), "0xabc", domain.CandleInterval1h, base, base.Add(48*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !repo.to.Equal(base.Add(4 * time.Hour)) {
+		t.Fatalf("expected to to be capped at the current bucket, got %s", repo.to)
+	}
+	if len(candles) != 2 || !candles[0].OpenTime.Equal(base.Add(2*time.Hour)) || !candles[1].Filled {
+		t.Fatalf("unexpected candles: %+v", candles)
+	}
+}
+
+func TestCandlesRejectsBadRanges(t *testing.T) {
+	base := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	svc := newTestService(&stubHistory{}, base.Add(time.Hour))
+	ctx := context.Background()
+
+	if _, err := svc.Candles(ctx, "0xabc", domain.CandleInterval1m, base, base.Add(-time.Minute)); !errors.Is(err, ErrInvalidRange) {
+		t.Fatalf("expected ErrInvalidRange, got %v", err)
+	}
+	if _, err := svc.Candles(ctx, "0xabc", "5m", base, base.Add(time.Hour)); !errors.Is(err, ErrInvalidRange) {
+		t.Fatalf("expected ErrInvalidRange for unsupported interval, got %v", err)
+	}
+	if _, err := svc.Candles(ctx, "0xabc", domain.CandleInterval1m, base.Add(-48*time.Hour), base); !errors.Is(err, ErrRangeTooLarge) {
+		t.Fatalf("expected ErrRangeTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+// AlertRepository persists price alerts and records when they fire.
+type AlertRepository interface {
+	Create(ctx context.Context, alert *domain.Alert) error
+	ListActiveByUser(ctx context.Context, userID int64) ([]*domain.Alert, error)
+	ListActiveByToken(ctx context.Context, tokenAddress string) ([]*domain.Alert, error)
+	Delete(ctx context.Context, userID, alertID int64) error
+	Rearm(ctx context.Context, alertID int64) error
+	Trigger(ctx context.Context, alertID int64, at time.Time) (bool, error)
+}
+
+const alertColumns = `id, telegram_id, token_address, token_symbol, kind, COALESCE(price_level, 0), COALESCE(percent, 0),
+		COALESCE(window_seconds, 0), repeating, cooldown_seconds, armed, status, trigger_count, last_triggered_at, created_at`
+
+type alertRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewAlertRepository creates a SQL-backed alert repository.
+func NewAlertRepository(db *sql.DB, log *slog.Logger) AlertRepository {
+	return &alertRepository{db: db, log: log}
+}
+
+// Create stores a new armed, active alert and populates alert.ID, Armed,
+// Status and CreatedAt.
+func (r *alertRepository) Create(ctx context.Context, alert *domain.Alert) error {
+	const query = `
+		INSERT INTO alerts (telegram_id, token_address, token_symbol, kind, price_level, percent, window_seconds, repeating, cooldown_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, armed, status, created_at
+	`
+
+	if alert == nil {
+		return errors.New("alert is nil")
+	}
+
+	var (
+		percent any
+		window  any
+		status  string
+	)
+	if alert.IsMove() {
+		percent = alert.Percent
+		window = int64(alert.Window / time.Second)
+	}
+
+	if err := r.db.QueryRowContext(ctx, query,
+		alert.TelegramID,
+		alert.TokenAddress,
+		nullableString(alert.TokenSymbol),
+		alert.Kind,
+		nullablePrice(alert.PriceUSD),
+		percent,
+		window,
+		alert.Repeating,
+		int64(alert.Cooldown/time.Second),
+	).Scan(&alert.ID, &alert.Armed, &status, &alert.CreatedAt); err != nil {
+		r.logError("create", alert.TelegramID, err)
+		return fmt.Errorf("insert alert: %w", err)
+	}
+
+	alert.Status = domain.AlertStatus(status)
+
+	return nil
+}
+
+// ListActiveByUser returns the user's active alerts, oldest first.
+func (r *alertRepository) ListActiveByUser(ctx context.Context, userID int64) ([]*domain.Alert, error) {
+	query := `
+		SELECT ` + alertColumns + `
+		FROM alerts
+		WHERE telegram_id = $1 AND status = 'active'
+		ORDER BY created_at, id
+	`
+
+	return r.list(ctx, "list_active_by_user", userID, query, userID)
+}
+
+// ListActiveByToken returns every active alert on the token, oldest first.
+func (r *alertRepository) ListActiveByToken(ctx context.Context, tokenAddress string) ([]*domain.Alert, error) {
+	query := `
+		SELECT ` + alertColumns + `
+		FROM alerts
+		WHERE token_address = $1 AND status = 'active'
+		ORDER BY created_at, id
+	`
+
+	return r.list(ctx, "list_active_by_token", 0, query, tokenAddress)
+}
+
+// Delete removes an active alert of the user or returns domain.ErrAlertNotFound.
+func (r *alertRepository) Delete(ctx context.Context, userID, alertID int64) error {
+	const query = `DELETE FROM alerts WHERE telegram_id = $1 AND id = $2 AND status = 'active'`
+
+	result, err := r.db.ExecContext(ctx, query, userID, alertID)
+	if err != nil {
+		r.logError("delete", userID, err)
+		return fmt.Errorf("delete alert: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete alert: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrAlertNotFound
+	}
+
+	return nil
+}
+
+// Rearm lets a disarmed repeating alert fire again.
+func (r *alertRepository) Rearm(ctx context.Context, alertID int64) error {
+	const query = `UPDATE alerts SET armed = TRUE WHERE id = $1 AND status = 'active' AND NOT armed`
+
+	if _, err := r.db.ExecContext(ctx, query, alertID); err != nil {
+		r.logError("rearm", 0, err)
+		return fmt.Errorf("rearm alert: %w", err)
+	}
+
+	return nil
+}
+
+// Trigger records that the alert fired at at and reports whether it did.
+// It does nothing, and returns false, when the alert is no longer active and
+// armed or still cooling down, so concurrent evaluations notify only once.
+// One-shot alerts become triggered; repeating ones are disarmed.
+func (r *alertRepository) Trigger(ctx context.Context, alertID int64, at time.Time) (bool, error) {
+	const query = `
+		UPDATE alerts
+		SET status = CASE WHEN repeating THEN 'active' ELSE 'triggered' END,
+			armed = FALSE,
+			trigger_count = trigger_count + 1,
+			last_triggered_at = $2
+		WHERE id = $1 AND status = 'active' AND armed
+			AND (last_triggered_at IS NULL OR last_triggered_at + cooldown_seconds * INTERVAL '1 second' <= $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, alertID, at.UTC())
+	if err != nil {
+		r.logError("trigger", 0, err)
+		return false, fmt.Errorf("trigger alert: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("trigger alert: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *alertRepository) list(ctx context.Context, operation string, userID int64, query string, args ...any) ([]*domain.Alert, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logError(operation, userID, err)
+		return nil, fmt.Errorf("select alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := make([]*domain.Alert, 0)
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			r.logError(operation, userID, err)
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError(operation, userID, err)
+		return nil, fmt.Errorf("iterate alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func (r *alertRepository) logError(operation string, userID int64, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"alert repository operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", userID),
+		slog.Any("error", err),
+	)
+}
+
+func scanAlert(row rowScanner) (*domain.Alert, error) {
+	var (
+		alert           domain.Alert
+		symbol          sql.NullString
+		kind            string
+		status          string
+		windowSeconds   int64
+		cooldownSeconds int64
+		lastTriggeredAt sql.NullTime
+	)
+
+	if err := row.Scan(
+		&alert.ID,
+		&alert.TelegramID,
+		&alert.TokenAddress,
+		&symbol,
+		&kind,
+		&alert.PriceUSD,
+		&alert.Percent,
+		&windowSeconds,
+		&alert.Repeating,
+		&cooldownSeconds,
+		&alert.Armed,
+		&status,
+		&alert.TriggerCount,
+		&lastTriggeredAt,
+		&alert.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("scan alert: %w", err)
+	}
+
+	alert.TokenSymbol = symbol.String
+	alert.Kind = domain.AlertKind(kind)
+	alert.Status = domain.AlertStatus(status)
+	alert.Window = time.Duration(windowSeconds) * time.Second
+	alert.Cooldown = time.Duration(cooldownSeconds) * time.Second
+
+	if lastTriggeredAt.Valid {
+		at := lastTriggeredAt.Time.UTC()
+		alert.LastTriggeredAt = &at
+	}
+
+	return &alert, nil
+}
@@ -0,0 +1,397 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	usercache "github.com/Proton-105/himera-bot/internal/usercache"
+)
+
+// DCABuy prices the buy of a locked, active DCA plan.
+type DCABuy func(plan *domain.DCAPlan) (*domain.Trade, error)
+
+// DCARepository persists recurring buy plans and records each scheduled run once.
+type DCARepository interface {
+	Create(ctx context.Context, plan *domain.DCAPlan) error
+	ListByUser(ctx context.Context, userID int64) ([]*domain.DCAPlan, error)
+	Get(ctx context.Context, planID int64) (*domain.DCAPlan, error)
+	Pause(ctx context.Context, userID, planID int64) (*domain.DCAPlan, error)
+	Resume(ctx context.Context, userID, planID int64, nextRunAt time.Time) (*domain.DCAPlan, error)
+	Delete(ctx context.Context, userID, planID int64) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.DCAPlan, error)
+	Advance(ctx context.Context, planID int64, from, next time.Time) (bool, error)
+	Execute(ctx context.Context, planID int64, scheduledFor time.Time, buy DCABuy) (*domain.Trade, error)
+	RecordSkip(ctx context.Context, planID int64, scheduledFor time.Time, reason string) (bool, error)
+}
+
+const dcaPlanColumns = `id, telegram_id, token_address, token_symbol, amount_usd, weekday, minute_of_day,
+		status, next_run_at, last_run_at, created_at`
+
+type dcaRepository struct {
+	db     *sql.DB
+	log    *slog.Logger
+	trades *tradeRepository
+}
+
+// NewDCARepository creates a SQL-backed DCA plan repository.
+// The optional cache is invalidated whenever a run changes the user's balance.
+func NewDCARepository(db *sql.DB, log *slog.Logger, cache ...*usercache.Cache) DCARepository {
+	var c *usercache.Cache
+	if len(cache) > 0 {
+		c = cache[0]
+	}
+
+	return &dcaRepository{
+		db:     db,
+		log:    log,
+		trades: &tradeRepository{db: db, log: log, cache: c},
+	}
+}
+
+// Create stores a new active plan and populates plan.ID, Status and CreatedAt.
+func (r *dcaRepository) Create(ctx context.Context, plan *domain.DCAPlan) error {
+	const query = `
+		INSERT INTO dca_plans (telegram_id, token_address, token_symbol, amount_usd, weekday, minute_of_day, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, created_at
+	`
+
+	if plan == nil {
+		return errors.New("dca plan is nil")
+	}
+
+	var (
+		weekday any
+		status  string
+	)
+	if plan.Weekday != nil {
+		weekday = int(*plan.Weekday)
+	}
+
+	if err := r.db.QueryRowContext(ctx, query,
+		plan.TelegramID,
+		plan.TokenAddress,
+		nullableString(plan.TokenSymbol),
+		plan.AmountUSD,
+		weekday,
+		plan.MinuteOfDay,
+		plan.NextRunAt.UTC(),
+	).Scan(&plan.ID, &status, &plan.CreatedAt); err != nil {
+		r.logError("create", plan.TelegramID, err)
+		return fmt.Errorf("insert dca plan: %w", err)
+	}
+
+	plan.Status = domain.PlanStatus(status)
+
+	return nil
+}
+
+// ListByUser returns the user's plans, oldest first.
+func (r *dcaRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.DCAPlan, error) {
+	query := `
+		SELECT ` + dcaPlanColumns + `
+		FROM dca_plans
+		WHERE telegram_id = $1
+		ORDER BY created_at, id
+	`
+
+	return r.list(ctx, "list_by_user", userID, query, userID)
+}
+
+// Get returns the plan or domain.ErrPlanNotFound.
+func (r *dcaRepository) Get(ctx context.Context, planID int64) (*domain.DCAPlan, error) {
+	query := `
+		SELECT ` + dcaPlanColumns + `
+		FROM dca_plans
+		WHERE id = $1
+	`
+
+	plan, err := scanDCAPlan(r.db.QueryRowContext(ctx, query, planID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPlanNotFound
+		}
+		r.logError("get", 0, err)
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// Pause stops the user's plan from running until it is resumed.
+func (r *dcaRepository) Pause(ctx context.Context, userID, planID int64) (*domain.DCAPlan, error) {
+	query := `
+		UPDATE dca_plans
+		SET status = 'paused'
+		WHERE telegram_id = $1 AND id = $2
+		RETURNING ` + dcaPlanColumns
+
+	return r.update(ctx, "pause", userID, query, userID, planID)
+}
+
+// Resume reactivates the user's paused plan from nextRunAt. Resuming an
+// active plan leaves its schedule untouched.
+func (r *dcaRepository) Resume(ctx context.Context, userID, planID int64, nextRunAt time.Time) (*domain.DCAPlan, error) {
+	query := `
+		UPDATE dca_plans
+		SET next_run_at = CASE WHEN status = 'paused' THEN $3 ELSE next_run_at END,
+			status = 'active'
+		WHERE telegram_id = $1 AND id = $2
+		RETURNING ` + dcaPlanColumns
+
+	return r.update(ctx, "resume", userID, query, userID, planID, nextRunAt.UTC())
+}
+
+// Delete removes the user's plan together with its run history.
+func (r *dcaRepository) Delete(ctx context.Context, userID, planID int64) error {
+	const query = `DELETE FROM dca_plans WHERE telegram_id = $1 AND id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, planID)
+	if err != nil {
+		r.logError("delete", userID, err)
+		return fmt.Errorf("delete dca plan: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete dca plan: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrPlanNotFound
+	}
+
+	return nil
+}
+
+// ListDue returns up to limit active plans whose next run is at or before now,
+// most overdue first.
+func (r *dcaRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.DCAPlan, error) {
+	query := `
+		SELECT ` + dcaPlanColumns + `
+		FROM dca_plans
+		WHERE status = 'active' AND next_run_at <= $1
+		ORDER BY next_run_at, id
+		LIMIT $2
+	`
+
+	return r.list(ctx, "list_due", 0, query, now.UTC(), limit)
+}
+
+// Advance moves the plan's next run from from to next and reports whether
+// it did; it does nothing when the plan was rescheduled in the meantime.
+func (r *dcaRepository) Advance(ctx context.Context, planID int64, from, next time.Time) (bool, error) {
+	const query = `UPDATE dca_plans SET next_run_at = $3 WHERE id = $1 AND next_run_at = $2`
+
+	result, err := r.db.ExecContext(ctx, query, planID, from.UTC(), next.UTC())
+	if err != nil {
+		r.logError("advance", 0, err)
+		return false, fmt.Errorf("advance dca plan: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("advance dca plan: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// Execute locks the plan, records its run for scheduledFor, lets buy price
+// the trade and applies it exactly like a manual buy in one SQL transaction.
+// A run that was already recorded returns domain.ErrRunRecorded, so retried
+// tasks never buy twice; a paused plan returns domain.ErrPlanNotActive.
+func (r *dcaRepository) Execute(ctx context.Context, planID int64, scheduledFor time.Time, buy DCABuy) (*domain.Trade, error) {
+	lockQuery := `
+		SELECT ` + dcaPlanColumns + `
+		FROM dca_plans
+		WHERE id = $1
+		FOR UPDATE
+	`
+	const runQuery = `
+		INSERT INTO dca_runs (plan_id, scheduled_for, status)
+		VALUES ($1, $2, 'executed')
+		ON CONFLICT (plan_id, scheduled_for) DO NOTHING
+		RETURNING id
+	`
+	const linkQuery = `UPDATE dca_runs SET transaction_id = $2 WHERE id = $1`
+	const lastRunQuery = `UPDATE dca_plans SET last_run_at = $2 WHERE id = $1`
+
+	if buy == nil {
+		return nil, errors.New("dca buy is nil")
+	}
+
+	var trade *domain.Trade
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		plan, err := scanDCAPlan(tx.QueryRowContext(ctx, lockQuery, planID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrPlanNotFound
+			}
+			return fmt.Errorf("lock dca plan: %w", err)
+		}
+		if plan.Status != domain.PlanStatusActive {
+			return domain.ErrPlanNotActive
+		}
+
+		var runID int64
+		if err := tx.QueryRowContext(ctx, runQuery, planID, scheduledFor.UTC()).Scan(&runID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrRunRecorded
+			}
+			return fmt.Errorf("insert dca run: %w", err)
+		}
+
+		if trade, err = buy(plan); err != nil {
+			return err
+		}
+		if _, err := buyInTx(ctx, tx, trade); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, linkQuery, runID, trade.ID); err != nil {
+			return fmt.Errorf("link dca run: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, lastRunQuery, planID, trade.CreatedAt); err != nil {
+			return fmt.Errorf("update dca plan: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrPlanNotFound) &&
+			!errors.Is(err, domain.ErrPlanNotActive) &&
+			!errors.Is(err, domain.ErrRunRecorded) &&
+			!errors.Is(err, domain.ErrInsufficientBalance) {
+			r.logError("execute", 0, err)
+		}
+		return nil, err
+	}
+
+	r.trades.invalidateCache(ctx, trade.TelegramID)
+
+	return trade, nil
+}
+
+// RecordSkip records that the run for scheduledFor did not buy and reports
+// whether it did; false means the run was already recorded.
+func (r *dcaRepository) RecordSkip(ctx context.Context, planID int64, scheduledFor time.Time, reason string) (bool, error) {
+	const query = `
+		INSERT INTO dca_runs (plan_id, scheduled_for, status, skip_reason)
+		VALUES ($1, $2, 'skipped', $3)
+		ON CONFLICT (plan_id, scheduled_for) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, planID, scheduledFor.UTC(), nullableString(reason))
+	if err != nil {
+		r.logError("record_skip", 0, err)
+		return false, fmt.Errorf("insert dca run: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("insert dca run: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *dcaRepository) update(ctx context.Context, operation string, userID int64, query string, args ...any) (*domain.DCAPlan, error) {
+	plan, err := scanDCAPlan(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPlanNotFound
+		}
+		r.logError(operation, userID, err)
+		return nil, fmt.Errorf("update dca plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+func (r *dcaRepository) list(ctx context.Context, operation string, userID int64, query string, args ...any) ([]*domain.DCAPlan, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logError(operation, userID, err)
+		return nil, fmt.Errorf("select dca plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := make([]*domain.DCAPlan, 0)
+	for rows.Next() {
+		plan, err := scanDCAPlan(rows)
+		if err != nil {
+			r.logError(operation, userID, err)
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError(operation, userID, err)
+		return nil, fmt.Errorf("iterate dca plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+func (r *dcaRepository) logError(operation string, userID int64, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"dca repository operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", userID),
+		slog.Any("error", err),
+	)
+}
+
+func scanDCAPlan(row rowScanner) (*domain.DCAPlan, error) {
+	var (
+		plan      domain.DCAPlan
+		symbol    sql.NullString
+		weekday   sql.NullInt16
+		status    string
+		lastRunAt sql.NullTime
+	)
+
+	if err := row.Scan(
+		&plan.ID,
+		&plan.TelegramID,
+		&plan.TokenAddress,
+		&symbol,
+		&plan.AmountUSD,
+		&weekday,
+		&plan.MinuteOfDay,
+		&status,
+		&plan.NextRunAt,
+		&lastRunAt,
+		&plan.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("scan dca plan: %w", err)
+	}
+
+	plan.TokenSymbol = symbol.String
+	plan.Status = domain.PlanStatus(status)
+	plan.NextRunAt = plan.NextRunAt.UTC()
+
+	if weekday.Valid {
+		day := time.Weekday(weekday.Int16)
+		plan.Weekday = &day
+	}
+	if lastRunAt.Valid {
+		at := lastRunAt.Time.UTC()
+		plan.LastRunAt = &at
+	}
+
+	return &plan, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+// DeadLetterRepository persists background tasks that exhausted their retries.
+type DeadLetterRepository interface {
+	Record(ctx context.Context, letter *domain.DeadLetter) error
+	Get(ctx context.Context, id int64) (*domain.DeadLetter, error)
+	MarkRequeued(ctx context.Context, id int64, taskID string, at time.Time) error
+}
+
+type deadLetterRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewDeadLetterRepository creates a SQL-backed dead-letter repository.
+func NewDeadLetterRepository(db *sql.DB, log *slog.Logger) DeadLetterRepository {
+	return &deadLetterRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// Record stores the failure and sets letter.ID. A task that was run again
+// from the asynq archive keeps its ID, so a repeated failure updates the
+// existing row and clears the requeue marker.
+func (r *deadLetterRepository) Record(ctx context.Context, letter *domain.DeadLetter) error {
+	const query = `
+		INSERT INTO job_dead_letters (task_id, task_type, queue, payload, error, error_chain, retried, max_retry, failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (task_id) DO UPDATE SET
+			error = EXCLUDED.error,
+			error_chain = EXCLUDED.error_chain,
+			retried = EXCLUDED.retried,
+			max_retry = EXCLUDED.max_retry,
+			failed_at = EXCLUDED.failed_at,
+			requeued_at = NULL,
+			requeued_task_id = NULL
+		RETURNING id
+	`
+
+	if letter == nil {
+		return errors.New("dead letter is nil")
+	}
+
+	chain := letter.ErrorChain
+	if chain == nil {
+		chain = []string{}
+	}
+	encodedChain, err := json.Marshal(chain)
+	if err != nil {
+		return fmt.Errorf("encode error chain: %w", err)
+	}
+
+	payload := letter.Payload
+	if payload == nil {
+		payload = []byte{}
+	}
+
+	if err := r.db.QueryRowContext(ctx, query,
+		letter.TaskID,
+		letter.TaskType,
+		letter.Queue,
+		payload,
+		letter.Error,
+		encodedChain,
+		letter.Retried,
+		letter.MaxRetry,
+		letter.FailedAt.UTC(),
+	).Scan(&letter.ID); err != nil {
+		r.logError("record", letter.TaskID, err)
+		return fmt.Errorf("insert dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the dead letter with the given ID, or nil when none exists.
+func (r *deadLetterRepository) Get(ctx context.Context, id int64) (*domain.DeadLetter, error) {
+	const query = `
+		SELECT id, task_id, task_type, queue, payload, error, error_chain, retried, max_retry, failed_at, requeued_at, COALESCE(requeued_task_id, '')
+		FROM job_dead_letters
+		WHERE id = $1
+	`
+
+	var (
+		letter       domain.DeadLetter
+		encodedChain []byte
+		requeuedAt   sql.NullTime
+	)
+
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&letter.ID,
+		&letter.TaskID,
+		&letter.TaskType,
+		&letter.Queue,
+		&letter.Payload,
+		&letter.Error,
+		&encodedChain,
+		&letter.Retried,
+		&letter.MaxRetry,
+		&letter.FailedAt,
+		&requeuedAt,
+		&letter.RequeuedTaskID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logError("get", "", err)
+		return nil, fmt.Errorf("select dead letter: %w", err)
+	}
+
+	if err := json.Unmarshal(encodedChain, &letter.ErrorChain); err != nil {
+		return nil, fmt.Errorf("decode error chain: %w", err)
+	}
+
+	letter.FailedAt = letter.FailedAt.UTC()
+	if requeuedAt.Valid {
+		at := requeuedAt.Time.UTC()
+		letter.RequeuedAt = &at
+	}
+
+	return &letter, nil
+}
+
+// MarkRequeued records that the dead letter was enqueued again as taskID.
+func (r *deadLetterRepository) MarkRequeued(ctx context.Context, id int64, taskID string, at time.Time) error {
+	const query = `
+		UPDATE job_dead_letters
+		SET requeued_at = $2, requeued_task_id = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, at.UTC(), taskID)
+	if err != nil {
+		r.logError("mark_requeued", taskID, err)
+		return fmt.Errorf("mark dead letter requeued: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("mark dead letter requeued: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *deadLetterRepository) logError(operation, taskID string, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"dead letter repository operation failed",
+		slog.String("operation", operation),
+		slog.String("task_id", taskID),
+		slog.Any("error", err),
+	)
+}
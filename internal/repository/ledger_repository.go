@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	usercache "github.com/Proton-105/himera-bot/internal/usercache"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// LedgerRepository records balance changes as immutable ledger entries.
+// Every method that changes users.balance appends the matching entry in the same SQL transaction.
+type LedgerRepository interface {
+	Post(ctx context.Context, entry *domain.LedgerEntry) error
+	Reset(ctx context.Context, userID int64, balance money.Money, note string) (*domain.LedgerEntry, error)
+	ListByUser(ctx context.Context, userID int64, limit int) ([]*domain.LedgerEntry, error)
+	Discrepancies(ctx context.Context) ([]domain.LedgerDiscrepancy, error)
+}
+
+type ledgerRepository struct {
+	db    *sql.DB
+	log   *slog.Logger
+	cache *usercache.Cache
+}
+
+// NewLedgerRepository creates a SQL-backed ledger repository.
+// The optional cache is invalidated whenever an entry changes the user's balance.
+func NewLedgerRepository(db *sql.DB, log *slog.Logger, cache ...*usercache.Cache) LedgerRepository {
+	var c *usercache.Cache
+	if len(cache) > 0 {
+		c = cache[0]
+	}
+
+	return &ledgerRepository{
+		db:    db,
+		log:   log,
+		cache: c,
+	}
+}
+
+// Post applies a bonus or administrative adjustment. Trades and resets have dedicated entry points.
+// On success entry.ID, entry.BalanceAfter and entry.CreatedAt are populated.
+func (r *ledgerRepository) Post(ctx context.Context, entry *domain.LedgerEntry) error {
+	if entry == nil {
+		return errors.New("ledger entry is nil")
+	}
+
+	if entry.Kind != domain.LedgerKindBonus && entry.Kind != domain.LedgerKindAdjustment {
+		return fmt.Errorf("ledger entry kind %q cannot be posted directly", entry.Kind)
+	}
+
+	if entry.Amount.IsZero() {
+		return errors.New("ledger entry amount must be non-zero")
+	}
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		return postLedgerEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrInsufficientBalance) && !errors.Is(err, sql.ErrNoRows) {
+			r.logError("post", entry.TelegramID, err)
+		}
+		return err
+	}
+
+	r.invalidateCache(ctx, entry.TelegramID)
+
+	return nil
+}
+
+// Reset sets the user's balance to the given amount and records the difference as a reset entry.
+func (r *ledgerRepository) Reset(ctx context.Context, userID int64, balance money.Money, note string) (*domain.LedgerEntry, error) {
+	const lockQuery = `
+		SELECT COALESCE(balance, 0)
+		FROM users
+		WHERE telegram_id = $1
+		FOR UPDATE
+	`
+
+	if balance.Sign() < 0 {
+		return nil, errors.New("reset balance must be non-negative")
+	}
+
+	entry := &domain.LedgerEntry{
+		TelegramID: userID,
+		Kind:       domain.LedgerKindReset,
+		Note:       note,
+	}
+
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var current money.Money
+		if err := tx.QueryRowContext(ctx, lockQuery, userID).Scan(&current); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return sql.ErrNoRows
+			}
+			return fmt.Errorf("lock balance: %w", err)
+		}
+
+		delta, err := balance.Sub(current)
+		if err != nil {
+			return err
+		}
+		entry.Amount = delta
+
+		return postLedgerEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			r.logError("reset", userID, err)
+		}
+		return nil, err
+	}
+
+	r.invalidateCache(ctx, userID)
+
+	return entry, nil
+}
+
+// ListByUser returns the user's most recent ledger entries, newest first.
+func (r *ledgerRepository) ListByUser(ctx context.Context, userID int64, limit int) ([]*domain.LedgerEntry, error) {
+	const query = `
+		SELECT id, telegram_id, kind, amount, balance_after, reference, note, created_at
+		FROM ledger_entries
+		WHERE telegram_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		r.logError("list_by_user", userID, err)
+		return nil, fmt.Errorf("select ledger entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.LedgerEntry, 0)
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			r.logError("list_by_user", userID, err)
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("list_by_user", userID, err)
+		return nil, fmt.Errorf("iterate ledger entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Discrepancies returns users whose balance differs from the sum of their ledger entries.
+func (r *ledgerRepository) Discrepancies(ctx context.Context) ([]domain.LedgerDiscrepancy, error) {
+	const query = `
+		SELECT u.telegram_id, COALESCE(u.balance, 0), COALESCE(SUM(l.amount), 0)
+		FROM users u
+		LEFT JOIN ledger_entries l ON l.telegram_id = u.telegram_id
+		GROUP BY u.telegram_id, u.balance
+		HAVING COALESCE(u.balance, 0) <> COALESCE(SUM(l.amount), 0)
+		ORDER BY u.telegram_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logError("discrepancies", 0, err)
+		return nil, fmt.Errorf("select ledger discrepancies: %w", err)
+	}
+	defer rows.Close()
+
+	discrepancies := make([]domain.LedgerDiscrepancy, 0)
+	for rows.Next() {
+		var item domain.LedgerDiscrepancy
+		if err := rows.Scan(&item.TelegramID, &item.Balance, &item.LedgerTotal); err != nil {
+			r.logError("discrepancies", 0, err)
+			return nil, fmt.Errorf("scan ledger discrepancy: %w", err)
+		}
+
+		discrepancies = append(discrepancies, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("discrepancies", 0, err)
+		return nil, fmt.Errorf("iterate ledger discrepancies: %w", err)
+	}
+
+	return discrepancies, nil
+}
+
+func (r *ledgerRepository) invalidateCache(ctx context.Context, userID int64) {
+	if r.cache == nil {
+		return
+	}
+
+	if err := r.cache.Invalidate(ctx, userID); err != nil && r.log != nil {
+		r.log.Warn(
+			"user cache operation failed",
+			slog.String("operation", "invalidate"),
+			slog.Int64("telegram_id", userID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (r *ledgerRepository) logError(operation string, userID int64, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"ledger repository operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", userID),
+		slog.Any("error", err),
+	)
+}
+
+// postLedgerEntry applies entry.Amount to users.balance and appends the entry inside tx.
+// It returns domain.ErrInsufficientBalance when the balance would become negative and
+// sql.ErrNoRows when the user does not exist. On success entry.ID, entry.BalanceAfter
+// and entry.CreatedAt are populated.
+func postLedgerEntry(ctx context.Context, tx *sql.Tx, entry *domain.LedgerEntry) error {
+	const balanceQuery = `
+		UPDATE users
+		SET balance = COALESCE(balance, 0) + $2::numeric
+		WHERE telegram_id = $1 AND COALESCE(balance, 0) + $2::numeric >= 0
+		RETURNING balance
+	`
+	const existsQuery = `
+		SELECT EXISTS (SELECT 1 FROM users WHERE telegram_id = $1)
+	`
+	const insertQuery = `
+		INSERT INTO ledger_entries (telegram_id, kind, amount, balance_after, reference, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	var balanceAfter money.Money
+	if err := tx.QueryRowContext(ctx, balanceQuery, entry.TelegramID, entry.Amount).Scan(&balanceAfter); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("update balance: %w", err)
+		}
+
+		var exists bool
+		if err := tx.QueryRowContext(ctx, existsQuery, entry.TelegramID).Scan(&exists); err != nil {
+			return fmt.Errorf("check user exists: %w", err)
+		}
+		if !exists {
+			return sql.ErrNoRows
+		}
+		return domain.ErrInsufficientBalance
+	}
+
+	if err := tx.QueryRowContext(
+		ctx,
+		insertQuery,
+		entry.TelegramID,
+		entry.Kind,
+		entry.Amount,
+		balanceAfter,
+		nullableString(entry.Reference),
+		nullableString(entry.Note),
+	).Scan(&entry.ID, &entry.CreatedAt); err != nil {
+		return fmt.Errorf("insert ledger entry: %w", err)
+	}
+
+	entry.BalanceAfter = balanceAfter
+
+	return nil
+}
+
+// postOpeningEntry records the balance a freshly inserted user starts with.
+func postOpeningEntry(ctx context.Context, tx *sql.Tx, userID int64) error {
+	const query = `
+		INSERT INTO ledger_entries (telegram_id, kind, amount, balance_after, reference)
+		SELECT telegram_id, $2, COALESCE(balance, 0), COALESCE(balance, 0), 'signup'
+		FROM users
+		WHERE telegram_id = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, userID, domain.LedgerKindOpening); err != nil {
+		return fmt.Errorf("insert opening ledger entry: %w", err)
+	}
+
+	return nil
+}
+
+func scanLedgerEntry(row rowScanner) (*domain.LedgerEntry, error) {
+	var (
+		entry     domain.LedgerEntry
+		kind      string
+		reference sql.NullString
+		note      sql.NullString
+	)
+
+	if err := row.Scan(
+		&entry.ID,
+		&entry.TelegramID,
+		&kind,
+		&entry.Amount,
+		&entry.BalanceAfter,
+		&reference,
+		&note,
+		&entry.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scan ledger entry: %w", err)
+	}
+
+	entry.Kind = domain.LedgerKind(kind)
+	entry.Reference = reference.String
+	entry.Note = note.String
+
+	return &entry, nil
+}
+
+func transactionReference(transactionID int64) string {
+	return fmt.Sprintf("transaction:%d", transactionID)
+}
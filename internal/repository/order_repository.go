@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	usercache "github.com/Proton-105/himera-bot/internal/usercache"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// OrderFill prices the trade that fills a locked open order. For sells,
+// position is the locked position in the order's token; for buys it is nil.
+type OrderFill func(order *domain.Order, position *domain.Position) (*domain.Trade, error)
+
+// OrderRepository persists limit orders and fills them together with their trades.
+type OrderRepository interface {
+	Create(ctx context.Context, order *domain.Order) error
+	ListOpenByUser(ctx context.Context, userID int64) ([]*domain.Order, error)
+	ListOpenByToken(ctx context.Context, tokenAddress string) ([]*domain.Order, error)
+	Cancel(ctx context.Context, userID, orderID int64, reason string) (*domain.Order, error)
+	ExpireDue(ctx context.Context, now time.Time) ([]*domain.Order, error)
+	Fill(ctx context.Context, orderID int64, fill OrderFill) (*domain.Order, *domain.Trade, error)
+}
+
+const orderColumns = `id, telegram_id, side, token_address, token_symbol, limit_price, COALESCE(amount_usd, 0),
+		COALESCE(percent, 0), status, expires_at, transaction_id, close_reason, created_at, closed_at`
+
+type orderRepository struct {
+	db     *sql.DB
+	log    *slog.Logger
+	trades *tradeRepository
+}
+
+// NewOrderRepository creates a SQL-backed order repository.
+// The optional cache is invalidated whenever a fill changes the user's balance.
+func NewOrderRepository(db *sql.DB, log *slog.Logger, cache ...*usercache.Cache) OrderRepository {
+	var c *usercache.Cache
+	if len(cache) > 0 {
+		c = cache[0]
+	}
+
+	return &orderRepository{
+		db:     db,
+		log:    log,
+		trades: &tradeRepository{db: db, log: log, cache: c},
+	}
+}
+
+// Create stores a new open order and populates order.ID, Status and CreatedAt.
+func (r *orderRepository) Create(ctx context.Context, order *domain.Order) error {
+	const query = `
+		INSERT INTO orders (telegram_id, side, token_address, token_symbol, limit_price, amount_usd, percent, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, status, created_at
+	`
+
+	if order == nil {
+		return errors.New("order is nil")
+	}
+
+	order.LimitPriceUSD = order.LimitPriceUSD.Round(domain.PricePrecision, money.RoundHalfEven)
+
+	var (
+		amount  any
+		percent any
+		status  string
+	)
+	if order.Side == domain.TradeTypeBuy {
+		amount = order.AmountUSD
+	} else {
+		percent = order.Percent
+	}
+
+	if err := r.db.QueryRowContext(ctx, query,
+		order.TelegramID,
+		order.Side,
+		order.TokenAddress,
+		nullableString(order.TokenSymbol),
+		order.LimitPriceUSD,
+		amount,
+		percent,
+		nullableTime(order.ExpiresAt),
+	).Scan(&order.ID, &status, &order.CreatedAt); err != nil {
+		r.logError("create", order.TelegramID, err)
+		return fmt.Errorf("insert order: %w", err)
+	}
+
+	order.Status = domain.OrderStatus(status)
+
+	return nil
+}
+
+// ListOpenByUser returns the user's open orders, oldest first.
+func (r *orderRepository) ListOpenByUser(ctx context.Context, userID int64) ([]*domain.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE telegram_id = $1 AND status = 'open'
+		ORDER BY created_at, id
+	`
+
+	return r.list(ctx, "list_open_by_user", userID, query, userID)
+}
+
+// ListOpenByToken returns every open order in the token, oldest first.
+func (r *orderRepository) ListOpenByToken(ctx context.Context, tokenAddress string) ([]*domain.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE token_address = $1 AND status = 'open'
+		ORDER BY created_at, id
+	`
+
+	return r.list(ctx, "list_open_by_token", 0, query, tokenAddress)
+}
+
+// Cancel closes an open order of the user. reason is empty when the user
+// cancelled the order themselves.
+func (r *orderRepository) Cancel(ctx context.Context, userID, orderID int64, reason string) (*domain.Order, error) {
+	query := `
+		UPDATE orders
+		SET status = 'cancelled', close_reason = $3, closed_at = NOW()
+		WHERE telegram_id = $1 AND id = $2 AND status = 'open'
+		RETURNING ` + orderColumns
+
+	order, err := scanOrder(r.db.QueryRowContext(ctx, query, userID, orderID, nullableString(reason)))
+	if err == nil {
+		return order, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		r.logError("cancel", userID, err)
+		return nil, fmt.Errorf("cancel order: %w", err)
+	}
+
+	const existsQuery = `SELECT EXISTS (SELECT 1 FROM orders WHERE telegram_id = $1 AND id = $2)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, existsQuery, userID, orderID).Scan(&exists); err != nil {
+		r.logError("cancel", userID, err)
+		return nil, fmt.Errorf("select order: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	return nil, domain.ErrOrderNotOpen
+}
+
+// ExpireDue closes every open order whose expiry passed at now and returns them.
+func (r *orderRepository) ExpireDue(ctx context.Context, now time.Time) ([]*domain.Order, error) {
+	query := `
+		UPDATE orders
+		SET status = 'expired', closed_at = $1
+		WHERE status = 'open' AND expires_at <= $1
+		RETURNING ` + orderColumns
+
+	return r.list(ctx, "expire_due", 0, query, now.UTC())
+}
+
+// Fill locks the open order, lets fill price the trade and applies it exactly
+// like an immediate buy or sell, marking the order filled in the same SQL
+// transaction. An order that is no longer open, or whose expiry passed,
+// returns domain.ErrOrderNotOpen; a sell without a position returns
+// domain.ErrPositionNotFound.
+func (r *orderRepository) Fill(ctx context.Context, orderID int64, fill OrderFill) (*domain.Order, *domain.Trade, error) {
+	lockQuery := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE id = $1
+		FOR UPDATE
+	`
+	const positionQuery = `
+		SELECT id, telegram_id, token_address, token_symbol, amount, avg_price, created_at
+		FROM positions
+		WHERE telegram_id = $1 AND token_address = $2
+		FOR UPDATE
+	`
+	const filledQuery = `
+		UPDATE orders
+		SET status = 'filled', transaction_id = $2, closed_at = $3
+		WHERE id = $1
+	`
+
+	if fill == nil {
+		return nil, nil, errors.New("order fill is nil")
+	}
+
+	var (
+		order *domain.Order
+		trade *domain.Trade
+	)
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		order, err = scanOrder(tx.QueryRowContext(ctx, lockQuery, orderID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrOrderNotFound
+			}
+			return fmt.Errorf("lock order: %w", err)
+		}
+		if order.Status != domain.OrderStatusOpen || order.Expired(time.Now()) {
+			return domain.ErrOrderNotOpen
+		}
+
+		switch order.Side {
+		case domain.TradeTypeBuy:
+			if trade, err = fill(order, nil); err != nil {
+				return err
+			}
+			if _, err := buyInTx(ctx, tx, trade); err != nil {
+				return err
+			}
+		case domain.TradeTypeSell:
+			position, err := scanPosition(tx.QueryRowContext(ctx, positionQuery, order.TelegramID, order.TokenAddress))
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return domain.ErrPositionNotFound
+				}
+				return fmt.Errorf("lock position: %w", err)
+			}
+			if trade, err = fill(order, position); err != nil {
+				return err
+			}
+			if err := sellInTx(ctx, tx, position, trade); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown order side %q", order.Side)
+		}
+
+		if _, err := tx.ExecContext(ctx, filledQuery, order.ID, trade.ID, trade.CreatedAt); err != nil {
+			return fmt.Errorf("mark order filled: %w", err)
+		}
+
+		order.Status = domain.OrderStatusFilled
+		order.TransactionID = trade.ID
+		closedAt := trade.CreatedAt
+		order.ClosedAt = &closedAt
+
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrOrderNotFound) &&
+			!errors.Is(err, domain.ErrOrderNotOpen) &&
+			!errors.Is(err, domain.ErrPositionNotFound) &&
+			!errors.Is(err, domain.ErrInsufficientBalance) {
+			r.logError("fill", 0, err)
+		}
+		return nil, nil, err
+	}
+
+	r.trades.invalidateCache(ctx, order.TelegramID)
+
+	return order, trade, nil
+}
+
+func (r *orderRepository) list(ctx context.Context, operation string, userID int64, query string, args ...any) ([]*domain.Order, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logError(operation, userID, err)
+		return nil, fmt.Errorf("select orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]*domain.Order, 0)
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			r.logError(operation, userID, err)
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError(operation, userID, err)
+		return nil, fmt.Errorf("iterate orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+func (r *orderRepository) logError(operation string, userID int64, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"order repository operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", userID),
+		slog.Any("error", err),
+	)
+}
+
+func nullableTime(value *time.Time) sql.NullTime {
+	if value == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: value.UTC(), Valid: true}
+}
+
+func scanOrder(row rowScanner) (*domain.Order, error) {
+	var (
+		order         domain.Order
+		side          string
+		status        string
+		symbol        sql.NullString
+		expiresAt     sql.NullTime
+		transactionID sql.NullInt64
+		closeReason   sql.NullString
+		closedAt      sql.NullTime
+	)
+
+	if err := row.Scan(
+		&order.ID,
+		&order.TelegramID,
+		&side,
+		&order.TokenAddress,
+		&symbol,
+		&order.LimitPriceUSD,
+		&order.AmountUSD,
+		&order.Percent,
+		&status,
+		&expiresAt,
+		&transactionID,
+		&closeReason,
+		&order.CreatedAt,
+		&closedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("scan order: %w", err)
+	}
+
+	order.Side = domain.TradeType(side)
+	order.Status = domain.OrderStatus(status)
+	order.TokenSymbol = symbol.String
+	order.TransactionID = transactionID.Int64
+	order.CloseReason = closeReason.String
+
+	if expiresAt.Valid {
+		at := expiresAt.Time.UTC()
+		order.ExpiresAt = &at
+	}
+	if closedAt.Valid {
+		at := closedAt.Time.UTC()
+		order.ClosedAt = &at
+	}
+
+	return &order, nil
+}
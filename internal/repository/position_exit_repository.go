@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	usercache "github.com/Proton-105/himera-bot/internal/usercache"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// PositionClose prices the sale that closes a locked position whose exit
+// levels, also locked, were hit.
+type PositionClose func(exit *domain.PositionExit, position *domain.Position) (*domain.Trade, error)
+
+// PositionExitRepository persists stop-loss, take-profit and trailing-stop
+// levels and closes positions when they trigger.
+type PositionExitRepository interface {
+	Save(ctx context.Context, exit *domain.PositionExit) error
+	Get(ctx context.Context, userID, positionID int64) (*domain.PositionExit, error)
+	Delete(ctx context.Context, userID, positionID int64) error
+	ListByToken(ctx context.Context, tokenAddress string) ([]*domain.PositionExit, error)
+	RaisePeak(ctx context.Context, positionID int64, price money.Decimal) (bool, error)
+	Close(ctx context.Context, positionID int64, sell PositionClose) (*domain.Trade, error)
+}
+
+const positionExitColumns = `position_id, telegram_id, token_address, COALESCE(stop_loss_price, 0),
+		COALESCE(take_profit_price, 0), COALESCE(trailing_percent, 0), COALESCE(trailing_peak_price, 0), updated_at`
+
+type positionExitRepository struct {
+	db     *sql.DB
+	log    *slog.Logger
+	trades *tradeRepository
+}
+
+// NewPositionExitRepository creates a SQL-backed position exit repository.
+// The optional cache is invalidated whenever a triggered exit changes the user's balance.
+func NewPositionExitRepository(db *sql.DB, log *slog.Logger, cache ...*usercache.Cache) PositionExitRepository {
+	var c *usercache.Cache
+	if len(cache) > 0 {
+		c = cache[0]
+	}
+
+	return &positionExitRepository{
+		db:     db,
+		log:    log,
+		trades: &tradeRepository{db: db, log: log, cache: c},
+	}
+}
+
+// Save creates or replaces the exit levels of a position owned by
+// exit.TelegramID and populates TokenAddress and UpdatedAt. It returns
+// domain.ErrPositionNotFound when the user holds no such position.
+func (r *positionExitRepository) Save(ctx context.Context, exit *domain.PositionExit) error {
+	if exit == nil {
+		return errors.New("position exit is nil")
+	}
+
+	if err := saveExit(ctx, r.db, exit); err != nil {
+		if !errors.Is(err, domain.ErrPositionNotFound) {
+			r.logError("save", exit.TelegramID, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Get returns the exit levels of the user's position or domain.ErrExitNotFound.
+func (r *positionExitRepository) Get(ctx context.Context, userID, positionID int64) (*domain.PositionExit, error) {
+	query := `
+		SELECT ` + positionExitColumns + `
+		FROM position_exits
+		WHERE telegram_id = $1 AND position_id = $2
+	`
+
+	exit, err := scanPositionExit(r.db.QueryRowContext(ctx, query, userID, positionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrExitNotFound
+		}
+		r.logError("get", userID, err)
+		return nil, err
+	}
+
+	return exit, nil
+}
+
+// Delete removes every exit level of the user's position. Deleting levels
+// that do not exist is not an error.
+func (r *positionExitRepository) Delete(ctx context.Context, userID, positionID int64) error {
+	const query = `DELETE FROM position_exits WHERE telegram_id = $1 AND position_id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, positionID); err != nil {
+		r.logError("delete", userID, err)
+		return fmt.Errorf("delete position exit: %w", err)
+	}
+
+	return nil
+}
+
+// ListByToken returns the exit levels of every position in the token.
+func (r *positionExitRepository) ListByToken(ctx context.Context, tokenAddress string) ([]*domain.PositionExit, error) {
+	query := `
+		SELECT ` + positionExitColumns + `
+		FROM position_exits
+		WHERE token_address = $1
+		ORDER BY position_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tokenAddress)
+	if err != nil {
+		r.logError("list_by_token", 0, err)
+		return nil, fmt.Errorf("select position exits: %w", err)
+	}
+	defer rows.Close()
+
+	exits := make([]*domain.PositionExit, 0)
+	for rows.Next() {
+		exit, err := scanPositionExit(rows)
+		if err != nil {
+			r.logError("list_by_token", 0, err)
+			return nil, err
+		}
+		exits = append(exits, exit)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("list_by_token", 0, err)
+		return nil, fmt.Errorf("iterate position exits: %w", err)
+	}
+
+	return exits, nil
+}
+
+// RaisePeak moves the trailing-stop peak of the position up to price and
+// reports whether it changed. Lower prices leave the peak untouched.
+func (r *positionExitRepository) RaisePeak(ctx context.Context, positionID int64, price money.Decimal) (bool, error) {
+	const query = `
+		UPDATE position_exits
+		SET trailing_peak_price = $2
+		WHERE position_id = $1 AND trailing_percent IS NOT NULL AND trailing_peak_price < $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, positionID, price.Round(domain.PricePrecision, money.RoundHalfEven))
+	if err != nil {
+		r.logError("raise_peak", 0, err)
+		return false, fmt.Errorf("raise trailing peak: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("raise trailing peak: %w", err)
+	}
+
+	return affected > 0, nil
+}
+
+// Close locks the position and its exit levels, lets sell price the sale
+// and applies it exactly like a manual sell in one SQL transaction. It
+// returns domain.ErrExitNotFound when the levels were removed in the
+// meantime, including when the position itself was closed.
+func (r *positionExitRepository) Close(ctx context.Context, positionID int64, sell PositionClose) (*domain.Trade, error) {
+	lockQuery := `
+		SELECT ` + positionExitColumns + `
+		FROM position_exits
+		WHERE position_id = $1
+		FOR UPDATE
+	`
+	const positionQuery = `
+		SELECT id, telegram_id, token_address, token_symbol, amount, avg_price, created_at
+		FROM positions
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	if sell == nil {
+		return nil, errors.New("position close is nil")
+	}
+
+	var trade *domain.Trade
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		// The position is locked first, like manual sells do, whose cascade
+		// delete locks the exit row afterwards.
+		position, err := scanPosition(tx.QueryRowContext(ctx, positionQuery, positionID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrExitNotFound
+			}
+			return fmt.Errorf("lock position: %w", err)
+		}
+
+		exit, err := scanPositionExit(tx.QueryRowContext(ctx, lockQuery, positionID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrExitNotFound
+			}
+			return fmt.Errorf("lock position exit: %w", err)
+		}
+
+		if trade, err = sell(exit, position); err != nil {
+			return err
+		}
+
+		return sellInTx(ctx, tx, position, trade)
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrExitNotFound) {
+			r.logError("close", 0, err)
+		}
+		return nil, err
+	}
+
+	r.trades.invalidateCache(ctx, trade.TelegramID)
+
+	return trade, nil
+}
+
+func (r *positionExitRepository) logError(operation string, userID int64, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"position exit repository operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", userID),
+		slog.Any("error", err),
+	)
+}
+
+// rowQuerier is implemented by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// saveExit upserts exit through q, see Save.
+func saveExit(ctx context.Context, q rowQuerier, exit *domain.PositionExit) error {
+	const query = `
+		INSERT INTO position_exits (position_id, telegram_id, token_address, stop_loss_price, take_profit_price, trailing_percent, trailing_peak_price)
+		SELECT id, telegram_id, token_address, $3, $4, $5, $6
+		FROM positions
+		WHERE id = $1 AND telegram_id = $2
+		ON CONFLICT (position_id) DO UPDATE
+		SET stop_loss_price = EXCLUDED.stop_loss_price,
+			take_profit_price = EXCLUDED.take_profit_price,
+			trailing_percent = EXCLUDED.trailing_percent,
+			trailing_peak_price = EXCLUDED.trailing_peak_price,
+			updated_at = NOW()
+		RETURNING token_address, updated_at
+	`
+
+	var (
+		trailingPercent any
+		trailingPeak    any
+	)
+	if exit.TrailingPercent > 0 {
+		trailingPercent = exit.TrailingPercent
+		trailingPeak = exit.TrailingPeakUSD.Round(domain.PricePrecision, money.RoundHalfEven)
+	}
+
+	err := q.QueryRowContext(ctx, query,
+		exit.PositionID,
+		exit.TelegramID,
+		nullablePrice(exit.StopLossUSD),
+		nullablePrice(exit.TakeProfitUSD),
+		trailingPercent,
+		trailingPeak,
+	).Scan(&exit.TokenAddress, &exit.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrPositionNotFound
+		}
+		return fmt.Errorf("upsert position exit: %w", err)
+	}
+
+	return nil
+}
+
+// setExitInTx lets exit set the levels of position, which tx has just
+// written, and stores them in tx. Levels that come back zero are removed.
+func setExitInTx(ctx context.Context, tx *sql.Tx, position *domain.Position, exit BuyExit) error {
+	lockQuery := `
+		SELECT ` + positionExitColumns + `
+		FROM position_exits
+		WHERE position_id = $1
+		FOR UPDATE
+	`
+	const deleteQuery = `DELETE FROM position_exits WHERE position_id = $1`
+
+	current, err := scanPositionExit(tx.QueryRowContext(ctx, lockQuery, position.ID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		current = &domain.PositionExit{PositionID: position.ID, TelegramID: position.TelegramID, TokenAddress: position.TokenAddress}
+	case err != nil:
+		return fmt.Errorf("lock position exit: %w", err)
+	}
+
+	updated, err := exit(position, current)
+	if err != nil || updated == nil {
+		return err
+	}
+
+	if updated.IsZero() {
+		if _, err := tx.ExecContext(ctx, deleteQuery, position.ID); err != nil {
+			return fmt.Errorf("delete position exit: %w", err)
+		}
+		return nil
+	}
+
+	return saveExit(ctx, tx, updated)
+}
+
+// nullablePrice stores a zero price, which means "not set", as NULL.
+func nullablePrice(value money.Decimal) any {
+	if value.IsZero() {
+		return nil
+	}
+	return value.Round(domain.PricePrecision, money.RoundHalfEven)
+}
+
+func scanPositionExit(row rowScanner) (*domain.PositionExit, error) {
+	var exit domain.PositionExit
+
+	if err := row.Scan(
+		&exit.PositionID,
+		&exit.TelegramID,
+		&exit.TokenAddress,
+		&exit.StopLossUSD,
+		&exit.TakeProfitUSD,
+		&exit.TrailingPercent,
+		&exit.TrailingPeakUSD,
+		&exit.UpdatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("scan position exit: %w", err)
+	}
+
+	return &exit, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+// PositionRepository reads users' open token positions.
+type PositionRepository interface {
+	ListByUser(ctx context.Context, userID int64) ([]*domain.Position, error)
+	GetByID(ctx context.Context, userID, positionID int64) (*domain.Position, error)
+	ListTokenAddresses(ctx context.Context) ([]string, error)
+}
+
+type positionRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewPositionRepository creates a SQL-backed position repository.
+func NewPositionRepository(db *sql.DB, log *slog.Logger) PositionRepository {
+	return &positionRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// ListByUser returns the user's positions, oldest first.
+func (r *positionRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.Position, error) {
+	const query = `
+		SELECT id, telegram_id, token_address, token_symbol, amount, avg_price, created_at
+		FROM positions
+		WHERE telegram_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		r.logError("list_by_user", userID, err)
+		return nil, fmt.Errorf("select positions: %w", err)
+	}
+	defer rows.Close()
+
+	positions := make([]*domain.Position, 0)
+	for rows.Next() {
+		position, err := scanPosition(rows)
+		if err != nil {
+			r.logError("list_by_user", userID, err)
+			return nil, err
+		}
+		positions = append(positions, position)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("list_by_user", userID, err)
+		return nil, fmt.Errorf("iterate positions: %w", err)
+	}
+
+	return positions, nil
+}
+
+// GetByID returns a single position owned by the user or domain.ErrPositionNotFound.
+func (r *positionRepository) GetByID(ctx context.Context, userID, positionID int64) (*domain.Position, error) {
+	const query = `
+		SELECT id, telegram_id, token_address, token_symbol, amount, avg_price, created_at
+		FROM positions
+		WHERE telegram_id = $1 AND id = $2
+	`
+
+	position, err := scanPosition(r.db.QueryRowContext(ctx, query, userID, positionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPositionNotFound
+		}
+
+		r.logError("get_by_id", userID, err)
+		return nil, err
+	}
+
+	return position, nil
+}
+
+func (r *positionRepository) logError(operation string, userID int64, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"position repository operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", userID),
+		slog.Any("error", err),
+	)
+}
+
+// ListTokenAddresses returns the distinct token addresses held in any open position.
+func (r *positionRepository) ListTokenAddresses(ctx context.Context) ([]string, error) {
+	const query = `
+		SELECT DISTINCT token_address
+		FROM positions
+		ORDER BY token_address
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logError("list_token_addresses", 0, err)
+		return nil, fmt.Errorf("select position tokens: %w", err)
+	}
+	defer rows.Close()
+
+	addresses := make([]string, 0)
+	for rows.Next() {
+		var address string
+		if err := rows.Scan(&address); err != nil {
+			r.logError("list_token_addresses", 0, err)
+			return nil, fmt.Errorf("scan position token: %w", err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("list_token_addresses", 0, err)
+		return nil, fmt.Errorf("iterate position tokens: %w", err)
+	}
+
+	return addresses, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPosition(row rowScanner) (*domain.Position, error) {
+	var (
+		position domain.Position
+		symbol   sql.NullString
+	)
+
+	if err := row.Scan(
+		&position.ID,
+		&position.TelegramID,
+		&position.TokenAddress,
+		&symbol,
+		&position.Amount,
+		&position.AvgPriceUSD,
+		&position.CreatedAt,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("scan position: %w", err)
+	}
+
+	position.TokenSymbol = symbol.String
+
+	return &position, nil
+}
@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// PriceHistoryRepository persists raw price ticks and the OHLCV candles rolled up from them.
+type PriceHistoryRepository interface {
+	InsertTicks(ctx context.Context, quotes []*domain.PriceQuote) error
+	RollupCandles(ctx context.Context, interval domain.CandleInterval, from, to time.Time) (int64, error)
+	Candles(ctx context.Context, tokenAddress string, interval domain.CandleInterval, from, to time.Time) ([]*domain.Candle, error)
+	LastCandleBefore(ctx context.Context, tokenAddress string, interval domain.CandleInterval, before time.Time) (*domain.Candle, error)
+	LastCandles(ctx context.Context, tokenAddresses []string, interval domain.CandleInterval, from, before time.Time) (map[string]*domain.Candle, error)
+	Volumes(ctx context.Context, tokenAddresses []string, interval domain.CandleInterval, from, to time.Time) (map[string]money.Decimal, error)
+}
+
+// candleRollups describes how each interval is built: the date_trunc unit and,
+// for coarser intervals, the finer candles it aggregates. 1m reads price_ticks.
+var candleRollups = map[domain.CandleInterval]struct {
+	unit   string
+	source domain.CandleInterval
+}{
+	domain.CandleInterval1m: {unit: "minute"},
+	domain.CandleInterval1h: {unit: "hour", source: domain.CandleInterval1m},
+	domain.CandleInterval1d: {unit: "day", source: domain.CandleInterval1h},
+}
+
+type priceHistoryRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewPriceHistoryRepository creates a SQL-backed price history repository.
+func NewPriceHistoryRepository(db *sql.DB, log *slog.Logger) PriceHistoryRepository {
+	return &priceHistoryRepository{
+		db:  db,
+		log: log,
+	}
+}
+
+// InsertTicks appends one tick per quote in a single statement.
+func (r *priceHistoryRepository) InsertTicks(ctx context.Context, quotes []*domain.PriceQuote) error {
+	const columns = 4
+
+	values := make([]string, 0, len(quotes))
+	args := make([]any, 0, len(quotes)*columns)
+	for _, quote := range quotes {
+		if quote == nil || quote.PriceUSD.Sign() <= 0 {
+			continue
+		}
+
+		n := len(args)
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+		args = append(args,
+			quote.TokenAddress,
+			quote.PriceUSD.Round(domain.PricePrecision, money.RoundHalfEven),
+			quote.Source,
+			quote.FetchedAt.UTC(),
+		)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query := "INSERT INTO price_ticks (token_address, price_usd, source, fetched_at) VALUES " + strings.Join(values, ", ")
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		r.logError("insert_ticks", "", err)
+		return fmt.Errorf("insert price ticks: %w", err)
+	}
+
+	return nil
+}
+
+// RollupCandles recomputes every interval candle whose bucket overlaps [from, to)
+// and upserts it, so repeated runs over the same window are idempotent.
+// It returns the number of candles written.
+func (r *priceHistoryRepository) RollupCandles(ctx context.Context, interval domain.CandleInterval, from, to time.Time) (int64, error) {
+	const upsert = `
+		INSERT INTO price_candles (token_address, resolution, open_time, open, high, low, close, volume_usd, tick_count, updated_at)
+		SELECT b.token_address, $3, b.open_time, b.open, b.high, b.low, b.close,
+			COALESCE((
+				SELECT SUM(t.total_usd)
+				FROM transactions t
+				WHERE t.token_address = b.token_address
+					AND t.created_at >= b.open_time
+					AND t.created_at < b.open_time + make_interval(secs => $4)
+			), 0),
+			b.tick_count, NOW()
+		FROM (%s) b
+		ON CONFLICT (token_address, resolution, open_time) DO UPDATE SET
+			open = EXCLUDED.open,
+			high = EXCLUDED.high,
+			low = EXCLUDED.low,
+			close = EXCLUDED.close,
+			volume_usd = EXCLUDED.volume_usd,
+			tick_count = EXCLUDED.tick_count,
+			updated_at = NOW()
+	`
+	const fromTicks = `
+		SELECT token_address,
+			date_trunc($5, fetched_at, 'UTC') AS open_time,
+			(array_agg(price_usd ORDER BY fetched_at, id))[1] AS open,
+			MAX(price_usd) AS high,
+			MIN(price_usd) AS low,
+			(array_agg(price_usd ORDER BY fetched_at DESC, id DESC))[1] AS close,
+			COUNT(*) AS tick_count
+		FROM price_ticks
+		WHERE fetched_at >= $1 AND fetched_at < $2
+		GROUP BY 1, 2
+	`
+	const fromCandles = `
+		SELECT token_address,
+			date_trunc($5, open_time, 'UTC') AS open_time,
+			(array_agg(open ORDER BY open_time))[1] AS open,
+			MAX(high) AS high,
+			MIN(low) AS low,
+			(array_agg(close ORDER BY open_time DESC))[1] AS close,
+			SUM(tick_count) AS tick_count
+		FROM price_candles
+		WHERE resolution = $6 AND open_time >= $1 AND open_time < $2
+		GROUP BY 1, 2
+	`
+
+	rollup, ok := candleRollups[interval]
+	if !ok {
+		return 0, fmt.Errorf("unsupported candle interval %q", interval)
+	}
+
+	from = interval.Truncate(from)
+	args := []any{from, to.UTC(), string(interval), int64(interval.Duration() / time.Second), rollup.unit}
+
+	query := fmt.Sprintf(upsert, fromTicks)
+	if rollup.source != "" {
+		query = fmt.Sprintf(upsert, fromCandles)
+		args = append(args, string(rollup.source))
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		r.logError("rollup_candles", "", err)
+		return 0, fmt.Errorf("rollup %s candles: %w", interval, err)
+	}
+
+	written, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rollup %s candles: %w", interval, err)
+	}
+
+	return written, nil
+}
+
+// Candles returns the stored candles whose open time falls in [from, to), oldest first.
+// Buckets without ticks have no row; see pricehistory.Service for gap filling.
+func (r *priceHistoryRepository) Candles(ctx context.Context, tokenAddress string, interval domain.CandleInterval, from, to time.Time) ([]*domain.Candle, error) {
+	const query = `
+		SELECT token_address, resolution, open_time, open, high, low, close, volume_usd, tick_count
+		FROM price_candles
+		WHERE token_address = $1 AND resolution = $2 AND open_time >= $3 AND open_time < $4
+		ORDER BY open_time
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tokenAddress, string(interval), from.UTC(), to.UTC())
+	if err != nil {
+		r.logError("candles", tokenAddress, err)
+		return nil, fmt.Errorf("select candles: %w", err)
+	}
+	defer rows.Close()
+
+	candles := make([]*domain.Candle, 0)
+	for rows.Next() {
+		candle, err := scanCandle(rows)
+		if err != nil {
+			r.logError("candles", tokenAddress, err)
+			return nil, err
+		}
+		candles = append(candles, candle)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("candles", tokenAddress, err)
+		return nil, fmt.Errorf("iterate candles: %w", err)
+	}
+
+	return candles, nil
+}
+
+// LastCandleBefore returns the latest candle opening before the given time, or nil when none exists.
+func (r *priceHistoryRepository) LastCandleBefore(ctx context.Context, tokenAddress string, interval domain.CandleInterval, before time.Time) (*domain.Candle, error) {
+	const query = `
+		SELECT token_address, resolution, open_time, open, high, low, close, volume_usd, tick_count
+		FROM price_candles
+		WHERE token_address = $1 AND resolution = $2 AND open_time < $3
+		ORDER BY open_time DESC
+		LIMIT 1
+	`
+
+	candle, err := scanCandle(r.db.QueryRowContext(ctx, query, tokenAddress, string(interval), before.UTC()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		r.logError("last_candle_before", tokenAddress, err)
+		return nil, err
+	}
+
+	return candle, nil
+}
+
+// LastCandles returns the latest candle of each token opening in [from, before).
+// Tokens without such a candle are omitted from the result.
+func (r *priceHistoryRepository) LastCandles(ctx context.Context, tokenAddresses []string, interval domain.CandleInterval, from, before time.Time) (map[string]*domain.Candle, error) {
+	const query = `
+		SELECT DISTINCT ON (token_address)
+			token_address, resolution, open_time, open, high, low, close, volume_usd, tick_count
+		FROM price_candles
+		WHERE token_address = ANY($1) AND resolution = $2 AND open_time >= $3 AND open_time < $4
+		ORDER BY token_address, open_time DESC
+	`
+
+	candles := make(map[string]*domain.Candle, len(tokenAddresses))
+	if len(tokenAddresses) == 0 {
+		return candles, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(tokenAddresses), string(interval), from.UTC(), before.UTC())
+	if err != nil {
+		r.logError("last_candles", "", err)
+		return nil, fmt.Errorf("select last candles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		candle, err := scanCandle(rows)
+		if err != nil {
+			r.logError("last_candles", "", err)
+			return nil, err
+		}
+		candles[candle.TokenAddress] = candle
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("last_candles", "", err)
+		return nil, fmt.Errorf("iterate last candles: %w", err)
+	}
+
+	return candles, nil
+}
+
+// Volumes returns the summed volume of each token's candles opening in
+// [from, to). Tokens without volume are omitted from the result.
+func (r *priceHistoryRepository) Volumes(ctx context.Context, tokenAddresses []string, interval domain.CandleInterval, from, to time.Time) (map[string]money.Decimal, error) {
+	const query = `
+		SELECT token_address, SUM(volume_usd)
+		FROM price_candles
+		WHERE token_address = ANY($1) AND resolution = $2 AND open_time >= $3 AND open_time < $4
+		GROUP BY token_address
+		HAVING SUM(volume_usd) > 0
+	`
+
+	volumes := make(map[string]money.Decimal, len(tokenAddresses))
+	if len(tokenAddresses) == 0 {
+		return volumes, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(tokenAddresses), string(interval), from.UTC(), to.UTC())
+	if err != nil {
+		r.logError("volumes", "", err)
+		return nil, fmt.Errorf("select volumes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			address string
+			volume  money.Decimal
+		)
+		if err := rows.Scan(&address, &volume); err != nil {
+			r.logError("volumes", "", err)
+			return nil, fmt.Errorf("scan volume: %w", err)
+		}
+		volumes[address] = volume
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("volumes", "", err)
+		return nil, fmt.Errorf("iterate volumes: %w", err)
+	}
+
+	return volumes, nil
+}
+
+func (r *priceHistoryRepository) logError(operation, tokenAddress string, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"price history repository operation failed",
+		slog.String("operation", operation),
+		slog.String("token_address", tokenAddress),
+		slog.Any("error", err),
+	)
+}
+
+func scanCandle(row rowScanner) (*domain.Candle, error) {
+	var (
+		candle     domain.Candle
+		resolution string
+	)
+
+	if err := row.Scan(
+		&candle.TokenAddress,
+		&resolution,
+		&candle.OpenTime,
+		&candle.Open,
+		&candle.High,
+		&candle.Low,
+		&candle.Close,
+		&candle.VolumeUSD,
+		&candle.Ticks,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("scan candle: %w", err)
+	}
+
+	candle.Interval = domain.CandleInterval(resolution)
+	candle.OpenTime = candle.OpenTime.UTC()
+
+	return &candle, nil
+}
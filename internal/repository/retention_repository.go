@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// retentionTables maps retention policy names to the rows they expire.
+// column is the timestamp compared with the cutoff; filter narrows the rows
+// when a policy covers only part of a table.
+var retentionTables = map[string]struct {
+	table  string
+	column string
+	filter string
+}{
+	"price_ticks":          {table: "price_ticks", column: "fetched_at"},
+	"price_candles_1m":     {table: "price_candles", column: "open_time", filter: "resolution = '1m'"},
+	"activity_events":      {table: "activity_events", column: "created_at"},
+	"transactions_archive": {table: "transactions_archive", column: "archived_at"},
+}
+
+// RetentionTables returns the policy names accepted by NewRetentionRepository.
+func RetentionTables() []string {
+	names := make([]string, 0, len(retentionTables))
+	for name := range retentionTables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RetentionRepository deletes expired rows of one table.
+type RetentionRepository interface {
+	Purge(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+}
+
+type retentionRepository struct {
+	db    *sql.DB
+	log   *slog.Logger
+	name  string
+	query string
+}
+
+// NewRetentionRepository creates a SQL-backed purger for the named retention policy.
+func NewRetentionRepository(db *sql.DB, log *slog.Logger, name string) (RetentionRepository, error) {
+	target, ok := retentionTables[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown retention table %q", name)
+	}
+
+	where := target.column + " < $1"
+	if target.filter != "" {
+		where += " AND " + target.filter
+	}
+
+	// Each batch is its own statement, so row locks are held only for one batch.
+	query := fmt.Sprintf(`
+		DELETE FROM %[1]s
+		WHERE ctid IN (
+			SELECT ctid
+			FROM %[1]s
+			WHERE %[2]s
+			LIMIT $2
+		)
+	`, target.table, where)
+
+	return &retentionRepository{
+		db:    db,
+		log:   log,
+		name:  name,
+		query: query,
+	}, nil
+}
+
+// Purge deletes rows older than cutoff in batches of at most batchSize until
+// none are left or ctx is cancelled. It returns the number of rows removed.
+func (r *retentionRepository) Purge(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("purge %s: batch size must be positive", r.name)
+	}
+
+	var deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		result, err := r.db.ExecContext(ctx, r.query, cutoff.UTC(), batchSize)
+		if err != nil {
+			r.logError(err)
+			return deleted, fmt.Errorf("purge %s: %w", r.name, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("purge %s: %w", r.name, err)
+		}
+		deleted += affected
+
+		if affected < int64(batchSize) {
+			return deleted, nil
+		}
+	}
+}
+
+func (r *retentionRepository) logError(err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"retention repository operation failed",
+		slog.String("operation", "purge"),
+		slog.String("table", r.name),
+		slog.Any("error", err),
+	)
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	// tokenActiveWindow is how recently a token must have been seen on a
+	// provider for ListTokenAddresses to keep refreshing it.
+	tokenActiveWindow = 7 * 24 * time.Hour
+	// maxListedTokens bounds the registry tokens refreshed per sync.
+	maxListedTokens = 500
+)
+
+// TokenRepository persists the registry of tokens known to the price providers.
+type TokenRepository interface {
+	Upsert(ctx context.Context, tokens []*domain.TokenInfo) error
+	Get(ctx context.Context, address string) (*domain.TokenInfo, error)
+	Search(ctx context.Context, query string, limit int) ([]*domain.TokenInfo, error)
+	ListActive(ctx context.Context) ([]*domain.TokenInfo, error)
+	ListTokenAddresses(ctx context.Context) ([]string, error)
+}
+
+type tokenRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewTokenRepository creates a SQL-backed token registry.
+func NewTokenRepository(db *sql.DB, log *slog.Logger) TokenRepository {
+	return &tokenRepository{db: db, log: log}
+}
+
+// Upsert inserts new tokens and refreshes known ones in a single statement.
+// Decimals already stored are kept when the update does not report them, and
+// the earliest known listing time wins.
+func (r *tokenRepository) Upsert(ctx context.Context, tokens []*domain.TokenInfo) error {
+	const columns = 8
+
+	seen := make(map[string]struct{}, len(tokens))
+	values := make([]string, 0, len(tokens))
+	args := make([]any, 0, len(tokens)*columns)
+	for _, token := range tokens {
+		if token == nil || token.Address == "" || token.Symbol == "" {
+			continue
+		}
+		// ON CONFLICT DO UPDATE rejects a statement that touches a row twice.
+		if _, ok := seen[token.Address]; ok {
+			continue
+		}
+		seen[token.Address] = struct{}{}
+
+		var decimals sql.NullInt16
+		if token.Decimals != nil {
+			decimals = sql.NullInt16{Int16: int16(*token.Decimals), Valid: true}
+		}
+		var listedAt sql.NullTime
+		if !token.ListedAt.IsZero() {
+			listedAt = sql.NullTime{Time: token.ListedAt.UTC(), Valid: true}
+		}
+
+		n := len(args)
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8))
+		args = append(args,
+			token.Address,
+			token.Chain,
+			token.Symbol,
+			token.Name,
+			decimals,
+			token.LiquidityUSD.Round(money.USD.Scale(), money.RoundHalfEven),
+			listedAt,
+			token.LastSeenAt.UTC(),
+		)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO tokens (address, chain, symbol, name, decimals, liquidity_usd, listed_at, last_seen_at)
+		VALUES ` + strings.Join(values, ", ") + `
+		ON CONFLICT (address) DO UPDATE SET
+			chain = EXCLUDED.chain,
+			symbol = EXCLUDED.symbol,
+			name = EXCLUDED.name,
+			decimals = COALESCE(EXCLUDED.decimals, tokens.decimals),
+			liquidity_usd = EXCLUDED.liquidity_usd,
+			listed_at = LEAST(tokens.listed_at, EXCLUDED.listed_at),
+			last_seen_at = GREATEST(tokens.last_seen_at, EXCLUDED.last_seen_at)
+	`
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		r.logError("upsert", "", err)
+		return fmt.Errorf("upsert tokens: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the registered token, or nil when the registry does not know it.
+func (r *tokenRepository) Get(ctx context.Context, address string) (*domain.TokenInfo, error) {
+	const query = `
+		SELECT address, chain, symbol, name, decimals, liquidity_usd, listed_at, last_seen_at
+		FROM tokens
+		WHERE address = $1
+	`
+
+	token, err := scanToken(r.db.QueryRowContext(ctx, query, address))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		r.logError("get", address, err)
+		return nil, fmt.Errorf("select token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Search returns up to limit candidates for query: the token with that exact
+// address and the tokens whose symbol or name contains the query's characters
+// in order. Exact symbol matches come first, then the most liquid tokens;
+// callers rank the candidates themselves.
+func (r *tokenRepository) Search(ctx context.Context, query string, limit int) ([]*domain.TokenInfo, error) {
+	const statement = `
+		SELECT address, chain, symbol, name, decimals, liquidity_usd, listed_at, last_seen_at
+		FROM tokens
+		WHERE address = $1
+			OR lower(symbol) LIKE $2 ESCAPE '\'
+			OR lower(name) LIKE $2 ESCAPE '\'
+		ORDER BY address = $1 DESC, ltrim(lower(symbol), '$') = $3 DESC, liquidity_usd DESC, symbol
+		LIMIT $4
+	`
+
+	query = strings.TrimSpace(query)
+	symbol := strings.ToLower(strings.TrimPrefix(query, "$"))
+	if symbol == "" || limit <= 0 {
+		return nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, statement, query, subsequencePattern(symbol), symbol, limit)
+	if err != nil {
+		r.logError("search", "", err)
+		return nil, fmt.Errorf("search tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*domain.TokenInfo, 0)
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			r.logError("search", "", err)
+			return nil, fmt.Errorf("scan token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("search", "", err)
+		return nil, fmt.Errorf("iterate tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// ListActive returns the most liquid tokens seen on a provider in the last
+// week, at most maxListedTokens of them.
+func (r *tokenRepository) ListActive(ctx context.Context) ([]*domain.TokenInfo, error) {
+	const query = `
+		SELECT address, chain, symbol, name, decimals, liquidity_usd, listed_at, last_seen_at
+		FROM tokens
+		WHERE last_seen_at >= $1
+		ORDER BY liquidity_usd DESC, address
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, time.Now().Add(-tokenActiveWindow).UTC(), maxListedTokens)
+	if err != nil {
+		r.logError("list_active", "", err)
+		return nil, fmt.Errorf("select active tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make([]*domain.TokenInfo, 0)
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			r.logError("list_active", "", err)
+			return nil, fmt.Errorf("scan token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("list_active", "", err)
+		return nil, fmt.Errorf("iterate active tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// ListTokenAddresses returns the addresses of the tokens ListActive returns.
+func (r *tokenRepository) ListTokenAddresses(ctx context.Context) ([]string, error) {
+	tokens, err := r.ListActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, len(tokens))
+	for i, token := range tokens {
+		addresses[i] = token.Address
+	}
+
+	return addresses, nil
+}
+
+func scanToken(row rowScanner) (*domain.TokenInfo, error) {
+	var (
+		token    domain.TokenInfo
+		decimals sql.NullInt16
+		listedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&token.Address,
+		&token.Chain,
+		&token.Symbol,
+		&token.Name,
+		&decimals,
+		&token.LiquidityUSD,
+		&listedAt,
+		&token.LastSeenAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if listedAt.Valid {
+		token.ListedAt = listedAt.Time.UTC()
+	}
+	if decimals.Valid {
+		value := int(decimals.Int16)
+		token.Decimals = &value
+	}
+
+	return &token, nil
+}
+
+// subsequencePattern builds a LIKE pattern matching any text that contains
+// the characters of query in order, e.g. "%b%n%k%" for "bnk".
+func subsequencePattern(query string) string {
+	var sb strings.Builder
+	sb.WriteByte('%')
+	for _, r := range query {
+		if r == '%' || r == '_' || r == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+		sb.WriteByte('%')
+	}
+	return sb.String()
+}
+
+func (r *tokenRepository) logError(operation, address string, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"token repository operation failed",
+		slog.String("operation", operation),
+		slog.String("token_address", address),
+		slog.Any("error", err),
+	)
+}
@@ -0,0 +1,419 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	usercache "github.com/Proton-105/himera-bot/internal/usercache"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// SellFill prices a sale against the locked position and returns the trade to record.
+// The returned trade's Amount must not exceed the position amount.
+type SellFill func(position *domain.Position) (*domain.Trade, error)
+
+// BuyExit sets the exit levels of the position a buy opened or grew. It gets
+// the position as the buy left it and its current levels, zero when it has
+// none, and returns the levels to store; nil keeps the current ones.
+type BuyExit func(position *domain.Position, current *domain.PositionExit) (*domain.PositionExit, error)
+
+// TradeRepository persists paper trades together with their balance and position effects.
+type TradeRepository interface {
+	GetBalance(ctx context.Context, userID int64) (money.Money, error)
+	ExecuteBuy(ctx context.Context, trade *domain.Trade, exit BuyExit) error
+	ExecuteSell(ctx context.Context, userID, positionID int64, fill SellFill) (*domain.Trade, error)
+	ListTrades(ctx context.Context, userID int64, filter domain.TradeFilter, after *domain.TradeCursor, limit int) ([]*domain.Trade, error)
+	CountTrades(ctx context.Context, userID int64, filter domain.TradeFilter) (int, error)
+}
+
+type tradeRepository struct {
+	db    *sql.DB
+	log   *slog.Logger
+	cache *usercache.Cache
+}
+
+// NewTradeRepository creates a SQL-backed trade repository.
+// The optional cache is invalidated whenever a trade changes the user's balance.
+func NewTradeRepository(db *sql.DB, log *slog.Logger, cache ...*usercache.Cache) TradeRepository {
+	var c *usercache.Cache
+	if len(cache) > 0 {
+		c = cache[0]
+	}
+
+	return &tradeRepository{
+		db:    db,
+		log:   log,
+		cache: c,
+	}
+}
+
+// GetBalance returns the user's USD balance.
+func (r *tradeRepository) GetBalance(ctx context.Context, userID int64) (money.Money, error) {
+	const query = `
+		SELECT COALESCE(balance, 0)
+		FROM users
+		WHERE telegram_id = $1
+	`
+
+	balance := money.ZeroOf(money.USD)
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&balance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return money.Money{}, sql.ErrNoRows
+		}
+
+		r.logError("get_balance", userID, err)
+		return money.Money{}, fmt.Errorf("select balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// ExecuteBuy records the transaction, debits the balance through the ledger and opens or grows
+// the position atomically. A non-nil exit sets the position's exit levels in the same transaction.
+// Amount and price are rounded to their column scales. On success trade.ID and trade.CreatedAt are populated.
+func (r *tradeRepository) ExecuteBuy(ctx context.Context, trade *domain.Trade, exit BuyExit) error {
+	if trade == nil {
+		return errors.New("trade is nil")
+	}
+
+	var exitErr error
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		position, err := buyInTx(ctx, tx, trade)
+		if err != nil || exit == nil {
+			return err
+		}
+		return setExitInTx(ctx, tx, position, func(position *domain.Position, current *domain.PositionExit) (*domain.PositionExit, error) {
+			levels, err := exit(position, current)
+			exitErr = err
+			return levels, err
+		})
+	})
+	if err != nil {
+		// Errors of exit are the caller's to report.
+		if !errors.Is(err, domain.ErrInsufficientBalance) && !errors.Is(err, sql.ErrNoRows) && exitErr == nil {
+			r.logError("execute_buy", trade.TelegramID, err)
+		}
+		return err
+	}
+
+	r.invalidateCache(ctx, trade.TelegramID)
+
+	return nil
+}
+
+// ExecuteSell locks the position, lets fill price the sale, then reduces or closes the position,
+// records the transaction and credits the proceeds through the ledger in a single SQL transaction.
+func (r *tradeRepository) ExecuteSell(ctx context.Context, userID, positionID int64, fill SellFill) (*domain.Trade, error) {
+	const lockQuery = `
+		SELECT id, telegram_id, token_address, token_symbol, amount, avg_price, created_at
+		FROM positions
+		WHERE telegram_id = $1 AND id = $2
+		FOR UPDATE
+	`
+
+	if fill == nil {
+		return nil, errors.New("sell fill is nil")
+	}
+
+	var trade *domain.Trade
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		position, err := scanPosition(tx.QueryRowContext(ctx, lockQuery, userID, positionID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrPositionNotFound
+			}
+			return fmt.Errorf("lock position: %w", err)
+		}
+
+		trade, err = fill(position)
+		if err != nil {
+			return err
+		}
+
+		return sellInTx(ctx, tx, position, trade)
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrPositionNotFound) {
+			r.logError("execute_sell", userID, err)
+		}
+		return nil, err
+	}
+
+	r.invalidateCache(ctx, userID)
+
+	return trade, nil
+}
+
+// buyInTx applies a buy inside tx: it records the transaction, debits the
+// balance through the ledger and opens or grows the position at the trade's
+// cost price, so buy fees count against the position's PnL. It returns the
+// position as the buy left it.
+func buyInTx(ctx context.Context, tx *sql.Tx, trade *domain.Trade) (*domain.Position, error) {
+	const positionQuery = `
+		INSERT INTO positions (telegram_id, token_address, token_symbol, amount, avg_price)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (telegram_id, token_address) DO UPDATE
+		SET avg_price = (positions.amount * positions.avg_price + EXCLUDED.amount * EXCLUDED.avg_price)
+				/ (positions.amount + EXCLUDED.amount),
+			amount = positions.amount + EXCLUDED.amount,
+			token_symbol = COALESCE(EXCLUDED.token_symbol, positions.token_symbol)
+		RETURNING id, telegram_id, token_address, token_symbol, amount, avg_price, created_at
+	`
+	const transactionQuery = `
+		INSERT INTO transactions (telegram_id, type, token_address, amount, price_usd, total_usd, fee_usd, slippage_usd)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	trade.Amount = trade.Amount.Round(domain.TokenAmountPrecision, money.RoundDown)
+	trade.PriceUSD = trade.PriceUSD.Round(domain.PricePrecision, money.RoundHalfEven)
+
+	if err := tx.QueryRowContext(
+		ctx,
+		transactionQuery,
+		trade.TelegramID,
+		trade.Type,
+		trade.TokenAddress,
+		trade.Amount,
+		trade.PriceUSD,
+		trade.TotalUSD,
+		trade.FeeUSD,
+		trade.SlippageUSD,
+	).Scan(&trade.ID, &trade.CreatedAt); err != nil {
+		return nil, fmt.Errorf("insert transaction: %w", err)
+	}
+
+	if err := postLedgerEntry(ctx, tx, &domain.LedgerEntry{
+		TelegramID: trade.TelegramID,
+		Kind:       domain.LedgerKindTrade,
+		Amount:     trade.TotalUSD.Neg(),
+		Reference:  transactionReference(trade.ID),
+	}); err != nil {
+		return nil, err
+	}
+
+	costPrice := trade.CostPriceUSD().Round(domain.PricePrecision, money.RoundHalfEven)
+	position, err := scanPosition(tx.QueryRowContext(ctx, positionQuery, trade.TelegramID, trade.TokenAddress, nullableString(trade.TokenSymbol), trade.Amount, costPrice))
+	if err != nil {
+		return nil, fmt.Errorf("upsert position: %w", err)
+	}
+
+	return position, nil
+}
+
+// sellInTx applies a sell of the position locked in tx: it reduces or closes
+// the position, records the transaction and credits the proceeds through the ledger.
+func sellInTx(ctx context.Context, tx *sql.Tx, position *domain.Position, trade *domain.Trade) error {
+	const reduceQuery = `
+		UPDATE positions
+		SET amount = amount - $2
+		WHERE id = $1
+	`
+	const closeQuery = `
+		DELETE FROM positions
+		WHERE id = $1
+	`
+	const transactionQuery = `
+		INSERT INTO transactions (telegram_id, type, token_address, amount, price_usd, total_usd, pnl_usd, fee_usd, slippage_usd, exit_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`
+
+	trade.Amount = trade.Amount.Round(domain.TokenAmountPrecision, money.RoundDown)
+	trade.PriceUSD = trade.PriceUSD.Round(domain.PricePrecision, money.RoundHalfEven)
+	if trade.Amount.Sign() <= 0 || trade.Amount.Cmp(position.Amount) > 0 {
+		return fmt.Errorf("sell amount %s is outside position amount %s", trade.Amount, position.Amount)
+	}
+
+	if trade.Amount.Equal(position.Amount) {
+		if _, err := tx.ExecContext(ctx, closeQuery, position.ID); err != nil {
+			return fmt.Errorf("close position: %w", err)
+		}
+	} else if _, err := tx.ExecContext(ctx, reduceQuery, position.ID, trade.Amount); err != nil {
+		return fmt.Errorf("reduce position: %w", err)
+	}
+
+	if err := tx.QueryRowContext(
+		ctx,
+		transactionQuery,
+		position.TelegramID,
+		trade.Type,
+		trade.TokenAddress,
+		trade.Amount,
+		trade.PriceUSD,
+		trade.TotalUSD,
+		trade.PnLUSD,
+		trade.FeeUSD,
+		trade.SlippageUSD,
+		nullableString(string(trade.ExitReason)),
+	).Scan(&trade.ID, &trade.CreatedAt); err != nil {
+		return fmt.Errorf("insert transaction: %w", err)
+	}
+
+	return postLedgerEntry(ctx, tx, &domain.LedgerEntry{
+		TelegramID: position.TelegramID,
+		Kind:       domain.LedgerKindTrade,
+		Amount:     trade.TotalUSD,
+		Reference:  transactionReference(trade.ID),
+	})
+}
+
+// ListTrades returns up to limit trades matching filter, newest first. When after is set,
+// only trades strictly older than the cursor are returned (keyset pagination on created_at, id).
+func (r *tradeRepository) ListTrades(
+	ctx context.Context,
+	userID int64,
+	filter domain.TradeFilter,
+	after *domain.TradeCursor,
+	limit int,
+) ([]*domain.Trade, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	where, args := tradeFilterClause(userID, filter)
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		where += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT id, telegram_id, type, token_address, amount, price_usd, total_usd, COALESCE(pnl_usd, 0), fee_usd, slippage_usd, COALESCE(exit_reason, ''), created_at
+		FROM transactions
+		WHERE ` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logError("list_trades", userID, err)
+		return nil, fmt.Errorf("select trades: %w", err)
+	}
+	defer rows.Close()
+
+	trades := make([]*domain.Trade, 0, limit)
+	for rows.Next() {
+		trade, err := scanTrade(rows)
+		if err != nil {
+			r.logError("list_trades", userID, err)
+			return nil, err
+		}
+		trades = append(trades, trade)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("list_trades", userID, err)
+		return nil, fmt.Errorf("iterate trades: %w", err)
+	}
+
+	return trades, nil
+}
+
+// CountTrades returns the number of trades matching filter.
+func (r *tradeRepository) CountTrades(ctx context.Context, userID int64, filter domain.TradeFilter) (int, error) {
+	where, args := tradeFilterClause(userID, filter)
+	query := `SELECT COUNT(*) FROM transactions WHERE ` + where
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		r.logError("count_trades", userID, err)
+		return 0, fmt.Errorf("count trades: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *tradeRepository) invalidateCache(ctx context.Context, userID int64) {
+	if r.cache == nil {
+		return
+	}
+
+	if err := r.cache.Invalidate(ctx, userID); err != nil && r.log != nil {
+		r.log.Warn(
+			"user cache operation failed",
+			slog.String("operation", "invalidate"),
+			slog.Int64("telegram_id", userID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func (r *tradeRepository) logError(operation string, userID int64, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"trade repository operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", userID),
+		slog.Any("error", err),
+	)
+}
+
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// tradeFilterClause builds the WHERE clause and positional arguments for a trade history query.
+func tradeFilterClause(userID int64, filter domain.TradeFilter) (string, []any) {
+	conditions := []string{"telegram_id = $1"}
+	args := []any{userID}
+
+	add := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Type != "" {
+		add("type = $%d", filter.Type)
+	}
+	if filter.TokenAddress != "" {
+		add("token_address = $%d", filter.TokenAddress)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func scanTrade(row rowScanner) (*domain.Trade, error) {
+	var (
+		trade      domain.Trade
+		tradeType  string
+		exitReason string
+	)
+
+	if err := row.Scan(
+		&trade.ID,
+		&trade.TelegramID,
+		&tradeType,
+		&trade.TokenAddress,
+		&trade.Amount,
+		&trade.PriceUSD,
+		&trade.TotalUSD,
+		&trade.PnLUSD,
+		&trade.FeeUSD,
+		&trade.SlippageUSD,
+		&exitReason,
+		&trade.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scan trade: %w", err)
+	}
+
+	trade.Type = domain.TradeType(tradeType)
+	trade.ExitReason = domain.ExitReason(exitReason)
+
+	return &trade, nil
+}
@@ -49,7 +49,7 @@ func NewUserRepository(db *sql.DB, log *slog.Logger, cache ...*usercache.Cache)
 // FindByID retrieves a user from the database by their Telegram identifier.
 func (r *userRepository) FindByID(ctx context.Context, id int64) (*domain.User, error) {
 	const query = `
-		SELECT id, telegram_id, first_name, last_name, username, balance, last_active_at, is_blocked, created_at
+		SELECT id, telegram_id, first_name, last_name, username, COALESCE(balance, 0), last_active_at, is_blocked, created_at
 		FROM users
 		WHERE telegram_id = $1
 	`
@@ -89,25 +89,32 @@ func (r *userRepository) FindByID(ctx context.Context, id int64) (*domain.User,
 	return &user, nil
 }
 
-// Create persists a new user record in the database.
+// Create persists a new user record together with its opening ledger entry.
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 	const query = `
 		INSERT INTO users (telegram_id, first_name, last_name, username, balance, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
-	if _, err := r.db.ExecContext(
-		ctx,
-		query,
-		user.TelegramID,
-		user.FirstName,
-		user.LastName,
-		user.Username,
-		user.Balance,
-		user.CreatedAt,
-	); err != nil {
+	err := withTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(
+			ctx,
+			query,
+			user.TelegramID,
+			user.FirstName,
+			user.LastName,
+			user.Username,
+			user.Balance,
+			user.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("insert user: %w", err)
+		}
+
+		return postOpeningEntry(ctx, tx, user.TelegramID)
+	})
+	if err != nil {
 		r.logError("create", user.TelegramID, err)
-		return fmt.Errorf("insert user: %w", err)
+		return err
 	}
 
 	if err := r.invalidateCache(ctx, user.TelegramID); err != nil {
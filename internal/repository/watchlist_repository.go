@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+)
+
+// WatchlistRepository persists the tokens users follow without holding them.
+type WatchlistRepository interface {
+	Add(ctx context.Context, entry *domain.WatchlistEntry) error
+	Remove(ctx context.Context, userID int64, tokenAddress string) error
+	ListByUser(ctx context.Context, userID int64) ([]*domain.WatchlistEntry, error)
+	ListTokenAddresses(ctx context.Context) ([]string, error)
+}
+
+type watchlistRepository struct {
+	db  *sql.DB
+	log *slog.Logger
+}
+
+// NewWatchlistRepository creates a SQL-backed watchlist repository.
+func NewWatchlistRepository(db *sql.DB, log *slog.Logger) WatchlistRepository {
+	return &watchlistRepository{db: db, log: log}
+}
+
+// Add puts the token on the user's watchlist and populates entry.CreatedAt.
+// It returns domain.ErrAlreadyWatching when the token is already there.
+func (r *watchlistRepository) Add(ctx context.Context, entry *domain.WatchlistEntry) error {
+	const query = `
+		INSERT INTO watchlist (telegram_id, token_address, token_symbol)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (telegram_id, token_address) DO NOTHING
+		RETURNING created_at
+	`
+
+	if entry == nil {
+		return errors.New("watchlist entry is nil")
+	}
+
+	err := r.db.QueryRowContext(ctx, query, entry.TelegramID, entry.TokenAddress, nullableString(entry.TokenSymbol)).
+		Scan(&entry.CreatedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.ErrAlreadyWatching
+	case err != nil:
+		r.logError("add", entry.TelegramID, err)
+		return fmt.Errorf("insert watchlist entry: %w", err)
+	}
+
+	return nil
+}
+
+// Remove takes the token off the user's watchlist or returns domain.ErrNotWatching.
+func (r *watchlistRepository) Remove(ctx context.Context, userID int64, tokenAddress string) error {
+	const query = `DELETE FROM watchlist WHERE telegram_id = $1 AND token_address = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userID, tokenAddress)
+	if err != nil {
+		r.logError("remove", userID, err)
+		return fmt.Errorf("delete watchlist entry: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete watchlist entry: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrNotWatching
+	}
+
+	return nil
+}
+
+// ListByUser returns the user's watchlist, oldest first.
+func (r *watchlistRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.WatchlistEntry, error) {
+	const query = `
+		SELECT telegram_id, token_address, token_symbol, created_at
+		FROM watchlist
+		WHERE telegram_id = $1
+		ORDER BY created_at, token_address
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		r.logError("list_by_user", userID, err)
+		return nil, fmt.Errorf("select watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.WatchlistEntry, 0)
+	for rows.Next() {
+		var (
+			entry  domain.WatchlistEntry
+			symbol sql.NullString
+		)
+		if err := rows.Scan(&entry.TelegramID, &entry.TokenAddress, &symbol, &entry.CreatedAt); err != nil {
+			r.logError("list_by_user", userID, err)
+			return nil, fmt.Errorf("scan watchlist entry: %w", err)
+		}
+		entry.TokenSymbol = symbol.String
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("list_by_user", userID, err)
+		return nil, fmt.Errorf("iterate watchlist: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ListTokenAddresses returns the distinct token addresses on any watchlist.
+func (r *watchlistRepository) ListTokenAddresses(ctx context.Context) ([]string, error) {
+	const query = `
+		SELECT DISTINCT token_address
+		FROM watchlist
+		ORDER BY token_address
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logError("list_token_addresses", 0, err)
+		return nil, fmt.Errorf("select watched tokens: %w", err)
+	}
+	defer rows.Close()
+
+	addresses := make([]string, 0)
+	for rows.Next() {
+		var address string
+		if err := rows.Scan(&address); err != nil {
+			r.logError("list_token_addresses", 0, err)
+			return nil, fmt.Errorf("scan watched token: %w", err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logError("list_token_addresses", 0, err)
+		return nil, fmt.Errorf("iterate watched tokens: %w", err)
+	}
+
+	return addresses, nil
+}
+
+func (r *watchlistRepository) logError(operation string, userID int64, err error) {
+	if r.log == nil {
+		return
+	}
+
+	r.log.Error(
+		"watchlist repository operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", userID),
+		slog.Any("error", err),
+	)
+}
@@ -41,6 +41,7 @@ type StateMachine interface {
 	GetState(ctx context.Context, userID int64) (*UserState, error)
 	SetState(ctx context.Context, userID int64, state State, contextData map[string]interface{}) error
 	TransitionTo(ctx context.Context, userID int64, newState State) error
+	TransitionWithContext(ctx context.Context, userID int64, newState State, contextData map[string]interface{}) error
 	ClearState(ctx context.Context, userID int64) error
 	GetAllStates(ctx context.Context) ([]*UserState, error)
 }
@@ -87,6 +88,11 @@ func (m *machine) SetState(ctx context.Context, userID int64, state State, conte
 
 // TransitionTo changes the state if the transition is allowed, guarded by a lock.
 func (m *machine) TransitionTo(ctx context.Context, userID int64, newState State) error {
+	return m.TransitionWithContext(ctx, userID, newState, nil)
+}
+
+// TransitionWithContext validates the transition like TransitionTo and stores contextData with the new state.
+func (m *machine) TransitionWithContext(ctx context.Context, userID int64, newState State, contextData map[string]interface{}) error {
 	if err := m.lock(ctx, userID); err != nil {
 		return err
 	}
@@ -112,7 +118,7 @@ func (m *machine) TransitionTo(ctx context.Context, userID int64, newState State
 
 	transitionRecorder(string(current), string(newState))
 
-	return m.saveState(ctx, userID, newState, nil)
+	return m.saveState(ctx, userID, newState, contextData)
 }
 
 // ClearState removes the stored state via the backing storage while holding the lock.
@@ -110,6 +110,26 @@ func TestStateMachine_TransitionTo(t *testing.T) {
 	}
 }
 
+func TestStateMachine_TransitionWithContext(t *testing.T) {
+	ctx := context.Background()
+	userID := int64(43)
+
+	ms := &mockStorage{}
+	ms.On("GetState", mock.Anything, userID).
+		Return(&UserState{CurrentState: StateBuyingSearch}, nil).Once()
+	ms.On("SetState", mock.Anything, userID, mock.MatchedBy(func(state *UserState) bool {
+		return state.CurrentState == StateBuyingAmount && state.Context["token_address"] == "0xabc"
+	})).Return(nil).Once()
+
+	fsm := NewStateMachine(ms, testLogger(), nil)
+	err := fsm.TransitionWithContext(ctx, userID, StateBuyingAmount, map[string]interface{}{"token_address": "0xabc"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ms.AssertExpectations(t)
+}
+
 func TestStateMachine_GetState(t *testing.T) {
 	ctx := context.Background()
 	userID := int64(7)
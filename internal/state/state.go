@@ -14,6 +14,12 @@ const (
 	StateBuyingAmount State = "buying_amount"
 	// StateBuyingConfirm indicates that the user is confirming the purchase.
 	StateBuyingConfirm State = "buying_confirm"
+	// StateSellingSelect indicates that the user is choosing which position to sell.
+	StateSellingSelect State = "selling_select"
+	// StateSellingPercent indicates that the user is choosing what share of the position to sell.
+	StateSellingPercent State = "selling_percent"
+	// StateSellingConfirm indicates that the user is confirming the sale.
+	StateSellingConfirm State = "selling_confirm"
 	// StateError indicates that the bot is in an error state and requires recovery.
 	StateError State = "error"
 )
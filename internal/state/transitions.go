@@ -4,6 +4,7 @@ package state
 var validTransitions = map[State][]State{
 	StateIdle: {
 		StateBuyingSearch,
+		StateSellingSelect,
 	},
 	StateBuyingSearch: {
 		StateBuyingAmount,
@@ -16,6 +17,17 @@ var validTransitions = map[State][]State{
 	StateBuyingConfirm: {
 		StateIdle,
 	},
+	StateSellingSelect: {
+		StateSellingPercent,
+		StateIdle,
+	},
+	StateSellingPercent: {
+		StateSellingConfirm,
+		StateSellingSelect,
+	},
+	StateSellingConfirm: {
+		StateIdle,
+	},
 }
 
 // IsTransitionAllowed reports whether moving from one state to another is valid.
@@ -15,6 +15,13 @@ func TestIsTransitionAllowed(t *testing.T) {
 		{name: "buying amount to buying confirm", from: StateBuyingAmount, to: StateBuyingConfirm, expected: true},
 		{name: "buying amount to buying search", from: StateBuyingAmount, to: StateBuyingSearch, expected: true},
 		{name: "buying confirm to idle", from: StateBuyingConfirm, to: StateIdle, expected: true},
+		{name: "idle to selling select", from: StateIdle, to: StateSellingSelect, expected: true},
+		{name: "selling select to selling percent", from: StateSellingSelect, to: StateSellingPercent, expected: true},
+		{name: "selling percent to selling confirm", from: StateSellingPercent, to: StateSellingConfirm, expected: true},
+		{name: "selling percent back to selling select", from: StateSellingPercent, to: StateSellingSelect, expected: true},
+		{name: "selling confirm to idle", from: StateSellingConfirm, to: StateIdle, expected: true},
+		{name: "idle to selling confirm invalid", from: StateIdle, to: StateSellingConfirm, expected: false},
+		{name: "selling select to buying amount invalid", from: StateSellingSelect, to: StateBuyingAmount, expected: false},
 		{name: "idle to buying confirm invalid", from: StateIdle, to: StateBuyingConfirm, expected: false},
 		{name: "buying confirm to buying amount invalid", from: StateBuyingConfirm, to: StateBuyingAmount, expected: false},
 		{name: "unknown state to buying search invalid", from: State("unknown"), to: StateBuyingSearch, expected: false},
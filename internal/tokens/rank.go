@@ -0,0 +1,157 @@
+package tokens
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// MatchKind is how a token matched a search query, from strongest to weakest.
+type MatchKind int
+
+const (
+	// MatchNone means the token does not match the query.
+	MatchNone MatchKind = iota
+	// MatchSubsequence means the symbol or name contains the query's characters in order.
+	MatchSubsequence
+	// MatchSubstring means the symbol or name contains the query.
+	MatchSubstring
+	// MatchPrefix means the symbol or name starts with the query.
+	MatchPrefix
+	// MatchSymbol means the symbol equals the query.
+	MatchSymbol
+	// MatchAddress means the address equals the query.
+	MatchAddress
+)
+
+// kindScores gives each kind its base score. Exact matches are further apart
+// than the most liquidity can add, so a deep pool never outranks them, while
+// the fuzzy kinds sit close enough for liquidity to reorder them.
+var kindScores = map[MatchKind]int{
+	MatchSubsequence: 300,
+	MatchSubstring:   350,
+	MatchPrefix:      400,
+	MatchSymbol:      700,
+	MatchAddress:     1000,
+}
+
+const (
+	// liquidityWeight is added to the score per integer digit of the token's
+	// USD liquidity, so $1M scores 7 × liquidityWeight.
+	liquidityWeight = 20
+	// maxLiquidityDigits caps the liquidity bonus.
+	maxLiquidityDigits = 12
+)
+
+// Match is a token ranked against a search query.
+type Match struct {
+	Token *domain.TokenInfo
+	Kind  MatchKind
+	Score int
+}
+
+// Rank scores candidates against query and returns the matching ones, best
+// first. Ties go to the more liquid token.
+func Rank(query string, candidates []*domain.TokenInfo) []Match {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	matches := make([]Match, 0, len(candidates))
+	for _, token := range candidates {
+		if token == nil {
+			continue
+		}
+
+		kind := matchKind(query, token)
+		if kind == MatchNone {
+			continue
+		}
+		matches = append(matches, Match{
+			Token: token,
+			Kind:  kind,
+			Score: kindScores[kind] + liquidityWeight*liquidityDigits(token.LiquidityUSD),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Token.LiquidityUSD.Cmp(matches[j].Token.LiquidityUSD) > 0
+	})
+
+	return matches
+}
+
+func matchKind(query string, token *domain.TokenInfo) MatchKind {
+	if sameAddress(token.Address, query) {
+		return MatchAddress
+	}
+
+	needle := normalize(query)
+	if needle == "" {
+		return MatchNone
+	}
+
+	symbol := normalize(token.Symbol)
+	name := strings.ToLower(token.Name)
+	switch {
+	case symbol == needle:
+		return MatchSymbol
+	case strings.HasPrefix(symbol, needle) || strings.HasPrefix(name, needle):
+		return MatchPrefix
+	case strings.Contains(symbol, needle) || strings.Contains(name, needle):
+		return MatchSubstring
+	case isSubsequence(needle, symbol) || isSubsequence(needle, name):
+		return MatchSubsequence
+	default:
+		return MatchNone
+	}
+}
+
+// liquidityDigits returns the number of integer digits of a positive
+// liquidity, capped at maxLiquidityDigits.
+func liquidityDigits(liquidity money.Decimal) int {
+	if liquidity.Sign() <= 0 {
+		return 0
+	}
+
+	whole := liquidity.StringFixed(0, money.RoundDown)
+	if whole == "0" {
+		return 0
+	}
+
+	return min(len(whole), maxLiquidityDigits)
+}
+
+// isSubsequence reports whether text contains the characters of needle in order.
+func isSubsequence(needle, text string) bool {
+	rest := []rune(needle)
+	for _, r := range text {
+		if len(rest) == 0 {
+			break
+		}
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
+
+// normalize lowercases a symbol or query and drops a leading "$".
+func normalize(value string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(value), "$"))
+}
+
+// sameAddress compares token addresses. EVM addresses are case-insensitive hex,
+// while Solana base58 addresses must match exactly.
+func sameAddress(a, b string) bool {
+	if strings.HasPrefix(a, "0x") || strings.HasPrefix(a, "0X") {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
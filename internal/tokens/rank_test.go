@@ -0,0 +1,73 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func token(address, symbol, name, liquidity string) *domain.TokenInfo {
+	d, err := money.Parse(liquidity)
+	if err != nil {
+		panic(err)
+	}
+	return &domain.TokenInfo{Token: domain.Token{Address: address, Symbol: symbol, Name: name}, LiquidityUSD: d}
+}
+
+func TestRankOrdersByMatchKindThenLiquidity(t *testing.T) {
+	candidates := []*domain.TokenInfo{
+		token("fuzzy", "PEYE", "Pop Eye Pet Egg", "900000000000"),
+		token("substring", "BABYPEPE", "Baby Pepe", "50000"),
+		token("prefix-deep", "PEPE2", "Pepe 2.0", "25000000"),
+		token("prefix", "PEPEX", "PepeX", "100"),
+		token("exact-shallow", "$PEPE", "Fake Pepe", "10"),
+		token("exact", "PEPE", "Pepe", "40000000"),
+		token("other", "BONK", "Bonk", "90000000"),
+	}
+
+	matches := Rank("pepe", candidates)
+
+	want := []struct {
+		address string
+		kind    MatchKind
+	}{
+		{"exact", MatchSymbol},
+		{"exact-shallow", MatchSymbol},
+		{"prefix-deep", MatchPrefix},
+		// A pool 9 digits deeper lifts a fuzzy name match over a shallow prefix match.
+		{"fuzzy", MatchSubsequence},
+		{"prefix", MatchPrefix},
+		{"substring", MatchSubstring},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("matches = %d, want %d", len(matches), len(want))
+	}
+	for i, w := range want {
+		if matches[i].Token.Address != w.address || matches[i].Kind != w.kind {
+			t.Errorf("match %d = %s (%d, score %d), want %s (%d)", i, matches[i].Token.Address, matches[i].Kind, matches[i].Score, w.address, w.kind)
+		}
+	}
+}
+
+func TestRankMatchKinds(t *testing.T) {
+	tests := []struct {
+		query string
+		token *domain.TokenInfo
+		want  MatchKind
+	}{
+		{"0xabcdef", token("0xABCDEF", "ABC", "", "0"), MatchAddress},
+		{"$wif", token("wif", "$WIF", "dogwifhat", "0"), MatchSymbol},
+		{"dogw", token("wif", "$WIF", "dogwifhat", "0"), MatchPrefix},
+		{"wifh", token("wif", "$WIF", "dogwifhat", "0"), MatchSubstring},
+		{"dwh", token("wif", "$WIF", "dogwifhat", "0"), MatchSubsequence},
+		{"hwd", token("wif", "$WIF", "dogwifhat", "0"), MatchNone},
+		{"DezXAZ", token("DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263", "Bonk", "Bonk", "0"), MatchNone},
+	}
+
+	for _, tt := range tests {
+		if got := matchKind(tt.query, tt.token); got != tt.want {
+			t.Errorf("matchKind(%q, %s) = %d, want %d", tt.query, tt.token.Symbol, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package tokens keeps the registry of known tokens and searches it.
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/internal/repository"
+)
+
+const (
+	// MaxResults is the number of matches Search returns.
+	MaxResults = 5
+	// candidateLimit is how many registry tokens are ranked per search.
+	candidateLimit = 50
+)
+
+// Service searches the token registry and fills it from a live directory.
+type Service struct {
+	registry  repository.TokenRepository
+	directory market.TokenDirectory
+	log       *slog.Logger
+}
+
+// NewService constructs a token Service. directory may be nil, in which case
+// only tokens already in the registry are found.
+func NewService(registry repository.TokenRepository, directory market.TokenDirectory, log *slog.Logger) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Service{registry: registry, directory: directory, log: log}
+}
+
+// Search returns up to MaxResults tokens matching query by address, symbol or
+// name, best first. The directory is asked live when the registry has no
+// exact address or symbol match, and the tokens it returns are registered.
+// It returns market.ErrTokenNotFound when nothing matches.
+func (s *Service) Search(ctx context.Context, query string) ([]Match, error) {
+	query = strings.TrimSpace(query)
+	if normalize(query) == "" {
+		return nil, market.ErrTokenNotFound
+	}
+
+	candidates, err := s.registry.Search(ctx, query, candidateLimit)
+	if err != nil {
+		return nil, fmt.Errorf("search registry: %w", err)
+	}
+
+	matches := Rank(query, candidates)
+	if len(matches) == 0 || matches[0].Kind < MatchSymbol {
+		found, err := s.lookup(ctx, query)
+		switch {
+		case err != nil && len(matches) == 0:
+			return nil, err
+		case err != nil:
+			s.log.WarnContext(ctx, "tokens: live search failed, using the registry only", slog.String("query", query), slog.Any("error", err))
+		default:
+			matches = Rank(query, merge(candidates, found))
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, market.ErrTokenNotFound
+	}
+	if len(matches) > MaxResults {
+		matches = matches[:MaxResults]
+	}
+
+	return matches, nil
+}
+
+// Get returns the token with the given address, asking the directory when
+// the registry does not know it. It returns market.ErrTokenNotFound when
+// neither does.
+func (s *Service) Get(ctx context.Context, address string) (*domain.TokenInfo, error) {
+	token, err := s.registry.Get(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("get token: %w", err)
+	}
+	if token != nil {
+		return token, nil
+	}
+
+	found, err := s.lookup(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range found {
+		if sameAddress(token.Address, address) {
+			return token, nil
+		}
+	}
+
+	return nil, market.ErrTokenNotFound
+}
+
+// lookup asks the directory about query, treating long space-free queries as
+// addresses, and registers what it finds. Failing to register is only logged.
+func (s *Service) lookup(ctx context.Context, query string) ([]*domain.TokenInfo, error) {
+	if s.directory == nil {
+		return nil, nil
+	}
+
+	var found []*domain.TokenInfo
+	if looksLikeAddress(query) {
+		described, err := s.directory.DescribeTokens(ctx, []string{query})
+		if err != nil {
+			return nil, fmt.Errorf("describe token: %w", err)
+		}
+		for _, token := range described {
+			found = append(found, token)
+		}
+	} else {
+		searched, err := s.directory.SearchTokens(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("search tokens: %w", err)
+		}
+		found = searched
+	}
+
+	if len(found) > 0 {
+		if err := s.registry.Upsert(ctx, found); err != nil {
+			s.log.WarnContext(ctx, "tokens: failed to register search results", slog.Int("tokens", len(found)), slog.Any("error", err))
+		}
+	}
+
+	return found, nil
+}
+
+// merge adds the live tokens to the registry candidates, replacing the
+// registry's copy of a token the directory also returned.
+func merge(registered, live []*domain.TokenInfo) []*domain.TokenInfo {
+	byAddress := make(map[string]int, len(registered)+len(live))
+	merged := make([]*domain.TokenInfo, 0, len(registered)+len(live))
+	for _, tokens := range [][]*domain.TokenInfo{registered, live} {
+		for _, token := range tokens {
+			if token == nil {
+				continue
+			}
+			if i, ok := byAddress[token.Address]; ok {
+				merged[i] = token
+				continue
+			}
+			byAddress[token.Address] = len(merged)
+			merged = append(merged, token)
+		}
+	}
+	return merged
+}
+
+func looksLikeAddress(query string) bool {
+	return len(query) >= 32 && !strings.ContainsAny(query, " \t")
+}
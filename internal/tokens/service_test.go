@@ -0,0 +1,149 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/internal/repository"
+)
+
+type fakeRegistry struct {
+	repository.TokenRepository
+
+	tokens map[string]*domain.TokenInfo
+}
+
+func (r *fakeRegistry) Upsert(_ context.Context, tokens []*domain.TokenInfo) error {
+	for _, token := range tokens {
+		r.tokens[token.Address] = token
+	}
+	return nil
+}
+
+func (r *fakeRegistry) Get(_ context.Context, address string) (*domain.TokenInfo, error) {
+	return r.tokens[address], nil
+}
+
+func (r *fakeRegistry) Search(_ context.Context, query string, _ int) ([]*domain.TokenInfo, error) {
+	var found []*domain.TokenInfo
+	for _, token := range r.tokens {
+		if matchKind(query, token) != MatchNone {
+			found = append(found, token)
+		}
+	}
+	return found, nil
+}
+
+type fakeDirectory struct {
+	tokens   []*domain.TokenInfo
+	err      error
+	searches int
+}
+
+func (d *fakeDirectory) DescribeTokens(_ context.Context, addresses []string) (map[string]*domain.TokenInfo, error) {
+	described := make(map[string]*domain.TokenInfo)
+	for _, token := range d.tokens {
+		for _, address := range addresses {
+			if token.Address == address {
+				described[address] = token
+			}
+		}
+	}
+	return described, d.err
+}
+
+func (d *fakeDirectory) SearchTokens(_ context.Context, query string) ([]*domain.TokenInfo, error) {
+	d.searches++
+	if d.err != nil {
+		return nil, d.err
+	}
+	var found []*domain.TokenInfo
+	for _, token := range d.tokens {
+		if matchKind(query, token) != MatchNone {
+			found = append(found, token)
+		}
+	}
+	return found, nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestServiceSearchFallsBackToDirectory(t *testing.T) {
+	registry := &fakeRegistry{tokens: map[string]*domain.TokenInfo{
+		"addr-inu": token("addr-inu", "BONKINU", "Bonk Inu", "1000"),
+	}}
+	directory := &fakeDirectory{tokens: []*domain.TokenInfo{
+		token("addr-bonk", "Bonk", "Bonk", "5000000"),
+		token("addr-inu", "BONKINU", "Bonk Inu", "2000"),
+	}}
+	service := NewService(registry, directory, discardLogger())
+	ctx := context.Background()
+
+	// Only a prefix match is registered, so the directory is asked.
+	matches, err := service.Search(ctx, "bonk")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(matches) != 2 || matches[0].Token.Address != "addr-bonk" || matches[0].Kind != MatchSymbol {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+	if registry.tokens["addr-bonk"] == nil || registry.tokens["addr-inu"].LiquidityUSD.String() != "2000" {
+		t.Errorf("live results were not registered: %+v", registry.tokens)
+	}
+
+	// Now the exact symbol is registered and the directory is left alone.
+	if _, err := service.Search(ctx, "$BONK"); err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if directory.searches != 1 {
+		t.Errorf("directory searches = %d, want 1", directory.searches)
+	}
+
+	if _, err := service.Search(ctx, "nothing"); !errors.Is(err, market.ErrTokenNotFound) {
+		t.Errorf("unknown token error = %v", err)
+	}
+}
+
+func TestServiceSearchKeepsRegistryMatchesWhenDirectoryFails(t *testing.T) {
+	registry := &fakeRegistry{tokens: map[string]*domain.TokenInfo{
+		"addr-inu": token("addr-inu", "BONKINU", "Bonk Inu", "1000"),
+	}}
+	directory := &fakeDirectory{err: errors.New("api down")}
+	service := NewService(registry, directory, discardLogger())
+
+	matches, err := service.Search(context.Background(), "bonk")
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("Search = %+v, %v", matches, err)
+	}
+
+	if _, err := service.Search(context.Background(), "wif"); err == nil || errors.Is(err, market.ErrTokenNotFound) {
+		t.Errorf("expected the directory error, got %v", err)
+	}
+}
+
+func TestServiceGetRegistersUnknownToken(t *testing.T) {
+	const address = "EKpQGSJtjMFqKZ9KQanSqYXRcF8fBopzLHYxdM65zcjm"
+
+	registry := &fakeRegistry{tokens: map[string]*domain.TokenInfo{}}
+	directory := &fakeDirectory{tokens: []*domain.TokenInfo{token(address, "$WIF", "dogwifhat", "1")}}
+	service := NewService(registry, directory, discardLogger())
+
+	got, err := service.Get(context.Background(), address)
+	if err != nil || got.Symbol != "$WIF" {
+		t.Fatalf("Get = %+v, %v", got, err)
+	}
+	if registry.tokens[address] == nil {
+		t.Error("token was not registered")
+	}
+
+	if _, err := service.Get(context.Background(), "So11111111111111111111111111111111111111112"); !errors.Is(err, market.ErrTokenNotFound) {
+		t.Errorf("unknown address error = %v", err)
+	}
+}
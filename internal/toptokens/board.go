@@ -0,0 +1,99 @@
+// Package toptokens ranks registered tokens by volume and price moves for the
+// top tokens screen.
+package toptokens
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// Tab is one ranking of the top tokens screen.
+type Tab string
+
+const (
+	// TabVolume ranks tokens by paper-trading volume over the last 24h.
+	TabVolume Tab = "volume"
+	// TabGainers ranks rising tokens by their 24h change.
+	TabGainers Tab = "gainers"
+	// TabLosers ranks falling tokens by their 24h change.
+	TabLosers Tab = "losers"
+	// TabNew ranks tokens by listing time, newest first.
+	TabNew Tab = "new"
+)
+
+// Tabs lists the tabs in display order.
+var Tabs = []Tab{TabVolume, TabGainers, TabLosers, TabNew}
+
+// ParseTab returns the tab with the given name.
+func ParseTab(name string) (Tab, bool) {
+	for _, tab := range Tabs {
+		if string(tab) == name {
+			return tab, true
+		}
+	}
+	return "", false
+}
+
+// BoardSize is the number of tokens a tab shows.
+const BoardSize = 10
+
+// Entry is a ranked token with the figures the tabs are ranked by.
+// ChangePct is only meaningful when HasChange is true.
+type Entry struct {
+	Address   string        `json:"address"`
+	Chain     string        `json:"chain,omitempty"`
+	Symbol    string        `json:"symbol"`
+	Name      string        `json:"name,omitempty"`
+	PriceUSD  money.Decimal `json:"price_usd"`
+	ChangePct money.Decimal `json:"change_pct"`
+	HasChange bool          `json:"has_change"`
+	VolumeUSD money.Decimal `json:"volume_usd"`
+	ListedAt  time.Time     `json:"listed_at"`
+}
+
+// Boards holds every tab as computed at ComputedAt.
+type Boards struct {
+	ComputedAt time.Time       `json:"computed_at"`
+	Tabs       map[Tab][]Entry `json:"tabs"`
+}
+
+// rank builds every tab from entries, which should be ordered by liquidity so
+// that ties go to the deeper pool. Only tokens listed after listedSince
+// appear on TabNew.
+func rank(entries []Entry, listedSince time.Time) map[Tab][]Entry {
+	tabs := map[Tab][]Entry{
+		TabVolume: top(entries, func(e Entry) bool { return e.VolumeUSD.Sign() > 0 }, func(a, b Entry) bool {
+			return a.VolumeUSD.Cmp(b.VolumeUSD) > 0
+		}),
+		TabGainers: top(entries, func(e Entry) bool { return e.HasChange && e.ChangePct.Sign() > 0 }, func(a, b Entry) bool {
+			return a.ChangePct.Cmp(b.ChangePct) > 0
+		}),
+		TabLosers: top(entries, func(e Entry) bool { return e.HasChange && e.ChangePct.Sign() < 0 }, func(a, b Entry) bool {
+			return a.ChangePct.Cmp(b.ChangePct) < 0
+		}),
+		TabNew: top(entries, func(e Entry) bool { return e.ListedAt.After(listedSince) }, func(a, b Entry) bool {
+			return a.ListedAt.After(b.ListedAt)
+		}),
+	}
+
+	return tabs
+}
+
+// top returns at most BoardSize of the entries that keep, ordered by less.
+func top(entries []Entry, keep func(Entry) bool, less func(a, b Entry) bool) []Entry {
+	board := make([]Entry, 0, BoardSize)
+	for _, entry := range entries {
+		if keep(entry) {
+			board = append(board, entry)
+		}
+	}
+
+	sort.SliceStable(board, func(i, j int) bool { return less(board[i], board[j]) })
+	if len(board) > BoardSize {
+		board = board[:BoardSize]
+	}
+
+	return board
+}
@@ -0,0 +1,68 @@
+package toptokens
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+const (
+	cacheKey = "top_tokens"
+	// cacheTTL drops boards the refresh job stopped updating rather than
+	// showing them indefinitely.
+	cacheTTL = time.Hour
+)
+
+// Cache stores the computed boards in Redis.
+type Cache struct {
+	client *redis.Client
+}
+
+// NewCache constructs a boards cache backed by the provided Redis client.
+func NewCache(client *redis.Client) *Cache {
+	return &Cache{client: client}
+}
+
+// Save replaces the cached boards.
+func (c *Cache) Save(ctx context.Context, boards *Boards) error {
+	if c == nil || c.client == nil || boards == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(boards)
+	if err != nil {
+		return fmt.Errorf("encode top tokens: %w", err)
+	}
+
+	if err := c.client.Set(ctx, cacheKey, payload, cacheTTL).Err(); err != nil {
+		return fmt.Errorf("set top tokens: %w", err)
+	}
+
+	return nil
+}
+
+// Load returns the cached boards, or nil when none are cached.
+func (c *Cache) Load(ctx context.Context) (*Boards, error) {
+	if c == nil || c.client == nil {
+		return nil, nil
+	}
+
+	raw, err := c.client.Get(ctx, cacheKey).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get top tokens: %w", err)
+	}
+
+	var boards Boards
+	if err := json.Unmarshal(raw, &boards); err != nil {
+		return nil, fmt.Errorf("decode top tokens: %w", err)
+	}
+
+	return &boards, nil
+}
@@ -0,0 +1,168 @@
+package toptokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	// changeWindow is the period of the volume and change figures.
+	changeWindow = 24 * time.Hour
+	// maxReferenceGap is how far before the window start the reference candle
+	// may open. Registered tokens are priced every 30 minutes.
+	maxReferenceGap = time.Hour
+	// maxQuoteAge drops tokens whose cached price was not refreshed recently.
+	maxQuoteAge = time.Hour
+	// newListingWindow is how recently a token must have been listed to appear on TabNew.
+	newListingWindow = 30 * 24 * time.Hour
+)
+
+// ErrNotReady indicates that the boards have not been computed yet.
+var ErrNotReady = errors.New("top tokens are not computed yet")
+
+// Registry lists the tokens to rank; repository.TokenRepository implements it.
+type Registry interface {
+	ListActive(ctx context.Context) ([]*domain.TokenInfo, error)
+}
+
+// PriceCache returns cached quotes; pricecache.Cache implements it.
+type PriceCache interface {
+	GetMany(ctx context.Context, tokenAddresses []string) (map[string]*domain.PriceQuote, error)
+}
+
+// PriceHistory summarises candles; repository.PriceHistoryRepository implements it.
+type PriceHistory interface {
+	LastCandles(ctx context.Context, tokenAddresses []string, interval domain.CandleInterval, from, before time.Time) (map[string]*domain.Candle, error)
+	Volumes(ctx context.Context, tokenAddresses []string, interval domain.CandleInterval, from, to time.Time) (map[string]money.Decimal, error)
+}
+
+// Store keeps the computed boards; Cache implements it on Redis.
+type Store interface {
+	Save(ctx context.Context, boards *Boards) error
+	Load(ctx context.Context) (*Boards, error)
+}
+
+// Service computes the top tokens boards and serves them from the store.
+type Service struct {
+	registry Registry
+	prices   PriceCache
+	history  PriceHistory
+	store    Store
+	log      *slog.Logger
+	now      func() time.Time
+}
+
+// NewService constructs a top tokens Service.
+func NewService(registry Registry, prices PriceCache, history PriceHistory, store Store, log *slog.Logger) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Service{registry: registry, prices: prices, history: history, store: store, log: log, now: time.Now}
+}
+
+// Refresh recomputes every tab from the registry, the price cache and the
+// candles, and stores the result. Tokens without a recent cached price are
+// left out.
+func (s *Service) Refresh(ctx context.Context) error {
+	tokens, err := s.registry.ListActive(ctx)
+	if err != nil {
+		return fmt.Errorf("list tokens: %w", err)
+	}
+
+	now := s.now().UTC()
+	boards := &Boards{ComputedAt: now, Tabs: map[Tab][]Entry{}}
+	if len(tokens) > 0 {
+		entries, err := s.entries(ctx, tokens, now)
+		if err != nil {
+			return err
+		}
+		boards.Tabs = rank(entries, now.Add(-newListingWindow))
+	}
+
+	if err := s.store.Save(ctx, boards); err != nil {
+		return fmt.Errorf("store top tokens: %w", err)
+	}
+
+	s.log.DebugContext(ctx, "top tokens refreshed",
+		slog.Int("tokens", len(tokens)),
+		slog.Int("volume", len(boards.Tabs[TabVolume])),
+		slog.Int("gainers", len(boards.Tabs[TabGainers])),
+		slog.Int("losers", len(boards.Tabs[TabLosers])),
+		slog.Int("new", len(boards.Tabs[TabNew])),
+	)
+
+	return nil
+}
+
+// Board returns the entries of one tab and when they were computed.
+func (s *Service) Board(ctx context.Context, tab Tab) ([]Entry, time.Time, error) {
+	boards, err := s.store.Load(ctx)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("load top tokens: %w", err)
+	}
+	if boards == nil {
+		return nil, time.Time{}, ErrNotReady
+	}
+
+	return boards.Tabs[tab], boards.ComputedAt, nil
+}
+
+// entries prices the tokens and attaches their 24h change and volume.
+func (s *Service) entries(ctx context.Context, tokens []*domain.TokenInfo, now time.Time) ([]Entry, error) {
+	addresses := make([]string, len(tokens))
+	for i, token := range tokens {
+		addresses[i] = token.Address
+	}
+
+	quotes, err := s.prices.GetMany(ctx, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("load cached prices: %w", err)
+	}
+
+	start := now.Add(-changeWindow)
+	references, err := s.history.LastCandles(ctx, addresses, domain.CandleInterval1m, start.Add(-maxReferenceGap), start.Add(time.Minute))
+	if err != nil {
+		return nil, fmt.Errorf("load reference candles: %w", err)
+	}
+
+	// The current hour and the 23 before it.
+	volumeFrom := domain.CandleInterval1h.Truncate(now).Add(-changeWindow + time.Hour)
+	volumes, err := s.history.Volumes(ctx, addresses, domain.CandleInterval1h, volumeFrom, now.Add(time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("load volumes: %w", err)
+	}
+
+	entries := make([]Entry, 0, len(tokens))
+	for _, token := range tokens {
+		quote := quotes[token.Address]
+		if quote == nil || quote.PriceUSD.Sign() <= 0 || now.Sub(quote.FetchedAt) > maxQuoteAge {
+			continue
+		}
+
+		entry := Entry{
+			Address:   token.Address,
+			Chain:     token.Chain,
+			Symbol:    token.Symbol,
+			Name:      token.Name,
+			PriceUSD:  quote.PriceUSD,
+			VolumeUSD: volumes[token.Address],
+			ListedAt:  token.ListedAt,
+		}
+		if reference := references[token.Address]; reference != nil && reference.Close.Sign() > 0 {
+			change, err := quote.PriceUSD.Sub(reference.Close).Mul(money.NewFromInt(100)).Quo(reference.Close, 2, money.RoundHalfEven)
+			if err == nil {
+				entry.ChangePct, entry.HasChange = change, true
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,157 @@
+package toptokens
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type fakeRegistry []*domain.TokenInfo
+
+func (r fakeRegistry) ListActive(context.Context) ([]*domain.TokenInfo, error) {
+	return r, nil
+}
+
+type fakeCache map[string]*domain.PriceQuote
+
+func (c fakeCache) GetMany(_ context.Context, addresses []string) (map[string]*domain.PriceQuote, error) {
+	quotes := make(map[string]*domain.PriceQuote)
+	for _, address := range addresses {
+		if quote, ok := c[address]; ok {
+			quotes[address] = quote
+		}
+	}
+	return quotes, nil
+}
+
+type fakeHistory struct {
+	closes  map[string]string
+	volumes map[string]string
+}
+
+func (h fakeHistory) LastCandles(_ context.Context, _ []string, _ domain.CandleInterval, _, _ time.Time) (map[string]*domain.Candle, error) {
+	candles := make(map[string]*domain.Candle)
+	for address, price := range h.closes {
+		candles[address] = &domain.Candle{TokenAddress: address, Close: mustDecimal(price)}
+	}
+	return candles, nil
+}
+
+func (h fakeHistory) Volumes(_ context.Context, _ []string, _ domain.CandleInterval, _, _ time.Time) (map[string]money.Decimal, error) {
+	volumes := make(map[string]money.Decimal)
+	for address, volume := range h.volumes {
+		volumes[address] = mustDecimal(volume)
+	}
+	return volumes, nil
+}
+
+func mustDecimal(value string) money.Decimal {
+	d, err := money.Parse(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func newTestCache(t *testing.T) *Cache {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewCache(client)
+}
+
+func addresses(entries []Entry) []string {
+	out := make([]string, len(entries))
+	for i, entry := range entries {
+		out[i] = entry.Address
+	}
+	return out
+}
+
+func TestServiceRefreshRanksTabs(t *testing.T) {
+	now := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	registry := fakeRegistry{
+		{Token: domain.Token{Address: "up", Symbol: "UP"}, ListedAt: now.Add(-48 * time.Hour)},
+		{Token: domain.Token{Address: "down", Symbol: "DOWN"}, ListedAt: now.Add(-400 * 24 * time.Hour)},
+		{Token: domain.Token{Address: "flat", Symbol: "FLAT"}, ListedAt: now.Add(-time.Hour)},
+		{Token: domain.Token{Address: "stale", Symbol: "STALE"}, ListedAt: now.Add(-time.Minute)},
+		{Token: domain.Token{Address: "unpriced", Symbol: "NONE"}},
+	}
+	prices := fakeCache{
+		"up":    {TokenAddress: "up", PriceUSD: mustDecimal("1.5"), FetchedAt: now.Add(-10 * time.Minute)},
+		"down":  {TokenAddress: "down", PriceUSD: mustDecimal("0.5"), FetchedAt: now},
+		"flat":  {TokenAddress: "flat", PriceUSD: mustDecimal("2"), FetchedAt: now},
+		"stale": {TokenAddress: "stale", PriceUSD: mustDecimal("9"), FetchedAt: now.Add(-2 * time.Hour)},
+	}
+	history := fakeHistory{
+		closes:  map[string]string{"up": "1", "down": "1", "flat": "2", "stale": "1"},
+		volumes: map[string]string{"down": "300", "flat": "1200", "stale": "5000"},
+	}
+	service := NewService(registry, prices, history, newTestCache(t), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, _, err := service.Board(ctx, TabVolume); !errors.Is(err, ErrNotReady) {
+		t.Fatalf("board before refresh error = %v", err)
+	}
+
+	if err := service.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	want := map[Tab][]string{
+		TabVolume:  {"flat", "down"},
+		TabGainers: {"up"},
+		TabLosers:  {"down"},
+		TabNew:     {"flat", "up"},
+	}
+	for tab, wantAddresses := range want {
+		entries, computedAt, err := service.Board(ctx, tab)
+		if err != nil {
+			t.Fatalf("Board(%s): %v", tab, err)
+		}
+		if !computedAt.Equal(now) {
+			t.Errorf("computed at %s, want %s", computedAt, now)
+		}
+		got := addresses(entries)
+		if len(got) != len(wantAddresses) {
+			t.Errorf("%s = %v, want %v", tab, got, wantAddresses)
+			continue
+		}
+		for i := range got {
+			if got[i] != wantAddresses[i] {
+				t.Errorf("%s = %v, want %v", tab, got, wantAddresses)
+				break
+			}
+		}
+	}
+
+	gainers, _, _ := service.Board(ctx, TabGainers)
+	if !gainers[0].ChangePct.Equal(mustDecimal("50")) || !gainers[0].PriceUSD.Equal(mustDecimal("1.5")) {
+		t.Errorf("unexpected gainer: %+v", gainers[0])
+	}
+}
+
+func TestRankLimitsBoardSize(t *testing.T) {
+	entries := make([]Entry, 0, BoardSize+5)
+	for i := 0; i < BoardSize+5; i++ {
+		entries = append(entries, Entry{Address: string(rune('a' + i)), VolumeUSD: money.NewFromInt(int64(i + 1))})
+	}
+
+	board := rank(entries, time.Time{})[TabVolume]
+	if len(board) != BoardSize || board[0].VolumeUSD.Cmp(money.NewFromInt(BoardSize+5)) != 0 {
+		t.Fatalf("unexpected board: %d entries, first %+v", len(board), board[0])
+	}
+}
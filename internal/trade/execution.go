@@ -0,0 +1,225 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// maxFeeBps caps a fee tier at 10%.
+const maxFeeBps = 1000
+
+var (
+	// ErrInsufficientLiquidity indicates that no pool liquidity is known for the token.
+	ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+	// ErrPriceImpactTooHigh indicates that an order would move the price more than allowed.
+	ErrPriceImpactTooHigh = errors.New("price impact too high")
+
+	errNoExecutionModel = errors.New("no execution model configured")
+)
+
+// PriceImpactError reports an order whose fill price is too far from the
+// market price. It matches ErrPriceImpactTooHigh with errors.Is.
+type PriceImpactError struct {
+	ImpactPct money.Decimal
+	MaxPct    money.Decimal
+}
+
+func (e *PriceImpactError) Error() string {
+	return fmt.Sprintf("price impact %s%% exceeds %s%%", e.ImpactPct, e.MaxPct)
+}
+
+// Is reports whether target is ErrPriceImpactTooHigh.
+func (e *PriceImpactError) Is(target error) bool {
+	return target == ErrPriceImpactTooHigh
+}
+
+// FeeTier charges FeeBps basis points on orders worth at least MinUSD.
+type FeeTier struct {
+	MinUSD money.Decimal
+	FeeBps int
+}
+
+// Fill is the execution of an order: the tokens traded, the average price
+// they traded at and what the trade cost on top of the market price.
+type Fill struct {
+	Amount      money.Decimal
+	MidPriceUSD money.Decimal
+	PriceUSD    money.Decimal
+	// ImpactPct is how far PriceUSD is from MidPriceUSD, in percent.
+	ImpactPct money.Decimal
+	// ValueUSD is what a buy debits, fee included, or what a sell credits, fee deducted.
+	ValueUSD    money.Money
+	FeeBps      int
+	FeeUSD      money.Money
+	SlippageUSD money.Money
+}
+
+// ExecutionModel fills paper orders as a constant-product pool holding half
+// of the token's USD liquidity on each side would, and charges a fee that
+// depends on the order size.
+type ExecutionModel struct {
+	tiers     []FeeTier
+	maxImpact money.Decimal
+}
+
+// NewExecutionModel constructs an execution model. Tiers may come in any
+// order; an order pays the fee of the highest tier it reaches, or nothing
+// below the lowest. Orders moving the price by more than maxPriceImpactBps
+// basis points are rejected; zero disables the check.
+func NewExecutionModel(tiers []FeeTier, maxPriceImpactBps int) (*ExecutionModel, error) {
+	if maxPriceImpactBps < 0 || maxPriceImpactBps > 10000 {
+		return nil, fmt.Errorf("max price impact %d bps is outside 0..10000", maxPriceImpactBps)
+	}
+
+	sorted := make([]FeeTier, len(tiers))
+	copy(sorted, tiers)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MinUSD.Cmp(sorted[j].MinUSD) < 0 })
+	for i, tier := range sorted {
+		if tier.MinUSD.Sign() < 0 {
+			return nil, fmt.Errorf("fee tier minimum %s is negative", tier.MinUSD)
+		}
+		if tier.FeeBps < 0 || tier.FeeBps > maxFeeBps {
+			return nil, fmt.Errorf("fee tier %s: fee %d bps is outside 0..%d", tier.MinUSD, tier.FeeBps, maxFeeBps)
+		}
+		if i > 0 && tier.MinUSD.Equal(sorted[i-1].MinUSD) {
+			return nil, fmt.Errorf("fee tier %s is listed twice", tier.MinUSD)
+		}
+	}
+
+	return &ExecutionModel{tiers: sorted, maxImpact: bps(maxPriceImpactBps)}, nil
+}
+
+// Buy fills a purchase worth amountUSD, fee included, at the market price
+// against pools holding liquidityUSD. A nil model refuses to fill.
+func (m *ExecutionModel) Buy(amountUSD money.Money, price, liquidityUSD money.Decimal) (*Fill, error) {
+	if amountUSD.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if m == nil {
+		return nil, errNoExecutionModel
+	}
+
+	feeBps := m.feeBps(amountUSD.Amount())
+	fee := amountUSD.Mul(bps(feeBps), money.RoundHalfEven)
+	net, err := amountUSD.Sub(fee)
+	if err != nil {
+		return nil, err
+	}
+
+	// Paying net into a pool with reserve R of USD fills at price·(R+net)/R.
+	reserve := poolReserve(liquidityUSD)
+	if reserve.Sign() <= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+	impact, err := m.impact(net.Amount(), reserve)
+	if err != nil {
+		return nil, err
+	}
+
+	fillPrice, err := price.Mul(reserve.Add(net.Amount())).Quo(reserve, domain.PricePrecision, money.RoundUp)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := buyAmount(net, fillPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fill{
+		Amount:      amount,
+		MidPriceUSD: price,
+		PriceUSD:    fillPrice,
+		ImpactPct:   impact,
+		ValueUSD:    amountUSD,
+		FeeBps:      feeBps,
+		FeeUSD:      fee,
+		SlippageUSD: money.NewMoney(net.Amount().Sub(amount.Mul(price)), money.USD, money.RoundHalfEven),
+	}, nil
+}
+
+// Sell fills the sale of amount tokens at the market price against pools
+// holding liquidityUSD. A nil model refuses to fill.
+func (m *ExecutionModel) Sell(amount, price, liquidityUSD money.Decimal) (*Fill, error) {
+	if m == nil {
+		return nil, errNoExecutionModel
+	}
+
+	// Selling tokens worth V into a pool with reserve R of USD fills at price·R/(R+V).
+	value := amount.Mul(price)
+	reserve := poolReserve(liquidityUSD)
+	if reserve.Sign() <= 0 {
+		return nil, ErrInsufficientLiquidity
+	}
+	impact, err := m.impact(value, reserve.Add(value))
+	if err != nil {
+		return nil, err
+	}
+
+	fillPrice, err := price.Mul(reserve).Quo(reserve.Add(value), domain.PricePrecision, money.RoundDown)
+	if err != nil {
+		return nil, err
+	}
+
+	gross := money.NewMoney(amount.Mul(fillPrice), money.USD, money.RoundDown)
+	feeBps := m.feeBps(value)
+	fee := gross.Mul(bps(feeBps), money.RoundHalfEven)
+	proceeds, err := gross.Sub(fee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fill{
+		Amount:      amount,
+		MidPriceUSD: price,
+		PriceUSD:    fillPrice,
+		ImpactPct:   impact,
+		ValueUSD:    proceeds,
+		FeeBps:      feeBps,
+		FeeUSD:      fee,
+		SlippageUSD: money.NewMoney(value.Sub(gross.Amount()), money.USD, money.RoundHalfEven),
+	}, nil
+}
+
+// impact returns part/whole in percent, rejecting it above the model's maximum.
+func (m *ExecutionModel) impact(part, whole money.Decimal) (money.Decimal, error) {
+	fraction, err := part.Quo(whole, 8, money.RoundHalfEven)
+	if err != nil {
+		return money.Zero, err
+	}
+
+	hundred := money.NewFromInt(100)
+	if m.maxImpact.Sign() > 0 && fraction.Cmp(m.maxImpact) > 0 {
+		return money.Zero, &PriceImpactError{
+			ImpactPct: fraction.Mul(hundred).Round(2, money.RoundHalfEven),
+			MaxPct:    m.maxImpact.Mul(hundred).Round(2, money.RoundHalfEven),
+		}
+	}
+
+	return fraction.Mul(hundred).Round(2, money.RoundHalfEven), nil
+}
+
+// feeBps returns the fee of the highest tier valueUSD reaches.
+func (m *ExecutionModel) feeBps(valueUSD money.Decimal) int {
+	fee := 0
+	for _, tier := range m.tiers {
+		if valueUSD.Cmp(tier.MinUSD) < 0 {
+			break
+		}
+		fee = tier.FeeBps
+	}
+	return fee
+}
+
+// poolReserve is the USD side of the token's pools, half of their liquidity.
+func poolReserve(liquidityUSD money.Decimal) money.Decimal {
+	return liquidityUSD.Mul(money.New(5, 1))
+}
+
+// bps converts basis points to a fraction.
+func bps(value int) money.Decimal {
+	return money.New(int64(value), 4)
+}
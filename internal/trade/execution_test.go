@@ -0,0 +1,146 @@
+package trade
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func testModel(t *testing.T, maxPriceImpactBps int) *ExecutionModel {
+	t.Helper()
+
+	model, err := NewExecutionModel([]FeeTier{
+		{MinUSD: money.NewFromInt(10000), FeeBps: 20},
+		{MinUSD: money.Zero, FeeBps: 30},
+	}, maxPriceImpactBps)
+	if err != nil {
+		t.Fatalf("NewExecutionModel: %v", err)
+	}
+	return model
+}
+
+func TestExecutionModelBuy(t *testing.T) {
+	model := testModel(t, 1000)
+	amountUSD, _ := money.ParseMoney("1000", money.USD)
+
+	fill, err := model.Buy(amountUSD, money.NewFromInt(1), money.NewFromInt(200000))
+	if err != nil {
+		t.Fatalf("Buy: %v", err)
+	}
+
+	checks := []struct{ name, got, want string }{
+		{"fee", fill.FeeUSD.StringFixed(8, money.RoundHalfEven), "3.00000000"},
+		{"price", fill.PriceUSD.String(), "1.009970000000000000"},
+		{"amount", fill.Amount.String(), "987.158034397061298850"},
+		{"impact", fill.ImpactPct.String(), "1.00"},
+		{"slippage", fill.SlippageUSD.StringFixed(8, money.RoundHalfEven), "9.84196560"},
+		{"value", fill.ValueUSD.StringFixed(2, money.RoundHalfEven), "1000.00"},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %s, want %s", check.name, check.got, check.want)
+		}
+	}
+	if fill.FeeBps != 30 {
+		t.Errorf("fee bps = %d, want 30", fill.FeeBps)
+	}
+}
+
+func TestExecutionModelSell(t *testing.T) {
+	model := testModel(t, 1000)
+
+	fill, err := model.Sell(money.NewFromInt(1000), money.NewFromInt(1), money.NewFromInt(200000))
+	if err != nil {
+		t.Fatalf("Sell: %v", err)
+	}
+
+	checks := []struct{ name, got, want string }{
+		{"price", fill.PriceUSD.String(), "0.990099009900990099"},
+		{"fee", fill.FeeUSD.StringFixed(8, money.RoundHalfEven), "2.97029703"},
+		{"proceeds", fill.ValueUSD.StringFixed(8, money.RoundHalfEven), "987.12871287"},
+		{"slippage", fill.SlippageUSD.StringFixed(8, money.RoundHalfEven), "9.90099010"},
+		{"impact", fill.ImpactPct.String(), "0.99"},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %s, want %s", check.name, check.got, check.want)
+		}
+	}
+}
+
+func TestExecutionModelFeeTiers(t *testing.T) {
+	model := testModel(t, 0)
+
+	testCases := []struct {
+		amount string
+		want   int
+	}{
+		{amount: "9999.99", want: 30},
+		{amount: "10000", want: 20},
+		{amount: "250000", want: 20},
+	}
+
+	for _, tc := range testCases {
+		amountUSD, _ := money.ParseMoney(tc.amount, money.USD)
+		fill, err := model.Buy(amountUSD, money.NewFromInt(1), money.NewFromInt(1_000_000))
+		if err != nil {
+			t.Fatalf("Buy(%s): %v", tc.amount, err)
+		}
+		if fill.FeeBps != tc.want {
+			t.Errorf("Buy(%s) fee = %d bps, want %d", tc.amount, fill.FeeBps, tc.want)
+		}
+	}
+}
+
+func TestExecutionModelRejects(t *testing.T) {
+	model := testModel(t, 500)
+	amountUSD, _ := money.ParseMoney("10000", money.USD)
+
+	_, err := model.Buy(amountUSD, money.NewFromInt(1), money.NewFromInt(200000))
+	var impactErr *PriceImpactError
+	if !errors.As(err, &impactErr) || !errors.Is(err, ErrPriceImpactTooHigh) {
+		t.Fatalf("expected a price impact error, got %v", err)
+	}
+	if got := impactErr.ImpactPct.String(); got != "9.98" {
+		t.Errorf("impact = %s, want 9.98", got)
+	}
+
+	if _, err := model.Sell(money.NewFromInt(10), money.NewFromInt(1), money.Zero); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Errorf("expected ErrInsufficientLiquidity, got %v", err)
+	}
+}
+
+func TestNilExecutionModelRefusesFills(t *testing.T) {
+	var model *ExecutionModel
+	amountUSD, _ := money.ParseMoney("100", money.USD)
+
+	if _, err := model.Buy(amountUSD, money.NewFromInt(4), money.NewFromInt(200000)); !errors.Is(err, errNoExecutionModel) {
+		t.Errorf("Buy: expected errNoExecutionModel, got %v", err)
+	}
+	if _, err := model.Sell(money.NewFromInt(25), money.NewFromInt(4), money.NewFromInt(200000)); !errors.Is(err, errNoExecutionModel) {
+		t.Errorf("Sell: expected errNoExecutionModel, got %v", err)
+	}
+}
+
+func TestNewExecutionModelValidates(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tiers  []FeeTier
+		impact int
+	}{
+		{name: "fee above cap", tiers: []FeeTier{{MinUSD: money.Zero, FeeBps: 1001}}},
+		{name: "negative minimum", tiers: []FeeTier{{MinUSD: money.NewFromInt(-1), FeeBps: 10}}},
+		{name: "duplicate tier", tiers: []FeeTier{{MinUSD: money.Zero, FeeBps: 10}, {MinUSD: money.Zero, FeeBps: 20}}},
+		{name: "impact above 100%", impact: 10001},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewExecutionModel(tc.tiers, tc.impact); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,556 @@
+// Package trade implements the paper-trading use cases.
+package trade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// MinPriceSources is how many agreeing feeds a price needs before it is traded on.
+const MinPriceSources = 2
+
+var (
+	// ErrInvalidAmount indicates that a user supplied amount cannot be traded.
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrInvalidPercent indicates that a sell percentage is outside 1..100.
+	ErrInvalidPercent = errors.New("invalid percent")
+)
+
+// BuyQuote describes the expected outcome of a purchase before it is confirmed.
+// PriceUSD is the market price; Fill breaks down the price, fee and slippage
+// the purchase would fill with.
+type BuyQuote struct {
+	Token       domain.Token
+	PriceUSD    money.Decimal
+	AmountUSD   money.Money
+	TokenAmount money.Decimal
+	BalanceUSD  money.Money
+	Fill        *Fill
+}
+
+// BuyOrder is a confirmed request to spend AmountUSD on a token. Exit, when
+// set, sets the exit levels of the position in the buy's SQL transaction.
+type BuyOrder struct {
+	UserID    int64
+	Token     domain.Token
+	AmountUSD money.Money
+	Exit      ExitLevels
+}
+
+// ExitLevels sets the exit levels of the position a buy opened or grew, like
+// repository.BuyExit, given the market price the buy filled against.
+type ExitLevels func(position *domain.Position, current *domain.PositionExit, priceUSD money.Decimal) (*domain.PositionExit, error)
+
+// SellQuote describes the expected outcome of selling a share of a position.
+// PriceUSD is the market price; ProceedsUSD and PnLUSD are net of the fee and
+// slippage broken down in Fill.
+type SellQuote struct {
+	Position    *domain.Position
+	Percent     int
+	TokenAmount money.Decimal
+	PriceUSD    money.Decimal
+	ProceedsUSD money.Money
+	PnLUSD      money.Money
+	Fill        *Fill
+}
+
+// SellOrder is a confirmed request to sell Percent of a position.
+type SellOrder struct {
+	UserID     int64
+	PositionID int64
+	Percent    int
+}
+
+// PriceCache returns a cached quote for a token. A quote past its staleness
+// threshold is returned with an error matching market.ErrStalePrice.
+type PriceCache interface {
+	Get(ctx context.Context, tokenAddress string) (*domain.PriceQuote, error)
+}
+
+// Market is the state of a token's market that a fill is priced against.
+type Market struct {
+	PriceUSD     money.Decimal
+	LiquidityUSD money.Decimal
+}
+
+// Tokens describes tokens, including the USD liquidity of their pools;
+// tokens.Service implements it.
+type Tokens interface {
+	Get(ctx context.Context, address string) (*domain.TokenInfo, error)
+}
+
+// Service executes paper trades against market prices.
+type Service struct {
+	repo      repository.TradeRepository
+	positions repository.PositionRepository
+	market    market.Source
+	prices    PriceCache
+	tokens    Tokens
+	execution *ExecutionModel
+	log       *slog.Logger
+}
+
+// NewService constructs a trade Service. The optional price cache is consulted
+// before the live market source. Buys and sells fill through the execution
+// model, which takes pool liquidity from tokens; without a model every fill
+// is refused.
+//
+// Without a market source the service is inert: tokens cannot be looked up
+// and only fresh cached prices are known, so /buy answers that prices are
+// unavailable. NewService logs a warning in that case so that a deployment
+// missing its source is not mistaken for a quiet market.
+func NewService(
+	repo repository.TradeRepository,
+	positions repository.PositionRepository,
+	source market.Source,
+	prices PriceCache,
+	tokens Tokens,
+	execution *ExecutionModel,
+	log *slog.Logger,
+) *Service {
+	if source == nil && log != nil {
+		log.Warn("trade service has no market source; token lookups and trades are refused until one is configured")
+	}
+
+	return &Service{repo: repo, positions: positions, market: source, prices: prices, tokens: tokens, execution: execution, log: log}
+}
+
+// FindToken resolves user input into a tradable token.
+func (s *Service) FindToken(ctx context.Context, query string) (*domain.Token, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, market.ErrTokenNotFound
+	}
+
+	if s.market == nil {
+		return nil, market.ErrPriceUnavailable
+	}
+
+	token, err := s.market.FindToken(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// LatestPrice returns the current USD price of the token. A fresh cached
+// price confirmed by MinPriceSources feeds is used as is; otherwise the live
+// source is asked. When the live source fails and the cache only holds a stale
+// price, the stale price error is returned so the trade is refused.
+func (s *Service) LatestPrice(ctx context.Context, tokenAddress string) (money.Decimal, error) {
+	var staleErr error
+	if s.prices != nil {
+		cached, err := s.prices.Get(ctx, tokenAddress)
+		switch {
+		case errors.Is(err, market.ErrStalePrice):
+			staleErr = err
+		case err != nil:
+			s.logError("latest_price.cache", 0, err)
+		case cached != nil && cached.PriceUSD.Sign() > 0 && len(cached.Sources) >= MinPriceSources:
+			return cached.PriceUSD, nil
+		}
+	}
+
+	if s.market == nil {
+		if staleErr != nil {
+			return money.Zero, staleErr
+		}
+		return money.Zero, market.ErrPriceUnavailable
+	}
+
+	quote, err := s.market.LatestPrice(ctx, tokenAddress)
+	if err != nil {
+		if staleErr != nil {
+			return money.Zero, staleErr
+		}
+		return money.Zero, err
+	}
+
+	if quote == nil || quote.PriceUSD.Sign() <= 0 {
+		return money.Zero, market.ErrPriceUnavailable
+	}
+
+	return quote.PriceUSD, nil
+}
+
+// QuoteBuy prices a purchase and verifies that the user can afford it.
+func (s *Service) QuoteBuy(ctx context.Context, userID int64, token domain.Token, amountUSD money.Money) (*BuyQuote, error) {
+	if amountUSD.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	balance, err := s.repo.GetBalance(ctx, userID)
+	if err != nil {
+		s.logError("quote_buy.balance", userID, err)
+		return nil, fmt.Errorf("get balance: %w", err)
+	}
+
+	cmp, err := balance.Cmp(amountUSD)
+	if err != nil {
+		return nil, err
+	}
+	if cmp < 0 {
+		return nil, domain.ErrInsufficientBalance
+	}
+
+	fill, price, err := s.fillBuy(ctx, token.Address, amountUSD)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BuyQuote{
+		Token:       token,
+		PriceUSD:    price,
+		AmountUSD:   amountUSD,
+		TokenAmount: fill.Amount,
+		BalanceUSD:  balance,
+		Fill:        fill,
+	}, nil
+}
+
+// ExecuteBuy fills the order at the latest price through the execution model
+// and persists the trade.
+func (s *Service) ExecuteBuy(ctx context.Context, order BuyOrder) (*domain.Trade, error) {
+	if order.AmountUSD.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	state, err := s.LatestMarket(ctx, order.Token.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := s.BuyTrade(order.UserID, order.Token, order.AmountUSD, state)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		exit    repository.BuyExit
+		exitErr error
+	)
+	if order.Exit != nil {
+		exit = func(position *domain.Position, current *domain.PositionExit) (*domain.PositionExit, error) {
+			levels, err := order.Exit(position, current, state.PriceUSD)
+			exitErr = err
+			return levels, err
+		}
+	}
+
+	if err := s.repo.ExecuteBuy(ctx, trade, exit); err != nil {
+		// Levels that exit rejects, such as a stop-loss above the price, roll the buy back.
+		if errors.Is(err, domain.ErrInsufficientBalance) || (exitErr != nil && errors.Is(err, exitErr)) {
+			return nil, err
+		}
+		s.logError("execute_buy", order.UserID, err)
+		return nil, fmt.Errorf("execute buy: %w", err)
+	}
+
+	if s.log != nil {
+		s.log.Info("buy executed",
+			slog.Int64("telegram_id", order.UserID),
+			slog.Int64("transaction_id", trade.ID),
+			slog.String("token_address", trade.TokenAddress),
+			slog.String("total_usd", trade.TotalUSD.StringFixed(2, money.RoundHalfEven)),
+			slog.String("fee_usd", trade.FeeUSD.StringFixed(4, money.RoundHalfEven)),
+			slog.String("slippage_usd", trade.SlippageUSD.StringFixed(4, money.RoundHalfEven)),
+		)
+	}
+
+	return trade, nil
+}
+
+// ListPositions returns the user's open positions.
+func (s *Service) ListPositions(ctx context.Context, userID int64) ([]*domain.Position, error) {
+	positions, err := s.positions.ListByUser(ctx, userID)
+	if err != nil {
+		s.logError("list_positions", userID, err)
+		return nil, fmt.Errorf("list positions: %w", err)
+	}
+
+	return positions, nil
+}
+
+// GetPosition returns a single position owned by the user.
+func (s *Service) GetPosition(ctx context.Context, userID, positionID int64) (*domain.Position, error) {
+	position, err := s.positions.GetByID(ctx, userID, positionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrPositionNotFound) {
+			return nil, err
+		}
+		s.logError("get_position", userID, err)
+		return nil, fmt.Errorf("get position: %w", err)
+	}
+
+	return position, nil
+}
+
+// QuoteSell prices the sale of percent of a position at the latest price.
+func (s *Service) QuoteSell(ctx context.Context, userID, positionID int64, percent int) (*SellQuote, error) {
+	if percent < 1 || percent > 100 {
+		return nil, ErrInvalidPercent
+	}
+
+	position, err := s.GetPosition(ctx, userID, positionID)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.LatestMarket(ctx, position.TokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	fill, err := s.execution.Sell(sellAmount(position, percent), state.PriceUSD, state.LiquidityUSD)
+	if err != nil {
+		return nil, err
+	}
+	trade := filledSell(position, fill)
+
+	return &SellQuote{
+		Position:    position,
+		Percent:     percent,
+		TokenAmount: trade.Amount,
+		PriceUSD:    state.PriceUSD,
+		ProceedsUSD: trade.TotalUSD,
+		PnLUSD:      trade.PnLUSD,
+		Fill:        fill,
+	}, nil
+}
+
+// ExecuteSell sells the requested share of a position at the latest price
+// through the execution model. Selling 100% closes the position.
+func (s *Service) ExecuteSell(ctx context.Context, order SellOrder) (*domain.Trade, error) {
+	if order.Percent < 1 || order.Percent > 100 {
+		return nil, ErrInvalidPercent
+	}
+
+	position, err := s.GetPosition(ctx, order.UserID, order.PositionID)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.LatestMarket(ctx, position.TokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	trade, err := s.repo.ExecuteSell(ctx, order.UserID, order.PositionID, func(locked *domain.Position) (*domain.Trade, error) {
+		return s.SellTrade(locked, order.Percent, state)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrPositionNotFound) || errors.Is(err, ErrInsufficientLiquidity) || errors.Is(err, ErrPriceImpactTooHigh) {
+			return nil, err
+		}
+		s.logError("execute_sell", order.UserID, err)
+		return nil, fmt.Errorf("execute sell: %w", err)
+	}
+
+	if s.log != nil {
+		s.log.Info("sell executed",
+			slog.Int64("telegram_id", order.UserID),
+			slog.Int64("transaction_id", trade.ID),
+			slog.Int64("position_id", order.PositionID),
+			slog.Int("percent", order.Percent),
+			slog.String("token_address", trade.TokenAddress),
+			slog.String("total_usd", trade.TotalUSD.StringFixed(2, money.RoundHalfEven)),
+			slog.String("pnl_usd", trade.PnLUSD.StringFixed(2, money.RoundHalfEven)),
+			slog.String("fee_usd", trade.FeeUSD.StringFixed(4, money.RoundHalfEven)),
+			slog.String("slippage_usd", trade.SlippageUSD.StringFixed(4, money.RoundHalfEven)),
+		)
+	}
+
+	return trade, nil
+}
+
+// ParsePercent parses a whole sell percentage such as "25" or "25%".
+func ParsePercent(input string) (int, error) {
+	cleaned := strings.TrimSpace(input)
+	cleaned = strings.TrimSuffix(cleaned, "%")
+
+	percent, err := strconv.Atoi(strings.TrimSpace(cleaned))
+	if err != nil || percent < 1 || percent > 100 {
+		return 0, ErrInvalidPercent
+	}
+
+	return percent, nil
+}
+
+// buyAmount converts a USD spend into a token quantity, rounding down so the
+// position never exceeds what was paid for.
+func buyAmount(amountUSD money.Money, price money.Decimal) (money.Decimal, error) {
+	amount, err := amountUSD.Amount().Quo(price, domain.TokenAmountPrecision, money.RoundDown)
+	if err != nil {
+		return money.Zero, market.ErrPriceUnavailable
+	}
+	return amount, nil
+}
+
+// BuyTrade fills the purchase of amountUSD of token in state through the
+// execution model. It does no I/O, so limit orders and DCA runs call it
+// inside the repository callback that locks them.
+func (s *Service) BuyTrade(userID int64, token domain.Token, amountUSD money.Money, state Market) (*domain.Trade, error) {
+	fill, err := s.execution.Buy(amountUSD, state.PriceUSD, state.LiquidityUSD)
+	if err != nil {
+		return nil, err
+	}
+
+	return filledBuy(userID, token, fill), nil
+}
+
+// SellTrade fills the sale of percent of position in state through the
+// execution model. Sold amounts and proceeds round down; selling 100% uses
+// the exact position amount. Like BuyTrade it does no I/O.
+func (s *Service) SellTrade(position *domain.Position, percent int, state Market) (*domain.Trade, error) {
+	if percent < 1 || percent > 100 {
+		return nil, ErrInvalidPercent
+	}
+
+	fill, err := s.execution.Sell(sellAmount(position, percent), state.PriceUSD, state.LiquidityUSD)
+	if err != nil {
+		return nil, err
+	}
+
+	return filledSell(position, fill), nil
+}
+
+func filledBuy(userID int64, token domain.Token, fill *Fill) *domain.Trade {
+	return &domain.Trade{
+		TelegramID:   userID,
+		Type:         domain.TradeTypeBuy,
+		TokenAddress: token.Address,
+		TokenSymbol:  token.Symbol,
+		Amount:       fill.Amount,
+		PriceUSD:     fill.PriceUSD,
+		TotalUSD:     fill.ValueUSD,
+		FeeUSD:       fill.FeeUSD,
+		SlippageUSD:  fill.SlippageUSD,
+	}
+}
+
+// filledSell computes the trade of a sale of position filled as fill. PnL is
+// net of the sale's fee; the fee of the purchase is in the average price.
+func filledSell(position *domain.Position, fill *Fill) *domain.Trade {
+	pnl := fill.PriceUSD.Sub(position.AvgPriceUSD).Mul(fill.Amount).Sub(fill.FeeUSD.Amount())
+
+	return &domain.Trade{
+		TelegramID:   position.TelegramID,
+		Type:         domain.TradeTypeSell,
+		TokenAddress: position.TokenAddress,
+		TokenSymbol:  position.TokenSymbol,
+		Amount:       fill.Amount,
+		PriceUSD:     fill.PriceUSD,
+		TotalUSD:     fill.ValueUSD,
+		PnLUSD:       money.NewMoney(pnl, money.USD, money.RoundHalfEven),
+		FeeUSD:       fill.FeeUSD,
+		SlippageUSD:  fill.SlippageUSD,
+	}
+}
+
+// sellAmount is percent of the position, rounded down; 100% is the exact position amount.
+func sellAmount(position *domain.Position, percent int) money.Decimal {
+	if percent >= 100 {
+		return position.Amount
+	}
+	return position.Amount.Mul(money.New(int64(percent), 2)).Round(domain.TokenAmountPrecision, money.RoundDown)
+}
+
+// ParseAmountUSD parses a user supplied USD amount such as "100", "$25.5" or
+// "12,75". A comma is only accepted as the decimal separator of up to two
+// cents digits; thousands separators such as "1,000" are rejected rather
+// than read as a decimal.
+func ParseAmountUSD(input string) (money.Money, error) {
+	cleaned := strings.TrimSpace(input)
+	cleaned = strings.TrimPrefix(cleaned, "$")
+	cleaned = strings.ReplaceAll(cleaned, " ", "")
+
+	if whole, cents, ok := strings.Cut(cleaned, ","); ok {
+		if strings.Contains(whole, ".") || len(cents) < 1 || len(cents) > 2 || strings.ContainsAny(cents, ",.") {
+			return money.Money{}, ErrInvalidAmount
+		}
+		cleaned = whole + "." + cents
+	}
+
+	amount, err := money.ParseMoney(cleaned, money.USD)
+	if err != nil || amount.Sign() <= 0 {
+		return money.Money{}, ErrInvalidAmount
+	}
+
+	return amount, nil
+}
+
+// fillBuy fills a purchase of amountUSD of the token at the latest price and
+// returns the fill along with that price.
+func (s *Service) fillBuy(ctx context.Context, tokenAddress string, amountUSD money.Money) (*Fill, money.Decimal, error) {
+	state, err := s.LatestMarket(ctx, tokenAddress)
+	if err != nil {
+		return nil, money.Zero, err
+	}
+
+	fill, err := s.execution.Buy(amountUSD, state.PriceUSD, state.LiquidityUSD)
+	if err != nil {
+		return nil, money.Zero, err
+	}
+
+	return fill, state.PriceUSD, nil
+}
+
+// LatestMarket returns the latest price of the token, as LatestPrice
+// confirms it, and the USD liquidity of its pools.
+func (s *Service) LatestMarket(ctx context.Context, tokenAddress string) (Market, error) {
+	price, err := s.LatestPrice(ctx, tokenAddress)
+	if err != nil {
+		return Market{}, err
+	}
+
+	liquidity, err := s.liquidity(ctx, tokenAddress)
+	if err != nil {
+		return Market{}, err
+	}
+
+	return Market{PriceUSD: price, LiquidityUSD: liquidity}, nil
+}
+
+// liquidity returns the USD liquidity of the token's pools, zero when no
+// pool is known.
+func (s *Service) liquidity(ctx context.Context, tokenAddress string) (money.Decimal, error) {
+	if s.tokens == nil {
+		return money.Zero, nil
+	}
+
+	token, err := s.tokens.Get(ctx, tokenAddress)
+	if err != nil {
+		if errors.Is(err, market.ErrTokenNotFound) {
+			return money.Zero, nil
+		}
+		s.logError("liquidity", 0, err)
+		return money.Zero, fmt.Errorf("get liquidity: %w", err)
+	}
+	if token == nil {
+		return money.Zero, nil
+	}
+
+	return token.LiquidityUSD, nil
+}
+
+func (s *Service) logError(operation string, telegramID int64, err error) {
+	if s == nil || s.log == nil || err == nil {
+		return
+	}
+
+	s.log.Error("trade service operation failed",
+		slog.String("operation", operation),
+		slog.Int64("telegram_id", telegramID),
+		slog.Any("error", err),
+	)
+}
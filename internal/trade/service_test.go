@@ -0,0 +1,236 @@
+package trade
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func TestParseAmountUSD(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "integer", input: "100", want: "100.00"},
+		{name: "dollar sign", input: "$25.5", want: "25.50"},
+		{name: "comma separator", input: "12,75", want: "12.75"},
+		{name: "comma with one decimal", input: "12,5", want: "12.50"},
+		{name: "thousands separator", input: "1,000", wantErr: true},
+		{name: "thousands separator with decimals", input: "1,000.50", wantErr: true},
+		{name: "two commas", input: "1,000,50", wantErr: true},
+		{name: "surrounding spaces", input: "  50 ", want: "50.00"},
+		{name: "zero", input: "0", wantErr: true},
+		{name: "negative", input: "-10", wantErr: true},
+		{name: "fraction syntax", input: "1/3", wantErr: true},
+		{name: "exponent syntax", input: "1e3", wantErr: true},
+		{name: "beyond storage precision", input: "0.000000001", wantErr: true},
+		{name: "text", input: "ten", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseAmountUSD(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Fatalf("expected ErrInvalidAmount, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.StringFixed(2, money.RoundHalfEven) != tc.want {
+				t.Errorf("ParseAmountUSD(%q) = %s, want %s", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{name: "plain", input: "25", want: 25},
+		{name: "percent sign", input: "50%", want: 50},
+		{name: "spaces", input: " 100 % ", want: 100},
+		{name: "zero", input: "0", wantErr: true},
+		{name: "above hundred", input: "101", wantErr: true},
+		{name: "fraction", input: "12.5", wantErr: true},
+		{name: "text", input: "half", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParsePercent(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidPercent) {
+					t.Fatalf("expected ErrInvalidPercent, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("ParsePercent(%q) = %d, want %d", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSellTrade(t *testing.T) {
+	position := &domain.Position{
+		TelegramID:   1,
+		TokenAddress: "0xabc",
+		Amount:       money.NewFromInt(3),
+		AvgPriceUSD:  money.NewFromInt(2),
+	}
+
+	testCases := []struct {
+		name     string
+		percent  int
+		wantAmt  string
+		wantUSD  string
+		wantPnL  string
+		priceUSD money.Decimal
+	}{
+		{name: "partial profit", percent: 25, priceUSD: money.NewFromInt(4), wantAmt: "0.75", wantUSD: "3.00", wantPnL: "1.50"},
+		{name: "full loss", percent: 100, priceUSD: money.NewFromInt(1), wantAmt: "3.00", wantUSD: "3.00", wantPnL: "-3.00"},
+		{name: "rounds sold amount down", percent: 33, priceUSD: money.NewFromInt(2), wantAmt: "0.99", wantUSD: "1.98", wantPnL: "0.00"},
+	}
+
+	// Without fee tiers and against deep pools the sale fills at the market price.
+	model, err := NewExecutionModel(nil, 0)
+	if err != nil {
+		t.Fatalf("NewExecutionModel: %v", err)
+	}
+	svc := NewService(nil, nil, nil, nil, nil, model, nil)
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := svc.SellTrade(position, tc.percent, Market{PriceUSD: tc.priceUSD, LiquidityUSD: money.NewFromInt(2_000_000_000_000)})
+			if err != nil {
+				t.Fatalf("SellTrade: %v", err)
+			}
+
+			if got.Type != domain.TradeTypeSell {
+				t.Fatalf("expected sell trade, got %s", got.Type)
+			}
+			if amount := got.Amount.StringFixed(2, money.RoundHalfEven); amount != tc.wantAmt {
+				t.Errorf("amount = %s, want %s", amount, tc.wantAmt)
+			}
+			if total := got.TotalUSD.StringFixed(2, money.RoundHalfEven); total != tc.wantUSD {
+				t.Errorf("total = %s, want %s", total, tc.wantUSD)
+			}
+			if pnl := got.PnLUSD.StringFixed(2, money.RoundHalfEven); pnl != tc.wantPnL {
+				t.Errorf("pnl = %s, want %s", pnl, tc.wantPnL)
+			}
+		})
+	}
+
+	if !position.Amount.Equal(money.NewFromInt(3)) {
+		t.Fatalf("SellTrade must not mutate the position")
+	}
+}
+
+type stubSource struct {
+	quote *domain.PriceQuote
+	err   error
+	calls int
+}
+
+func (s *stubSource) FindToken(context.Context, string) (*domain.Token, error) {
+	return nil, market.ErrTokenNotFound
+}
+
+func (s *stubSource) LatestPrice(context.Context, string) (*domain.PriceQuote, error) {
+	s.calls++
+	return s.quote, s.err
+}
+
+type stubCache struct {
+	quote *domain.PriceQuote
+	err   error
+}
+
+func (s *stubCache) Get(context.Context, string) (*domain.PriceQuote, error) {
+	return s.quote, s.err
+}
+
+func TestLatestPrice(t *testing.T) {
+	stale := &market.StalePriceError{TokenAddress: "0xabc", Age: 10 * time.Minute}
+	confirmed := &domain.PriceQuote{PriceUSD: money.NewFromInt(2), Sources: []string{"coingecko", "dexscreener"}}
+	single := &domain.PriceQuote{PriceUSD: money.NewFromInt(2), Sources: []string{"dexscreener"}}
+	live := &domain.PriceQuote{PriceUSD: money.NewFromInt(3)}
+
+	testCases := []struct {
+		name      string
+		cache     *stubCache
+		source    *stubSource
+		want      string
+		wantErr   error
+		liveCalls int
+	}{
+		{name: "fresh confirmed cache", cache: &stubCache{quote: confirmed}, source: &stubSource{quote: live}, want: "2.00"},
+		{name: "single source cache", cache: &stubCache{quote: single}, source: &stubSource{quote: live}, want: "3.00", liveCalls: 1},
+		{name: "cache miss", cache: &stubCache{}, source: &stubSource{quote: live}, want: "3.00", liveCalls: 1},
+		{name: "stale cache, live ok", cache: &stubCache{quote: confirmed, err: stale}, source: &stubSource{quote: live}, want: "3.00", liveCalls: 1},
+		{name: "stale cache, live down", cache: &stubCache{quote: confirmed, err: stale}, source: &stubSource{err: market.ErrPriceUnavailable}, wantErr: market.ErrStalePrice, liveCalls: 1},
+		{name: "cache error, live down", cache: &stubCache{err: errors.New("redis down")}, source: &stubSource{err: market.ErrPriceUnavailable}, wantErr: market.ErrPriceUnavailable, liveCalls: 1},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			svc := NewService(nil, nil, tc.source, tc.cache, nil, nil, nil)
+
+			got, err := svc.LatestPrice(context.Background(), "0xabc")
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("expected %v, got %v", tc.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			} else if price := got.StringFixed(2, money.RoundHalfEven); price != tc.want {
+				t.Errorf("price = %s, want %s", price, tc.want)
+			}
+
+			if tc.source.calls != tc.liveCalls {
+				t.Errorf("live source calls = %d, want %d", tc.source.calls, tc.liveCalls)
+			}
+		})
+	}
+}
+
+func TestServiceWithoutSourceIsInert(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil, nil, nil, nil)
+	amountUSD, _ := money.ParseMoney("10", money.USD)
+
+	if _, err := svc.FindToken(context.Background(), "PEPE"); !errors.Is(err, market.ErrPriceUnavailable) {
+		t.Errorf("FindToken: expected ErrPriceUnavailable, got %v", err)
+	}
+	if _, err := svc.LatestPrice(context.Background(), "0xabc"); !errors.Is(err, market.ErrPriceUnavailable) {
+		t.Errorf("LatestPrice: expected ErrPriceUnavailable, got %v", err)
+	}
+	order := BuyOrder{UserID: 1, Token: domain.Token{Address: "0xabc"}, AmountUSD: amountUSD}
+	if _, err := svc.ExecuteBuy(context.Background(), order); !errors.Is(err, market.ErrPriceUnavailable) {
+		t.Errorf("ExecuteBuy: expected ErrPriceUnavailable, got %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/Proton-105/himera-bot/internal/domain"
 	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
 )
 
 // Service provides business operations over users.
@@ -47,7 +48,7 @@ func (s *Service) GetOrCreate(ctx context.Context, telegramUser *telebot.User) (
 		FirstName:    telegramUser.FirstName,
 		LastName:     telegramUser.LastName,
 		Username:     telegramUser.Username,
-		Balance:      0,
+		Balance:      money.ZeroOf(money.USD),
 		LastActiveAt: now,
 		CreatedAt:    now,
 	}
@@ -22,7 +22,9 @@ func NewCache(client *redis.Client) *Cache {
 	return &Cache{client: client}
 }
 
-// Get fetches a cached user profile if it exists.
+// Get fetches a cached user profile if it exists. An entry that no longer
+// decodes, e.g. one written before a change to domain.User, is evicted and
+// reported as a miss so that the caller reloads the user from Postgres.
 func (c *Cache) Get(ctx context.Context, userID int64) (*domain.User, error) {
 	if c == nil || c.client == nil {
 		return nil, nil
@@ -38,7 +40,10 @@ func (c *Cache) Get(ctx context.Context, userID int64) (*domain.User, error) {
 
 	var user domain.User
 	if err := json.Unmarshal(data, &user); err != nil {
-		return nil, fmt.Errorf("decode cached user: %w", err)
+		if err := c.client.Del(ctx, cacheKey(userID)).Err(); err != nil {
+			return nil, fmt.Errorf("evict undecodable cached user: %w", err)
+		}
+		return nil, nil
 	}
 
 	return &user, nil
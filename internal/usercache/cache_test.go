@@ -0,0 +1,59 @@
+package usercache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+func newTestCache(t *testing.T) (*miniredis.Miniredis, *Cache) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return mr, NewCache(client)
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	_, cache := newTestCache(t)
+	ctx := context.Background()
+
+	balance, _ := money.ParseMoney("1250.5", money.USD)
+	if err := cache.Set(ctx, 42, &domain.User{TelegramID: 42, Balance: balance}, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	user, err := cache.Get(ctx, 42)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if user == nil || user.TelegramID != 42 || user.Balance.StringFixed(2, money.RoundHalfEven) != "1250.50" {
+		t.Fatalf("cached user = %+v", user)
+	}
+}
+
+func TestCacheEvictsUndecodableEntries(t *testing.T) {
+	mr, cache := newTestCache(t)
+
+	// A balance cached as a bare number, as before balances were money.Money.
+	mr.Set(cacheKey(42), `{"TelegramID":42,"Balance":1250.5}`)
+
+	user, err := cache.Get(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("expected a miss, got error %v", err)
+	}
+	if user != nil {
+		t.Fatalf("expected a miss, got %+v", user)
+	}
+	if mr.Exists(cacheKey(42)) {
+		t.Error("undecodable entry was not evicted")
+	}
+}
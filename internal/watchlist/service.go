@@ -0,0 +1,231 @@
+// Package watchlist lets users follow token prices without holding the tokens.
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	// MaxEntries bounds how many tokens a user may watch.
+	MaxEntries = 30
+	// changeWindow is the period Item.Change covers.
+	changeWindow = 24 * time.Hour
+	// maxReferenceGap is how far before the window start the reference candle
+	// may open. Watched tokens are priced every 30 minutes.
+	maxReferenceGap = time.Hour
+)
+
+// ErrWatchlistFull indicates that the user already watches MaxEntries tokens.
+var ErrWatchlistFull = errors.New("watchlist is full")
+
+// Tokens resolves user input into tokens; trade.Service implements it.
+type Tokens interface {
+	FindToken(ctx context.Context, query string) (*domain.Token, error)
+}
+
+// PriceCache returns cached quotes; pricecache.Cache implements it.
+type PriceCache interface {
+	GetMany(ctx context.Context, tokenAddresses []string) (map[string]*domain.PriceQuote, error)
+}
+
+// PriceHistory looks up past prices; repository.PriceHistoryRepository implements it.
+type PriceHistory interface {
+	LastCandleBefore(ctx context.Context, tokenAddress string, interval domain.CandleInterval, before time.Time) (*domain.Candle, error)
+}
+
+// Item is a watched token with its cached price. PriceUSD is zero when no
+// price is cached and OpenUSD is zero when no price from a day ago is known.
+type Item struct {
+	Entry     *domain.WatchlistEntry
+	PriceUSD  money.Decimal
+	UpdatedAt time.Time
+	OpenUSD   money.Decimal
+}
+
+// Change returns the 24h price change in percent, rounded to two decimals,
+// and whether it is known.
+func (i Item) Change() (money.Decimal, bool) {
+	if i.PriceUSD.Sign() <= 0 || i.OpenUSD.Sign() <= 0 {
+		return money.Zero, false
+	}
+
+	change, err := i.PriceUSD.Sub(i.OpenUSD).Mul(money.NewFromInt(100)).Quo(i.OpenUSD, 2, money.RoundHalfEven)
+	if err != nil {
+		return money.Zero, false
+	}
+
+	return change, true
+}
+
+// Service manages users' watchlists.
+type Service struct {
+	repo    repository.WatchlistRepository
+	tokens  Tokens
+	prices  PriceCache
+	history PriceHistory
+	log     *slog.Logger
+	now     func() time.Time
+}
+
+// NewService constructs a watchlist Service. history may be nil, in which
+// case 24h changes are not shown.
+func NewService(repo repository.WatchlistRepository, tokens Tokens, prices PriceCache, history PriceHistory, log *slog.Logger) *Service {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return &Service{repo: repo, tokens: tokens, prices: prices, history: history, log: log, now: time.Now}
+}
+
+// Watch resolves query to a token and adds it to the user's watchlist. It
+// returns domain.ErrAlreadyWatching together with the token when the token is
+// already watched.
+func (s *Service) Watch(ctx context.Context, userID int64, query string) (*domain.Token, error) {
+	token, err := s.tokens.FindToken(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchlist: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.TokenAddress == token.Address {
+			return token, domain.ErrAlreadyWatching
+		}
+	}
+	if len(entries) >= MaxEntries {
+		return nil, ErrWatchlistFull
+	}
+
+	entry := &domain.WatchlistEntry{TelegramID: userID, TokenAddress: token.Address, TokenSymbol: token.Symbol}
+	if err := s.repo.Add(ctx, entry); err != nil {
+		if errors.Is(err, domain.ErrAlreadyWatching) {
+			return token, err
+		}
+		return nil, fmt.Errorf("add to watchlist: %w", err)
+	}
+
+	s.log.Info("token watched", slog.Int64("telegram_id", userID), slog.String("token_address", token.Address))
+
+	return token, nil
+}
+
+// Unwatch removes a token from the user's watchlist. query is matched
+// against the watched addresses and symbols first and only resolved through
+// the market when nothing matches. It returns domain.ErrNotWatching when the
+// token is not watched.
+func (s *Service) Unwatch(ctx context.Context, userID int64, query string) (*domain.WatchlistEntry, error) {
+	entries, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchlist: %w", err)
+	}
+
+	entry := findEntry(entries, query)
+	if entry == nil {
+		token, err := s.tokens.FindToken(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		if entry = findEntry(entries, token.Address); entry == nil {
+			return nil, domain.ErrNotWatching
+		}
+	}
+
+	if err := s.repo.Remove(ctx, userID, entry.TokenAddress); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("token unwatched", slog.Int64("telegram_id", userID), slog.String("token_address", entry.TokenAddress))
+
+	return entry, nil
+}
+
+// List returns the user's watched tokens, oldest first, with their cached
+// prices and 24h changes. Tokens without a cached price are still listed.
+func (s *Service) List(ctx context.Context, userID int64) ([]Item, error) {
+	entries, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list watchlist: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	addresses := make([]string, len(entries))
+	for i, entry := range entries {
+		addresses[i] = entry.TokenAddress
+	}
+
+	quotes, err := s.prices.GetMany(ctx, addresses)
+	if err != nil {
+		s.log.WarnContext(ctx, "watchlist: failed to load cached prices", slog.Int64("telegram_id", userID), slog.Any("error", err))
+		quotes = nil
+	}
+
+	now := s.now()
+	items := make([]Item, len(entries))
+	for i, entry := range entries {
+		items[i] = Item{Entry: entry}
+
+		quote := quotes[entry.TokenAddress]
+		if quote == nil || quote.PriceUSD.Sign() <= 0 {
+			continue
+		}
+		items[i].PriceUSD = quote.PriceUSD
+		items[i].UpdatedAt = quote.FetchedAt
+		items[i].OpenUSD = s.openPrice(ctx, entry.TokenAddress, now)
+	}
+
+	return items, nil
+}
+
+// openPrice returns the close of the last one-minute candle at the start of
+// the 24h window, or zero when the history has no recent enough candle.
+func (s *Service) openPrice(ctx context.Context, tokenAddress string, now time.Time) money.Decimal {
+	if s.history == nil {
+		return money.Zero
+	}
+
+	start := now.Add(-changeWindow)
+	candle, err := s.history.LastCandleBefore(ctx, tokenAddress, domain.CandleInterval1m, start.Add(time.Minute))
+	if err != nil {
+		s.log.WarnContext(ctx, "watchlist: failed to load price history", slog.String("token_address", tokenAddress), slog.Any("error", err))
+		return money.Zero
+	}
+	if candle == nil || candle.OpenTime.Before(start.Add(-maxReferenceGap)) {
+		return money.Zero
+	}
+
+	return candle.Close
+}
+
+func findEntry(entries []*domain.WatchlistEntry, query string) *domain.WatchlistEntry {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	for _, entry := range entries {
+		if entry.TokenAddress == query {
+			return entry
+		}
+	}
+	for _, entry := range entries {
+		if entry.TokenSymbol != "" && strings.EqualFold(strings.TrimPrefix(query, "$"), entry.TokenSymbol) {
+			return entry
+		}
+	}
+
+	return nil
+}
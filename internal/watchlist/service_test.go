@@ -0,0 +1,181 @@
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/internal/repository"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+type fakeRepo struct {
+	repository.WatchlistRepository
+
+	entries []*domain.WatchlistEntry
+}
+
+func (r *fakeRepo) Add(_ context.Context, entry *domain.WatchlistEntry) error {
+	for _, existing := range r.entries {
+		if existing.TelegramID == entry.TelegramID && existing.TokenAddress == entry.TokenAddress {
+			return domain.ErrAlreadyWatching
+		}
+	}
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeRepo) Remove(_ context.Context, userID int64, tokenAddress string) error {
+	for i, entry := range r.entries {
+		if entry.TelegramID == userID && entry.TokenAddress == tokenAddress {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotWatching
+}
+
+func (r *fakeRepo) ListByUser(_ context.Context, userID int64) ([]*domain.WatchlistEntry, error) {
+	var entries []*domain.WatchlistEntry
+	for _, entry := range r.entries {
+		if entry.TelegramID == userID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+type fakeTokens struct{}
+
+func (fakeTokens) FindToken(_ context.Context, query string) (*domain.Token, error) {
+	if query == "missing" {
+		return nil, market.ErrTokenNotFound
+	}
+	return &domain.Token{Address: strings.ToLower(query) + "-address", Symbol: strings.ToUpper(query)}, nil
+}
+
+type fakeCache map[string]*domain.PriceQuote
+
+func (c fakeCache) GetMany(_ context.Context, addresses []string) (map[string]*domain.PriceQuote, error) {
+	quotes := make(map[string]*domain.PriceQuote)
+	for _, address := range addresses {
+		if quote, ok := c[address]; ok {
+			quotes[address] = quote
+		}
+	}
+	return quotes, nil
+}
+
+type fakeHistory map[string]*domain.Candle
+
+func (h fakeHistory) LastCandleBefore(_ context.Context, tokenAddress string, _ domain.CandleInterval, before time.Time) (*domain.Candle, error) {
+	candle, ok := h[tokenAddress]
+	if !ok || !candle.OpenTime.Before(before) {
+		return nil, nil
+	}
+	return candle, nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func mustDecimal(t *testing.T, value string) money.Decimal {
+	t.Helper()
+
+	d, err := money.Parse(value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return d
+}
+
+func TestServiceWatchAndUnwatch(t *testing.T) {
+	repo := &fakeRepo{}
+	service := NewService(repo, fakeTokens{}, fakeCache{}, nil, discardLogger())
+	ctx := context.Background()
+
+	token, err := service.Watch(ctx, 10, "pepe")
+	if err != nil || token.Address != "pepe-address" {
+		t.Fatalf("watch = %+v, %v", token, err)
+	}
+	if _, err := service.Watch(ctx, 10, "pepe"); !errors.Is(err, domain.ErrAlreadyWatching) {
+		t.Errorf("second watch error = %v", err)
+	}
+	if _, err := service.Watch(ctx, 10, "missing"); !errors.Is(err, market.ErrTokenNotFound) {
+		t.Errorf("unknown token error = %v", err)
+	}
+
+	// Symbols match the watchlist without asking the market.
+	entry, err := service.Unwatch(ctx, 10, "$Pepe")
+	if err != nil || entry.TokenAddress != "pepe-address" {
+		t.Fatalf("unwatch = %+v, %v", entry, err)
+	}
+	if _, err := service.Unwatch(ctx, 10, "pepe"); !errors.Is(err, domain.ErrNotWatching) {
+		t.Errorf("second unwatch error = %v", err)
+	}
+
+	for i := 0; i < MaxEntries; i++ {
+		repo.entries = append(repo.entries, &domain.WatchlistEntry{TelegramID: 20, TokenAddress: strings.Repeat("x", i+1)})
+	}
+	if _, err := service.Watch(ctx, 20, "bonk"); !errors.Is(err, ErrWatchlistFull) {
+		t.Errorf("full watchlist error = %v", err)
+	}
+}
+
+func TestServiceListShowsPriceAndChange(t *testing.T) {
+	now := time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{entries: []*domain.WatchlistEntry{
+		{TelegramID: 10, TokenAddress: "pepe-address", TokenSymbol: "PEPE"},
+		{TelegramID: 10, TokenAddress: "bonk-address", TokenSymbol: "BONK"},
+		{TelegramID: 10, TokenAddress: "wif-address", TokenSymbol: "WIF"},
+		{TelegramID: 10, TokenAddress: "new-address", TokenSymbol: "NEW"},
+	}}
+	cache := fakeCache{
+		"pepe-address": {TokenAddress: "pepe-address", PriceUSD: mustDecimal(t, "1.1"), FetchedAt: now},
+		"bonk-address": {TokenAddress: "bonk-address", PriceUSD: mustDecimal(t, "0.5"), FetchedAt: now},
+		"wif-address":  {TokenAddress: "wif-address", PriceUSD: mustDecimal(t, "3"), FetchedAt: now},
+	}
+	history := fakeHistory{
+		"pepe-address": {OpenTime: now.Add(-24*time.Hour - 20*time.Minute), Close: mustDecimal(t, "1")},
+		"bonk-address": {OpenTime: now.Add(-24 * time.Hour), Close: mustDecimal(t, "0.8")},
+		// Too old to stand for the price a day ago.
+		"wif-address": {OpenTime: now.Add(-30 * time.Hour), Close: mustDecimal(t, "1")},
+	}
+	service := NewService(repo, fakeTokens{}, cache, history, discardLogger())
+	service.now = func() time.Time { return now }
+
+	items, err := service.List(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 4 {
+		t.Fatalf("items = %d, want 4", len(items))
+	}
+
+	want := []struct {
+		price  string
+		change string
+		known  bool
+	}{
+		{"1.1", "10", true},
+		{"0.5", "-37.5", true},
+		{"3", "", false},
+		{"0", "", false},
+	}
+	for i, item := range items {
+		if !item.PriceUSD.Equal(mustDecimal(t, want[i].price)) {
+			t.Errorf("%s price = %s, want %s", item.Entry.TokenSymbol, item.PriceUSD, want[i].price)
+		}
+		change, known := item.Change()
+		if known != want[i].known || (known && !change.Equal(mustDecimal(t, want[i].change))) {
+			t.Errorf("%s change = %s, %t", item.Entry.TokenSymbol, change, known)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+-- 000005_unique_positions.down.sql
+
+DROP INDEX IF EXISTS uq_positions_telegram_id_token_address;
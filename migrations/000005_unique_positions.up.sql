@@ -0,0 +1,4 @@
+-- 000005_unique_positions.up.sql
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_positions_telegram_id_token_address
+    ON positions (telegram_id, token_address);
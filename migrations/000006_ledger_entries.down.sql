@@ -0,0 +1,5 @@
+-- 000006_ledger_entries.down.sql
+
+DROP TRIGGER IF EXISTS ledger_entries_append_only ON ledger_entries;
+DROP FUNCTION IF EXISTS ledger_entries_immutable();
+DROP TABLE IF EXISTS ledger_entries;
@@ -0,0 +1,38 @@
+-- 000006_ledger_entries.up.sql
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT NOT NULL REFERENCES users(telegram_id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('opening', 'trade', 'bonus', 'adjustment', 'reset')),
+    amount DECIMAL(20,8) NOT NULL,
+    balance_after DECIMAL(20,8) NOT NULL CHECK (balance_after >= 0),
+    reference VARCHAR(64),
+    note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_telegram_id
+    ON ledger_entries (telegram_id, id);
+
+-- Ledger entries are append-only. Deletes are only allowed when cascaded from
+-- users (the foreign key trigger runs one level above this one).
+CREATE OR REPLACE FUNCTION ledger_entries_immutable()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND pg_trigger_depth() > 1 THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'ledger_entries are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_append_only
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW
+    EXECUTE FUNCTION ledger_entries_immutable();
+
+-- Seed every existing user with an opening entry so that the ledger sums to
+-- the current balance.
+INSERT INTO ledger_entries (telegram_id, kind, amount, balance_after, reference)
+SELECT telegram_id, 'opening', COALESCE(balance, 0), COALESCE(balance, 0), 'migration:000006'
+FROM users;
@@ -0,0 +1,3 @@
+-- 000007_transactions_history_index.down.sql
+
+DROP INDEX IF EXISTS idx_transactions_telegram_id_created_at;
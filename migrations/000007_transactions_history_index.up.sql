@@ -0,0 +1,4 @@
+-- 000007_transactions_history_index.up.sql
+
+CREATE INDEX IF NOT EXISTS idx_transactions_telegram_id_created_at
+    ON transactions (telegram_id, created_at DESC, id DESC);
@@ -0,0 +1,5 @@
+-- 000008_price_history.down.sql
+
+DROP INDEX IF EXISTS idx_transactions_token_address_created_at;
+DROP TABLE IF EXISTS price_candles;
+DROP TABLE IF EXISTS price_ticks;
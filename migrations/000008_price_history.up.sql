@@ -0,0 +1,30 @@
+-- 000008_price_history.up.sql
+
+CREATE TABLE IF NOT EXISTS price_ticks (
+    id BIGSERIAL PRIMARY KEY,
+    token_address VARCHAR(64) NOT NULL,
+    price_usd DECIMAL(30,18) NOT NULL CHECK (price_usd > 0),
+    source VARCHAR(32) NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_ticks_fetched_at
+    ON price_ticks (fetched_at);
+
+CREATE TABLE IF NOT EXISTS price_candles (
+    token_address VARCHAR(64) NOT NULL,
+    resolution VARCHAR(3) NOT NULL CHECK (resolution IN ('1m', '1h', '1d')),
+    open_time TIMESTAMPTZ NOT NULL,
+    open DECIMAL(30,18) NOT NULL,
+    high DECIMAL(30,18) NOT NULL,
+    low DECIMAL(30,18) NOT NULL,
+    close DECIMAL(30,18) NOT NULL,
+    volume_usd DECIMAL(20,8) NOT NULL DEFAULT 0,
+    tick_count INTEGER NOT NULL CHECK (tick_count > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (token_address, resolution, open_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_token_address_created_at
+    ON transactions (token_address, created_at);
@@ -0,0 +1,3 @@
+-- 000009_job_dead_letters.down.sql
+
+DROP TABLE IF EXISTS job_dead_letters;
@@ -0,0 +1,20 @@
+-- 000009_job_dead_letters.up.sql
+
+CREATE TABLE IF NOT EXISTS job_dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    task_id VARCHAR(64) NOT NULL UNIQUE,
+    task_type VARCHAR(64) NOT NULL,
+    queue VARCHAR(32) NOT NULL,
+    payload BYTEA NOT NULL,
+    error TEXT NOT NULL,
+    error_chain JSONB NOT NULL DEFAULT '[]'::jsonb,
+    retried INTEGER NOT NULL DEFAULT 0,
+    max_retry INTEGER NOT NULL DEFAULT 0,
+    failed_at TIMESTAMPTZ NOT NULL,
+    requeued_at TIMESTAMPTZ,
+    requeued_task_id VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_dead_letters_failed_at
+    ON job_dead_letters (failed_at DESC);
@@ -0,0 +1,6 @@
+-- 000010_orders.down.sql
+
+DROP INDEX IF EXISTS idx_orders_open_expires_at;
+DROP INDEX IF EXISTS idx_orders_telegram_id_status;
+DROP INDEX IF EXISTS idx_orders_open_token_address;
+DROP TABLE IF EXISTS orders;
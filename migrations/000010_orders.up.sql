@@ -0,0 +1,32 @@
+-- 000010_orders.up.sql
+
+CREATE TABLE IF NOT EXISTS orders (
+    id BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT NOT NULL REFERENCES users(telegram_id) ON DELETE CASCADE,
+    side VARCHAR(4) NOT NULL CHECK (side IN ('buy', 'sell')),
+    token_address VARCHAR(64) NOT NULL,
+    token_symbol VARCHAR(32),
+    limit_price DECIMAL(30,18) NOT NULL CHECK (limit_price > 0),
+    amount_usd DECIMAL(20,8) CHECK (amount_usd > 0),
+    percent SMALLINT CHECK (percent BETWEEN 1 AND 100),
+    status VARCHAR(10) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'filled', 'cancelled', 'expired')),
+    expires_at TIMESTAMPTZ,
+    transaction_id BIGINT REFERENCES transactions(id) ON DELETE SET NULL,
+    close_reason VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMPTZ,
+    -- Buys spend a USD amount; sells give up a share of the position.
+    CHECK ((side = 'buy' AND amount_usd IS NOT NULL AND percent IS NULL)
+        OR (side = 'sell' AND percent IS NOT NULL AND amount_usd IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_open_token_address
+    ON orders (token_address)
+    WHERE status = 'open';
+
+CREATE INDEX IF NOT EXISTS idx_orders_telegram_id_status
+    ON orders (telegram_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_orders_open_expires_at
+    ON orders (expires_at)
+    WHERE status = 'open' AND expires_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- 000011_position_exits.down.sql
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS exit_reason;
+DROP INDEX IF EXISTS idx_position_exits_token_address;
+DROP TABLE IF EXISTS position_exits;
@@ -0,0 +1,24 @@
+-- 000011_position_exits.up.sql
+
+-- Stop-loss, take-profit and trailing-stop levels attached to a position.
+-- Rows disappear with the position when it is closed.
+CREATE TABLE IF NOT EXISTS position_exits (
+    position_id BIGINT PRIMARY KEY REFERENCES positions(id) ON DELETE CASCADE,
+    telegram_id BIGINT NOT NULL REFERENCES users(telegram_id) ON DELETE CASCADE,
+    token_address VARCHAR(64) NOT NULL,
+    stop_loss_price DECIMAL(30,18) CHECK (stop_loss_price > 0),
+    take_profit_price DECIMAL(30,18) CHECK (take_profit_price > 0),
+    trailing_percent SMALLINT CHECK (trailing_percent BETWEEN 1 AND 99),
+    trailing_peak_price DECIMAL(30,18) CHECK (trailing_peak_price > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((trailing_percent IS NULL) = (trailing_peak_price IS NULL)),
+    CHECK (stop_loss_price IS NOT NULL OR take_profit_price IS NOT NULL OR trailing_percent IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_position_exits_token_address
+    ON position_exits (token_address);
+
+-- Why an automatic exit closed the position; NULL for manual trades.
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS exit_reason VARCHAR(16)
+    CHECK (exit_reason IN ('stop_loss', 'take_profit', 'trailing_stop'));
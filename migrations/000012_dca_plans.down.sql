@@ -0,0 +1,6 @@
+-- 000012_dca_plans.down.sql
+
+DROP TABLE IF EXISTS dca_runs;
+DROP INDEX IF EXISTS idx_dca_plans_active_next_run_at;
+DROP INDEX IF EXISTS idx_dca_plans_telegram_id;
+DROP TABLE IF EXISTS dca_plans;
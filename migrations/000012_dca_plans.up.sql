@@ -0,0 +1,36 @@
+-- 000012_dca_plans.up.sql
+
+-- Recurring buys. weekday follows Go's time.Weekday (0 = Sunday) and is NULL
+-- for daily plans; minute_of_day is in the owner's settings timezone.
+CREATE TABLE IF NOT EXISTS dca_plans (
+    id BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT NOT NULL REFERENCES users(telegram_id) ON DELETE CASCADE,
+    token_address VARCHAR(64) NOT NULL,
+    token_symbol VARCHAR(32),
+    amount_usd DECIMAL(20,8) NOT NULL CHECK (amount_usd > 0),
+    weekday SMALLINT CHECK (weekday BETWEEN 0 AND 6),
+    minute_of_day SMALLINT NOT NULL CHECK (minute_of_day BETWEEN 0 AND 1439),
+    status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused')),
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dca_plans_telegram_id
+    ON dca_plans (telegram_id);
+
+CREATE INDEX IF NOT EXISTS idx_dca_plans_active_next_run_at
+    ON dca_plans (next_run_at)
+    WHERE status = 'active';
+
+-- One row per scheduled occurrence; the unique key makes task retries idempotent.
+CREATE TABLE IF NOT EXISTS dca_runs (
+    id BIGSERIAL PRIMARY KEY,
+    plan_id BIGINT NOT NULL REFERENCES dca_plans(id) ON DELETE CASCADE,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('executed', 'skipped')),
+    transaction_id BIGINT REFERENCES transactions(id) ON DELETE SET NULL,
+    skip_reason VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (plan_id, scheduled_for)
+);
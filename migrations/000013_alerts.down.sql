@@ -0,0 +1,5 @@
+-- 000013_alerts.down.sql
+
+DROP INDEX IF EXISTS idx_alerts_telegram_id_status;
+DROP INDEX IF EXISTS idx_alerts_active_token_address;
+DROP TABLE IF EXISTS alerts;
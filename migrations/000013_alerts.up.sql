@@ -0,0 +1,33 @@
+-- 000013_alerts.up.sql
+
+-- Price alerts. above/below fire when the price crosses price_level; rise/fall
+-- fire when the price moved by percent over window_seconds. A repeating alert
+-- is disarmed when it fires and re-armed once its condition no longer holds.
+CREATE TABLE IF NOT EXISTS alerts (
+    id BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT NOT NULL REFERENCES users(telegram_id) ON DELETE CASCADE,
+    token_address VARCHAR(64) NOT NULL,
+    token_symbol VARCHAR(32),
+    kind VARCHAR(8) NOT NULL CHECK (kind IN ('above', 'below', 'rise', 'fall')),
+    price_level DECIMAL(30,18) CHECK (price_level > 0),
+    percent SMALLINT CHECK (percent > 0),
+    window_seconds INTEGER CHECK (window_seconds > 0),
+    repeating BOOLEAN NOT NULL DEFAULT FALSE,
+    cooldown_seconds INTEGER NOT NULL DEFAULT 0 CHECK (cooldown_seconds >= 0),
+    armed BOOLEAN NOT NULL DEFAULT TRUE,
+    status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'triggered')),
+    trigger_count INTEGER NOT NULL DEFAULT 0,
+    last_triggered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (
+        (kind IN ('above', 'below') AND price_level IS NOT NULL AND percent IS NULL AND window_seconds IS NULL)
+        OR (kind IN ('rise', 'fall') AND price_level IS NULL AND percent IS NOT NULL AND window_seconds IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_active_token_address
+    ON alerts (token_address)
+    WHERE status = 'active';
+
+CREATE INDEX IF NOT EXISTS idx_alerts_telegram_id_status
+    ON alerts (telegram_id, status);
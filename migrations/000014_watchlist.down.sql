@@ -0,0 +1,4 @@
+-- 000014_watchlist.down.sql
+
+DROP INDEX IF EXISTS idx_watchlist_token_address;
+DROP TABLE IF EXISTS watchlist;
@@ -0,0 +1,14 @@
+-- 000014_watchlist.up.sql
+
+-- Tokens a user follows without holding them. Watched tokens are refreshed by
+-- price:update like held ones.
+CREATE TABLE IF NOT EXISTS watchlist (
+    telegram_id BIGINT NOT NULL REFERENCES users(telegram_id) ON DELETE CASCADE,
+    token_address VARCHAR(64) NOT NULL,
+    token_symbol VARCHAR(32),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (telegram_id, token_address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_token_address
+    ON watchlist (token_address);
@@ -0,0 +1,5 @@
+-- 000015_tokens.down.sql
+
+DROP INDEX IF EXISTS idx_tokens_last_seen_at;
+DROP INDEX IF EXISTS idx_tokens_symbol;
+DROP TABLE IF EXISTS tokens;
@@ -0,0 +1,20 @@
+-- 000015_tokens.up.sql
+
+-- Registry of tokens seen on the price providers, refreshed by tokens:sync
+-- and searched by the buy flow. decimals is NULL while no provider reports it.
+CREATE TABLE IF NOT EXISTS tokens (
+    address VARCHAR(64) PRIMARY KEY,
+    chain VARCHAR(32) NOT NULL DEFAULT '',
+    symbol VARCHAR(32) NOT NULL,
+    name VARCHAR(128) NOT NULL DEFAULT '',
+    decimals SMALLINT CHECK (decimals BETWEEN 0 AND 36),
+    liquidity_usd DECIMAL(30,8) NOT NULL DEFAULT 0 CHECK (liquidity_usd >= 0),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tokens_symbol
+    ON tokens (lower(symbol) text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS idx_tokens_last_seen_at
+    ON tokens (last_seen_at);
@@ -0,0 +1,4 @@
+-- 000016_tokens_listed_at.down.sql
+
+DROP INDEX IF EXISTS idx_tokens_listed_at;
+ALTER TABLE tokens DROP COLUMN IF EXISTS listed_at;
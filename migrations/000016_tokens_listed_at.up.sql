@@ -0,0 +1,9 @@
+-- 000016_tokens_listed_at.up.sql
+
+-- When the token's first pair was created on a DEX, for the "new" tab of the
+-- top tokens screen. NULL while no provider reports it.
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS listed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_tokens_listed_at
+    ON tokens (listed_at DESC)
+    WHERE listed_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- 000017_transactions_execution.down.sql
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS slippage_usd,
+    DROP COLUMN IF EXISTS fee_usd;
@@ -0,0 +1,7 @@
+-- 000017_transactions_execution.up.sql
+
+-- What a trade cost on top of the market price. price_usd holds the fill
+-- price; trades recorded before fees and slippage were modelled keep zeros.
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS fee_usd DECIMAL(20,8) NOT NULL DEFAULT 0 CHECK (fee_usd >= 0),
+    ADD COLUMN IF NOT EXISTS slippage_usd DECIMAL(20,8) NOT NULL DEFAULT 0 CHECK (slippage_usd >= 0);
@@ -0,0 +1,5 @@
+-- 000018_activity_events.down.sql
+
+DROP INDEX IF EXISTS idx_activity_events_created_at;
+DROP INDEX IF EXISTS idx_activity_events_telegram_id_created_at;
+DROP TABLE IF EXISTS activity_events;
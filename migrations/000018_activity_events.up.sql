@@ -0,0 +1,18 @@
+-- 000018_activity_events.up.sql
+
+-- What users did in the bot, one row per action, for support and usage
+-- analysis. Rows expire through the activity_events retention policy of the
+-- data:cleanup job.
+CREATE TABLE IF NOT EXISTS activity_events (
+    id BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT NOT NULL REFERENCES users(telegram_id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL,
+    detail JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_events_telegram_id_created_at
+    ON activity_events (telegram_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_activity_events_created_at
+    ON activity_events (created_at);
@@ -0,0 +1,4 @@
+-- 000019_transactions_archive.down.sql
+
+DROP INDEX IF EXISTS idx_transactions_archive_archived_at;
+DROP TABLE IF EXISTS transactions_archive;
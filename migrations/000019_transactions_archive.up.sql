@@ -0,0 +1,25 @@
+-- 000019_transactions_archive.up.sql
+
+-- Trades moved out of transactions once they no longer matter for history
+-- and PnL. Rows keep their original id and timestamps and expire through the
+-- transactions_archive retention policy of the data:cleanup job, counted
+-- from archived_at. There is no foreign key to users, so the archive
+-- outlives deleted accounts until it expires.
+CREATE TABLE IF NOT EXISTS transactions_archive (
+    id BIGINT PRIMARY KEY,
+    telegram_id BIGINT NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('buy', 'sell')),
+    token_address VARCHAR(64) NOT NULL,
+    amount DECIMAL(30,18) NOT NULL CHECK (amount > 0),
+    price_usd DECIMAL(30,18) NOT NULL CHECK (price_usd > 0),
+    total_usd DECIMAL(20,8) NOT NULL,
+    pnl_usd DECIMAL(20,8),
+    fee_usd DECIMAL(20,8) NOT NULL DEFAULT 0 CHECK (fee_usd >= 0),
+    slippage_usd DECIMAL(20,8) NOT NULL DEFAULT 0 CHECK (slippage_usd >= 0),
+    exit_reason VARCHAR(16) CHECK (exit_reason IN ('stop_loss', 'take_profit', 'trailing_stop')),
+    created_at TIMESTAMPTZ NOT NULL,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_archive_archived_at
+    ON transactions_archive (archived_at);
@@ -0,0 +1,218 @@
+// Package coingecko is a typed client for the CoinGecko token price API.
+package coingecko
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	// apiName identifies CoinGecko in quotes and external API errors.
+	apiName = "coingecko"
+	// maxTokensPerRequest keeps request URLs well below common length limits.
+	maxTokensPerRequest = 50
+	// maxResponseBytes bounds the size of a decoded response body.
+	maxResponseBytes = 4 << 20
+
+	platformEthereum = "ethereum"
+	platformSolana   = "solana"
+)
+
+// Client fetches token prices from CoinGecko. Every request goes through a
+// circuit breaker and is retried on transient failures.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	breaker    *apperrors.CircuitBreaker
+	log        *slog.Logger
+}
+
+var _ market.PriceProvider = (*Client)(nil)
+
+// NewClient constructs a Client for the API rooted at baseURL,
+// for example "https://api.coingecko.com/api/v3".
+func NewClient(baseURL string, timeout time.Duration, log *slog.Logger) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+		breaker:    apperrors.NewCircuitBreaker(),
+		log:        log,
+	}
+}
+
+// Name identifies CoinGecko as a price source.
+func (c *Client) Name() string {
+	return apiName
+}
+
+// Prices returns the USD price of each token CoinGecko lists. Addresses are
+// grouped by platform: 0x-prefixed addresses are looked up on Ethereum and
+// everything else on Solana.
+func (c *Client) Prices(ctx context.Context, tokenAddresses []string) (map[string]*domain.PriceQuote, error) {
+	byPlatform := make(map[string][]string)
+	for _, address := range tokenAddresses {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		platform := platformFor(address)
+		byPlatform[platform] = append(byPlatform[platform], address)
+	}
+
+	fetchedAt := time.Now().UTC()
+	quotes := make(map[string]*domain.PriceQuote, len(tokenAddresses))
+	for platform, addresses := range byPlatform {
+		for start := 0; start < len(addresses); start += maxTokensPerRequest {
+			end := start + maxTokensPerRequest
+			if end > len(addresses) {
+				end = len(addresses)
+			}
+			batch := addresses[start:end]
+
+			prices, err := c.tokenPrices(ctx, platform, batch)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, address := range batch {
+				price, ok := lookup(prices, address)
+				if !ok {
+					continue
+				}
+				quotes[address] = &domain.PriceQuote{
+					TokenAddress: address,
+					PriceUSD:     price,
+					Source:       apiName,
+					FetchedAt:    fetchedAt,
+				}
+			}
+		}
+	}
+
+	return quotes, nil
+}
+
+// tokenPrices calls /simple/token_price/{platform} and returns USD prices keyed as CoinGecko reports them.
+func (c *Client) tokenPrices(ctx context.Context, platform string, addresses []string) (map[string]money.Decimal, error) {
+	query := url.Values{}
+	query.Set("contract_addresses", strings.Join(addresses, ","))
+	query.Set("vs_currencies", "usd")
+
+	var resp map[string]struct {
+		USD json.Number `json:"usd"`
+	}
+	if err := c.get(ctx, "/simple/token_price/"+url.PathEscape(platform)+"?"+query.Encode(), &resp); err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]money.Decimal, len(resp))
+	for address, entry := range resp {
+		if entry.USD == "" {
+			continue
+		}
+		price, err := money.ParseNumber(entry.USD.String())
+		if err != nil || price.Sign() <= 0 {
+			continue
+		}
+		prices[address] = price
+	}
+
+	return prices, nil
+}
+
+// get performs a GET request against path and decodes the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	endpoint := c.baseURL + path
+
+	err := apperrors.WithRetry(ctx, func() error {
+		return c.breaker.Call(func() error {
+			return c.fetch(ctx, endpoint, out)
+		})
+	})
+	if err == nil {
+		return nil
+	}
+
+	if c.log != nil {
+		c.log.WarnContext(ctx, "coingecko request failed", slog.String("url", endpoint), slog.Any("error", err))
+	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	return permanent(err)
+}
+
+func (c *Client) fetch(ctx context.Context, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return permanent(fmt.Errorf("build request: %w", err))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return permanent(ctx.Err())
+		}
+		return apperrors.NewExternalAPIError(apiName, fmt.Errorf("send request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		statusErr := fmt.Errorf("unexpected status %d", resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			return apperrors.NewExternalAPIError(apiName, statusErr)
+		}
+		return permanent(statusErr)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out); err != nil {
+		return permanent(fmt.Errorf("decode response: %w", err))
+	}
+
+	return nil
+}
+
+// permanent wraps cause in a non-retryable external API error.
+func permanent(cause error) error {
+	appErr := apperrors.NewExternalAPIError(apiName, cause)
+	appErr.Retryable = false
+	return appErr
+}
+
+func platformFor(address string) string {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return platformEthereum
+	}
+	return platformSolana
+}
+
+// lookup finds the price for address. CoinGecko lowercases EVM addresses in
+// its response, while Solana addresses keep their case.
+func lookup(prices map[string]money.Decimal, address string) (money.Decimal, bool) {
+	if price, ok := prices[address]; ok {
+		return price, true
+	}
+	if platformFor(address) == platformEthereum {
+		price, ok := prices[strings.ToLower(address)]
+		return price, ok
+	}
+	return money.Zero, false
+}
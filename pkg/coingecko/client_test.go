@@ -0,0 +1,93 @@
+package coingecko
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+)
+
+const (
+	bonkAddress    = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+	wifAddress     = "EKpQGSJtjMFqKZ9KQanSqYXRcF8fBopzLHYxdM65zcjm"
+	solAddress     = "So11111111111111111111111111111111111111112"
+	ethBonkAddress = "0x1151CB3d861920e07a38e03eEAd12C32178567F6"
+)
+
+// newFixtureServer serves the recorded /simple/token_price responses in testdata.
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		platform := strings.TrimPrefix(r.URL.Path, "/simple/token_price/")
+		if platform == r.URL.Path || r.URL.Query().Get("vs_currencies") != "usd" {
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", "token_price_"+platform+".json"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClientPrices(t *testing.T) {
+	client := NewClient(newFixtureServer(t).URL, time.Second, nil)
+
+	quotes, err := client.Prices(context.Background(), []string{bonkAddress, wifAddress, solAddress, ethBonkAddress})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(quotes) != 3 {
+		t.Fatalf("expected 3 quotes, got %d", len(quotes))
+	}
+	if got := quotes[bonkAddress].PriceUSD.String(); got != "0.00002049" {
+		t.Errorf("bonk price = %s", got)
+	}
+	if got := quotes[ethBonkAddress].PriceUSD.String(); got != "0.00002047" {
+		t.Errorf("eth bonk price = %s", got)
+	}
+	if _, ok := quotes[solAddress]; ok {
+		t.Errorf("token without a usd price must be omitted")
+	}
+	if quotes[wifAddress].Source != "coingecko" {
+		t.Errorf("unexpected source %q", quotes[wifAddress].Source)
+	}
+}
+
+func TestClientRateLimitIsRetryable(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, time.Second, nil)
+	_, err := client.Prices(context.Background(), []string{bonkAddress})
+
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || !appErr.Retryable {
+		t.Fatalf("expected retryable external API error, got %v", err)
+	}
+	if attempts.Load() != int32(apperrors.MaxRetries+1) {
+		t.Fatalf("expected %d attempts, got %d", apperrors.MaxRetries+1, attempts.Load())
+	}
+}
@@ -16,6 +16,8 @@ type Config struct {
 	Database  DatabaseConfig  `mapstructure:"database" yaml:"database" validate:"required"`
 	Redis     RedisConfig     `mapstructure:"redis" yaml:"redis" validate:"required"`
 	API       APIConfig       `mapstructure:"api" yaml:"api" validate:"required"`
+	Prices    PricesConfig    `mapstructure:"prices" yaml:"prices"`
+	Trading   TradingConfig   `mapstructure:"trading" yaml:"trading"`
 	Logger    LoggerConfig    `mapstructure:"logging" yaml:"logging" validate:"required"`
 	Sentry    SentryConfig    `mapstructure:"sentry" yaml:"sentry" validate:"required"`
 	RateLimit RateLimitConfig `mapstructure:"ratelimit" yaml:"ratelimit"`
@@ -25,13 +27,15 @@ type Config struct {
 // String returns a masked representation of the configuration.
 func (c Config) String() string {
 	return fmt.Sprintf(
-		"Config{AppEnv:%s, Server:%s, Bot:%s, Database:%s, Redis:%s, API:%s, Logger:%s, Sentry:%s, RateLimit:%s, Jobs:%s}",
+		"Config{AppEnv:%s, Server:%s, Bot:%s, Database:%s, Redis:%s, API:%s, Prices:%s, Trading:%s, Logger:%s, Sentry:%s, RateLimit:%s, Jobs:%s}",
 		c.AppEnv,
 		c.Server.String(),
 		c.Bot.String(),
 		c.Database.String(),
 		c.Redis.String(),
 		c.API.String(),
+		c.Prices.String(),
+		c.Trading.String(),
 		c.Logger.String(),
 		fmt.Sprintf("Sentry{DSN:%s, Enabled:%t}", maskSecret(c.Sentry.DSN), c.Sentry.Enabled),
 		c.RateLimit.String(),
@@ -45,10 +49,13 @@ type ServerConfig struct {
 	MetricsPort  string        `mapstructure:"metrics_port" yaml:"metrics_port" validate:"required"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout" yaml:"read_timeout" validate:"required"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout" yaml:"write_timeout" validate:"required"`
+	// AdminToken guards the /admin endpoints on the metrics port. They are
+	// disabled when it is empty.
+	AdminToken string `mapstructure:"admin_token" yaml:"admin_token"`
 }
 
 func (s ServerConfig) String() string {
-	return fmt.Sprintf("Server{Port:%s, MetricsPort:%s, ReadTimeout:%s, WriteTimeout:%s}", s.Port, s.MetricsPort, s.ReadTimeout, s.WriteTimeout)
+	return fmt.Sprintf("Server{Port:%s, MetricsPort:%s, ReadTimeout:%s, WriteTimeout:%s, AdminToken:%s}", s.Port, s.MetricsPort, s.ReadTimeout, s.WriteTimeout, maskSecret(s.AdminToken))
 }
 
 // BotConfig contains bot-related settings.
@@ -57,15 +64,18 @@ type BotConfig struct {
 	Timeout    time.Duration `mapstructure:"timeout" yaml:"timeout" validate:"required"`
 	Mode       string        `mapstructure:"mode" yaml:"mode" validate:"required"`
 	WebhookURL string        `mapstructure:"webhook_url" yaml:"webhook_url"`
+	// AdminIDs are Telegram user IDs allowed to run admin commands; they also receive operational alerts.
+	AdminIDs []int64 `mapstructure:"admin_ids" yaml:"admin_ids"`
 }
 
 func (b BotConfig) String() string {
 	return fmt.Sprintf(
-		"Bot{Token:%s, Timeout:%s, Mode:%s, WebhookURL:%s}",
+		"Bot{Token:%s, Timeout:%s, Mode:%s, WebhookURL:%s, Admins:%d}",
 		maskSecret(b.Token),
 		b.Timeout,
 		b.Mode,
 		b.WebhookURL,
+		len(b.AdminIDs),
 	)
 }
 
@@ -139,6 +149,33 @@ func (a APIConfig) String() string {
 	return fmt.Sprintf("API{DexScreenerURL:%s, CoinGeckoURL:%s, Timeout:%s}", a.DexScreenerURL, a.CoinGeckoURL, a.Timeout)
 }
 
+// PricesConfig controls how long cached prices live and when they count as stale.
+type PricesConfig struct {
+	TTL        time.Duration `mapstructure:"ttl" yaml:"ttl"`
+	StaleAfter time.Duration `mapstructure:"stale_after" yaml:"stale_after"`
+}
+
+func (p PricesConfig) String() string {
+	return fmt.Sprintf("Prices{TTL:%s, StaleAfter:%s}", p.TTL, p.StaleAfter)
+}
+
+// FeeTierConfig charges FeeBps basis points on orders worth at least MinUSD dollars.
+type FeeTierConfig struct {
+	MinUSD int64 `mapstructure:"min_usd" yaml:"min_usd" validate:"gte=0"`
+	FeeBps int   `mapstructure:"fee_bps" yaml:"fee_bps" validate:"gte=0,lte=1000"`
+}
+
+// TradingConfig controls how paper orders fill. MaxPriceImpactBps rejects
+// orders that would move the price further; zero disables the check.
+type TradingConfig struct {
+	FeeTiers          []FeeTierConfig `mapstructure:"fee_tiers" yaml:"fee_tiers" validate:"dive"`
+	MaxPriceImpactBps int             `mapstructure:"max_price_impact_bps" yaml:"max_price_impact_bps" validate:"gte=0,lte=10000"`
+}
+
+func (t TradingConfig) String() string {
+	return fmt.Sprintf("Trading{FeeTiers:%d, MaxPriceImpactBps:%d}", len(t.FeeTiers), t.MaxPriceImpactBps)
+}
+
 // LoggerConfig contains logging settings.
 type LoggerConfig struct {
 	Level  string `mapstructure:"level" yaml:"level" validate:"required"`
@@ -217,15 +254,37 @@ func (j JobsQueuesConfig) ToMap() map[string]int {
 	return filtered
 }
 
+// CleanupConfig defines the retention policies applied by the data:cleanup job.
+// Retention maps a policy name (usually a table) to how long its rows are kept.
+type CleanupConfig struct {
+	BatchSize int                      `mapstructure:"batch_size" yaml:"batch_size" validate:"gte=0"`
+	Retention map[string]time.Duration `mapstructure:"retention" yaml:"retention"`
+}
+
+// JobScheduleConfig is a cron entry that enqueues a background task.
+// Queue, Timeout and MaxRetries fall back to the task type's defaults when unset.
+type JobScheduleConfig struct {
+	Task       string         `mapstructure:"task" yaml:"task" validate:"required"`
+	Cron       string         `mapstructure:"cron" yaml:"cron" validate:"required"`
+	Queue      string         `mapstructure:"queue" yaml:"queue"`
+	Payload    map[string]any `mapstructure:"payload" yaml:"payload"`
+	Timeout    time.Duration  `mapstructure:"timeout" yaml:"timeout"`
+	MaxRetries *int           `mapstructure:"max_retries" yaml:"max_retries"`
+}
+
 // JobsConfig groups background job scheduler settings.
 type JobsConfig struct {
-	Enabled bool             `mapstructure:"enabled" yaml:"enabled"`
-	Queues  JobsQueuesConfig `mapstructure:"queues" yaml:"queues"`
+	Enabled   bool                     `mapstructure:"enabled" yaml:"enabled"`
+	Backend   string                   `mapstructure:"backend" yaml:"backend" validate:"omitempty,oneof=redis memory"`
+	Queues    JobsQueuesConfig         `mapstructure:"queues" yaml:"queues"`
+	Cleanup   CleanupConfig            `mapstructure:"cleanup" yaml:"cleanup"`
+	Schedules []JobScheduleConfig      `mapstructure:"schedules" yaml:"schedules" validate:"dive"`
+	Timeouts  map[string]time.Duration `mapstructure:"timeouts" yaml:"timeouts"`
 }
 
 func (j JobsConfig) String() string {
-	return fmt.Sprintf("Jobs{Enabled:%t, Queues:{Critical:%d Default:%d Low:%d}}",
-		j.Enabled, j.Queues.Critical, j.Queues.Default, j.Queues.Low)
+	return fmt.Sprintf("Jobs{Enabled:%t, Backend:%s, Queues:{Critical:%d Default:%d Low:%d}, Cleanup:{BatchSize:%d Policies:%d}, Schedules:%d, Timeouts:%d}",
+		j.Enabled, j.Backend, j.Queues.Critical, j.Queues.Default, j.Queues.Low, j.Cleanup.BatchSize, len(j.Cleanup.Retention), len(j.Schedules), len(j.Timeouts))
 }
 
 func maskSecret(value string) string {
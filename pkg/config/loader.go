@@ -2,15 +2,23 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"strings"
 
+	"github.com/fsnotify/fsnotify"
 	validator "github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
+// sectionFiles are optional YAML files whose top-level keys populate one config section.
+var sectionFiles = map[string]string{
+	"jobs": "./configs/jobs.yaml",
+}
+
 // Load reads configuration from YAML files and environment variables, validates it, and returns the resulting Config.
 func Load() (*Config, *viper.Viper, error) {
 	if err := godotenv.Load(".env.local", ".env"); err != nil {
@@ -24,11 +32,22 @@ func Load() (*Config, *viper.Viper, error) {
 	}
 
 	v := viper.New()
-	v.SetConfigFile(fmt.Sprintf("./configs/%s.yaml", env))
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
+	// AutomaticEnv only covers keys viper already knows; secrets have no YAML entry.
+	if err := v.BindEnv("server.admin_token"); err != nil {
+		return nil, nil, fmt.Errorf("bind env: %w", err)
+	}
 
-	if err := v.ReadInConfig(); err != nil {
+	// Section files are merged first so the environment file can override them.
+	for key, path := range sectionFiles {
+		if err := mergeSection(v, key, path); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	v.SetConfigFile(fmt.Sprintf("./configs/%s.yaml", env))
+	if err := v.MergeInConfig(); err != nil {
 		return nil, nil, fmt.Errorf("read config: %w", err)
 	}
 
@@ -45,3 +64,43 @@ func Load() (*Config, *viper.Viper, error) {
 
 	return &cfg, v, nil
 }
+
+// mergeSection reads path and merges its contents under key. A missing file is skipped.
+func mergeSection(v *viper.Viper, key, path string) error {
+	section := viper.New()
+	section.SetConfigFile(path)
+	if err := section.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read %s config: %w", key, err)
+	}
+
+	if err := v.MergeConfigMap(map[string]any{key: section.AllSettings()}); err != nil {
+		return fmt.Errorf("merge %s config: %w", key, err)
+	}
+
+	return nil
+}
+
+// WatchSections watches the section files merged by Load. When one changes it
+// is merged into v again and onChange is called with the event and any merge error.
+func WatchSections(v *viper.Viper, onChange func(event fsnotify.Event, err error)) {
+	for key, path := range sectionFiles {
+		section := viper.New()
+		section.SetConfigFile(path)
+		if err := section.ReadInConfig(); err != nil {
+			continue
+		}
+
+		key := key
+		section.OnConfigChange(func(event fsnotify.Event) {
+			err := v.MergeConfigMap(map[string]any{key: section.AllSettings()})
+			if err != nil {
+				err = fmt.Errorf("merge %s config: %w", key, err)
+			}
+			onChange(event, err)
+		})
+		section.WatchConfig()
+	}
+}
@@ -0,0 +1,385 @@
+// Package dexscreener is a typed client for the DexScreener public API.
+package dexscreener
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Proton-105/himera-bot/internal/domain"
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+	"github.com/Proton-105/himera-bot/internal/market"
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+const (
+	// apiName identifies DexScreener in external API errors.
+	apiName = "dexscreener"
+	// maxTokensPerRequest is the number of addresses the tokens endpoint accepts per call.
+	maxTokensPerRequest = 30
+	// maxResponseBytes bounds the size of a decoded response body.
+	maxResponseBytes = 4 << 20
+)
+
+// Client fetches pairs and prices from DexScreener. Every request goes through a
+// circuit breaker and is retried on transient failures.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	breaker    *apperrors.CircuitBreaker
+	log        *slog.Logger
+}
+
+var (
+	_ market.Source         = (*Client)(nil)
+	_ market.PriceProvider  = (*Client)(nil)
+	_ market.TokenDirectory = (*Client)(nil)
+)
+
+// NewClient constructs a Client for the API rooted at baseURL,
+// for example "https://api.dexscreener.com/latest".
+func NewClient(baseURL string, timeout time.Duration, log *slog.Logger) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+		breaker:    apperrors.NewCircuitBreaker(),
+		log:        log,
+	}
+}
+
+// Name identifies DexScreener as a price source.
+func (c *Client) Name() string {
+	return apiName
+}
+
+// TokenPairs returns every pair trading any of the given token addresses.
+func (c *Client) TokenPairs(ctx context.Context, addresses ...string) ([]Pair, error) {
+	pairs := make([]Pair, 0)
+	for start := 0; start < len(addresses); start += maxTokensPerRequest {
+		end := start + maxTokensPerRequest
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+
+		escaped := make([]string, 0, end-start)
+		for _, address := range addresses[start:end] {
+			if address = strings.TrimSpace(address); address != "" {
+				escaped = append(escaped, url.PathEscape(address))
+			}
+		}
+		if len(escaped) == 0 {
+			continue
+		}
+
+		var resp pairsResponse
+		if err := c.get(ctx, "/dex/tokens/"+strings.Join(escaped, ","), &resp); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, resp.Pairs...)
+	}
+
+	return pairs, nil
+}
+
+// Search returns pairs matching a free-text query such as a symbol or name.
+func (c *Client) Search(ctx context.Context, query string) ([]Pair, error) {
+	var resp pairsResponse
+	if err := c.get(ctx, "/dex/search?q="+url.QueryEscape(strings.TrimSpace(query)), &resp); err != nil {
+		return nil, err
+	}
+
+	return resp.Pairs, nil
+}
+
+// Prices returns the latest USD price of each token, taken from its most liquid pair.
+// Tokens without a priced pair are omitted from the result.
+func (c *Client) Prices(ctx context.Context, addresses []string) (map[string]*domain.PriceQuote, error) {
+	pairs, err := c.TokenPairs(ctx, addresses...)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchedAt := time.Now().UTC()
+	quotes := make(map[string]*domain.PriceQuote, len(addresses))
+	for _, address := range addresses {
+		pair, ok := bestPair(pairs, func(p Pair) bool {
+			return sameAddress(p.BaseToken.Address, address)
+		})
+		if !ok {
+			continue
+		}
+
+		quotes[address] = &domain.PriceQuote{
+			TokenAddress: address,
+			PriceUSD:     pair.PriceUSD,
+			Source:       apiName,
+			FetchedAt:    fetchedAt,
+		}
+	}
+
+	return quotes, nil
+}
+
+// FindToken resolves an address or a symbol to the token of its most liquid pair.
+func (c *Client) FindToken(ctx context.Context, query string) (*domain.Token, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, market.ErrTokenNotFound
+	}
+
+	var (
+		pairs []Pair
+		match func(Pair) bool
+		err   error
+	)
+	if looksLikeAddress(query) {
+		pairs, err = c.TokenPairs(ctx, query)
+		match = func(p Pair) bool { return sameAddress(p.BaseToken.Address, query) }
+	} else {
+		pairs, err = c.Search(ctx, query)
+		match = func(p Pair) bool { return sameSymbol(p.BaseToken.Symbol, query) }
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pair, ok := bestPair(pairs, match)
+	if !ok {
+		return nil, market.ErrTokenNotFound
+	}
+
+	return &domain.Token{
+		Address: pair.BaseToken.Address,
+		Chain:   pair.ChainID,
+		Symbol:  pair.BaseToken.Symbol,
+		Name:    pair.BaseToken.Name,
+	}, nil
+}
+
+// LatestPrice returns the USD price of the token's most liquid pair.
+func (c *Client) LatestPrice(ctx context.Context, tokenAddress string) (*domain.PriceQuote, error) {
+	quotes, err := c.Prices(ctx, []string{tokenAddress})
+	if err != nil {
+		return nil, err
+	}
+
+	quote, ok := quotes[tokenAddress]
+	if !ok {
+		return nil, market.ErrPriceUnavailable
+	}
+
+	return quote, nil
+}
+
+// DescribeTokens returns each token's details from its priced pairs. Tokens
+// without a priced pair are omitted from the result. DexScreener does not
+// report decimals, so Decimals is always nil.
+func (c *Client) DescribeTokens(ctx context.Context, addresses []string) (map[string]*domain.TokenInfo, error) {
+	pairs, err := c.TokenPairs(ctx, addresses...)
+	if err != nil {
+		return nil, err
+	}
+
+	seenAt := time.Now().UTC()
+	tokens := make(map[string]*domain.TokenInfo, len(addresses))
+	for _, address := range addresses {
+		info, ok := describeToken(pairs, address, seenAt)
+		if !ok {
+			continue
+		}
+		tokens[address] = info
+	}
+
+	return tokens, nil
+}
+
+// SearchTokens returns the distinct base tokens of the pairs matching query,
+// in the order DexScreener ranks them.
+func (c *Client) SearchTokens(ctx context.Context, query string) ([]*domain.TokenInfo, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	pairs, err := c.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	seenAt := time.Now().UTC()
+	tokens := make([]*domain.TokenInfo, 0)
+	seen := make(map[string]struct{})
+	for _, pair := range pairs {
+		address := pair.BaseToken.Address
+		if _, ok := seen[address]; ok || address == "" {
+			continue
+		}
+		seen[address] = struct{}{}
+
+		if info, ok := describeToken(pairs, address, seenAt); ok {
+			tokens = append(tokens, info)
+		}
+	}
+
+	return tokens, nil
+}
+
+// get performs a GET request against path and decodes the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	endpoint := c.baseURL + path
+
+	err := apperrors.WithRetry(ctx, func() error {
+		return c.breaker.Call(func() error {
+			return c.fetch(ctx, endpoint, out)
+		})
+	})
+	if err == nil {
+		return nil
+	}
+
+	if c.log != nil {
+		c.log.WarnContext(ctx, "dexscreener request failed", slog.String("url", endpoint), slog.Any("error", err))
+	}
+
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	// Context cancellation and an open circuit are not worth retrying at a higher level either.
+	return permanent(err)
+}
+
+func (c *Client) fetch(ctx context.Context, endpoint string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return permanent(fmt.Errorf("build request: %w", err))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return permanent(ctx.Err())
+		}
+		return apperrors.NewExternalAPIError(apiName, fmt.Errorf("send request: %w", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		statusErr := fmt.Errorf("unexpected status %d", resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			return apperrors.NewExternalAPIError(apiName, statusErr)
+		}
+		return permanent(statusErr)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out); err != nil {
+		return permanent(fmt.Errorf("decode response: %w", err))
+	}
+
+	return nil
+}
+
+// permanent wraps cause in a non-retryable external API error.
+func permanent(cause error) error {
+	appErr := apperrors.NewExternalAPIError(apiName, cause)
+	appErr.Retryable = false
+	return appErr
+}
+
+// bestPair returns the matching pair with a positive price and the deepest USD liquidity.
+func bestPair(pairs []Pair, match func(Pair) bool) (Pair, bool) {
+	var (
+		best  Pair
+		found bool
+	)
+
+	for _, pair := range pairs {
+		if !match(pair) || pair.PriceUSD.Sign() <= 0 {
+			continue
+		}
+		if !found || pair.LiquidityUSD().Cmp(best.LiquidityUSD()) > 0 {
+			best, found = pair, true
+		}
+	}
+
+	return best, found
+}
+
+// describeToken builds the token's details from its priced pairs: the name and
+// symbol of the most liquid one, the liquidity of all of them and the creation
+// time of the oldest.
+func describeToken(pairs []Pair, address string, seenAt time.Time) (*domain.TokenInfo, bool) {
+	match := func(p Pair) bool { return sameAddress(p.BaseToken.Address, address) }
+
+	best, ok := bestPair(pairs, match)
+	if !ok {
+		return nil, false
+	}
+
+	liquidity := money.Zero
+	var listedAt time.Time
+	for _, pair := range pairs {
+		if !match(pair) || pair.PriceUSD.Sign() <= 0 {
+			continue
+		}
+		liquidity = liquidity.Add(pair.LiquidityUSD())
+		if pair.PairCreatedAt > 0 {
+			if created := time.UnixMilli(pair.PairCreatedAt).UTC(); listedAt.IsZero() || created.Before(listedAt) {
+				listedAt = created
+			}
+		}
+	}
+
+	return &domain.TokenInfo{
+		Token: domain.Token{
+			Address: best.BaseToken.Address,
+			Chain:   best.ChainID,
+			Symbol:  best.BaseToken.Symbol,
+			Name:    best.BaseToken.Name,
+		},
+		LiquidityUSD: liquidity,
+		ListedAt:     listedAt,
+		LastSeenAt:   seenAt,
+	}, true
+}
+
+// sameAddress compares token addresses. EVM addresses are case-insensitive hex,
+// while Solana base58 addresses must match exactly.
+func sameAddress(a, b string) bool {
+	if strings.HasPrefix(a, "0x") || strings.HasPrefix(a, "0X") {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func looksLikeAddress(query string) bool {
+	return len(query) >= 32 && !strings.ContainsAny(query, " \t")
+}
+
+// decimalFromNumber converts a JSON number to a Decimal, treating unparsable values as zero.
+func decimalFromNumber(n json.Number) money.Decimal {
+	if n == "" {
+		return money.Zero
+	}
+
+	if d, err := money.ParseNumber(n.String()); err == nil {
+		return d
+	}
+
+	return money.Zero
+}
+
+// sameSymbol compares ticker symbols case-insensitively, ignoring a leading "$".
+func sameSymbol(a, b string) bool {
+	return strings.EqualFold(strings.TrimPrefix(a, "$"), strings.TrimPrefix(b, "$"))
+}
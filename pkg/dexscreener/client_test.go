@@ -0,0 +1,216 @@
+package dexscreener
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apperrors "github.com/Proton-105/himera-bot/internal/errors"
+	"github.com/Proton-105/himera-bot/internal/market"
+)
+
+const (
+	bonkAddress = "DezXAZ8z7PnrnRJjz3wXBoRgixCa6xjnB7YaB1pPB263"
+	wifAddress  = "EKpQGSJtjMFqKZ9KQanSqYXRcF8fBopzLHYxdM65zcjm"
+)
+
+// newFixtureServer serves the recorded responses in testdata for the endpoints the client uses.
+func newFixtureServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		var fixture string
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/dex/tokens/"):
+			fixture = "tokens.json"
+		case r.URL.Path == "/dex/search" && r.URL.Query().Get("q") != "":
+			fixture = "search.json"
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Errorf("read fixture: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestClientPrices(t *testing.T) {
+	server, _ := newFixtureServer(t)
+	client := NewClient(server.URL, time.Second, nil)
+
+	quotes, err := client.Prices(context.Background(), []string{bonkAddress, wifAddress, "missing"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(quotes) != 2 {
+		t.Fatalf("expected 2 quotes, got %d", len(quotes))
+	}
+	// The most liquid pair wins; the unpriced WIF pair is skipped despite its liquidity.
+	if got := quotes[bonkAddress].PriceUSD.String(); got != "0.00002051" {
+		t.Errorf("bonk price = %s", got)
+	}
+	if got := quotes[wifAddress].PriceUSD.String(); got != "2.231" {
+		t.Errorf("wif price = %s", got)
+	}
+	if quotes[bonkAddress].Source != "dexscreener" || quotes[bonkAddress].FetchedAt.IsZero() {
+		t.Errorf("unexpected quote metadata: %+v", quotes[bonkAddress])
+	}
+}
+
+func TestClientFindToken(t *testing.T) {
+	server, _ := newFixtureServer(t)
+	client := NewClient(server.URL, time.Second, nil)
+	ctx := context.Background()
+
+	bySymbol, err := client.FindToken(ctx, "bonk")
+	if err != nil {
+		t.Fatalf("FindToken(symbol): %v", err)
+	}
+	if bySymbol.Address != bonkAddress || bySymbol.Chain != "solana" || bySymbol.Name != "Bonk" {
+		t.Errorf("unexpected token: %+v", bySymbol)
+	}
+
+	byAddress, err := client.FindToken(ctx, wifAddress)
+	if err != nil {
+		t.Fatalf("FindToken(address): %v", err)
+	}
+	if byAddress.Symbol != "$WIF" {
+		t.Errorf("unexpected token: %+v", byAddress)
+	}
+
+	if _, err := client.FindToken(ctx, "nothing-matches"); !errors.Is(err, market.ErrTokenNotFound) {
+		t.Errorf("expected ErrTokenNotFound, got %v", err)
+	}
+}
+
+func TestClientDescribeTokens(t *testing.T) {
+	server, _ := newFixtureServer(t)
+	client := NewClient(server.URL, time.Second, nil)
+	ctx := context.Background()
+
+	tokens, err := client.DescribeTokens(ctx, []string{bonkAddress, wifAddress, "missing"})
+	if err != nil {
+		t.Fatalf("DescribeTokens: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(tokens))
+	}
+	// Liquidity adds up the priced pairs only.
+	if got := tokens[bonkAddress].LiquidityUSD.String(); got != "5935801.90" {
+		t.Errorf("bonk liquidity = %s", got)
+	}
+	// The oldest pair dates the listing.
+	if got := tokens[bonkAddress].ListedAt; !got.Equal(time.UnixMilli(1671998400000)) {
+		t.Errorf("bonk listed at %s", got)
+	}
+	if got := tokens[wifAddress]; got.LiquidityUSD.String() != "10234567.3" || got.Name != "dogwifhat" || got.Decimals != nil {
+		t.Errorf("unexpected wif token: %+v", got)
+	}
+
+	found, err := client.SearchTokens(ctx, "bonk")
+	if err != nil {
+		t.Fatalf("SearchTokens: %v", err)
+	}
+	if len(found) != 3 || found[0].Address != bonkAddress || found[1].Chain != "ethereum" {
+		t.Errorf("unexpected search results: %+v", found)
+	}
+}
+
+func TestClientLatestPriceUnavailable(t *testing.T) {
+	server, _ := newFixtureServer(t)
+	client := NewClient(server.URL, time.Second, nil)
+
+	if _, err := client.LatestPrice(context.Background(), "So11111111111111111111111111111111111111112"); !errors.Is(err, market.ErrPriceUnavailable) {
+		t.Fatalf("expected ErrPriceUnavailable, got %v", err)
+	}
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	fixture, _ := newFixtureServer(t)
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		http.Redirect(w, r, fixture.URL+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, time.Second, nil)
+	if _, err := client.LatestPrice(context.Background(), bonkAddress); err != nil {
+		t.Fatalf("unexpected error after retries: %v", err)
+	}
+	if attempts.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts.Load())
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(server.URL, time.Second, nil)
+	_, err := client.TokenPairs(context.Background(), bonkAddress)
+
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code != "E300" || appErr.Retryable {
+		t.Fatalf("expected non-retryable external API error, got %v", err)
+	}
+	if attempts.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", attempts.Load())
+	}
+}
+
+func TestClientBatchesTokenRequests(t *testing.T) {
+	server, requests := newFixtureServer(t)
+	client := NewClient(server.URL, time.Second, nil)
+
+	addresses := make([]string, maxTokensPerRequest+1)
+	for i := range addresses {
+		addresses[i] = bonkAddress
+	}
+
+	if _, err := client.TokenPairs(context.Background(), addresses...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestSameSymbol(t *testing.T) {
+	if !sameSymbol("$WIF", "wif") || !sameSymbol("Bonk", "$BONK") {
+		t.Error("expected symbols to match ignoring case and a leading $")
+	}
+	if sameSymbol("BONK", "BONKINU") {
+		t.Error("expected distinct symbols not to match")
+	}
+}
@@ -0,0 +1,56 @@
+package dexscreener
+
+import (
+	"encoding/json"
+
+	"github.com/Proton-105/himera-bot/pkg/money"
+)
+
+// Pair is a DEX trading pair as reported by DexScreener.
+type Pair struct {
+	ChainID       string        `json:"chainId"`
+	DexID         string        `json:"dexId"`
+	URL           string        `json:"url"`
+	PairAddress   string        `json:"pairAddress"`
+	BaseToken     Token         `json:"baseToken"`
+	QuoteToken    Token         `json:"quoteToken"`
+	PriceNative   string        `json:"priceNative"`
+	PriceUSD      money.Decimal `json:"priceUsd"`
+	Liquidity     Liquidity     `json:"liquidity"`
+	Volume        Window        `json:"volume"`
+	PriceChange   Window        `json:"priceChange"`
+	FDV           json.Number   `json:"fdv"`
+	PairCreatedAt int64         `json:"pairCreatedAt"`
+}
+
+// LiquidityUSD returns the pair's USD liquidity, or zero when unknown.
+func (p Pair) LiquidityUSD() money.Decimal {
+	return decimalFromNumber(p.Liquidity.USD)
+}
+
+// Token identifies one side of a pair.
+type Token struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Symbol  string `json:"symbol"`
+}
+
+// Liquidity is the pair's pooled liquidity.
+type Liquidity struct {
+	USD   json.Number `json:"usd"`
+	Base  json.Number `json:"base"`
+	Quote json.Number `json:"quote"`
+}
+
+// Window holds rolling-window statistics keyed by period.
+type Window struct {
+	M5  json.Number `json:"m5"`
+	H1  json.Number `json:"h1"`
+	H6  json.Number `json:"h6"`
+	H24 json.Number `json:"h24"`
+}
+
+type pairsResponse struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Pairs         []Pair `json:"pairs"`
+}
@@ -19,11 +19,16 @@ func CorrelationIDFromContext(ctx context.Context) string {
 	return ""
 }
 
+// WithCorrelationID returns a copy of ctx carrying the correlation identifier.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
 // Middleware injects a correlation identifier into the request context before delegating to the next handler.
 func Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		correlationID := uuid.NewString()
-		ctxWithID := context.WithValue(r.Context(), correlationIDKey{}, correlationID)
+		ctxWithID := WithCorrelationID(r.Context(), correlationID)
 		next.ServeHTTP(w, r.WithContext(ctxWithID))
 	})
 }
@@ -45,6 +45,41 @@ var (
 			Help: "Current number of active users",
 		},
 	)
+	ledgerDiscrepancies = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ledger_discrepancies",
+			Help: "Number of users whose balance differs from the sum of their ledger entries",
+		},
+	)
+	cleanupRowsDeletedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cleanup_rows_deleted_total",
+			Help: "Total number of rows removed by the data cleanup job labeled by table",
+		},
+		[]string{"table"},
+	)
+	jobsProcessedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "jobs_processed_total",
+			Help: "Total number of background tasks processed labeled by task type and outcome",
+		},
+		[]string{"task_type", "outcome"},
+	)
+	jobDurationSeconds = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "job_duration_seconds",
+			Help:    "Duration of background tasks in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"task_type", "outcome"},
+	)
+	leaderStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "leader_status",
+			Help: "Whether this instance holds the leader lease (1) or not (0) labeled by lease",
+		},
+		[]string{"lease"},
+	)
 	usersByState = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "users_by_state",
@@ -59,6 +94,9 @@ var trackedStates = []state.State{
 	state.StateBuyingSearch,
 	state.StateBuyingAmount,
 	state.StateBuyingConfirm,
+	state.StateSellingSelect,
+	state.StateSellingPercent,
+	state.StateSellingConfirm,
 	state.StateError,
 }
 
@@ -108,6 +146,43 @@ func SetActiveUsers(count int) {
 	activeUsers.Set(float64(count))
 }
 
+// SetLedgerDiscrepancies updates the gauge for users failing ledger reconciliation.
+func SetLedgerDiscrepancies(count int) {
+	ledgerDiscrepancies.Set(float64(count))
+}
+
+// RecordJob counts a processed background task and records its duration.
+func RecordJob(taskType, outcome string, duration time.Duration) {
+	if taskType == "" {
+		taskType = "unknown"
+	}
+	if outcome == "" {
+		outcome = "unknown"
+	}
+
+	jobsProcessedTotal.WithLabelValues(taskType, outcome).Inc()
+	jobDurationSeconds.WithLabelValues(taskType, outcome).Observe(duration.Seconds())
+}
+
+// AddCleanupRowsDeleted records rows removed from a table by the cleanup job.
+func AddCleanupRowsDeleted(table string, rows int64) {
+	if table == "" {
+		table = "unknown"
+	}
+
+	cleanupRowsDeletedTotal.WithLabelValues(table).Add(float64(rows))
+}
+
+// SetLeader records whether this instance holds the named leader lease.
+func SetLeader(lease string, leader bool) {
+	value := 0.0
+	if leader {
+		value = 1
+	}
+
+	leaderStatus.WithLabelValues(lease).Set(value)
+}
+
 // SetUsersByState updates the gauge for the given state.
 func SetUsersByState(state string, count int) {
 	if state == "" {
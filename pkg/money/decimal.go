@@ -0,0 +1,373 @@
+// Package money provides exact fixed-point decimals and currency-tagged amounts.
+//
+// Values never pass through float64. Every operation that can lose precision
+// (division, rescaling, multiplying money) takes an explicit RoundingMode.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExponent bounds exponents accepted by ParseNumber.
+const maxExponent = 1000
+
+var (
+	// ErrInvalidDecimal is returned when a string is not a plain decimal number.
+	ErrInvalidDecimal = errors.New("invalid decimal")
+	// ErrDivisionByZero is returned when dividing by a zero Decimal.
+	ErrDivisionByZero = errors.New("division by zero")
+)
+
+// RoundingMode selects how values are rounded when digits must be dropped.
+type RoundingMode int
+
+const (
+	// RoundDown truncates toward zero.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundHalfUp rounds to the nearest neighbour, ties away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest neighbour, ties to the even digit.
+	RoundHalfEven
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor
+	// RoundCeiling rounds toward positive infinity.
+	RoundCeiling
+)
+
+// Decimal is an immutable fixed-point number equal to unscaled × 10^-scale.
+// The zero value is 0 with scale 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int32
+}
+
+// Zero is the Decimal 0.
+var Zero = Decimal{}
+
+// New returns unscaled × 10^-scale, e.g. New(1250, 2) is 12.50.
+func New(unscaled int64, scale int32) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+	return Decimal{unscaled: big.NewInt(unscaled), scale: scale}
+}
+
+// NewFromInt returns the integer value with scale 0.
+func NewFromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+// Parse reads a plain decimal such as "-12.340". Exponents, fractions and
+// thousand separators are rejected. The scale equals the number of fraction digits.
+func Parse(value string) (Decimal, error) {
+	s := strings.TrimSpace(value)
+	if s == "" {
+		return Zero, fmt.Errorf("%w %q", ErrInvalidDecimal, value)
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasPoint := strings.Cut(s, ".")
+	if intPart == "" || (hasPoint && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return Zero, fmt.Errorf("%w %q", ErrInvalidDecimal, value)
+	}
+
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return Zero, fmt.Errorf("%w %q", ErrInvalidDecimal, value)
+	}
+	if negative {
+		unscaled.Neg(unscaled)
+	}
+
+	return Decimal{unscaled: unscaled, scale: int32(len(fracPart))}, nil
+}
+
+// ParseNumber reads a decimal that may use exponent notation, such as the
+// "2.05e-05" JSON encoders emit for small floats. The conversion is exact.
+func ParseNumber(value string) (Decimal, error) {
+	s := strings.TrimSpace(value)
+	cut := strings.IndexAny(s, "eE")
+	if cut < 0 {
+		return Parse(s)
+	}
+
+	mantissa, err := Parse(s[:cut])
+	if err != nil {
+		return Zero, fmt.Errorf("%w %q", ErrInvalidDecimal, value)
+	}
+
+	exponent, err := strconv.ParseInt(s[cut+1:], 10, 32)
+	if err != nil || exponent > maxExponent || exponent < -maxExponent {
+		return Zero, fmt.Errorf("%w %q", ErrInvalidDecimal, value)
+	}
+
+	scale := int64(mantissa.scale) - exponent
+	if scale >= 0 {
+		return Decimal{unscaled: new(big.Int).Set(mantissa.int()), scale: int32(scale)}, nil
+	}
+
+	return Decimal{unscaled: new(big.Int).Mul(mantissa.int(), pow10(int32(-scale))), scale: 0}, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return d.unscaled
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d equals 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares d and other numerically, ignoring scale.
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := align(d, other)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and other are numerically equal.
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{unscaled: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	return Decimal{unscaled: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Add returns d + other exactly, using the larger scale.
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{unscaled: a.Add(a, b), scale: maxScale(d, other)}
+}
+
+// Sub returns d - other exactly, using the larger scale.
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{unscaled: a.Sub(a, b), scale: maxScale(d, other)}
+}
+
+// Mul returns d × other exactly; the result scale is the sum of both scales.
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{
+		unscaled: new(big.Int).Mul(d.int(), other.int()),
+		scale:    d.scale + other.scale,
+	}
+}
+
+// Quo returns d ÷ other rounded to scale digits with mode.
+func (d Decimal) Quo(other Decimal, scale int32, mode RoundingMode) (Decimal, error) {
+	if other.IsZero() {
+		return Zero, ErrDivisionByZero
+	}
+	if scale < 0 {
+		scale = 0
+	}
+
+	// d/other = (a × 10^-sa) / (b × 10^-sb); scaled by 10^scale this is
+	// (a × 10^(sb+scale)) / (b × 10^sa).
+	numerator := new(big.Int).Mul(d.int(), pow10(other.scale+scale))
+	denominator := new(big.Int).Mul(other.int(), pow10(d.scale))
+
+	return Decimal{unscaled: roundQuo(numerator, denominator, mode), scale: scale}, nil
+}
+
+// Round returns d with exactly scale fraction digits, rounding with mode when
+// digits are dropped and padding with zeros otherwise.
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale < 0 {
+		scale = 0
+	}
+
+	switch {
+	case scale == d.scale:
+		return Decimal{unscaled: new(big.Int).Set(d.int()), scale: scale}
+	case scale > d.scale:
+		return Decimal{unscaled: new(big.Int).Mul(d.int(), pow10(scale-d.scale)), scale: scale}
+	default:
+		return Decimal{unscaled: roundQuo(d.int(), pow10(d.scale-scale), mode), scale: scale}
+	}
+}
+
+// String renders d with exactly Scale() fraction digits, e.g. "-0.050".
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		cut := len(digits) - int(d.scale)
+		digits = digits[:cut] + "." + digits[cut:]
+	}
+
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// StringFixed rounds d to places digits with mode and renders it.
+func (d Decimal) StringFixed(places int32, mode RoundingMode) string {
+	return d.Round(places, mode).String()
+}
+
+// StringTrimmed rounds d to places fraction digits and renders it without
+// trailing fraction zeros, e.g. "12.5" or "3". It is meant for display.
+func (d Decimal) StringTrimmed(places int32, mode RoundingMode) string {
+	value := d.StringFixed(places, mode)
+	if !strings.Contains(value, ".") {
+		return value
+	}
+
+	value = strings.TrimRight(value, "0")
+	return strings.TrimSuffix(value, ".")
+}
+
+// Value implements driver.Valuer; decimals are sent to the database as text.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner for NUMERIC/DECIMAL columns. NULL and floating
+// point values are rejected; use COALESCE for nullable columns.
+func (d *Decimal) Scan(src any) error {
+	var raw string
+	switch v := src.(type) {
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	case int64:
+		*d = NewFromInt(v)
+		return nil
+	case nil:
+		return fmt.Errorf("%w: NULL", ErrInvalidDecimal)
+	default:
+		return fmt.Errorf("%w: unsupported source type %T", ErrInvalidDecimal, src)
+	}
+
+	parsed, err := Parse(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalJSON encodes d as a JSON string to avoid float conversion by decoders.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a JSON string or number in plain decimal notation;
+// null leaves d unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	raw := strings.Trim(string(data), `"`)
+	parsed, err := Parse(raw)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	scale := maxScale(a, b)
+	x := new(big.Int).Mul(a.int(), pow10(scale-a.scale))
+	y := new(big.Int).Mul(b.int(), pow10(scale-b.scale))
+	return x, y
+}
+
+func maxScale(a, b Decimal) int32 {
+	if a.scale > b.scale {
+		return a.scale
+	}
+	return b.scale
+}
+
+func pow10(n int32) *big.Int {
+	if n <= 0 {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundQuo divides numerator by denominator and rounds the integer quotient with mode.
+func roundQuo(numerator, denominator *big.Int, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	direction := int64(numerator.Sign() * denominator.Sign())
+	twiceRemainder := new(big.Int).Abs(remainder)
+	twiceRemainder.Lsh(twiceRemainder, 1)
+	half := twiceRemainder.Cmp(new(big.Int).Abs(denominator))
+
+	increment := false
+	switch mode {
+	case RoundDown:
+	case RoundUp:
+		increment = true
+	case RoundHalfUp:
+		increment = half >= 0
+	case RoundHalfEven:
+		increment = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+	case RoundFloor:
+		increment = direction < 0
+	case RoundCeiling:
+		increment = direction > 0
+	}
+
+	if increment {
+		quotient.Add(quotient, big.NewInt(direction))
+	}
+
+	return quotient
+}
@@ -0,0 +1,203 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "0", want: "0"},
+		{input: "12.340", want: "12.340"},
+		{input: "-0.05", want: "-0.05"},
+		{input: "+7", want: "7"},
+		{input: " 1.5 ", want: "1.5"},
+		{input: "", wantErr: true},
+		{input: ".5", wantErr: true},
+		{input: "5.", wantErr: true},
+		{input: "1e3", wantErr: true},
+		{input: "1/3", wantErr: true},
+		{input: "1,5", wantErr: true},
+		{input: "--1", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := Parse(tc.input)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidDecimal) {
+					t.Fatalf("expected ErrInvalidDecimal, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("Parse(%q) = %s, want %s", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	testCases := map[string]string{
+		"2.05e-05": "0.0000205",
+		"1E3":      "1000",
+		"-1.5e+2":  "-150",
+		"12.5":     "12.5",
+	}
+
+	for input, want := range testCases {
+		got, err := ParseNumber(input)
+		if err != nil {
+			t.Errorf("ParseNumber(%q): %v", input, err)
+			continue
+		}
+		if got.String() != want {
+			t.Errorf("ParseNumber(%q) = %s, want %s", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"1e", "e5", "1.5e1.5", "1e99999"} {
+		if _, err := ParseNumber(input); !errors.Is(err, ErrInvalidDecimal) {
+			t.Errorf("ParseNumber(%q) error = %v, want ErrInvalidDecimal", input, err)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := mustParse(t, "1.25")
+	b := mustParse(t, "0.375")
+
+	if got := a.Add(b).String(); got != "1.625" {
+		t.Errorf("Add = %s", got)
+	}
+	if got := b.Sub(a).String(); got != "-0.875" {
+		t.Errorf("Sub = %s", got)
+	}
+	if got := a.Mul(b).String(); got != "0.46875" {
+		t.Errorf("Mul = %s", got)
+	}
+	if a.Cmp(mustParse(t, "1.250000")) != 0 {
+		t.Errorf("Cmp should ignore scale")
+	}
+	if got := Zero.Add(a).String(); got != "1.25" {
+		t.Errorf("zero value Add = %s", got)
+	}
+
+	quo, err := NewFromInt(1).Quo(NewFromInt(3), 4, RoundHalfEven)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quo.String() != "0.3333" {
+		t.Errorf("Quo = %s", quo)
+	}
+
+	if _, err := a.Quo(Zero, 2, RoundDown); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("expected ErrDivisionByZero, got %v", err)
+	}
+}
+
+func TestDecimalRound(t *testing.T) {
+	testCases := []struct {
+		input string
+		mode  RoundingMode
+		want  string
+	}{
+		{input: "2.345", mode: RoundDown, want: "2.34"},
+		{input: "-2.345", mode: RoundDown, want: "-2.34"},
+		{input: "2.341", mode: RoundUp, want: "2.35"},
+		{input: "-2.341", mode: RoundUp, want: "-2.35"},
+		{input: "2.345", mode: RoundHalfUp, want: "2.35"},
+		{input: "-2.345", mode: RoundHalfUp, want: "-2.35"},
+		{input: "2.345", mode: RoundHalfEven, want: "2.34"},
+		{input: "2.355", mode: RoundHalfEven, want: "2.36"},
+		{input: "-2.345", mode: RoundHalfEven, want: "-2.34"},
+		{input: "2.349", mode: RoundFloor, want: "2.34"},
+		{input: "-2.341", mode: RoundFloor, want: "-2.35"},
+		{input: "2.341", mode: RoundCeiling, want: "2.35"},
+		{input: "-2.349", mode: RoundCeiling, want: "-2.34"},
+		{input: "2.3", mode: RoundDown, want: "2.30"},
+		{input: "0.004", mode: RoundHalfUp, want: "0.00"},
+	}
+
+	for _, tc := range testCases {
+		got := mustParse(t, tc.input).Round(2, tc.mode).String()
+		if got != tc.want {
+			t.Errorf("Round(%s, mode %d) = %s, want %s", tc.input, tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestDecimalStringTrimmed(t *testing.T) {
+	testCases := []struct {
+		input  string
+		places int32
+		mode   RoundingMode
+		want   string
+	}{
+		{input: "12.500", places: 2, mode: RoundHalfUp, want: "12.5"},
+		{input: "3.000", places: 2, mode: RoundHalfUp, want: "3"},
+		{input: "0.000012345", places: 8, mode: RoundHalfUp, want: "0.00001235"},
+		{input: "-1.2349", places: 3, mode: RoundDown, want: "-1.234"},
+		{input: "0.004", places: 2, mode: RoundHalfUp, want: "0"},
+		{input: "1500", places: 0, mode: RoundDown, want: "1500"},
+	}
+
+	for _, tc := range testCases {
+		got := mustParse(t, tc.input).StringTrimmed(tc.places, tc.mode)
+		if got != tc.want {
+			t.Errorf("StringTrimmed(%s, %d) = %s, want %s", tc.input, tc.places, got, tc.want)
+		}
+	}
+}
+
+func TestDecimalSQLAndJSON(t *testing.T) {
+	var d Decimal
+	if err := d.Scan([]byte("10000.00000000")); err != nil {
+		t.Fatalf("unexpected scan error: %v", err)
+	}
+	if d.String() != "10000.00000000" {
+		t.Errorf("Scan = %s", d)
+	}
+
+	if err := d.Scan(1.5); err == nil {
+		t.Errorf("expected float scan to fail")
+	}
+	if err := d.Scan(nil); err == nil {
+		t.Errorf("expected NULL scan to fail")
+	}
+
+	value, err := mustParse(t, "-3.50").Value()
+	if err != nil || value != "-3.50" {
+		t.Errorf("Value = %v, %v", value, err)
+	}
+
+	encoded, err := json.Marshal(mustParse(t, "0.10"))
+	if err != nil || string(encoded) != `"0.10"` {
+		t.Fatalf("MarshalJSON = %s, %v", encoded, err)
+	}
+
+	var decoded Decimal
+	if err := json.Unmarshal(encoded, &decoded); err != nil || decoded.String() != "0.10" {
+		t.Errorf("UnmarshalJSON = %s, %v", decoded, err)
+	}
+}
+
+func mustParse(t *testing.T, value string) Decimal {
+	t.Helper()
+
+	d, err := Parse(value)
+	if err != nil {
+		t.Fatalf("parse %q: %v", value, err)
+	}
+	return d
+}
@@ -0,0 +1,207 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrCurrencyMismatch is returned when combining amounts in different currencies.
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Currency is an ISO-4217-style currency code.
+type Currency string
+
+// USD is the settlement currency of the paper-trading account.
+const USD Currency = "USD"
+
+// currencyScales holds the number of fraction digits amounts are stored with.
+// USD keeps 8 digits to match the DECIMAL(20,8) balance columns.
+var currencyScales = map[Currency]int32{
+	USD: 8,
+}
+
+// Scale returns the storage precision of the currency.
+func (c Currency) Scale() int32 {
+	if scale, ok := currencyScales[c]; ok {
+		return scale
+	}
+	return 8
+}
+
+// Money is an amount in a specific currency, always held at the currency scale.
+// The zero value is 0 with no currency; it adopts the currency of the first
+// operand it is combined with.
+type Money struct {
+	amount   Decimal
+	currency Currency
+}
+
+// NewMoney rounds amount to the currency scale using mode.
+func NewMoney(amount Decimal, currency Currency, mode RoundingMode) Money {
+	return Money{amount: amount.Round(currency.Scale(), mode), currency: currency}
+}
+
+// ParseMoney parses a plain decimal amount. It rejects values with more
+// fraction digits than the currency supports instead of silently rounding.
+func ParseMoney(value string, currency Currency) (Money, error) {
+	amount, err := Parse(value)
+	if err != nil {
+		return Money{}, err
+	}
+	if amount.Scale() > currency.Scale() {
+		return Money{}, fmt.Errorf("%w %q: more than %d fraction digits", ErrInvalidDecimal, value, currency.Scale())
+	}
+
+	return NewMoney(amount, currency, RoundDown), nil
+}
+
+// ZeroOf returns a zero amount in the currency.
+func ZeroOf(currency Currency) Money {
+	return NewMoney(Zero, currency, RoundDown)
+}
+
+// Amount returns the decimal amount.
+func (m Money) Amount() Decimal {
+	return m.amount
+}
+
+// Currency returns the currency code.
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// Sign returns -1, 0 or +1.
+func (m Money) Sign() int {
+	return m.amount.Sign()
+}
+
+// IsZero reports whether the amount is 0.
+func (m Money) IsZero() bool {
+	return m.amount.IsZero()
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return Money{amount: m.amount.Neg(), currency: m.currency}
+}
+
+// Abs returns |m|.
+func (m Money) Abs() Money {
+	return Money{amount: m.amount.Abs(), currency: m.currency}
+}
+
+// Add returns m + other. Both amounts must share a currency.
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := commonCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.amount.Add(other.amount), currency, RoundDown), nil
+}
+
+// Sub returns m - other. Both amounts must share a currency.
+func (m Money) Sub(other Money) (Money, error) {
+	currency, err := commonCurrency(m, other)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(m.amount.Sub(other.amount), currency, RoundDown), nil
+}
+
+// Cmp compares m and other. Both amounts must share a currency.
+func (m Money) Cmp(other Money) (int, error) {
+	if _, err := commonCurrency(m, other); err != nil {
+		return 0, err
+	}
+	return m.amount.Cmp(other.amount), nil
+}
+
+// Mul multiplies m by a dimensionless factor and rounds with mode.
+func (m Money) Mul(factor Decimal, mode RoundingMode) Money {
+	return NewMoney(m.amount.Mul(factor), m.currency, mode)
+}
+
+// Quo divides m by a dimensionless divisor and rounds with mode.
+func (m Money) Quo(divisor Decimal, mode RoundingMode) (Money, error) {
+	amount, err := m.amount.Quo(divisor, m.currency.Scale(), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, currency: m.currency}, nil
+}
+
+// StringFixed renders the amount with places fraction digits, without the currency.
+func (m Money) StringFixed(places int32, mode RoundingMode) string {
+	return m.amount.StringFixed(places, mode)
+}
+
+// String renders the amount followed by the currency code, e.g. "12.50000000 USD".
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.amount.String()
+	}
+	return m.amount.String() + " " + string(m.currency)
+}
+
+// Value implements driver.Valuer; only the amount is stored.
+func (m Money) Value() (driver.Value, error) {
+	return m.amount.Round(m.currency.Scale(), RoundDown).String(), nil
+}
+
+// Scan implements sql.Scanner. Money columns carry no currency, so the
+// receiver's currency is kept, defaulting to USD.
+func (m *Money) Scan(src any) error {
+	var amount Decimal
+	if err := amount.Scan(src); err != nil {
+		return err
+	}
+
+	currency := m.currency
+	if currency == "" {
+		currency = USD
+	}
+
+	*m = NewMoney(amount, currency, RoundDown)
+	return nil
+}
+
+type moneyJSON struct {
+	Amount   Decimal  `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount":"…","currency":"…"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.amount, Currency: m.currency})
+}
+
+// UnmarshalJSON decodes the representation produced by MarshalJSON.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var decoded moneyJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if decoded.Currency == "" {
+		*m = Money{amount: decoded.Amount}
+		return nil
+	}
+
+	*m = NewMoney(decoded.Amount, decoded.Currency, RoundDown)
+	return nil
+}
+
+func commonCurrency(a, b Money) (Currency, error) {
+	switch {
+	case a.currency == b.currency:
+		return a.currency, nil
+	case a.currency == "":
+		return b.currency, nil
+	case b.currency == "":
+		return a.currency, nil
+	default:
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a.currency, b.currency)
+	}
+}
@@ -0,0 +1,83 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMoneyArithmetic(t *testing.T) {
+	a := NewMoney(mustParse(t, "10.5"), USD, RoundDown)
+	b := NewMoney(mustParse(t, "0.25"), USD, RoundDown)
+
+	sum, err := a.Add(b)
+	if err != nil || sum.String() != "10.75000000 USD" {
+		t.Fatalf("Add = %s, %v", sum, err)
+	}
+
+	diff, err := b.Sub(a)
+	if err != nil || diff.StringFixed(2, RoundHalfUp) != "-10.25" {
+		t.Fatalf("Sub = %s, %v", diff, err)
+	}
+
+	fromZero, err := Money{}.Add(a)
+	if err != nil || fromZero.Currency() != USD {
+		t.Fatalf("zero value Add = %s, %v", fromZero, err)
+	}
+
+	if _, err := a.Add(NewMoney(Zero, Currency("EUR"), RoundDown)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+
+	third, err := a.Quo(NewFromInt(3), RoundHalfEven)
+	if err != nil || third.Amount().String() != "3.50000000" {
+		t.Fatalf("Quo = %s, %v", third, err)
+	}
+
+	product := a.Mul(mustParse(t, "0.000000001"), RoundHalfUp)
+	if product.Amount().String() != "0.00000001" {
+		t.Fatalf("Mul = %s", product)
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	m, err := ParseMoney("25.5", USD)
+	if err != nil || m.Amount().String() != "25.50000000" {
+		t.Fatalf("ParseMoney = %s, %v", m, err)
+	}
+
+	if _, err := ParseMoney("0.000000001", USD); !errors.Is(err, ErrInvalidDecimal) {
+		t.Fatalf("expected precision error, got %v", err)
+	}
+}
+
+func TestMoneySQLAndJSON(t *testing.T) {
+	var m Money
+	if err := m.Scan([]byte("9999.12345678")); err != nil {
+		t.Fatalf("unexpected scan error: %v", err)
+	}
+	if m.Currency() != USD || m.Amount().String() != "9999.12345678" {
+		t.Fatalf("Scan = %s", m)
+	}
+
+	value, err := m.Value()
+	if err != nil || value != "9999.12345678" {
+		t.Fatalf("Value = %v, %v", value, err)
+	}
+
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if string(encoded) != `{"amount":"9999.12345678","currency":"USD"}` {
+		t.Fatalf("MarshalJSON = %s", encoded)
+	}
+
+	var decoded Money
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if cmp, err := decoded.Cmp(m); err != nil || cmp != 0 {
+		t.Fatalf("round trip mismatch: %s vs %s", decoded, m)
+	}
+}